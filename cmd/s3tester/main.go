@@ -1,15 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/s3-bucket-tester/s3tester/pkg/checker"
 	"github.com/s3-bucket-tester/s3tester/pkg/config"
+	"github.com/s3-bucket-tester/s3tester/pkg/history"
+	"github.com/s3-bucket-tester/s3tester/pkg/i18n"
+	"github.com/s3-bucket-tester/s3tester/pkg/notify"
+	"github.com/s3-bucket-tester/s3tester/pkg/options"
 	"github.com/s3-bucket-tester/s3tester/pkg/output"
+	"github.com/s3-bucket-tester/s3tester/pkg/proxy"
 	"github.com/s3-bucket-tester/s3tester/pkg/remediation"
+	"github.com/s3-bucket-tester/s3tester/pkg/sts"
 )
 
 // Version is set via ldflags at build time
@@ -23,12 +33,59 @@ const (
 )
 
 func main() {
-	// Parse command-line flags
-	cfg, err := config.ParseFlags(os.Args[1:])
+	config.Version = version
+
+	command, args, err := config.SplitCommand(os.Args[1:])
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(ExitCodeConfig)
 	}
+	switch command {
+	case "cert-watch":
+		// Dedicated certificate expiry watch mode
+		os.Exit(runCertWatch(args))
+	case "bench":
+		// Upload/download throughput benchmark mode
+		os.Exit(runBench(args))
+	case "compare":
+		// Same checks against several endpoints, side by side
+		os.Exit(runCompare(args))
+	case "policy":
+		os.Exit(runPolicy(args))
+	case "history":
+		os.Exit(runHistory(args))
+	case "providers":
+		if err := config.ParseProvidersFlags(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(ExitCodeConfig)
+		}
+		config.ListProviders()
+		os.Exit(ExitCodeSuccess)
+	case "version":
+		fmt.Printf("s3-bucket-tester version %s\n", version)
+		os.Exit(ExitCodeSuccess)
+	case "help":
+		topic := ""
+		if len(args) > 0 {
+			topic = args[0]
+		}
+		if err := config.PrintHelp(topic); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(ExitCodeConfig)
+		}
+		os.Exit(ExitCodeSuccess)
+	}
+
+	// Parse command-line flags
+	cfg, err := config.ParseFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exitConfigError(nagiosRequested(args), err)
+	}
+
+	if cfg.ASCII {
+		output.SetASCII()
+	}
 
 	// Validate configuration
 	if cfg.Verbose {
@@ -36,7 +93,7 @@ func main() {
 	}
 	if err := cfg.Validate(); err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
-		os.Exit(ExitCodeConfig)
+		exitConfigError(cfg.Nagios, err)
 	}
 
 	// Print debug information about detected provider
@@ -59,21 +116,52 @@ func main() {
 		}
 	}
 
-	warningCount := 0
-	if cfg.Warning != "" {
-		warningCount = strings.Count(cfg.Warning, "\n") + 1
-	}
 	if cfg.Verbose {
-		fmt.Fprintf(os.Stderr, "DEBUG: After validation, WarningCount=%d\n", warningCount)
+		fmt.Fprintf(os.Stderr, "DEBUG: After validation, WarningCount=%d\n", len(cfg.Warnings))
+	}
+
+	// Load the report to compare with before anything is sent
+	var baseline *output.Baseline
+	if cfg.Baseline != "" {
+		baseline, err = output.LoadBaseline(cfg.Baseline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			exitConfigError(cfg.Nagios, err)
+		}
+	}
+
+	// Open the history database up front, so a run is not wasted on a path
+	// that cannot be written
+	var store *history.Store
+	if cfg.History != "" {
+		store, err = history.Open(cfg.History)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			exitConfigError(cfg.Nagios, err)
+		}
+		defer store.Close()
 	}
 
-	// Print warning if any (only in verbose mode)
-	if cfg.Verbose && cfg.Warning != "" {
-		fmt.Fprintf(os.Stderr, "\n%s\n", cfg.Warning)
+	// Assume a role and run all checks with its temporary credentials
+	var assumedRole *output.AssumedRoleInfo
+	if cfg.RoleArn != "" {
+		assumedRole, err = assumeRole(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to assume role %s: %v\n", cfg.RoleArn, err)
+			os.Exit(ExitCodeError)
+		}
+	}
+
+	// Fail fast on expired session credentials instead of an ExpiredToken
+	// error from every check
+	if err := cfg.CheckCredentialExpiry(time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitCodeError)
 	}
 
 	// Convert to output config
 	outputConfig := cfg.ToOutputConfig()
+	outputConfig.AssumedRole = assumedRole
 
 	// Extract hostname and port from endpoint
 	hostname := checker.ParseHostname(cfg.Endpoint)
@@ -84,70 +172,960 @@ func main() {
 		Config:    outputConfig,
 		StartTime: time.Now(),
 		Results:   make([]output.TestResult, 0, 5), // Up to 5 tests if policy check is enabled
+		Metadata:  output.NewRunMetadata(version, hostname, port, cfg.IPFamily),
+	}
+
+	// The oneline format and Nagios mode print nothing but the final status
+	// line on stdout, and a JSON or YAML report on stdout nothing but the
+	// report
+	oneline := cfg.Format == "oneline"
+	quiet := oneline || cfg.Nagios || cfg.ReportToStdout()
+
+	// SIGINT or SIGTERM cancels the in-flight checks, records the remaining
+	// ones as skipped and prints the partial report; a second signal exits
+	// immediately. In watch mode it ends the loop and prints the aggregated
+	// report.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	// Prime DNS caches and the endpoint before anything is measured
+	if cfg.Warmup {
+		report.Warmup = checker.Warmup(checker.WithOperationLabel(ctx, "Warm-up"), outputConfig)
+	}
+
+	interval := time.Duration(cfg.Interval) * time.Second
+	if cfg.Watch {
+		if !quiet {
+			fmt.Printf("Watching %s every %v, press Ctrl+C to stop and print the aggregated report\n\n", outputConfig.Target(), interval)
+		}
+	}
+
+	// Run tests, repeating the suite when a reliability sample is requested
+	runs := make([][]output.TestResult, 0, cfg.Repeat)
+	cleanRuns := 0
+watch:
+	for run := 1; cfg.Watch || run <= cfg.Repeat; run++ {
+		report.Results = make([]output.TestResult, 0, 5)
+		runStart := time.Now()
+		expired := runTests(ctx, report, hostname, port, cfg.CheckPolicy)
+
+		// A run cut short by a signal is only reported when it is the only one
+		if ctx.Err() != nil && len(runs) > 0 {
+			report.Results = runs[len(runs)-1]
+			if !quiet {
+				fmt.Println()
+			}
+			break
+		}
+
+		output.ApplySLO(report.Results, cfg.SLO)
+		runs = append(runs, report.Results)
+
+		// Record every complete run, each --watch and --repeat run included
+		if store != nil && ctx.Err() == nil {
+			if err := store.Record(outputConfig, runStart, time.Now(), report.Results); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to record the run in %s: %v\n", cfg.History, err)
+			}
+		}
+
+		// Alert on a changed bucket policy, ACL or Block Public Access setting
+		if cfg.NotifyWebhook != "" {
+			notifyPolicyChange(cfg, report)
+		}
+
+		summary := output.NewTestSummary(report.Results)
+		if summary.Failed == 0 {
+			cleanRuns++
+		}
+
+		if cfg.Watch {
+			if !quiet {
+				printWatchStatus(run, report.Results, summary, time.Since(runStart), cleanRuns)
+			}
+			if expired {
+				break watch
+			}
+			select {
+			case <-ctx.Done():
+				if !quiet {
+					fmt.Println()
+				}
+				break watch
+			case <-time.After(interval):
+			}
+		} else if cfg.Repeat > 1 && !quiet {
+			fmt.Printf("Run %d/%d: %d passed, %d failed, %d warnings (%v)\n",
+				run, cfg.Repeat, summary.Passed, summary.Failed, summary.Warnings, time.Since(runStart).Round(time.Millisecond))
+		}
+
+		// Later runs would fail the same way
+		if expired || ctx.Err() != nil {
+			break
+		}
+	}
+	if len(runs) > 1 {
+		report.Repeat = output.NewRepeatReport(runs)
+		if !quiet {
+			fmt.Println()
+		}
 	}
 
-	// Run tests
-	runTests(report, hostname, port, cfg.CheckPolicy)
+	// Record that a read-only run sent no write requests
+	if cfg.ReadOnly {
+		report.SideEffectFree = checker.WriteRequests() == 0
+	}
+
+	if cfg.SlowThresholdMs > 0 {
+		report.SlowestOperations = checker.SlowestOperations()
+	}
 
 	// Calculate summary
 	report.EndTime = time.Now()
 	report.Duration = report.EndTime.Sub(report.StartTime)
 	report.Summary = output.NewTestSummary(report.Results)
+	if baseline != nil {
+		report.Baseline = output.CompareBaseline(baseline, report)
+	}
+
+	// Everything printed or written for sharing uses the anonymized report;
+	// the IAM policy and the support bundle need the real names
+	shared := report
+	if cfg.Anonymize {
+		shared = output.Anonymize(report)
+	}
 
-	// Print console output (always)
-	output.PrintConsole(report)
+	// Print the report to stdout
+	nagiosState := output.NagiosOK
+	switch {
+	case cfg.Nagios:
+		nagiosState = output.PrintNagios(shared, ctx.Err() != nil)
+	case oneline:
+		output.PrintOneLine(shared)
+	case cfg.ReportToStdout():
+		if err := writeReport(shared, cfg.OutputFormat, ""); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write %s report: %v\n", cfg.OutputFormat, err)
+			os.Exit(ExitCodeError)
+		}
+	default:
+		output.PrintConsole(shared)
+	}
 
-	// Print JSON output if output file is specified
+	// Write the report file if an output file is specified
 	if cfg.OutputFile != "" {
-		if err := output.PrintJSON(report, cfg.OutputFile); err != nil {
-			fmt.Fprintf(os.Stderr, "\nWarning: Failed to write JSON output: %v\n", err)
-		} else {
-			fmt.Printf("\nJSON output saved to: %s\n", cfg.OutputFile)
+		format := cfg.OutputFormat
+		if format == "" {
+			format = "json"
+		}
+		if err := writeReport(shared, format, cfg.OutputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: Failed to write %s output: %v\n", formatName(format), err)
+		} else if !quiet {
+			fmt.Printf("\n%s output saved to: %s\n", formatName(format), cfg.OutputFile)
+		}
+	}
+
+	// Write the least-privilege IAM policy for the checks that were run
+	if cfg.EmitPolicy != "" {
+		policy := checker.RequiredPolicy(report.Config, cfg.CheckPolicy)
+		if err := output.WriteIAMPolicy(policy, cfg.EmitPolicy); err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: Failed to write IAM policy: %v\n", err)
+		} else if cfg.EmitPolicy != "-" && !quiet {
+			fmt.Printf("\nIAM policy saved to: %s\n", cfg.EmitPolicy)
+		}
+	}
+
+	// Collect everything the provider's support asks for into one zip
+	if cfg.SupportBundle != "" {
+		data := checker.CollectSupportData(context.Background(), report.Config)
+		if err := output.WriteSupportBundle(report, data, cfg.SupportBundle); err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: Failed to write support bundle: %v\n", err)
+		} else if !quiet {
+			fmt.Printf("\nSupport bundle saved to: %s\n", cfg.SupportBundle)
+		}
+	}
+
+	// Archive the report and apply the retention policy
+	if cfg.ReportDir != "" {
+		policy := output.RetentionPolicy{
+			Keep:   cfg.ReportKeep,
+			MaxAge: time.Duration(cfg.ReportMaxAge) * 24 * time.Hour,
+		}
+		path, pruned, err := output.ArchiveReport(shared, cfg.ReportDir, policy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: Failed to archive report: %v\n", err)
+		}
+		if path != "" && !quiet {
+			fmt.Printf("\nReport archived to: %s\n", path)
+		}
+		if len(pruned) > 0 && !quiet {
+			fmt.Printf("Removed %d old report(s) from %s\n", len(pruned), cfg.ReportDir)
 		}
 	}
 
 	// Print remediations for failed tests
-	printRemediations(report.Results)
+	if !quiet {
+		printRemediations(shared)
+	}
 
-	// Exit with appropriate code
+	// Exit with appropriate code; an interrupted run is incomplete, except in
+	// watch mode where the signal is the normal way to stop. Nagios mode uses
+	// the plugin exit codes instead.
+	if cfg.Nagios {
+		os.Exit(nagiosState)
+	}
+	if ctx.Err() != nil && !cfg.Watch {
+		fmt.Fprintln(os.Stderr, "Interrupted: the report is partial, checks that did not complete are marked SKIP")
+		os.Exit(ExitCodeError)
+	}
+	// Failures already present in the baseline are accepted
+	if cfg.FailOnRegression {
+		if len(report.Baseline.Regressions) > 0 {
+			os.Exit(ExitCodeFailed)
+		}
+		os.Exit(ExitCodeSuccess)
+	}
 	if report.Summary.Failed > 0 {
 		os.Exit(ExitCodeFailed)
 	}
+	if report.Repeat != nil {
+		for _, stats := range report.Repeat.Checks {
+			if stats.Failed > 0 {
+				os.Exit(ExitCodeFailed)
+			}
+		}
+	}
 	os.Exit(ExitCodeSuccess)
 }
 
-// runTests runs all tests and populates the report
-func runTests(report *output.TestReport, hostname string, port int, checkPolicy bool) {
+// writeReport writes the report in a machine-readable format to a file, or to
+// stdout when outputFile is empty
+func writeReport(report *output.TestReport, format, outputFile string) error {
+	switch format {
+	case "junit":
+		return output.PrintJUnit(report, outputFile)
+	case "yaml":
+		return output.PrintYAML(report, outputFile)
+	default:
+		return output.PrintJSON(report, outputFile)
+	}
+}
+
+// formatName returns the display name of a report format
+func formatName(format string) string {
+	switch format {
+	case "junit":
+		return "JUnit"
+	case "yaml":
+		return "YAML"
+	default:
+		return "JSON"
+	}
+}
+
+// exitConfigError exits after a configuration error, in Nagios mode with an
+// UNKNOWN status line since the checks never ran
+func exitConfigError(nagios bool, err error) {
+	if nagios {
+		fmt.Printf("S3 UNKNOWN - configuration error: %v\n", err)
+		os.Exit(output.NagiosUnknown)
+	}
+	os.Exit(ExitCodeConfig)
+}
+
+// nagiosRequested reports whether --nagios is among the arguments, for
+// errors raised before the flags are parsed
+func nagiosRequested(args []string) bool {
+	for _, arg := range args {
+		if arg == "--nagios" || arg == "--nagios=true" {
+			return true
+		}
+	}
+	return false
+}
+
+// printWatchStatus prints the condensed status line of one watch run with the
+// rolling share of runs without failures
+func printWatchStatus(run int, results []output.TestResult, summary output.TestSummary, elapsed time.Duration, cleanRuns int) {
+	status := "OK"
+	var failed []string
+	for _, result := range results {
+		if result.Status == output.StatusFail {
+			failed = append(failed, result.TestName)
+		}
+	}
+	if len(failed) > 0 {
+		status = "FAIL " + strings.Join(failed, ", ")
+	} else if summary.Warnings > 0 {
+		status = "WARN"
+	}
+
+	fmt.Printf("[%s] Run %d: %s (%d passed, %d failed, %d warnings, %v) | %d/%d runs clean (%.1f%%)\n",
+		time.Now().Format("15:04:05"), run, status, summary.Passed, summary.Failed, summary.Warnings,
+		elapsed.Round(time.Millisecond), cleanRuns, run, float64(cleanRuns)*100/float64(run))
+}
+
+// notifyPolicyChange posts a notification when the policy fingerprint check
+// found that the bucket's access settings changed since the previous run
+func notifyPolicyChange(cfg *config.Config, report *output.TestReport) {
+	for _, result := range report.Results {
+		details, ok := result.Details.(output.PolicyFingerprintResult)
+		if !ok || len(details.Changes) == 0 {
+			continue
+		}
+
+		changed := make([]string, 0, len(details.Changes))
+		for _, change := range details.Changes {
+			changed = append(changed, change.Component)
+		}
+		notifier := notify.NewWebhookNotifier(cfg.NotifyWebhook, time.Duration(cfg.Timeout)*time.Second)
+		err := notifier.Notify(notify.Notification{
+			Event:    "policy-change",
+			Severity: notify.SeverityWarning,
+			Title:    fmt.Sprintf("Access settings of %s changed", report.Config.Target()),
+			Message:  fmt.Sprintf("%s changed since the previous check", strings.Join(changed, ", ")),
+			Details:  details,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to send notification: %v\n", err)
+		}
+	}
+}
+
+// runTests runs all tests and populates the report. It stops early and
+// returns true when the temporary credentials expire during the run. Once ctx
+// is cancelled, the remaining checks are recorded as skipped, and with
+// --budget so are the checks that no longer fit in it.
+func runTests(ctx context.Context, report *output.TestReport, hostname string, port int, checkPolicy bool) bool {
+	budget := checker.NewBudget(report.Config)
+
+	// Each run starts from --region, so a wrong one is reported every run
+	if report.Config.ConfiguredRegion != "" {
+		report.Config.Region, report.Config.ConfiguredRegion = report.Config.ConfiguredRegion, ""
+	}
+	report.Config.ObjectOwnership = ""
+
 	// Test 1: DNS Resolution Check
-	dnsChecker := checker.NewDNSChecker(report.Config, hostname)
-	dnsResult := dnsChecker.Check()
-	report.Results = append(report.Results, dnsResult)
+	runCheck(ctx, report, checker.NewDNSChecker(report.Config, hostname), budget.Core())
 
 	// Test 2: TCP Connectivity Check
-	tcpChecker := checker.NewTCPChecker(report.Config, hostname, port)
-	tcpResult := tcpChecker.Check()
-	report.Results = append(report.Results, tcpResult)
+	runCheck(ctx, report, checker.NewTCPChecker(report.Config, hostname, port), budget.Core())
 
 	// Test 3: SSL/TLS Certificate Check (continue even if failed)
-	tlsChecker := checker.NewTLSChecker(report.Config, hostname, port)
-	tlsResult := tlsChecker.Check()
-	report.Results = append(report.Results, tlsResult)
+	runCheck(ctx, report, checker.NewTLSChecker(report.Config, hostname, port), budget.Core())
 
 	// Test 4: Bucket Authentication Check
-	authChecker := checker.NewAuthChecker(report.Config)
-	authResult := authChecker.Check()
-	report.Results = append(report.Results, authResult)
+	if credentialsExpired(report) {
+		return true
+	}
+	runCheck(ctx, report, checker.NewAuthChecker(report.Config), budget.Core())
+	adoptDetectedRegion(report)
 
-	// Test 5: Bucket Policy & ACL Check (optional)
+	// Test 5: Bucket Policy & ACL Check (optional). The Object Ownership
+	// Check, the first optional check, runs before it, so the ACL check is
+	// passed whether ACLs are disabled.
+	if runOptionalCheck(ctx, report, budget, checker.Registry[0]) {
+		return true
+	}
 	if checkPolicy {
-		policyChecker := checker.NewPolicyChecker(report.Config)
-		policyResult := policyChecker.Check()
-		report.Results = append(report.Results, policyResult)
+		if credentialsExpired(report) {
+			return true
+		}
+		runCheck(ctx, report, checker.NewPolicyChecker(report.Config), budget.Core())
+	}
+
+	// The other optional checks
+	for _, reg := range checker.Registry[1:] {
+		if runOptionalCheck(ctx, report, budget, reg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runOptionalCheck runs an optional check if it is enabled; read-only mode
+// disables every checker that would write to the bucket and records it as
+// skipped. It returns true when the temporary credentials have expired.
+func runOptionalCheck(ctx context.Context, report *output.TestReport, budget *checker.Budget, reg checker.Registration) bool {
+	if !reg.Enabled(report.Config) {
+		return false
+	}
+	if report.Config.ReadOnly && reg.Mutates(report.Config) {
+		report.AddResult(output.TestResult{
+			TestName: reg.Name,
+			Status:   output.StatusSkip,
+			Error:    "disabled by --read-only (this check writes to the bucket)",
+		})
+		return false
+	}
+	if ctx.Err() != nil {
+		report.AddResult(interruptedResult(reg.Name))
+		return false
+	}
+	if credentialsExpired(report) {
+		return true
+	}
+	blocked := func(name string) bool { return failedPrerequisite(report, name) != "" }
+	runCheck(ctx, report, reg.New(report.Config), budget.Optional(reg, blocked))
+	adoptObjectOwnership(report)
+	return false
+}
+
+// adoptDetectedRegion signs the later checks of the run for the region the
+// authentication check found the bucket in, when that was not --region, and
+// keeps --region as ConfiguredRegion for the Bucket Location Check. The
+// endpoint stays the configured one, which the results are recorded for.
+func adoptDetectedRegion(report *output.TestReport) {
+	last := report.Results[len(report.Results)-1]
+	if details, ok := last.Details.(output.AuthResult); ok && details.DetectedRegion != "" {
+		report.Config.ConfiguredRegion = report.Config.Region
+		report.Config.Region = details.DetectedRegion
+	}
+}
+
+// adoptObjectOwnership passes the Object Ownership setting the ownership
+// check read on to the later checks of the run, which skip their ACL probes
+// when ACLs are disabled
+func adoptObjectOwnership(report *output.TestReport) {
+	last := report.Results[len(report.Results)-1]
+	if details, ok := last.Details.(output.OwnershipResult); ok {
+		report.Config.ObjectOwnership = details.ObjectOwnership
+	}
+}
+
+// runCheck runs a check and records its result. A check cancelled while it
+// was running, or not started because the run was already cancelled, is
+// recorded as skipped rather than as a failure, and so is a check that
+// depends on one that failed. With a budget, the check runs for at most its
+// allowance; one left too little time is skipped, and an optional check cut
+// off by its allowance is skipped too. A core check cut off keeps the
+// timeout failure, since a connection that does not complete within the
+// budget is what a budgeted run is meant to catch.
+func runCheck(ctx context.Context, report *output.TestReport, c checker.Checker, allowance checker.Allowance) {
+	if ctx.Err() != nil {
+		report.AddResult(interruptedResult(c.Name()))
+		return
+	}
+	if failed := failedPrerequisite(report, c.Name()); failed != "" {
+		report.AddResult(output.TestResult{
+			TestName:       c.Name(),
+			Status:         output.StatusSkip,
+			Error:          fmt.Sprintf("not run because %s failed", failed),
+			SkippedBecause: failed,
+		})
+		return
+	}
+
+	if allowance.Total > 0 && allowance.Limit < options.MinBudgetShare {
+		report.AddResult(output.TestResult{
+			TestName: c.Name(),
+			Status:   output.StatusSkip,
+			Error: fmt.Sprintf("not run: only %s of the %s --budget was left for it",
+				budgetDuration(allowance.Limit), budgetDuration(allowance.Total)),
+		})
+		return
+	}
+
+	checkCtx := checker.WithOperationLabel(ctx, c.Name())
+	if allowance.Total > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(checkCtx, allowance.Limit)
+		defer cancel()
+	}
+	result := c.Check(checkCtx)
+
+	// Retry transient failures with exponential backoff
+	var attemptErrors []string
+	delay := time.Duration(report.Config.RetryDelayMs) * time.Millisecond
+	for retry := 1; retry <= report.Config.Retries && checker.IsTransient(result); retry++ {
+		attemptErrors = append(attemptErrors, result.Error)
+		select {
+		case <-checkCtx.Done():
+		case <-time.After(checker.RetryBackoff(delay, retry)):
+		}
+		if checkCtx.Err() != nil {
+			break
+		}
+		result = c.Check(checkCtx)
+	}
+	if report.Config.Retries > 0 {
+		result.Attempts = len(attemptErrors) + 1
+		result.AttemptErrors = attemptErrors
+	}
+
+	result.SlowOperations = checker.TakeSlowOperations(c.Name())
+	result.RateLimit = checker.TakeRateLimit(c.Name())
+	result.FailureKind = checker.ClassifyFailure(result)
+	cleanupFailures := checker.TakeCleanupFailures(c.Name())
+	if ctx.Err() != nil {
+		interrupted := interruptedResult(c.Name())
+		interrupted.Duration = result.Duration
+		interrupted.Details = result.Details
+		result = interrupted
+	} else if allowance.Optional && checkCtx.Err() != nil {
+		result = output.TestResult{
+			TestName: c.Name(),
+			Status:   output.StatusSkip,
+			Error: fmt.Sprintf("stopped: did not finish within its %s share of the %s --budget",
+				budgetDuration(allowance.Limit), budgetDuration(allowance.Total)),
+			Duration: result.Duration,
+			Details:  result.Details,
+		}
+		// A stopped check is harmless unless it left test objects behind
+		if len(cleanupFailures) > 0 {
+			result.Status = output.StatusWarn
+			result.Error += fmt.Sprintf("; cleanup failed, test objects may be left behind: %s",
+				strings.Join(cleanupFailures, "; "))
+		}
+	}
+	report.AddResult(result)
+}
+
+// budgetDuration formats a duration of --budget, which may have run out
+func budgetDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d.Round(100 * time.Millisecond)
+}
+
+// failedPrerequisite returns the failed check that the named check depends
+// on, directly or through a check skipped for the same reason, or "" when
+// it can run. Checks that were not part of the run, and policy failures
+// such as an expiring certificate, do not block anything.
+func failedPrerequisite(report *output.TestReport, name string) string {
+	for _, required := range checker.Requires(report.Config, name) {
+		for _, result := range report.Results {
+			if result.TestName != required {
+				continue
+			}
+			if result.Status == output.StatusFail && !result.PolicyFailure {
+				return required
+			}
+			if result.SkippedBecause != "" {
+				return result.SkippedBecause
+			}
+		}
+	}
+	return ""
+}
+
+// interruptedResult is the skipped result of a check cut short by a signal
+func interruptedResult(name string) output.TestResult {
+	return output.TestResult{
+		TestName: name,
+		Status:   output.StatusSkip,
+		Error:    "interrupted: the run was cancelled before this check completed",
+	}
+}
+
+// credentialsExpired reports whether the temporary credentials have expired,
+// by their expiration time or because the last check was rejected with
+// ExpiredToken. The expiry is recorded as a failed check so the report says
+// why the remaining checks did not run.
+func credentialsExpired(report *output.TestReport) bool {
+	rejected := false
+	if n := len(report.Results); n > 0 {
+		rejected = strings.Contains(report.Results[n-1].Error, "ExpiredToken")
+	}
+	if !rejected && !report.Config.CredentialsExpired(time.Now()) {
+		return false
+	}
+
+	message := "temporary credentials were rejected as expired (ExpiredToken)"
+	if report.Config.CredentialExpiry != nil {
+		message = fmt.Sprintf("temporary credentials expired at %s",
+			report.Config.CredentialExpiry.Local().Format(time.RFC3339))
+	}
+	report.AddResult(output.TestResult{
+		TestName: "Credential Expiry Check",
+		Status:   output.StatusFail,
+		Error:    message + "; remaining checks were not run",
+	})
+
+	return true
+}
+
+// assumeRole calls sts:AssumeRole with the configured credentials and replaces
+// them with the returned temporary credentials
+func assumeRole(cfg *config.Config) (*output.AssumedRoleInfo, error) {
+	endpoint := cfg.ResolveSTSEndpoint()
+	client := sts.NewClient(endpoint, cfg.Region, cfg.AccessKey, cfg.SecretKey, cfg.SessionToken,
+		cfg.Insecure, time.Duration(cfg.Timeout)*time.Second)
+	if cfg.Proxy != "" {
+		client.SetProxy(proxy.Func(cfg.Proxy))
+	}
+	if cfg.IPFamily != "" {
+		client.SetDialNetwork(output.FamilyNetwork("tcp", cfg.IPFamily))
+	}
+
+	sessionName := fmt.Sprintf("s3tester-%d", time.Now().Unix())
+	role, err := client.AssumeRole(cfg.RoleArn, cfg.ExternalID, sessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.AccessKey = role.Credentials.AccessKey
+	cfg.SecretKey = role.Credentials.SecretKey
+	cfg.SessionToken = role.Credentials.SessionToken
+	cfg.CredentialExpiration = role.Credentials.Expiration
+
+	if cfg.Verbose {
+		fmt.Fprintf(os.Stderr, "DEBUG: Assumed role %s via %s (expires %s)\n",
+			role.Arn, endpoint, role.Credentials.Expiration.Format(time.RFC3339))
+	}
+
+	return &output.AssumedRoleInfo{
+		Arn:           role.Arn,
+		AssumedRoleID: role.AssumedRoleID,
+		SessionName:   role.SessionName,
+		STSEndpoint:   endpoint,
+		Expiration:    role.Credentials.Expiration,
+	}, nil
+}
+
+// runCertWatch checks only TLS certificate expiry for a list of endpoints
+func runCertWatch(args []string) int {
+	cfg, err := config.ParseCertWatchFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitCodeConfig
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return ExitCodeConfig
+	}
+	if cfg.ASCII {
+		output.SetASCII()
+	}
+
+	report := &output.CertWatchReport{
+		CheckedAt: time.Now(),
+		WarnDays:  cfg.WarnDays,
+		Results:   make([]output.CertWatchResult, 0, len(cfg.Endpoints)),
+	}
+
+	for _, endpoint := range cfg.Endpoints {
+		outputConfig := cfg.ToOutputConfig(endpoint)
+		host := checker.ParseHostname(endpoint)
+		tlsChecker := checker.NewTLSChecker(outputConfig, host, outputConfig.Port)
+		tlsResult := tlsChecker.Check(context.Background())
+
+		watchResult := output.CertWatchResult{
+			Endpoint: endpoint,
+			Host:     host,
+			Port:     outputConfig.Port,
+			Status:   output.StatusPass,
+			Error:    tlsResult.Error,
+		}
+
+		details, ok := tlsResult.Details.(output.TLSResult)
+		if !ok {
+			watchResult.Status = output.StatusFail
+			if watchResult.Error == "" {
+				watchResult.Error = "no certificate retrieved"
+			}
+			report.Results = append(report.Results, watchResult)
+			continue
+		}
+
+		// Certificate info may be available even when verification failed;
+		// expiry is what this mode is about, so only treat missing info as failure
+		watchResult.Error = ""
+		watchResult.Subject = details.Certificate.Subject
+		watchResult.Issuer = details.Certificate.Issuer
+		watchResult.NotAfter = details.Certificate.NotAfter
+		watchResult.DaysUntilExpiry = details.Certificate.DaysUntilExpiry
+		watchResult.Verified = details.Verified
+
+		// An expiring certificate that also fails verification is a
+		// failure: each finding can only make the status worse
+		if details.Certificate.IsExpired {
+			watchResult.Status = output.WorseStatus(watchResult.Status, output.StatusFail)
+		} else if details.Certificate.DaysUntilExpiry < cfg.WarnDays {
+			watchResult.Status = output.WorseStatus(watchResult.Status, output.StatusWarn)
+		}
+		if !details.Verified && !cfg.Insecure {
+			watchResult.Status = output.WorseStatus(watchResult.Status, output.StatusFail)
+			watchResult.Error = tlsResult.Error
+		}
+
+		report.Results = append(report.Results, watchResult)
+	}
+
+	for _, r := range report.Results {
+		report.Summary.Total++
+		switch r.Status {
+		case output.StatusPass:
+			report.Summary.Passed++
+		case output.StatusWarn:
+			report.Summary.Warnings++
+		default:
+			report.Summary.Failed++
+		}
+	}
+
+	output.PrintCertWatch(report)
+
+	if cfg.OutputFile != "" {
+		if err := output.PrintCertWatchJSON(report, cfg.OutputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to write JSON output: %v\n", err)
+		} else {
+			fmt.Printf("JSON output saved to: %s\n", cfg.OutputFile)
+		}
+	}
+
+	needsAttention := report.Summary.Warnings+report.Summary.Failed > 0
+
+	if needsAttention && cfg.NotifyWebhook != "" {
+		notifier := notify.NewWebhookNotifier(cfg.NotifyWebhook, time.Duration(cfg.Timeout)*time.Second)
+		severity := notify.SeverityWarning
+		if report.Summary.Failed > 0 {
+			severity = notify.SeverityCritical
+		}
+		err := notifier.Notify(notify.Notification{
+			Event:    "cert-expiry",
+			Severity: severity,
+			Title:    "S3 endpoint certificates need attention",
+			Message: fmt.Sprintf("%d of %d endpoint certificate(s) expire within %d days or could not be checked",
+				report.Summary.Warnings+report.Summary.Failed, report.Summary.Total, cfg.WarnDays),
+			Details: report,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to send notification: %v\n", err)
+		}
+	}
+
+	if needsAttention {
+		return ExitCodeFailed
+	}
+	return ExitCodeSuccess
+}
+
+// runBench uploads and downloads objects to measure throughput and latency
+func runBench(args []string) int {
+	cfg, err := config.ParseBenchFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitCodeConfig
+	}
+	if cfg.ASCII {
+		output.SetASCII()
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return ExitCodeConfig
+	}
+	if err := cfg.ValidateBench(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return ExitCodeConfig
+	}
+
+	if cfg.RoleArn != "" {
+		if _, err := assumeRole(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to assume role %s: %v\n", cfg.RoleArn, err)
+			return ExitCodeError
+		}
+	}
+	if err := cfg.CheckCredentialExpiry(time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitCodeError
+	}
+
+	outputConfig := cfg.ToOutputConfig()
+	fmt.Printf("Benchmarking %s: %d objects, %d concurrent...\n\n", outputConfig.Target(), cfg.BenchCount, cfg.BenchConcurrency)
+
+	report := checker.NewBenchmark(outputConfig).Run()
+	report.Metadata = output.NewRunMetadata(version, checker.ParseHostname(cfg.Endpoint), cfg.Port, cfg.IPFamily)
+
+	output.PrintBench(report)
+
+	if cfg.OutputFile != "" {
+		if err := output.PrintBenchJSON(report, cfg.OutputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to write JSON output: %v\n", err)
+		} else {
+			fmt.Printf("JSON output saved to: %s\n", cfg.OutputFile)
+		}
+	}
+
+	if report.Failed() {
+		return ExitCodeFailed
+	}
+	return ExitCodeSuccess
+}
+
+// runCompare runs the same checks against each endpoint in turn and prints
+// the results side by side
+func runCompare(args []string) int {
+	configs, err := config.ParseCompareFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitCodeConfig
+	}
+	for _, cfg := range configs {
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %s: %v\n", cfg.Endpoint, err)
+			return ExitCodeConfig
+		}
+		if err := cfg.ValidateCompare(); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			return ExitCodeConfig
+		}
+	}
+	first := configs[0]
+	if first.ASCII {
+		output.SetASCII()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reports := make([]*output.TestReport, 0, len(configs))
+	for i, cfg := range configs {
+		var assumedRole *output.AssumedRoleInfo
+		if cfg.RoleArn != "" {
+			if assumedRole, err = assumeRole(cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: failed to assume role %s: %v\n", cfg.Endpoint, cfg.RoleArn, err)
+				return ExitCodeError
+			}
+		}
+		if err := cfg.CheckCredentialExpiry(time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", cfg.Endpoint, err)
+			return ExitCodeError
+		}
+
+		outputConfig := cfg.ToOutputConfig()
+		outputConfig.AssumedRole = assumedRole
+		hostname := checker.ParseHostname(cfg.Endpoint)
+		report := &output.TestReport{
+			Config:    outputConfig,
+			StartTime: time.Now(),
+			Metadata:  output.NewRunMetadata(version, hostname, cfg.Port, cfg.IPFamily),
+		}
+
+		fmt.Printf("[%c] Checking %s...\n", 'A'+i, outputConfig.Target())
+		if cfg.Warmup {
+			report.Warmup = checker.Warmup(checker.WithOperationLabel(ctx, "Warm-up"), outputConfig)
+		}
+		runTests(ctx, report, hostname, cfg.Port, cfg.CheckPolicy)
+		output.ApplySLO(report.Results, cfg.SLO)
+
+		report.EndTime = time.Now()
+		report.Duration = report.EndTime.Sub(report.StartTime)
+		report.Summary = output.NewTestSummary(report.Results)
+		reports = append(reports, report)
+	}
+	fmt.Println()
+
+	// The comparison is printed and written for sharing, like a check report
+	if first.Anonymize {
+		for i, report := range reports {
+			reports[i] = output.Anonymize(report)
+		}
+	}
+	compare := output.NewCompareReport(reports)
+	compare.Metadata = output.NewRunMetadata(version, "", 0, first.IPFamily)
+
+	output.PrintCompare(compare)
+
+	if first.OutputFile != "" {
+		if err := output.PrintCompareJSON(compare, first.OutputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to write JSON output: %v\n", err)
+		} else {
+			fmt.Printf("JSON output saved to: %s\n", first.OutputFile)
+		}
+	}
+
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "Interrupted: the comparison is partial, checks that did not complete are marked SKIP")
+		return ExitCodeError
+	}
+	if compare.Failed() || compare.Differences > 0 {
+		return ExitCodeFailed
+	}
+	return ExitCodeSuccess
+}
+
+// runHistory prints the trends of the runs recorded with --history
+func runHistory(args []string) int {
+	cfg, err := config.ParseHistoryFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitCodeConfig
+	}
+	if cfg.ASCII {
+		output.SetASCII()
+	}
+
+	path := cfg.Database
+	if path == "" {
+		path = history.DefaultPath()
+	}
+	if _, err := os.Stat(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: no history at %s: record runs with --history %s first\n", path, path)
+		return ExitCodeConfig
+	}
+	store, err := history.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitCodeConfig
+	}
+	defer store.Close()
+
+	report, err := store.Report(cfg.Target, time.Now().Add(-cfg.Since))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read history %s: %v\n", path, err)
+		return ExitCodeError
+	}
+
+	output.PrintHistory(report)
+
+	if cfg.OutputFile != "" {
+		if err := output.PrintHistoryJSON(report, cfg.OutputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to write JSON output: %v\n", err)
+		} else {
+			fmt.Printf("JSON output saved to: %s\n", cfg.OutputFile)
+		}
+	}
+	return ExitCodeSuccess
+}
+
+// runPolicy prints the least-privilege IAM policy for the checks the flags
+// select, without running them
+func runPolicy(args []string) int {
+	cfg, err := config.ParsePolicyFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitCodeConfig
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return ExitCodeConfig
+	}
+
+	file := cfg.EmitPolicy
+	if file == "" {
+		file = "-"
+	}
+	policy := checker.RequiredPolicy(cfg.ToOutputConfig(), cfg.CheckPolicy)
+	if err := output.WriteIAMPolicy(policy, file); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write IAM policy: %v\n", err)
+		return ExitCodeError
+	}
+	if file != "-" {
+		fmt.Printf("IAM policy saved to: %s\n", file)
 	}
+	return ExitCodeSuccess
 }
 
-// printRemediations prints remediation suggestions for failed tests
-func printRemediations(results []output.TestResult) {
+// printRemediations prints remediation suggestions for failed tests, with
+// the commands of the provider's tools where known
+func printRemediations(report *output.TestReport) {
+	results := report.Results
+	provider := report.Provider()
 	hasFailures := false
 	for _, result := range results {
 		if result.Status == output.StatusFail && result.Error != "" {
@@ -161,15 +1139,15 @@ func printRemediations(results []output.TestResult) {
 	}
 
 	fmt.Println(strings.Repeat("=", 50))
-	fmt.Println(bold("Remediation Suggestions"))
+	fmt.Println(bold(i18n.T("Remediation Suggestions")))
 	fmt.Println(strings.Repeat("=", 50))
 	fmt.Println()
 
 	for _, result := range results {
 		if result.Status == output.StatusFail && result.Error != "" {
-			rem := remediation.GetRemediation(result.TestName, fmt.Errorf(result.Error))
+			rem := remediation.GetRemediation(result.TestName, provider, errors.New(result.Error))
 			if rem != nil {
-				fmt.Printf("%s:\n", bold(result.TestName))
+				fmt.Printf("%s:\n", bold(i18n.T(result.TestName)))
 				fmt.Println(remediation.FormatRemediation(rem))
 				fmt.Println()
 			}
@@ -179,5 +1157,5 @@ func printRemediations(results []output.TestResult) {
 
 // bold returns bold text (helper function)
 func bold(s string) string {
-	return fmt.Sprintf("\033[1m%s\033[0m", s)
+	return output.Bold(s)
 }
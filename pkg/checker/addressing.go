@@ -0,0 +1,131 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// Addressing styles as reported by the addressing style check
+const (
+	StyleVirtualHosted = "virtual-hosted"
+	StylePathStyle     = "path-style"
+)
+
+// AddressingStyleChecker runs the authentication check with virtual-hosted
+// and with path-style addressing to find which styles work for the bucket
+type AddressingStyleChecker struct {
+	BaseChecker
+	virtualHosted *AuthChecker
+	pathStyle     *AuthChecker
+	verbose       *VerboseLogger
+}
+
+// NewAddressingStyleChecker creates a new addressing style checker
+func NewAddressingStyleChecker(config output.Config) *AddressingStyleChecker {
+	virtualHosted, pathStyle := config, config
+	virtualHosted.PathStyle = false
+	pathStyle.PathStyle = true
+	return &AddressingStyleChecker{
+		BaseChecker:   NewBaseChecker(config),
+		virtualHosted: NewAuthChecker(virtualHosted),
+		pathStyle:     NewAuthChecker(pathStyle),
+		verbose:       NewVerboseLogger(config.Verbose),
+	}
+}
+
+// Name returns the name of the checker
+func (c *AddressingStyleChecker) Name() string {
+	return "Addressing Style Check"
+}
+
+// Check authenticates against the bucket with both addressing styles. It
+// passes when the configured style works, warns naming the flag to use when
+// only the other one does, and fails when neither does.
+func (c *AddressingStyleChecker) Check(ctx context.Context) output.TestResult {
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Addressing Style Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	styleResult := output.AddressingStyleResult{Configured: StyleVirtualHosted}
+	if c.Config.PathStyle {
+		styleResult.Configured = StylePathStyle
+	}
+
+	var virtualHosted output.AddressingStyleOutcome
+	if net.ParseIP(ParseHostname(c.Config.Endpoint)) != nil {
+		// Not sent: the request would go to the IP address and fail for a
+		// reason that says nothing about the provider
+		virtualHosted = output.AddressingStyleOutcome{
+			Style: StyleVirtualHosted,
+			Error: "not possible: the endpoint is an IP address, which cannot carry the bucket as a host label",
+		}
+	} else {
+		virtualHosted = c.try(ctx, c.virtualHosted, StyleVirtualHosted)
+	}
+	pathStyle := c.try(ctx, c.pathStyle, StylePathStyle)
+	styleResult.Styles = []output.AddressingStyleOutcome{virtualHosted, pathStyle}
+
+	var working []string
+	for _, style := range styleResult.Styles {
+		if style.Works {
+			working = append(working, style.Style)
+		}
+	}
+	styleResult.Working = working
+
+	switch {
+	case len(working) == 0:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("neither addressing style works: virtual-hosted: %s; path-style: %s",
+			virtualHosted.Error, pathStyle.Error)
+	case len(working) == 1 && working[0] != styleResult.Configured:
+		flag := "--path-style"
+		if working[0] == StyleVirtualHosted {
+			flag = "--virtual-hosted"
+		}
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("only %s addressing works for this bucket, not the configured %s: use %s",
+			working[0], styleResult.Configured, flag)
+	}
+
+	result.Details = styleResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Addressing style check completed in %v", result.Duration)
+
+	return result
+}
+
+// try runs the authentication check with one addressing style. A warning,
+// such as a corrected region, still counts as working.
+func (c *AddressingStyleChecker) try(ctx context.Context, auth *AuthChecker, style string) output.AddressingStyleOutcome {
+	check := auth.Check(ctx)
+	outcome := output.AddressingStyleOutcome{
+		Style: style,
+		Works: check.Status != output.StatusFail,
+		Error: check.Error,
+	}
+	if details, ok := check.Details.(output.AuthResult); ok {
+		outcome.StatusCode = details.StatusCode
+		outcome.ResponseTimeMs = details.ResponseTime
+	}
+	if u, err := bucketBaseURL(auth.Endpoint, auth.Bucket, auth.PathStyle); err == nil {
+		outcome.URL = u.String()
+		// The usual reason virtual-hosted addressing fails
+		if style == StyleVirtualHosted && strings.Contains(check.Error, "no such host") {
+			outcome.Error = fmt.Sprintf("%s does not resolve: the endpoint has no wildcard DNS for bucket hosts", u.Hostname())
+		}
+	}
+	c.verbose.LogMessage("%s: works=%v, HTTP %d %s", style, outcome.Works, outcome.StatusCode, outcome.Error)
+	return outcome
+}
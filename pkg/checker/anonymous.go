@@ -0,0 +1,241 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// anonymousProbe describes one unauthenticated request and the exposure it
+// reveals when the server answers it
+type anonymousProbe struct {
+	name     string
+	method   string
+	query    url.Values
+	header   http.Header
+	object   bool
+	severity output.ExposureSeverity
+	exposure string
+}
+
+// anonymousProbes are issued in order; the object probe uses a key found by
+// the listing probes or by an authenticated listing
+var anonymousProbes = []anonymousProbe{
+	{
+		name:     "ListObjectsV2",
+		method:   "GET",
+		query:    url.Values{"list-type": {"2"}, "max-keys": {"1"}},
+		severity: output.ExposureCritical,
+		exposure: "bucket contents are publicly listable",
+	},
+	{
+		name:     "ListObjects",
+		method:   "GET",
+		query:    url.Values{"max-keys": {"1"}},
+		severity: output.ExposureCritical,
+		exposure: "bucket contents are publicly listable",
+	},
+	// The object can be of any size, and its first byte proves it readable
+	{
+		name:     "GetObject",
+		method:   "GET",
+		header:   http.Header{"Range": {"bytes=0-0"}},
+		object:   true,
+		severity: output.ExposureHigh,
+		exposure: "objects are publicly readable",
+	},
+	{
+		name:     "GetBucketAcl",
+		method:   "GET",
+		query:    url.Values{"acl": {""}},
+		severity: output.ExposureMedium,
+		exposure: "bucket ACL is publicly readable",
+	},
+	{
+		name:     "GetBucketPolicy",
+		method:   "GET",
+		query:    url.Values{"policy": {""}},
+		severity: output.ExposureMedium,
+		exposure: "bucket policy is publicly readable",
+	},
+	{
+		name:     "HeadBucket",
+		method:   "HEAD",
+		severity: output.ExposureLow,
+		exposure: "bucket answers anonymous HEAD requests",
+	},
+}
+
+// AnonymousAccessChecker sends unauthenticated requests to the bucket and
+// reports whether it or its objects are publicly readable or listable
+type AnonymousAccessChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewAnonymousAccessChecker creates a new anonymous access checker
+func NewAnonymousAccessChecker(config output.Config) *AnonymousAccessChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &AnonymousAccessChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *AnonymousAccessChecker) Name() string {
+	return "Anonymous Access Check"
+}
+
+// Check performs the anonymous access scan
+func (c *AnonymousAccessChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Anonymous Access Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+		Duration: time.Since(startTime),
+	}
+
+	anonResult := output.AnonymousAccessResult{
+		Severity: output.ExposureNone,
+	}
+
+	sampleKey := ""
+	for _, probe := range anonymousProbes {
+		if probe.object && sampleKey == "" {
+			sampleKey = c.findSampleKey()
+			if sampleKey == "" {
+				anonResult.Probes = append(anonResult.Probes, output.AnonymousProbe{
+					Name:   probe.name,
+					Method: probe.method,
+					Detail: "skipped: no object found to probe",
+				})
+				continue
+			}
+		}
+
+		key := ""
+		if probe.object {
+			key = sampleKey
+		}
+
+		status, body, err := c.client.doAnonymous(probe.method, key, probe.query, probe.header)
+		probeResult := output.AnonymousProbe{
+			Name:       probe.name,
+			Method:     probe.method,
+			Key:        key,
+			StatusCode: status,
+		}
+
+		switch {
+		case err != nil:
+			probeResult.Detail = fmt.Sprintf("request failed: %v", err)
+		case status == http.StatusOK || status == http.StatusPartialContent:
+			probeResult.Allowed = true
+			probeResult.Severity = probe.severity
+			probeResult.Detail = probe.exposure
+			if output.ExposureRank(probe.severity) > output.ExposureRank(anonResult.Severity) {
+				anonResult.Severity = probe.severity
+			}
+			if sampleKey == "" && probe.query.Get("max-keys") != "" {
+				sampleKey = firstListedKey(body)
+			}
+		default:
+			probeResult.Detail = parseErrorResponse(status, body)
+		}
+
+		c.verbose.LogMessage("%s %s: HTTP %d (%s)", probe.method, probe.name, status, probeResult.Detail)
+		anonResult.Probes = append(anonResult.Probes, probeResult)
+	}
+
+	var exposures []string
+	seen := make(map[string]bool)
+	for _, p := range anonResult.Probes {
+		if p.Allowed && !seen[p.Detail] {
+			seen[p.Detail] = true
+			exposures = append(exposures, p.Detail)
+		}
+	}
+	anonResult.Exposed = len(exposures) > 0
+
+	switch anonResult.Severity {
+	case output.ExposureCritical, output.ExposureHigh:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("public access (%s): %s", anonResult.Severity, strings.Join(exposures, "; "))
+	case output.ExposureMedium, output.ExposureLow:
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("public access (%s): %s", anonResult.Severity, strings.Join(exposures, "; "))
+	}
+
+	result.Details = anonResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Anonymous access check completed in %v", result.Duration)
+
+	return result
+}
+
+// findSampleKey returns an object key from an authenticated listing, or an
+// empty string if the bucket is empty or cannot be listed
+func (c *AnonymousAccessChecker) findSampleKey() string {
+	query := url.Values{"list-type": {"2"}, "max-keys": {"1"}}
+	req, err := c.client.newRequest("GET", "", query, nil)
+	if err != nil {
+		return ""
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	return firstListedKey(body)
+}
+
+// firstListedKey returns the first key of a ListObjects or ListObjectsV2 response
+func firstListedKey(body []byte) string {
+	var page listBucketResult
+	if err := xml.Unmarshal(body, &page); err != nil || len(page.Contents) == 0 {
+		return ""
+	}
+	return page.Contents[0].Key
+}
+
+// doAnonymous sends an unsigned request with the extra headers and returns
+// the status code and at most a megabyte of the body
+func (s *s3Client) doAnonymous(method, key string, query url.Values, header http.Header) (int, []byte, error) {
+	req, err := s.newRequest(method, key, query, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	s.verbose.LogRequest(req)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	s.verbose.LogResponse(resp)
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+
+	return resp.StatusCode, body, nil
+}
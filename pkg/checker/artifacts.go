@@ -0,0 +1,180 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// artifactStaleAfter is the age after which an object under the reserved
+// prefix is considered left behind; younger objects may belong to a run that
+// is still in progress
+const artifactStaleAfter = 1 * time.Hour
+
+// listBucketResult is the ListObjectsV2 response
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"ListBucketResult"`
+	Contents              []listedObject `xml:"Contents"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	NextContinuationToken string         `xml:"NextContinuationToken"`
+}
+
+// listedObject is one entry of a ListObjectsV2 response
+type listedObject struct {
+	Key          string    `xml:"Key"`
+	LastModified time.Time `xml:"LastModified"`
+	Size         int64     `xml:"Size"`
+}
+
+// ArtifactChecker lists objects under the tool's reserved prefix and reports
+// stale artifacts left by interrupted runs, optionally deleting them
+type ArtifactChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewArtifactChecker creates a new artifact inventory checker
+func NewArtifactChecker(config output.Config) *ArtifactChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	client := newS3Client(config, verbose)
+
+	// Purging removes artifacts of earlier runs, outside this run's prefix;
+	// each delete is scoped to the run directory of its key instead
+	client.writeScope = ""
+
+	return &ArtifactChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      client,
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *ArtifactChecker) Name() string {
+	return "Test Artifact Inventory"
+}
+
+// Check performs the artifact inventory
+func (c *ArtifactChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Test Artifact Inventory")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+		Duration: time.Since(startTime),
+	}
+
+	prefix := ArtifactPrefix(c.Config)
+	objects, err := c.client.listObjects(prefix)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("ListObjectsV2 failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	inventory := output.ArtifactInventoryResult{
+		Prefix:     prefix,
+		StaleAfter: artifactStaleAfter.String(),
+	}
+
+	runPrefixes := make(map[string]string)
+	for _, obj := range objects {
+		runPrefix := artifactRunPrefix(c.Config, obj.Key)
+		if runPrefix == "" {
+			continue
+		}
+		inventory.Total++
+		runPrefixes[obj.Key] = runPrefix
+
+		age := startTime.Sub(obj.LastModified)
+		if age < artifactStaleAfter {
+			continue
+		}
+		inventory.Stale = append(inventory.Stale, output.Artifact{
+			Key:          obj.Key,
+			Size:         obj.Size,
+			LastModified: obj.LastModified,
+			Age:          age.Round(time.Second).String(),
+		})
+	}
+	c.verbose.LogMessage("Found %d object(s) under %s, %d stale", inventory.Total, prefix, len(inventory.Stale))
+
+	if c.Config.PurgeArtifacts {
+		for _, artifact := range inventory.Stale {
+			c.client.writeScope = runPrefixes[artifact.Key]
+			if err := c.client.deleteObject(artifact.Key); err != nil {
+				inventory.PurgeErrors = append(inventory.PurgeErrors, fmt.Sprintf("%s: %v", artifact.Key, err))
+				continue
+			}
+			c.verbose.LogMessage("Deleted stale artifact %s", artifact.Key)
+			inventory.Purged++
+		}
+	}
+
+	switch {
+	case len(inventory.PurgeErrors) > 0:
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("failed to purge %d of %d stale artifact(s): %s",
+			len(inventory.PurgeErrors), len(inventory.Stale), inventory.PurgeErrors[0])
+	case len(inventory.Stale) > 0 && !c.Config.PurgeArtifacts:
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("%d stale artifact(s) under %s from previous runs (remove with --purge-artifacts)",
+			len(inventory.Stale), prefix)
+	}
+
+	result.Details = inventory
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Artifact inventory completed in %v", result.Duration)
+
+	return result
+}
+
+// listObjects returns all objects under a prefix, following continuation tokens
+func (s *s3Client) listObjects(prefix string) ([]listedObject, error) {
+	var objects []listedObject
+	token := ""
+
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := s.newRequest("GET", "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, body, err := s.do(req, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, body))
+		}
+
+		var page listBucketResult
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("invalid ListObjectsV2 response: %w", err)
+		}
+		objects = append(objects, page.Contents...)
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
@@ -2,6 +2,7 @@ package checker
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"crypto/tls"
@@ -11,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strings"
 	"time"
@@ -21,28 +23,35 @@ import (
 // AuthChecker performs bucket authentication checks
 type AuthChecker struct {
 	BaseChecker
-	Endpoint  string
-	Bucket    string
-	AccessKey string
-	SecretKey string
-	Region    string
-	AuthType  string
-	PathStyle bool
-	verbose   *VerboseLogger
+	Endpoint     string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Region       string
+	AuthType     string
+	PathStyle    bool
+	verbose      *VerboseLogger
+
+	// canonicalRequest and stringToSign are those of the last signed
+	// request, to compare with a SignatureDoesNotMatch error
+	canonicalRequest string
+	stringToSign     string
 }
 
 // NewAuthChecker creates a new auth checker
 func NewAuthChecker(config output.Config) *AuthChecker {
 	return &AuthChecker{
-		BaseChecker: NewBaseChecker(config),
-		Endpoint:    config.Endpoint,
-		Bucket:      config.Bucket,
-		AccessKey:   config.AccessKey,
-		SecretKey:   config.SecretKey,
-		Region:      config.Region,
-		AuthType:    strings.ToLower(config.AuthType),
-		PathStyle:   config.PathStyle,
-		verbose:     NewVerboseLogger(config.Verbose),
+		BaseChecker:  NewBaseChecker(config),
+		Endpoint:     config.Endpoint,
+		Bucket:       config.Bucket,
+		AccessKey:    config.AccessKey,
+		SecretKey:    config.SecretKey,
+		SessionToken: config.SessionToken,
+		Region:       config.Region,
+		AuthType:     strings.ToLower(config.AuthType),
+		PathStyle:    config.PathStyle,
+		verbose:      NewVerboseLogger(config.Verbose),
 	}
 }
 
@@ -52,7 +61,7 @@ func (c *AuthChecker) Name() string {
 }
 
 // Check performs the authentication check
-func (c *AuthChecker) Check() output.TestResult {
+func (c *AuthChecker) Check(ctx context.Context) output.TestResult {
 	startTime := time.Now()
 
 	c.verbose.LogSection("Starting Bucket Authentication Check")
@@ -64,85 +73,131 @@ func (c *AuthChecker) Check() output.TestResult {
 	}
 
 	// Create HTTP client with custom transport for insecure TLS
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: c.Config.Insecure,
-		},
-	}
-	client := &http.Client{
-		Timeout:   time.Duration(c.Config.Timeout) * time.Second,
-		Transport: transport,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if !c.Config.FollowRedirect {
-				return http.ErrUseLastResponse
+	client := newHTTPClient(c.Config)
+
+	// Trace each phase of the request so a slow response can be attributed
+	// to DNS, connecting, the TLS handshake or the server itself
+	var dnsStart, dnsDone, connectStart, connectDone, tlsStart, tlsDone, firstByte time.Time
+	var reused bool
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:  func(httptrace.DNSDoneInfo) { dnsDone = time.Now() },
+		ConnectStart: func(string, string) {
+			// Happy eyeballs may start several dials; time from the first
+			if connectStart.IsZero() {
+				connectStart = time.Now()
 			}
-			if len(via) >= c.Config.MaxRedirects {
-				return fmt.Errorf("stopped after %d redirects", c.Config.MaxRedirects)
-			}
-			return nil
 		},
+		ConnectDone:          func(string, string, error) { connectDone = time.Now() },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { tlsDone = time.Now() },
+		GotConn:              func(info httptrace.GotConnInfo) { reused = info.Reused },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
 	}
 
-	// Create request
-	req, err := c.createRequest()
-	if err != nil {
-		c.verbose.LogMessage("Failed to create request: %v", err)
-		result.Status = output.StatusFail
-		result.Error = fmt.Sprintf("failed to create request: %v", err)
-		result.Duration = time.Since(startTime)
-		return result
-	}
+	// A bucket in another region than --region answers with its region; the
+	// request is then signed for that region, sent to its endpoint on AWS,
+	// and retried once
+	configuredRegion, configuredEndpoint := c.Region, c.Endpoint
+	retried := false
+	var resp *http.Response
+	var body []byte
+	var requestStart, requestDone time.Time
+	for {
+		// Create request
+		req, err := c.createRequest("HEAD", nil)
+		if err != nil {
+			c.verbose.LogMessage("Failed to create request: %v", err)
+			result.Status = output.StatusFail
+			result.Error = fmt.Sprintf("failed to create request: %v", err)
+			result.Duration = time.Since(startTime)
+			return result
+		}
 
-	c.verbose.LogMessage("Request created successfully")
-	c.verbose.LogMessage("Endpoint: %s", c.Endpoint)
-	c.verbose.LogMessage("Bucket: %s", c.Bucket)
-	c.verbose.LogMessage("Auth Type: %s", strings.ToUpper(c.AuthType))
-	c.verbose.LogMessage("Path Style: %v", c.PathStyle)
+		c.verbose.LogMessage("Request created successfully")
+		c.verbose.LogMessage("Endpoint: %s", c.Endpoint)
+		c.verbose.LogMessage("Bucket: %s", c.Bucket)
+		c.verbose.LogMessage("Auth Type: %s", strings.ToUpper(c.AuthType))
+		c.verbose.LogMessage("Path Style: %v", c.PathStyle)
 
-	// Add authentication headers based on auth type
-	if c.AuthType == "sigv2" {
-		c.verbose.LogMessage("Using AWS Signature Version 2 authentication")
-		if err := c.addSigV2Auth(req); err != nil {
-			c.verbose.LogMessage("Failed to add SigV2 auth: %v", err)
+		// Add authentication headers based on auth type
+		if err := c.signRequest(req); err != nil {
 			result.Status = output.StatusFail
-			result.Error = fmt.Sprintf("failed to add SigV2 auth: %v", err)
+			result.Error = err.Error()
 			result.Duration = time.Since(startTime)
 			return result
 		}
-	} else {
-		// Default to SigV4
-		c.verbose.LogMessage("Using AWS Signature Version 4 authentication")
-		if err := c.addSigV4Auth(req); err != nil {
-			c.verbose.LogMessage("Failed to add SigV4 auth: %v", err)
+
+		// Log the request
+		c.verbose.LogRequest(req)
+
+		dnsStart, dnsDone, connectStart, connectDone = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+		tlsStart, tlsDone, firstByte, reused = time.Time{}, time.Time{}, time.Time{}, false
+		req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
+
+		// Send request
+		c.verbose.LogMessage("Sending request to S3 endpoint...")
+		requestStart = time.Now()
+		resp, err = client.Do(req)
+		if err != nil {
+			c.verbose.LogMessage("Request failed: %v", err)
 			result.Status = output.StatusFail
-			result.Error = fmt.Sprintf("failed to add SigV4 auth: %v", err)
+			result.Error = fmt.Sprintf("request failed: %v", err)
 			result.Duration = time.Since(startTime)
 			return result
 		}
-	}
 
-	// Log the request
-	c.verbose.LogRequest(req)
+		c.verbose.LogMessage("Request completed successfully")
 
-	// Send request
-	c.verbose.LogMessage("Sending request to S3 endpoint...")
-	resp, err := client.Do(req)
-	if err != nil {
-		c.verbose.LogMessage("Request failed: %v", err)
-		result.Status = output.StatusFail
-		result.Error = fmt.Sprintf("request failed: %v", err)
-		result.Duration = time.Since(startTime)
-		return result
-	}
-	defer resp.Body.Close()
+		// Log the response
+		c.verbose.LogResponse(resp)
 
-	c.verbose.LogMessage("Request completed successfully")
+		// Read response body
+		body, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		requestDone = time.Now()
 
-	// Log the response
-	c.verbose.LogResponse(resp)
+		region := bucketRegion(resp.StatusCode, resp.Header, body)
+		if retried || region == "" {
+			break
+		}
+		endpoint := regionalEndpoint(c.Endpoint, region)
+		if endpoint == c.Endpoint && (region == c.Region || c.AuthType == "sigv2") {
+			break
+		}
+		c.verbose.LogMessage("Bucket is in region %s, not %s: retrying signed for %s against %s", region, c.Region, region, endpoint)
+		c.Region, c.Endpoint = region, endpoint
+		retried = true
+	}
 
-	// Read response body
-	body, _ := io.ReadAll(resp.Body)
+	// The Date header is the server's clock when it answered, which is
+	// compared with the local clock halfway to the first byte
+	received := firstByte
+	if received.IsZero() {
+		received = requestDone
+	}
+	skew, skewKnown := clockSkew(resp.Header, requestStart.Add(received.Sub(requestStart)/2))
+	if skewKnown {
+		c.verbose.LogMessage("Server date: %s, local clock %s", resp.Header.Get("Date"), describeClockSkew(skew))
+	}
+
+	// Some providers deny HEAD Bucket but allow listing: before reporting
+	// the credentials as denied, try ListObjectsV2 for one key
+	operation, headBucketStatus, listError := "HeadBucket", 0, ""
+	if resp.StatusCode == http.StatusForbidden {
+		c.verbose.LogMessage("HEAD Bucket was denied, trying ListObjectsV2")
+		listResp, listBody, err := c.listBucket(ctx, client)
+		switch {
+		case err != nil:
+			listError = fmt.Sprintf("request failed: %v", err)
+		case listResp.StatusCode == http.StatusOK && isBucketListing(listBody):
+			c.verbose.LogMessage("ListObjectsV2 succeeded: the credentials can access the bucket")
+			operation, headBucketStatus = "ListObjectsV2", resp.StatusCode
+			resp, body = listResp, listBody
+		default:
+			listError = parseErrorResponse(listResp.StatusCode, listBody)
+		}
+	}
 
 	// Log response body if present and verbose
 	if c.verbose != nil && c.verbose.enabled && len(body) > 0 {
@@ -182,7 +237,25 @@ func (c *AuthChecker) Check() output.TestResult {
 		Provider:     c.detectProvider(resp),
 		Endpoint:     c.Endpoint,
 		ResponseBody: string(body),
+		RateLimit:    parseRateLimit(resp.StatusCode, resp.Header),
+
+		Operation:        operation,
+		HeadBucketStatus: headBucketStatus,
 	}
+	if !firstByte.IsZero() {
+		authResult.TTFBMs = durationToMs(firstByte.Sub(requestStart))
+	}
+	if !dnsDone.IsZero() {
+		authResult.DNSMs = durationToMs(dnsDone.Sub(dnsStart))
+	}
+	if !connectDone.IsZero() {
+		authResult.ConnectMs = durationToMs(connectDone.Sub(connectStart))
+	}
+	if !tlsDone.IsZero() {
+		authResult.TLSHandshakeMs = durationToMs(tlsDone.Sub(tlsStart))
+	}
+	authResult.ConnectionReused = reused
+	authResult.TotalMs = durationToMs(requestDone.Sub(requestStart))
 
 	// Check bucket existence and access
 	if resp.StatusCode == 200 {
@@ -207,15 +280,76 @@ func (c *AuthChecker) Check() output.TestResult {
 		if err := xml.Unmarshal(body, &errResp); err == nil {
 			result.Error = fmt.Sprintf("%s: %s", errResp.Code, errResp.Message)
 			c.verbose.LogMessage("Error response: %s - %s", errResp.Code, errResp.Message)
+		} else if desc := describeNonS3Response(resp.StatusCode, resp.Header, body); desc != "" {
+			result.Error = desc
+			c.verbose.LogMessage("Error response is not from the S3 API: %s", desc)
 		} else {
 			result.Error = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body))
 			c.verbose.LogMessage("Error response: HTTP %d", resp.StatusCode)
 		}
+		if listError != "" {
+			result.Error += "; ListObjectsV2 fallback: " + listError
+		}
+		result.Status = output.StatusFail
+	} else if resp.StatusCode >= 300 {
+		// A redirect that was not followed, such as S3's 301 for a bucket
+		// of another region, which has no Location
 		result.Status = output.StatusFail
+		result.Error = describeRedirect(resp.StatusCode, resp.Header, body)
+		c.verbose.LogMessage("Redirect response: %s", result.Error)
+	} else if desc := describeNonS3Response(resp.StatusCode, resp.Header, body); desc != "" {
+		// A banner or maintenance page served with a success status
+		authResult.Success = false
+		authResult.AccessGranted = false
+		result.Status = output.StatusFail
+		result.Error = desc
+		c.verbose.LogMessage("Response is not from the S3 API: %s", desc)
+	}
+
+	// A skewed clock fails every signed request, often with an error that
+	// does not say so: attribute the failure when the skew explains it
+	if skewKnown {
+		authResult.ServerDate = resp.Header.Get("Date")
+		authResult.ClockSkewSeconds = int64(skew / time.Second)
+		abs := skew
+		if abs < 0 {
+			abs = -abs
+		}
+		switch {
+		case result.Status == output.StatusFail && (abs > maxClockSkew || strings.Contains(result.Error, "RequestTimeTooSkewed")):
+			result.Error += "; the local clock is " + describeClockSkew(skew)
+			if abs > maxClockSkew {
+				result.Error += fmt.Sprintf(", more than the %.0f minutes S3 allows", maxClockSkew.Minutes())
+			}
+		case result.Status == output.StatusPass && abs > clockSkewWarning:
+			result.Status = output.StatusWarn
+			result.Error = fmt.Sprintf("the local clock is %s; requests fail once it is off by more than %.0f minutes",
+				describeClockSkew(skew), maxClockSkew.Minutes())
+		}
+	}
+
+	// The region was wrong: say which flags to fix, since the run only
+	// passed thanks to the retry
+	if retried {
+		authResult.DetectedRegion = c.Region
+		fix := "--region " + c.Region
+		if c.Endpoint != configuredEndpoint {
+			fix += " --endpoint " + c.Endpoint
+		}
+		if result.Status == output.StatusPass {
+			result.Status = output.StatusWarn
+			result.Error = fmt.Sprintf("bucket is in region %s, not %s: it answered when signed for %s; set %s",
+				c.Region, configuredRegion, c.Region, fix)
+		} else {
+			result.Error += fmt.Sprintf(" (after retrying for region %s, where the bucket is; set %s)", c.Region, fix)
+		}
 	}
 
 	c.verbose.LogMessage("Provider detected: %s", authResult.Provider)
 	c.verbose.LogMessage("Response time: %dms", authResult.ResponseTime)
+	c.verbose.LogMessage("Time to first byte: %.2fms", authResult.TTFBMs)
+	c.verbose.LogMessage("Phases: DNS %.2fms, connect %.2fms, TLS %.2fms, total %.2fms (connection reused: %v)",
+		authResult.DNSMs, authResult.ConnectMs, authResult.TLSHandshakeMs, authResult.TotalMs, authResult.ConnectionReused)
 
 	result.Details = authResult
 	result.Duration = time.Since(startTime)
@@ -225,6 +359,40 @@ func (c *AuthChecker) Check() output.TestResult {
 	return result
 }
 
+const (
+	// maxClockSkew is how far S3 lets the request time differ from its own
+	// clock before refusing a request with RequestTimeTooSkewed
+	maxClockSkew = 15 * time.Minute
+
+	// clockSkewWarning is the skew the authentication check warns about
+	// while requests still succeed
+	clockSkewWarning = 5 * time.Minute
+)
+
+// clockSkew returns how far the local clock, read at local, is ahead of the
+// server's Date header; it is negative when the local clock is behind. The
+// header has whole seconds, so the server is taken to be half a second
+// past it and the skew is rounded to seconds.
+func clockSkew(header http.Header, local time.Time) (time.Duration, bool) {
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		return 0, false
+	}
+	return local.Sub(date.Add(500 * time.Millisecond)).Round(time.Second), true
+}
+
+// describeClockSkew describes a clock skew relative to the server
+func describeClockSkew(skew time.Duration) string {
+	switch {
+	case skew > time.Second:
+		return fmt.Sprintf("%v ahead of the server's", skew)
+	case skew < -time.Second:
+		return fmt.Sprintf("%v behind the server's", -skew)
+	default:
+		return "in sync with the server's"
+	}
+}
+
 // cleanHost removes default ports from host (443 for HTTPS, 80 for HTTP)
 func cleanHost(host string, scheme string) string {
 	// Remove default ports
@@ -237,45 +405,85 @@ func cleanHost(host string, scheme string) string {
 	return host
 }
 
-// createRequest creates the HTTP request for authentication check
-func (c *AuthChecker) createRequest() (*http.Request, error) {
-	// Parse endpoint
-	endpointURL, err := url.Parse(c.Endpoint)
-	if err != nil {
-		return nil, fmt.Errorf("invalid endpoint: %w", err)
-	}
-
-	// Clean host by removing default ports
-	cleanHost := cleanHost(endpointURL.Host, endpointURL.Scheme)
-
+// createRequest creates the HTTP request for authentication check: HEAD
+// Bucket, or ListObjectsV2 when HEAD is denied
+func (c *AuthChecker) createRequest(method string, query url.Values) (*http.Request, error) {
 	// Build bucket URL based on addressing style
-	var bucketURL string
-	var hostHeader string
-
-	if c.PathStyle {
-		// Path-style addressing: https://endpoint/bucket
-		bucketURL = fmt.Sprintf("%s://%s/%s", endpointURL.Scheme, cleanHost, c.Bucket)
-		hostHeader = cleanHost
-	} else {
-		// Virtual-hosted addressing (default): https://bucket.endpoint
-		bucketURL = fmt.Sprintf("%s://%s.%s", endpointURL.Scheme, c.Bucket, cleanHost)
-		hostHeader = fmt.Sprintf("%s.%s", c.Bucket, cleanHost)
+	bucketURL, err := bucketBaseURL(c.Endpoint, c.Bucket, c.PathStyle)
+	if err != nil {
+		return nil, err
 	}
+	bucketURL.RawQuery = canonicalQueryString(query)
 
-	// Create HEAD request to check bucket existence
-	req, err := http.NewRequest("HEAD", bucketURL, nil)
+	req, err := http.NewRequest(method, bucketURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// Set headers
-	req.Header.Set("Host", hostHeader)
+	req.Header.Set("Host", bucketURL.Host)
 	req.Header.Set("User-Agent", "s3-bucket-tester/1.0")
 	req.Header.Set("Date", time.Now().UTC().Format(time.RFC1123))
 
 	return req, nil
 }
 
+// signRequest adds the authentication headers of the configured auth type
+func (c *AuthChecker) signRequest(req *http.Request) error {
+	if c.AuthType == "sigv2" {
+		c.verbose.LogMessage("Using AWS Signature Version 2 authentication")
+		if err := c.addSigV2Auth(req); err != nil {
+			c.verbose.LogMessage("Failed to add SigV2 auth: %v", err)
+			return fmt.Errorf("failed to add SigV2 auth: %v", err)
+		}
+		return nil
+	}
+
+	// Default to SigV4
+	c.verbose.LogMessage("Using AWS Signature Version 4 authentication")
+	if err := c.addSigV4Auth(req); err != nil {
+		c.verbose.LogMessage("Failed to add SigV4 auth: %v", err)
+		return fmt.Errorf("failed to add SigV4 auth: %v", err)
+	}
+	return nil
+}
+
+// listBucket lists one key of the bucket with ListObjectsV2
+func (c *AuthChecker) listBucket(ctx context.Context, client *http.Client) (*http.Response, []byte, error) {
+	req, err := c.createRequest("GET", url.Values{"list-type": {"2"}, "max-keys": {"1"}})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := c.signRequest(req); err != nil {
+		return nil, nil, err
+	}
+	c.verbose.LogRequest(req)
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	c.verbose.LogResponse(resp)
+
+	// HEAD responses have no body, so a SignatureDoesNotMatch of the
+	// authentication check can only be explained here
+	body, err := io.ReadAll(resp.Body)
+	if err == nil && resp.StatusCode == http.StatusForbidden {
+		c.verbose.LogSignatureMismatch(c.canonicalRequest, c.stringToSign, body)
+	}
+	return resp, body, err
+}
+
+// isBucketListing reports whether a body is a bucket listing, rather than
+// the bucket list of a service root that was reached instead
+func isBucketListing(body []byte) bool {
+	var listing struct {
+		XMLName xml.Name
+	}
+	return xml.Unmarshal(body, &listing) == nil && listing.XMLName.Local == "ListBucketResult"
+}
+
 // addSigV4Auth adds AWS Signature Version 4 authentication
 func (c *AuthChecker) addSigV4Auth(req *http.Request) error {
 	// Get current time
@@ -293,16 +501,23 @@ func (c *AuthChecker) addSigV4Auth(req *http.Request) error {
 	if canonicalURI == "" {
 		canonicalURI = "/"
 	}
-	canonicalQueryString := ""
+	canonicalQuery := canonicalQueryString(req.URL.Query())
 	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:UNSIGNED-PAYLOAD\nx-amz-date:%s\n", req.Host, amzDate)
 	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
 
+	// Temporary (STS) credentials must send and sign the session token
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+		canonicalHeaders += fmt.Sprintf("x-amz-security-token:%s\n", c.SessionToken)
+		signedHeaders += ";x-amz-security-token"
+	}
+
 	payloadHash := "UNSIGNED-PAYLOAD"
 
 	canonicalRequest := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s",
 		req.Method,
 		canonicalURI,
-		canonicalQueryString,
+		canonicalQuery,
 		canonicalHeaders,
 		signedHeaders,
 		payloadHash)
@@ -317,6 +532,8 @@ func (c *AuthChecker) addSigV4Auth(req *http.Request) error {
 	// Calculate signature
 	signingKey := c.getSignatureKey(dateStamp)
 	signature := hmacSHA256(signingKey, stringToSign)
+	c.canonicalRequest, c.stringToSign = canonicalRequest, stringToSign
+	c.verbose.LogSigning(canonicalRequest, stringToSign, credentialScope)
 
 	// Create authorization header
 	authorizationHeader := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
@@ -337,12 +554,17 @@ func (c *AuthChecker) addSigV2Auth(req *http.Request) error {
 
 	// Set headers
 	req.Header.Set("Date", now.Format(time.RFC1123))
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
 
 	// Create canonical string
 	canonicalString := c.createSigV2CanonicalString(req)
 
 	// Calculate signature
 	signature := hmacSHA256([]byte(c.SecretKey), canonicalString)
+	c.canonicalRequest, c.stringToSign = "", canonicalString
+	c.verbose.LogSigning("", canonicalString, "")
 
 	// Add signature to query string
 	if req.URL.RawQuery == "" {
@@ -379,6 +601,13 @@ func (c *AuthChecker) createSigV2CanonicalString(req *http.Request) string {
 	buf.WriteString(req.Header.Get("Date"))
 	buf.WriteString("\n")
 
+	// CanonicalizedAmzHeaders (only the session token is sent)
+	if token := req.Header.Get("X-Amz-Security-Token"); token != "" {
+		buf.WriteString("x-amz-security-token:")
+		buf.WriteString(token)
+		buf.WriteString("\n")
+	}
+
 	// CanonicalizedResource
 	// For SigV2, the resource path should include the bucket
 	canonicalizedResource := c.getCanonicalizedResource(req)
@@ -415,11 +644,7 @@ func (c *AuthChecker) getCanonicalizedResource(req *http.Request) string {
 
 // getSignatureKey derives the signing key for SigV4
 func (c *AuthChecker) getSignatureKey(dateStamp string) []byte {
-	kDate := hmacSHA256([]byte("AWS4"+c.SecretKey), dateStamp)
-	kRegion := hmacSHA256(kDate, c.Region)
-	kService := hmacSHA256(kRegion, "s3")
-	kSigning := hmacSHA256(kService, "aws4_request")
-	return kSigning
+	return deriveSigningKey(c.SecretKey, dateStamp, c.Region, "s3")
 }
 
 // hashSHA256 returns the SHA256 hash of the input
@@ -457,4 +682,5 @@ type ErrorResponse struct {
 	Message   string   `xml:"Message"`
 	Resource  string   `xml:"Resource"`
 	RequestID string   `xml:"RequestId"`
+	Region    string   `xml:"Region"`
 }
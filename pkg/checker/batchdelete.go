@@ -0,0 +1,249 @@
+package checker
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// BatchDeleteChecker verifies multi-object deletes with DeleteObjects, which
+// sync tools and lifecycle scripts use to remove many keys in one request
+type BatchDeleteChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewBatchDeleteChecker creates a new batch delete checker
+func NewBatchDeleteChecker(config output.Config) *BatchDeleteChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &BatchDeleteChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *BatchDeleteChecker) Name() string {
+	return "Batch Delete Check"
+}
+
+// deleteRequest is the DeleteObjects request body
+type deleteRequest struct {
+	XMLName xml.Name `xml:"Delete"`
+	Quiet   bool     `xml:"Quiet,omitempty"`
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+// deleteResult is the DeleteObjects response
+type deleteResult struct {
+	Deleted []struct {
+		Key string `xml:"Key"`
+	} `xml:"Deleted"`
+	Errors []struct {
+		Key  string `xml:"Key"`
+		Code string `xml:"Code"`
+	} `xml:"Error"`
+}
+
+// Check uploads test objects, one with a key that needs escaping in XML, and
+// deletes them with DeleteObjects together with a key that never existed,
+// which S3 reports as deleted. It then checks that the objects are gone and
+// that a quiet delete reports nothing. It fails when DeleteObjects is not
+// supported or an object reported deleted still exists, and warns on a
+// quirk: the missing key reported as an error or quiet mode ignored.
+func (c *BatchDeleteChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Batch Delete Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	keys := []string{
+		c.client.testObjectKey("batch-delete-1"),
+		c.client.testObjectKey("batch-delete-2"),
+		c.client.testObjectKey("batch-delete & ä"),
+	}
+	quietKey := c.client.testObjectKey("batch-delete-quiet")
+	missingKey := c.client.testObjectKey("batch-delete-missing")
+	batch := output.BatchDeleteResult{Keys: keys}
+
+	// Whatever DeleteObjects leaves behind is removed one by one
+	defer c.client.cleanup(func() {
+		for _, key := range append(keys, quietKey) {
+			if err := c.client.deleteObject(key); err != nil {
+				c.verbose.LogMessage("Failed to delete %s: %v", key, err)
+			}
+		}
+	})
+
+	for _, key := range append(keys, quietKey) {
+		put, err := sendOperation(c.client, "PUT", key, nil, []byte("s3tester batch delete test\n"), http.Header{"Content-Type": {"text/plain"}})
+		if err == nil && put.status >= 300 {
+			err = fmt.Errorf("%s", parseErrorResponse(put.status, put.body))
+		}
+		if err != nil {
+			result.Status = output.StatusFail
+			result.Error = fmt.Sprintf("failed to upload test object %s: %v", key, err)
+			result.Duration = time.Since(startTime)
+			return result
+		}
+	}
+
+	batch.DeleteObjects = c.deleteObjects(keys, missingKey, quietKey, &batch)
+
+	switch {
+	case batch.DeleteObjects == OperationDenied:
+		result.Status = output.StatusWarn
+		result.Error = "DeleteObjects denied, whether it is supported could not be tested"
+	case batch.DeleteObjects != OperationSupported:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("DeleteObjects is %s: tools that delete many objects at once fail", batch.DeleteObjects)
+		if len(batch.Quirks) > 0 {
+			result.Error += ": " + strings.Join(batch.Quirks, ", ")
+		}
+	case len(batch.Quirks) > 0:
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("DeleteObjects works with quirks: %s", strings.Join(batch.Quirks, ", "))
+	}
+
+	result.Details = batch
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Batch delete check completed in %v", result.Duration)
+
+	return result
+}
+
+// deleteObjects deletes the keys and the missing key in one request, checks
+// that the keys are gone and then deletes the quiet key in quiet mode
+func (c *BatchDeleteChecker) deleteObjects(keys []string, missingKey, quietKey string, batch *output.BatchDeleteResult) string {
+	resp, err := c.send(append(keys, missingKey), false)
+	var deleted deleteResult
+	if err == nil && resp.status == http.StatusOK {
+		xml.Unmarshal(resp.body, &deleted)
+	}
+	reported := make(map[string]string)
+	for _, d := range deleted.Deleted {
+		reported[d.Key] = "deleted"
+	}
+	for _, e := range deleted.Errors {
+		reported[e.Key] = e.Code
+	}
+
+	step := newStep(c.verbose, "DeleteObjects", "200 with every key Deleted", resp.status, resp.code, err, resultElement(resp.body) == "DeleteResult" && len(deleted.Deleted) == len(keys)+1)
+	if err == nil && resp.status == http.StatusOK {
+		step.Observed += fmt.Sprintf(", %d deleted, %d errors", len(deleted.Deleted), len(deleted.Errors))
+	}
+	batch.Steps = append(batch.Steps, step)
+
+	switch {
+	case err != nil:
+		return OperationBroken
+	case resp.status == http.StatusNotImplemented || resp.code == "NotImplemented":
+		return OperationNotSupported
+	case resp.status == http.StatusForbidden:
+		return OperationDenied
+	case resp.status != http.StatusOK || resultElement(resp.body) != "DeleteResult":
+		return OperationBroken
+	}
+
+	outcome := OperationSupported
+	var failed []string
+	for _, key := range keys {
+		if reported[key] != "deleted" {
+			failed = append(failed, fmt.Sprintf("%s: %s", key, orNotReported(reported[key])))
+		}
+	}
+	if len(failed) > 0 {
+		batch.Quirks = append(batch.Quirks, "keys not deleted: "+strings.Join(failed, ", "))
+		outcome = OperationBroken
+	}
+	if code := reported[missingKey]; code != "deleted" {
+		batch.Quirks = append(batch.Quirks, "a missing key reported as "+orNotReported(code))
+	}
+
+	var remaining []string
+	for _, key := range keys {
+		if head, err := sendOperation(c.client, "HEAD", key, nil, nil, nil); err != nil || head.status != http.StatusNotFound {
+			remaining = append(remaining, key)
+		}
+	}
+	batch.Steps = append(batch.Steps, output.Step{
+		Step:     "HEAD deleted objects",
+		Expected: "404 for each",
+		Observed: fmt.Sprintf("%d of %d still exist", len(remaining), len(keys)),
+		Match:    len(remaining) == 0,
+	})
+	if len(remaining) > 0 {
+		batch.Quirks = append(batch.Quirks, "reported deleted but still exist: "+strings.Join(remaining, ", "))
+		outcome = OperationBroken
+	}
+
+	quiet, err := c.send([]string{quietKey}, true)
+	var quietResult deleteResult
+	if err == nil && quiet.status == http.StatusOK {
+		xml.Unmarshal(quiet.body, &quietResult)
+	}
+	quietStep := newStep(c.verbose, "DeleteObjects quiet", "200 without Deleted entries", quiet.status, quiet.code, err,
+		resultElement(quiet.body) == "DeleteResult" && len(quietResult.Deleted) == 0 && len(quietResult.Errors) == 0)
+	if err == nil && quiet.status == http.StatusOK {
+		quietStep.Observed += fmt.Sprintf(", %d deleted entries", len(quietResult.Deleted))
+	}
+	batch.Steps = append(batch.Steps, quietStep)
+	if len(quietResult.Deleted) > 0 {
+		batch.Quirks = append(batch.Quirks, "quiet mode ignored")
+	}
+
+	return outcome
+}
+
+// send sends a DeleteObjects request for the keys. The request is sent to the
+// bucket, so newRequest cannot confine it to the test prefix; the keys are
+// checked here instead.
+func (c *BatchDeleteChecker) send(keys []string, quiet bool) (operationResponse, error) {
+	request := deleteRequest{Quiet: quiet}
+	for _, key := range keys {
+		if c.client.writeScope == "" || !strings.HasPrefix(key, c.client.writeScope) {
+			return operationResponse{}, fmt.Errorf("refusing to delete %q outside the test prefix %q", key, c.client.writeScope)
+		}
+		request.Objects = append(request.Objects, struct {
+			Key string `xml:"Key"`
+		}{key})
+	}
+	body, err := xml.Marshal(request)
+	if err != nil {
+		return operationResponse{}, err
+	}
+
+	// S3 requires a checksum of the request body
+	sum := md5.Sum(body)
+	return sendOperation(c.client, "POST", "", url.Values{"delete": {""}}, body, http.Header{
+		"Content-Type": {"application/xml"},
+		"Content-Md5":  {base64.StdEncoding.EncodeToString(sum[:])},
+	})
+}
+
+// orNotReported names a key's DeleteObjects outcome, which may be missing
+// from the response
+func orNotReported(code string) string {
+	if code == "" {
+		return "not reported"
+	}
+	return code
+}
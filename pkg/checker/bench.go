@@ -0,0 +1,191 @@
+package checker
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// Benchmark measures upload and download throughput against the bucket
+type Benchmark struct {
+	config  output.Config
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewBenchmark creates a new benchmark for the configured workload
+func NewBenchmark(config output.Config) *Benchmark {
+	verbose := NewVerboseLogger(config.Verbose)
+	client := newS3Client(config, verbose)
+
+	// Keep one connection per worker alive between requests
+	if transport := httpTransport(client.httpClient); transport != nil {
+		transport.MaxIdleConnsPerHost = config.BenchConcurrency
+	}
+
+	return &Benchmark{
+		config:  config,
+		client:  client,
+		verbose: verbose,
+	}
+}
+
+// benchSample is the outcome of one benchmark request
+type benchSample struct {
+	latencyMs float64
+	bytes     int64
+	err       error
+}
+
+// Run uploads the objects, downloads them and deletes them again. Only the
+// uploads and downloads are timed.
+func (b *Benchmark) Run() *output.BenchReport {
+	report := &output.BenchReport{
+		Endpoint:    b.config.Endpoint,
+		Bucket:      b.config.Bucket,
+		ObjectSize:  b.config.BenchSize,
+		Objects:     b.config.BenchCount,
+		Concurrency: b.config.BenchConcurrency,
+		StartTime:   time.Now(),
+	}
+
+	// Random content, so compression or deduplication cannot inflate the
+	// numbers; each object is made unique by its index in the first bytes
+	content := make([]byte, b.config.BenchSize)
+	if _, err := rand.Read(content); err != nil {
+		report.Upload = output.NewBenchPhase(nil, b.config.BenchCount, 0, 0, fmt.Sprintf("failed to generate test content: %v", err))
+		return report
+	}
+
+	prefix := b.client.testObjectKey("bench")
+	keys := make([]string, b.config.BenchCount)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%s/%06d", prefix, i)
+	}
+
+	b.verbose.LogSection("Starting Benchmark")
+	b.verbose.LogMessage("%d objects of %d bytes under %s/, %d workers", len(keys), b.config.BenchSize, prefix, b.config.BenchConcurrency)
+
+	report.Upload = b.runPhase(keys, func(i int, key string) benchSample {
+		return b.put(key, content, i)
+	})
+	b.verbose.LogMessage("Upload: %.2f MB/s, %d errors", report.Upload.ThroughputMBps, report.Upload.Errors)
+
+	report.Download = b.runPhase(keys, func(_ int, key string) benchSample {
+		return b.get(key)
+	})
+	b.verbose.LogMessage("Download: %.2f MB/s, %d errors", report.Download.ThroughputMBps, report.Download.Errors)
+
+	// Always delete, including objects whose upload reported an error
+	var mu sync.Mutex
+	b.client.cleanup(func() {
+		b.forEach(keys, func(_ int, key string) {
+			err := b.client.deleteObject(key)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Cleanup.Errors++
+				if report.Cleanup.FirstError == "" {
+					report.Cleanup.FirstError = err.Error()
+				}
+				return
+			}
+			report.Cleanup.Deleted++
+		})
+	})
+
+	report.DurationMs = float64(time.Since(report.StartTime).Microseconds()) / 1000
+
+	return report
+}
+
+// runPhase runs op for every key with the configured concurrency and
+// aggregates the samples
+func (b *Benchmark) runPhase(keys []string, op func(i int, key string) benchSample) output.BenchPhase {
+	samples := make([]benchSample, len(keys))
+	start := time.Now()
+	b.forEach(keys, func(i int, key string) {
+		samples[i] = op(i, key)
+	})
+	elapsed := time.Since(start)
+
+	var latencies []float64
+	var bytes int64
+	errors := 0
+	firstError := ""
+	for _, sample := range samples {
+		if sample.err != nil {
+			errors++
+			if firstError == "" {
+				firstError = sample.err.Error()
+			}
+			continue
+		}
+		latencies = append(latencies, sample.latencyMs)
+		bytes += sample.bytes
+	}
+
+	return output.NewBenchPhase(latencies, errors, bytes, elapsed, firstError)
+}
+
+// forEach calls fn for every key from BenchConcurrency workers
+func (b *Benchmark) forEach(keys []string, fn func(i int, key string)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < b.config.BenchConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i, keys[i])
+			}
+		}()
+	}
+
+	for i := range keys {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// put uploads one object, stamping its index into a private copy of content
+func (b *Benchmark) put(key string, content []byte, index int) benchSample {
+	body := make([]byte, len(content))
+	copy(body, content)
+	if len(body) >= 8 {
+		binary.BigEndian.PutUint64(body, uint64(index))
+	}
+
+	req, err := b.client.newRequest("PUT", key, nil, body)
+	if err != nil {
+		return benchSample{err: err}
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	start := time.Now()
+	resp, respBody, err := b.client.do(req, body)
+	sample := benchSample{latencyMs: durationToMs(time.Since(start)), bytes: int64(len(body))}
+	if err == nil && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		err = fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, respBody))
+	}
+	sample.err = err
+	return sample
+}
+
+// get downloads one object and checks its size
+func (b *Benchmark) get(key string) benchSample {
+	start := time.Now()
+	_, body, err := b.client.getObject(key)
+	sample := benchSample{latencyMs: durationToMs(time.Since(start)), bytes: int64(len(body))}
+	if err == nil && sample.bytes != b.config.BenchSize {
+		err = fmt.Errorf("received %d bytes, expected %d", sample.bytes, b.config.BenchSize)
+	}
+	sample.err = err
+	return sample
+}
@@ -0,0 +1,104 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// bucketHost returns the host virtual-hosted requests are sent to, or "" when
+// requests do not go to a bucket host of their own: with path-style
+// addressing, an IP address endpoint, or a fixed --sni server name
+func (c *TLSChecker) bucketHost() string {
+	if c.Config.PathStyle || c.Config.Bucket == "" || c.Config.SNI != "" || net.ParseIP(c.Host) != nil {
+		return ""
+	}
+	return c.Config.Bucket + "." + c.Host
+}
+
+// checkBucketHost verifies that the certificate served for the bucket host
+// covers it. The server is asked for that certificate by SNI on the endpoint's
+// address, as the S3 requests do; when that handshake fails, the endpoint's
+// certificate is checked instead.
+func (c *TLSChecker) checkBucketHost(ctx context.Context, address, host string, endpointCert *x509.Certificate) *output.BucketHostCoverage {
+	cert := endpointCert
+	conn, err := dialTLS(ctx, c.Config, address, time.Duration(c.Config.TCPTimeout)*time.Second, &tls.Config{
+		InsecureSkipVerify: true,
+		ServerName:         host,
+		MinVersion:         tls.VersionTLS12,
+		Certificates:       clientCertificates(c.Config),
+	})
+	if err != nil {
+		c.verbose.LogMessage("Handshake for bucket host %s failed, checking the endpoint certificate: %v", host, err)
+	} else {
+		if certs := conn.ConnectionState().PeerCertificates; len(certs) > 0 {
+			cert = certs[0]
+		}
+		conn.Close()
+	}
+
+	coverage := matchBucketHost(host, c.Config.Bucket, cert.DNSNames)
+	coverage.Subject = cert.Subject.String()
+	c.verbose.LogMessage("Bucket host %s: covered %v (%s)", host, coverage.Covered, coverage.Match)
+	return coverage
+}
+
+// matchBucketHost matches the bucket host against the DNS SANs of a
+// certificate. A wildcard stands for exactly one leftmost label, so
+// *.s3.example.com covers bucket.s3.example.com but not a.b.s3.example.com,
+// the host of the bucket a.b.
+func matchBucketHost(host, bucket string, sans []string) *output.BucketHostCoverage {
+	coverage := &output.BucketHostCoverage{Host: host}
+	host = strings.ToLower(host)
+	parent := host[strings.Index(host, ".")+1:]
+
+	for _, san := range sans {
+		san = strings.ToLower(strings.TrimSuffix(san, "."))
+		switch {
+		case san == host:
+			coverage.Covered = true
+			coverage.Match = output.BucketHostExact
+			coverage.SAN = san
+			return coverage
+		case strings.HasPrefix(san, "*.") && san[2:] == parent:
+			coverage.Covered = true
+			coverage.Match = output.BucketHostWildcard
+			coverage.SAN = san
+		}
+	}
+	if coverage.Covered {
+		return coverage
+	}
+
+	// A dotted bucket name puts its host several labels below the endpoint,
+	// where the endpoint's wildcard does not reach
+	if strings.Contains(bucket, ".") {
+		endpoint := strings.ToLower(strings.TrimPrefix(host, strings.ToLower(bucket)+"."))
+		for _, san := range sans {
+			if strings.EqualFold(strings.TrimSuffix(san, "."), "*."+endpoint) {
+				coverage.Match = output.BucketHostDottedBucket
+				coverage.SAN = san
+				return coverage
+			}
+		}
+	}
+
+	coverage.Match = output.BucketHostNotCovered
+	return coverage
+}
+
+// bucketHostError describes a bucket host the certificate does not cover
+func bucketHostError(coverage *output.BucketHostCoverage, bucket string) string {
+	if coverage.Match == output.BucketHostDottedBucket {
+		return fmt.Sprintf("certificate does not cover the virtual-hosted bucket host %s: bucket name %q contains dots, and the wildcard %s covers only one label; use --path-style",
+			coverage.Host, bucket, coverage.SAN)
+	}
+	return fmt.Sprintf("certificate does not cover the virtual-hosted bucket host %s: no SAN matches it or a *.%s wildcard; use --path-style or add the SAN",
+		coverage.Host, coverage.Host[strings.Index(coverage.Host, ".")+1:])
+}
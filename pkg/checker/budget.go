@@ -0,0 +1,77 @@
+package checker
+
+import (
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// Budget divides the time of --budget among the checks of a run. The core
+// connectivity and authentication checks run first and may use all of what
+// is left; each optional check then gets a share of the remaining time in
+// proportion to its weight among the optional checks still to run, so time
+// a fast check leaves unused carries over to the ones after it.
+type Budget struct {
+	config   output.Config
+	total    time.Duration
+	deadline time.Time
+}
+
+// Allowance is the time a check may use. A zero Total means there is no
+// budget and no limit.
+type Allowance struct {
+	Limit time.Duration
+	Total time.Duration
+
+	// Optional is set for registry checks, which are skipped rather than
+	// failed when the budget cuts them off
+	Optional bool
+}
+
+// NewBudget starts the budget of a run, or returns nil when no budget was
+// set. A nil Budget hands out unlimited allowances.
+func NewBudget(config output.Config) *Budget {
+	if config.BudgetMs <= 0 {
+		return nil
+	}
+
+	total := time.Duration(config.BudgetMs) * time.Millisecond
+	return &Budget{config: config, total: total, deadline: time.Now().Add(total)}
+}
+
+// Core returns the allowance of a core check: whatever is left
+func (b *Budget) Core() Allowance {
+	if b == nil {
+		return Allowance{}
+	}
+	return Allowance{Limit: time.Until(b.deadline), Total: b.total}
+}
+
+// Optional returns the allowance of an optional check, about to run. Checks
+// after it that are disabled, or that blocked reports will be skipped
+// because a check they depend on failed, get no share.
+func (b *Budget) Optional(reg Registration, blocked func(name string) bool) Allowance {
+	if b == nil {
+		return Allowance{}
+	}
+
+	pending := 0
+	for i := len(Registry) - 1; i >= 0 && Registry[i].Name != reg.Name; i-- {
+		later := Registry[i]
+		if later.Enabled(b.config) && !(b.config.ReadOnly && later.Mutates(b.config)) && !blocked(later.Name) {
+			pending += later.weight()
+		}
+	}
+
+	weight := reg.weight()
+	share := time.Until(b.deadline) * time.Duration(weight) / time.Duration(weight+pending)
+	return Allowance{Limit: share, Total: b.total, Optional: true}
+}
+
+// weight returns the registration's weight, defaulting to 1
+func (reg Registration) weight() int {
+	if reg.Weight < 1 {
+		return 1
+	}
+	return reg.Weight
+}
@@ -0,0 +1,180 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+const (
+	// testCacheControl is the Cache-Control value stored on the test object
+	testCacheControl = "public, max-age=3600"
+
+	// testExpires is the Expires value stored on the test object
+	testExpires = "Wed, 21 Oct 2037 07:28:00 GMT"
+)
+
+// cdnHeaders lists response headers that indicate a cache or CDN in the path
+var cdnHeaders = []string{
+	"Age",
+	"Via",
+	"X-Cache",
+	"X-Cache-Hits",
+	"X-Served-By",
+	"Cf-Cache-Status",
+	"Cf-Ray",
+	"X-Amz-Cf-Id",
+	"X-Amz-Cf-Pop",
+	"X-Fastly-Request-Id",
+	"X-Akamai-Request-Id",
+	"X-Varnish",
+	"X-Proxy-Cache",
+}
+
+// CacheHeaderChecker verifies that Cache-Control and Expires set on an object
+// are returned unchanged and reports caches detected in the path
+type CacheHeaderChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewCacheHeaderChecker creates a new cache header checker
+func NewCacheHeaderChecker(config output.Config) *CacheHeaderChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &CacheHeaderChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *CacheHeaderChecker) Name() string {
+	return "Cache Header Check"
+}
+
+// Check performs the cache header check
+func (c *CacheHeaderChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Cache Header Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+		Duration: time.Since(startTime),
+	}
+
+	key := c.client.testObjectKey("cache")
+	body := []byte("s3tester cache header test\n")
+
+	cacheResult := output.CacheHeaderResult{
+		Key: key,
+		Headers: []output.CacheHeaderComparison{
+			{Name: "Cache-Control", Sent: testCacheControl},
+			{Name: "Expires", Sent: testExpires},
+		},
+	}
+
+	req, err := c.client.newRequest("PUT", key, nil, body)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	for _, h := range cacheResult.Headers {
+		req.Header.Set(h.Name, h.Sent)
+	}
+
+	c.verbose.LogMessage("Uploading %s with Cache-Control %q and Expires %q", key, testCacheControl, testExpires)
+
+	resp, respBody, err := c.client.do(req, body)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("PUT failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("PUT failed: %s", parseErrorResponse(resp.StatusCode, respBody))
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	resp, _, getErr := c.client.getObject(key)
+
+	c.client.cleanup(func() {
+		if err := c.client.deleteObject(key); err != nil {
+			c.verbose.LogMessage("Failed to delete test object %s: %v", key, err)
+		}
+	})
+
+	if getErr != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GET failed: %v", getErr)
+		result.Details = cacheResult
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	var altered []string
+	for i := range cacheResult.Headers {
+		h := &cacheResult.Headers[i]
+		h.Received = resp.Header.Get(h.Name)
+		h.Unchanged = h.Received == h.Sent
+		if !h.Unchanged {
+			altered = append(altered, fmt.Sprintf("%s returned as %q", h.Name, h.Received))
+		}
+		c.verbose.LogMessage("%s: sent %q, received %q", h.Name, h.Sent, h.Received)
+	}
+
+	cacheResult.CDNHeaders = detectCDNHeaders(resp.Header)
+	cacheResult.CacheDetected = len(cacheResult.CDNHeaders) > 0
+	if cacheResult.CacheDetected {
+		c.verbose.LogMessage("Cache or CDN headers detected: %d", len(cacheResult.CDNHeaders))
+	}
+
+	if len(altered) > 0 {
+		result.Status = output.StatusWarn
+		result.Error = strings.Join(altered, "; ")
+	}
+
+	result.Details = cacheResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Cache header check completed in %v", result.Duration)
+
+	return result
+}
+
+// detectCDNHeaders returns the cache and CDN headers present in a response
+func detectCDNHeaders(header http.Header) map[string]string {
+	found := make(map[string]string)
+	for _, name := range cdnHeaders {
+		if value := header.Get(name); value != "" {
+			found[name] = value
+		}
+	}
+
+	// Catch vendor-specific headers not in the list above
+	for name, values := range header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-akamai-") || strings.HasPrefix(lower, "x-fastly-") {
+			found[http.CanonicalHeaderKey(name)] = strings.Join(values, ", ")
+		}
+	}
+
+	if len(found) == 0 {
+		return nil
+	}
+	return found
+}
@@ -0,0 +1,223 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/options"
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// CapabilityChecker verifies that the endpoint provides the required capabilities
+type CapabilityChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewCapabilityChecker creates a new capability requirements checker
+func NewCapabilityChecker(config output.Config) *CapabilityChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &CapabilityChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *CapabilityChecker) Name() string {
+	return "Capability Requirements Check"
+}
+
+// Check probes each required capability and compares it with the requirement
+func (c *CapabilityChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Capability Requirements Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+		Duration: time.Since(startTime),
+	}
+
+	requirements, err := options.ParseRequirements(c.Config.Require)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = err.Error()
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	capabilityResult := output.CapabilityResult{}
+	probed := make(map[string]output.CapabilityStatus)
+	var unmet []string
+
+	for _, req := range requirements {
+		status, ok := probed[req.Capability]
+		if !ok {
+			status = c.probe(req.Capability)
+			probed[req.Capability] = status
+			capabilityResult.Capabilities = append(capabilityResult.Capabilities, status)
+			c.verbose.LogMessage("%s: supported=%v, value=%q %s", status.Name, status.Supported, status.Value, status.Detail)
+		}
+
+		reqResult := output.RequirementResult{
+			Requirement: req.String(),
+			Detected:    status.Value,
+			Met:         requirementMet(req, status),
+		}
+		if !reqResult.Met {
+			reqResult.Reason = unmetReason(req, status)
+			unmet = append(unmet, fmt.Sprintf("%s: %s", req, reqResult.Reason))
+		}
+		capabilityResult.Requirements = append(capabilityResult.Requirements, reqResult)
+	}
+
+	if len(unmet) > 0 {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("requirements not met: %s", strings.Join(unmet, "; "))
+	}
+
+	result.Details = capabilityResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Capability requirements check completed in %v", result.Duration)
+
+	return result
+}
+
+// probe determines whether and how a capability is provided by the endpoint
+func (c *CapabilityChecker) probe(capability string) output.CapabilityStatus {
+	status := output.CapabilityStatus{Name: capability}
+
+	// Every capability name is also the bucket sub-resource that configures it
+	req, err := c.client.newRequest("GET", "", url.Values{capability: {""}}, nil)
+	if err != nil {
+		status.Value = "unknown"
+		status.Detail = err.Error()
+		return status
+	}
+
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		status.Value = "unknown"
+		status.Detail = err.Error()
+		return status
+	}
+
+	code := errorCode(body)
+	switch {
+	case resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusMethodNotAllowed ||
+		code == "NotImplemented" || code == "MethodNotAllowed":
+		status.Value = "none"
+		status.Detail = "not implemented by the endpoint"
+		return status
+	case resp.StatusCode == http.StatusOK:
+		status.Supported = true
+	case resp.StatusCode == http.StatusNotFound && code != "" && code != "NoSuchBucket":
+		// The API exists but nothing is configured (e.g. NoSuchBucketPolicy)
+		status.Supported = true
+	default:
+		status.Value = "unknown"
+		status.Detail = fmt.Sprintf("could not verify: %s", parseErrorResponse(resp.StatusCode, body))
+		return status
+	}
+
+	switch capability {
+	case "versioning":
+		var v struct {
+			Status string `xml:"Status"`
+		}
+		xml.Unmarshal(body, &v)
+		status.Value = strings.ToLower(v.Status)
+		if status.Value == "" {
+			status.Value = "disabled"
+		}
+	case "encryption":
+		var e struct {
+			Algorithm string `xml:"Rule>ApplyServerSideEncryptionByDefault>SSEAlgorithm"`
+		}
+		if resp.StatusCode == http.StatusOK {
+			xml.Unmarshal(body, &e)
+		}
+		status.Value = strings.ToLower(e.Algorithm)
+		if status.Value == "" {
+			status.Value = "none"
+		}
+	case "object-lock":
+		var l struct {
+			Enabled string `xml:"ObjectLockEnabled"`
+		}
+		if resp.StatusCode == http.StatusOK {
+			xml.Unmarshal(body, &l)
+		}
+		status.Value = "disabled"
+		if strings.EqualFold(l.Enabled, "Enabled") {
+			status.Value = "enabled"
+		}
+	case "policy":
+		status.Value = declaredSupport(c.Config.PolicySupport)
+	case "acl":
+		status.Value = declaredSupport(c.Config.ACLSupport)
+	}
+
+	return status
+}
+
+// declaredSupport normalizes the support level the provider is known for
+func declaredSupport(level string) string {
+	if level == "" {
+		return "unknown"
+	}
+	return strings.ToLower(level)
+}
+
+// errorCode returns the S3 error code of a response body, if any
+func errorCode(body []byte) string {
+	var errResp ErrorResponse
+	if err := xml.Unmarshal(body, &errResp); err != nil {
+		return ""
+	}
+	return errResp.Code
+}
+
+// requirementMet reports whether a probed capability satisfies a requirement
+func requirementMet(req options.Requirement, status output.CapabilityStatus) bool {
+	if !status.Supported {
+		return false
+	}
+
+	if req.Value != "" {
+		normalize := func(s string) string { return strings.ReplaceAll(strings.ToLower(s), "-", " ") }
+		return normalize(status.Value) == normalize(req.Value)
+	}
+
+	switch req.Capability {
+	case "versioning", "object-lock":
+		return status.Value == "enabled"
+	default:
+		return status.Value != "none"
+	}
+}
+
+// unmetReason explains why a requirement is not satisfied
+func unmetReason(req options.Requirement, status output.CapabilityStatus) string {
+	switch {
+	case status.Value == "unknown" && status.Detail != "":
+		return status.Detail
+	case !status.Supported:
+		return "not supported"
+	case req.Value != "":
+		return fmt.Sprintf("detected %q", status.Value)
+	default:
+		return fmt.Sprintf("supported but %s", status.Value)
+	}
+}
@@ -0,0 +1,291 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// CapabilityProbeChecker tests the endpoint for the capabilities of the
+// provider capability matrix, so an unknown provider gets measured values
+// instead of the static "custom" entry
+type CapabilityProbeChecker struct {
+	BaseChecker
+	client        *s3Client
+	virtualHosted *s3Client
+	pathStyle     *s3Client
+	verbose       *VerboseLogger
+}
+
+// NewCapabilityProbeChecker creates a new capability probe checker
+func NewCapabilityProbeChecker(config output.Config) *CapabilityProbeChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	virtualHosted, pathStyle := config, config
+	virtualHosted.PathStyle = false
+	pathStyle.PathStyle = true
+	return &CapabilityProbeChecker{
+		BaseChecker:   NewBaseChecker(config),
+		client:        newS3Client(config, verbose),
+		virtualHosted: newS3Client(virtualHosted, verbose),
+		pathStyle:     newS3Client(pathStyle, verbose),
+		verbose:       verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *CapabilityProbeChecker) Name() string {
+	return "Capability Probe"
+}
+
+// Check probes both addressing styles, the policy, ACL and versioning APIs
+// and multipart uploads
+func (c *CapabilityProbeChecker) Check(ctx context.Context) output.TestResult {
+	for _, client := range []*s3Client{c.client, c.virtualHosted, c.pathStyle} {
+		client.bind(ctx)
+	}
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Capability Probe")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	virtualHosted := c.probeAddressing(c.virtualHosted, "virtual-hosted")
+	pathStyle := c.probeAddressing(c.pathStyle, "path-style")
+
+	// The API probes use the configured addressing style unless the other
+	// one fares better
+	client := c.client
+	configured, alternative, other := virtualHosted, pathStyle, c.pathStyle
+	if c.Config.PathStyle {
+		configured, alternative, other = pathStyle, virtualHosted, c.virtualHosted
+	}
+	if (configured.Outcome != output.ProbeSupported && alternative.Outcome == output.ProbeSupported) ||
+		(configured.Outcome == output.ProbeNotSupported && alternative.Outcome != output.ProbeNotSupported) {
+		client = other
+	}
+
+	policy := c.probeSubresource(client, "policy")
+	acl := c.probeSubresource(client, "acl")
+	versioning := c.probeSubresource(client, "versioning")
+	multipart := c.probeMultipart(client)
+
+	probeResult := output.CapabilityProbeResult{
+		Probes: []output.CapabilityProbe{virtualHosted, pathStyle, policy, acl, versioning, multipart},
+		Generated: output.GeneratedCapabilities{
+			PolicySupport:      supportLevel(policy),
+			ACLSupport:         supportLevel(acl),
+			VirtualHostSupport: virtualHosted.Outcome == output.ProbeSupported,
+			PathStyleSupport:   pathStyle.Outcome == output.ProbeSupported,
+			Versioning:         versioning.Outcome,
+			Multipart:          multipart.Outcome,
+		},
+	}
+
+	var undetermined []string
+	for _, probe := range probeResult.Probes {
+		c.verbose.LogMessage("%s: %s %s", probe.Name, probe.Outcome, probe.Detail)
+		if probe.Outcome == output.ProbeDenied || probe.Outcome == output.ProbeInconclusive {
+			undetermined = append(undetermined, fmt.Sprintf("%s (%s)", probe.Name, probe.Outcome))
+		}
+	}
+
+	switch {
+	case virtualHosted.Outcome == output.ProbeNotSupported && pathStyle.Outcome == output.ProbeNotSupported:
+		result.Status = output.StatusFail
+		result.Error = "neither virtual-hosted nor path-style requests reached the bucket"
+	case len(undetermined) > 0:
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("could not determine %s", strings.Join(undetermined, ", "))
+	}
+
+	result.Details = probeResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Capability probe completed in %v", result.Duration)
+
+	return result
+}
+
+// probeAddressing lists the bucket with one addressing style. The style is
+// supported when the listing names the bucket; a service root answering with
+// its bucket list means the request never reached the bucket.
+func (c *CapabilityProbeChecker) probeAddressing(client *s3Client, style string) output.CapabilityProbe {
+	probe := output.CapabilityProbe{Name: style}
+
+	if style == "virtual-hosted" && net.ParseIP(ParseHostname(c.Config.Endpoint)) != nil {
+		probe.Outcome = output.ProbeNotSupported
+		probe.Detail = "the endpoint is an IP address, which cannot carry the bucket as a host label"
+		return probe
+	}
+
+	req, err := client.newRequest("GET", "", url.Values{"list-type": {"2"}, "max-keys": {"0"}}, nil)
+	if err != nil {
+		probe.Outcome = output.ProbeInconclusive
+		probe.Detail = err.Error()
+		return probe
+	}
+
+	resp, body, err := client.do(req, nil)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			probe.Outcome = output.ProbeNotSupported
+			probe.Detail = fmt.Sprintf("%s does not resolve", req.URL.Hostname())
+			return probe
+		}
+		probe.Outcome = output.ProbeInconclusive
+		probe.Detail = err.Error()
+		return probe
+	}
+	probe.StatusCode = resp.StatusCode
+
+	var listing struct {
+		XMLName xml.Name
+		Name    string `xml:"Name"`
+	}
+	xml.Unmarshal(body, &listing)
+	code := errorCode(body)
+
+	switch {
+	case resp.StatusCode == http.StatusOK && listing.XMLName.Local == "ListBucketResult" && listing.Name == c.Config.Bucket:
+		probe.Outcome = output.ProbeSupported
+	case resp.StatusCode == http.StatusOK && listing.XMLName.Local == "ListAllMyBucketsResult":
+		probe.Outcome = output.ProbeNotSupported
+		probe.Detail = "the request reached the service root, not the bucket"
+	case resp.StatusCode == http.StatusForbidden && (code == "AccessDenied" || code == ""):
+		probe.Outcome = output.ProbeDenied
+		probe.Detail = parseErrorResponse(resp.StatusCode, body)
+	case resp.StatusCode == http.StatusNotFound && code == "NoSuchBucket",
+		resp.StatusCode == http.StatusBadRequest,
+		resp.StatusCode == http.StatusForbidden:
+		probe.Outcome = output.ProbeNotSupported
+		probe.Detail = parseErrorResponse(resp.StatusCode, body)
+	default:
+		probe.Outcome = output.ProbeInconclusive
+		probe.Detail = parseErrorResponse(resp.StatusCode, body)
+	}
+	return probe
+}
+
+// probeSubresource reads a bucket sub-resource to learn whether its API is
+// implemented; an empty configuration such as NoSuchBucketPolicy counts
+func (c *CapabilityProbeChecker) probeSubresource(client *s3Client, name string) output.CapabilityProbe {
+	probe := output.CapabilityProbe{Name: name}
+
+	req, err := client.newRequest("GET", "", url.Values{name: {""}}, nil)
+	if err != nil {
+		probe.Outcome = output.ProbeInconclusive
+		probe.Detail = err.Error()
+		return probe
+	}
+	resp, body, err := client.do(req, nil)
+	if err != nil {
+		probe.Outcome = output.ProbeInconclusive
+		probe.Detail = err.Error()
+		return probe
+	}
+	probe.StatusCode = resp.StatusCode
+
+	probe.Outcome, probe.Detail = apiOutcome(resp.StatusCode, body)
+	return probe
+}
+
+// probeMultipart starts a multipart upload under the test prefix and aborts
+// it right away
+func (c *CapabilityProbeChecker) probeMultipart(client *s3Client) output.CapabilityProbe {
+	probe := output.CapabilityProbe{Name: "multipart"}
+	if c.Config.ReadOnly {
+		probe.Outcome = output.ProbeSkipped
+		probe.Detail = "starting an upload writes to the bucket (--read-only)"
+		return probe
+	}
+
+	key := client.testObjectKey("capability-probe")
+	req, err := client.newRequest("POST", key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		probe.Outcome = output.ProbeInconclusive
+		probe.Detail = err.Error()
+		return probe
+	}
+	resp, body, err := client.do(req, nil)
+	if err != nil {
+		probe.Outcome = output.ProbeInconclusive
+		probe.Detail = err.Error()
+		return probe
+	}
+	probe.StatusCode = resp.StatusCode
+
+	probe.Outcome, probe.Detail = apiOutcome(resp.StatusCode, body)
+	if resp.StatusCode != http.StatusOK {
+		return probe
+	}
+
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.Unmarshal(body, &initiated); err != nil || initiated.UploadID == "" {
+		probe.Outcome = output.ProbeInconclusive
+		probe.Detail = "the response carries no UploadId"
+		return probe
+	}
+
+	// Abort the upload so no parts or upload state are left behind
+	client.cleanup(func() {
+		var abort *http.Request
+		if abort, err = client.newRequest("DELETE", key, url.Values{"uploadId": {initiated.UploadID}}, nil); err != nil {
+			return
+		}
+		var abortResp *http.Response
+		if abortResp, body, err = client.do(abort, nil); err == nil && abortResp.StatusCode != http.StatusNoContent && abortResp.StatusCode != http.StatusOK {
+			err = errors.New(parseErrorResponse(abortResp.StatusCode, body))
+		}
+	})
+	if err != nil {
+		probe.Detail = fmt.Sprintf("failed to abort upload %s: %v", initiated.UploadID, err)
+	}
+	return probe
+}
+
+// apiOutcome classifies the response to an API probe: implemented, not
+// implemented, or hidden behind a denial
+func apiOutcome(statusCode int, body []byte) (string, string) {
+	code := errorCode(body)
+	switch {
+	case statusCode == http.StatusNotImplemented || statusCode == http.StatusMethodNotAllowed ||
+		code == "NotImplemented" || code == "MethodNotAllowed":
+		return output.ProbeNotSupported, "not implemented by the endpoint"
+	case statusCode == http.StatusOK:
+		return output.ProbeSupported, ""
+	case statusCode == http.StatusNotFound && code != "" && code != "NoSuchBucket":
+		// The API exists but nothing is configured (e.g. NoSuchBucketPolicy)
+		return output.ProbeSupported, code
+	case statusCode == http.StatusForbidden:
+		return output.ProbeDenied, parseErrorResponse(statusCode, body)
+	default:
+		return output.ProbeInconclusive, parseErrorResponse(statusCode, body)
+	}
+}
+
+// supportLevel maps an API probe to the support levels of the capability
+// matrix; an implemented API is assumed to be complete
+func supportLevel(probe output.CapabilityProbe) string {
+	switch probe.Outcome {
+	case output.ProbeSupported:
+		return "Full"
+	case output.ProbeNotSupported:
+		return "None"
+	default:
+		return "Unknown"
+	}
+}
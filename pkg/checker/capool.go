@@ -0,0 +1,22 @@
+package checker
+
+import (
+	"crypto/x509"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/options"
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// rootCAs returns the pool for the configured --ca-cert, or nil to use the
+// system roots
+func rootCAs(config output.Config) *x509.CertPool {
+	if config.CACert == "" {
+		return nil
+	}
+	pool, err := options.LoadCAPool(config.CACert)
+	if err != nil {
+		// Validated when the configuration was loaded
+		return nil
+	}
+	return pool
+}
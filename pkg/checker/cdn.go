@@ -0,0 +1,440 @@
+package checker
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+const (
+	// cdnCacheControl is the Cache-Control value of the CDN test object; it
+	// allows caching but keeps the deleted object's cached copy short-lived
+	cdnCacheControl = "public, max-age=300"
+
+	// cdnCompareLimit is the number of bytes of an existing object compared
+	// between the origin and the CDN; larger objects are compared by a range
+	cdnCompareLimit = 8 << 20
+
+	// cdnFetches is the number of requests sent through the CDN; the second
+	// one should be served from the cache
+	cdnFetches = 2
+)
+
+// cdnComparedHeaders lists the response headers that should reach clients
+// through the CDN as the origin sent them
+var cdnComparedHeaders = []string{"Content-Type", "ETag", "Last-Modified", "Cache-Control"}
+
+// CDNChecker validates delivery of the bucket's objects through a CDN: DNS
+// and TLS of the CDN host, and an object fetched through the CDN compared
+// with the one the origin returns
+type CDNChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewCDNChecker creates a new CDN delivery checker
+func NewCDNChecker(config output.Config) *CDNChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &CDNChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *CDNChecker) Name() string {
+	return "CDN Delivery Check"
+}
+
+// Check resolves and connects to the CDN host, then fetches the --object-key
+// object, or else a test object written for the check, from the origin and
+// twice through the CDN. The check fails when the CDN cannot be reached or
+// delivers other content than the origin, and warns when headers differ or
+// the second request is not served from the cache.
+func (c *CDNChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting CDN Delivery Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	fail := func(details output.CDNResult, format string, args ...interface{}) output.TestResult {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf(format, args...)
+		result.Details = details
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	base, err := url.Parse(c.Config.CDN)
+	if err != nil {
+		// Validated when the configuration was loaded
+		return fail(output.CDNResult{URL: c.Config.CDN}, "invalid CDN URL: %v", err)
+	}
+	cdn := output.CDNResult{URL: c.Config.CDN, Host: base.Hostname()}
+	timeout := time.Duration(c.Config.TCPTimeout) * time.Second
+
+	// DNS of the CDN host, with the CNAME that usually names the CDN
+	if err := c.resolve(ctx, &cdn, timeout); err != nil {
+		return fail(cdn, "CDN host %s does not resolve: %v", cdn.Host, err)
+	}
+
+	// TLS of the CDN host, verified against the CDN host name
+	if base.Scheme == "https" {
+		cdn.TLS = c.handshake(ctx, base, timeout)
+		if cdn.TLS.Error != "" {
+			return fail(cdn, "TLS handshake with CDN host %s failed: %s", cdn.Host, cdn.TLS.Error)
+		}
+	}
+
+	// The object compared: the existing one, or a test object written now
+	key := c.Config.ObjectKey
+	if key == "" {
+		key = c.client.testObjectKey("cdn")
+		if err := c.putTestObject(key); err != nil {
+			return fail(cdn, "PUT of the test object failed: %v", err)
+		}
+		defer c.client.cleanup(func() {
+			if err := c.client.deleteObject(key); err != nil {
+				c.verbose.LogMessage("Failed to delete test object %s: %v", key, err)
+			}
+		})
+	}
+	cdn.Key = key
+
+	// Objects larger than the limit are compared by their first bytes
+	var rangeHeader http.Header
+	if c.Config.ObjectKey != "" {
+		if size, err := c.originSize(key); err == nil && size > cdnCompareLimit {
+			rangeHeader = http.Header{"Range": {fmt.Sprintf("bytes=0-%d", cdnCompareLimit-1)}}
+			cdn.Range = rangeHeader.Get("Range")
+			c.verbose.LogMessage("Object is %d bytes, comparing %s", size, cdn.Range)
+		}
+	}
+
+	originResp, originBody, err := c.client.getObjectWithHeaders(key, rangeHeader)
+	if err != nil {
+		return fail(cdn, "GET from the origin failed: %v", err)
+	}
+	cdn.Origin = cdnResponse(originResp, originBody, 0)
+
+	objectURL := strings.TrimSuffix(base.String(), "/") + "/" + awsURIEncode(key, false)
+	cdn.ObjectURL = objectURL
+	httpClient := newCDNClient(c.Config)
+	for i := 0; i < cdnFetches; i++ {
+		fetch := c.fetch(ctx, httpClient, objectURL, rangeHeader)
+		c.verbose.LogMessage("CDN fetch %d: HTTP %d, cache %q, age %q", i+1, fetch.StatusCode, fetch.CacheStatus, fetch.Age)
+		cdn.Fetches = append(cdn.Fetches, fetch)
+		if fetch.Error != "" || !successful(fetch.StatusCode) {
+			break
+		}
+	}
+	first := cdn.Fetches[0]
+	last := cdn.Fetches[len(cdn.Fetches)-1]
+
+	cdn.Provider = detectCDNProvider(first.Headers)
+	switch {
+	case first.Error != "":
+		return fail(cdn, "GET through the CDN failed: %s", first.Error)
+	case last.Error != "":
+		return fail(cdn, "repeated GET through the CDN failed: %s", last.Error)
+	case last.StatusCode == http.StatusForbidden:
+		return fail(cdn, "the CDN returned HTTP 403 for %s; it is not allowed to read the object from the origin", objectURL)
+	case !successful(last.StatusCode):
+		return fail(cdn, "the CDN returned HTTP %d for %s, the origin HTTP %d", last.StatusCode, objectURL, cdn.Origin.StatusCode)
+	}
+
+	cdn.BodyMatch = last.SHA256 == cdn.Origin.SHA256
+	if !cdn.BodyMatch {
+		return fail(cdn, "the CDN delivers different content than the origin: %d bytes with SHA-256 %s, the origin %d bytes with SHA-256 %s",
+			last.Size, last.SHA256[:16], cdn.Origin.Size, cdn.Origin.SHA256[:16])
+	}
+
+	var warnings []string
+	for _, name := range cdnComparedHeaders {
+		comparison := output.CDNHeaderComparison{
+			Name:   name,
+			Origin: cdn.Origin.Headers[name],
+			CDN:    last.Headers[name],
+		}
+		comparison.Match = sameHeaderValue(name, comparison.Origin, comparison.CDN)
+		if !comparison.Match {
+			warnings = append(warnings, fmt.Sprintf("%s %q returned as %q", name, comparison.Origin, comparison.CDN))
+		}
+		cdn.Headers = append(cdn.Headers, comparison)
+	}
+
+	for _, fetch := range cdn.Fetches[1:] {
+		cdn.Cached = cdn.Cached || fetch.CacheStatus == "HIT" || (fetch.Age != "" && fetch.Age != "0")
+	}
+	switch {
+	case cdn.Provider == "":
+		warnings = append(warnings, "no CDN headers in the response; the host may not be a CDN")
+	case !cdn.Cached && last.CacheStatus != "":
+		warnings = append(warnings, fmt.Sprintf("the repeated request was not served from the cache (%s)", last.CacheStatus))
+	}
+
+	if len(warnings) > 0 {
+		result.Status = output.StatusWarn
+		result.Error = strings.Join(warnings, "; ")
+	}
+
+	result.Details = cdn
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("CDN delivery check completed in %v", result.Duration)
+
+	return result
+}
+
+// resolve looks up the addresses and CNAME of the CDN host
+func (c *CDNChecker) resolve(ctx context.Context, cdn *output.CDNResult, timeout time.Duration) error {
+	if ip := net.ParseIP(cdn.Host); ip != nil {
+		cdn.Addresses = []string{ip.String()}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addrs, err := lookupIPAddr(ctx, c.Config, cdn.Host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		cdn.Addresses = append(cdn.Addresses, addr.IP.String())
+	}
+	if cname, err := lookupCNAME(ctx, c.Config, cdn.Host); err == nil {
+		cname = strings.TrimSuffix(cname, ".")
+		if !strings.EqualFold(cname, cdn.Host) {
+			cdn.CNAME = cname
+		}
+	}
+	c.verbose.LogMessage("CDN host %s resolves to %s (CNAME %q)", cdn.Host, strings.Join(cdn.Addresses, ", "), cdn.CNAME)
+	return nil
+}
+
+// handshake connects to the CDN host and verifies its certificate. The SNI
+// override applies to the origin only.
+func (c *CDNChecker) handshake(ctx context.Context, base *url.URL, timeout time.Duration) *output.CDNTLSResult {
+	port := base.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	tlsResult := &output.CDNTLSResult{}
+	conn, err := dialTLS(ctx, c.Config, net.JoinHostPort(base.Hostname(), port), timeout, &tls.Config{
+		ServerName:         base.Hostname(),
+		InsecureSkipVerify: c.Config.Insecure,
+		MinVersion:         tls.VersionTLS12,
+		RootCAs:            rootCAs(c.Config),
+		Certificates:       clientCertificates(c.Config),
+	})
+	if err != nil {
+		tlsResult.Error = err.Error()
+		return tlsResult
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	tlsResult.Version = tlsVersionToString(state.Version)
+	tlsResult.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	tlsResult.Verified = len(state.VerifiedChains) > 0
+	if len(state.PeerCertificates) > 0 {
+		info := output.NewCertificateInfo(state.PeerCertificates[0])
+		tlsResult.Certificate = &info
+	}
+	c.verbose.LogMessage("CDN TLS: %s %s, verified %v", tlsResult.Version, tlsResult.CipherSuite, tlsResult.Verified)
+	return tlsResult
+}
+
+// putTestObject writes the test object with a cacheable Cache-Control
+func (c *CDNChecker) putTestObject(key string) error {
+	body := []byte(fmt.Sprintf("s3tester CDN delivery test %s\n", key))
+	req, err := c.client.newRequest("PUT", key, nil, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Cache-Control", cdnCacheControl)
+
+	c.verbose.LogMessage("Uploading %s with Cache-Control %q", key, cdnCacheControl)
+
+	resp, respBody, err := c.client.do(req, body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, respBody))
+	}
+	return nil
+}
+
+// originSize returns the size of an existing object
+func (c *CDNChecker) originSize(key string) (int64, error) {
+	req, err := c.client.newRequest("HEAD", key, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, _, err := c.client.do(req, nil)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return resp.ContentLength, nil
+}
+
+// fetch sends an unauthenticated GET for the object through the CDN
+func (c *CDNChecker) fetch(ctx context.Context, client *http.Client, objectURL string, header http.Header) output.CDNFetch {
+	req, err := http.NewRequestWithContext(ctx, "GET", objectURL, nil)
+	if err != nil {
+		return output.CDNFetch{Error: err.Error()}
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("User-Agent", "s3-bucket-tester/1.0")
+	c.verbose.LogRequest(req)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return output.CDNFetch{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+	c.verbose.LogResponse(resp)
+
+	// A CDN that ignores the Range is compared by the same first bytes
+	limit := int64(cdnCompareLimit + 1)
+	if header.Get("Range") != "" {
+		limit = cdnCompareLimit
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return output.CDNFetch{StatusCode: resp.StatusCode, Error: err.Error()}
+	}
+	return cdnResponse(resp, body, time.Since(start))
+}
+
+// newCDNClient creates the client for requests through the CDN or another
+// host than the endpoint. It uses the transport of the S3 requests, with the
+// proxy, --ipv4/--ipv6 and the client certificate, but verifies the host it
+// connects to instead of --sni. It sends no Accept-Encoding, so the body is
+// compared as the CDN stores it.
+func newCDNClient(config output.Config) *http.Client {
+	transport := httpTransport(newHTTPClient(config)).Clone()
+	transport.TLSClientConfig.ServerName = ""
+	transport.DisableCompression = true
+	return &http.Client{
+		Timeout:   time.Duration(config.HTTPTimeout) * time.Second,
+		Transport: transport,
+	}
+}
+
+// cdnResponse summarizes a response from the origin or the CDN
+func cdnResponse(resp *http.Response, body []byte, duration time.Duration) output.CDNFetch {
+	sum := sha256.Sum256(body)
+	fetch := output.CDNFetch{
+		StatusCode:  resp.StatusCode,
+		Size:        int64(len(body)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		CacheStatus: cacheStatus(resp.Header),
+		Age:         resp.Header.Get("Age"),
+		Headers:     make(map[string]string),
+		Duration:    duration,
+	}
+	for _, name := range cdnComparedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			fetch.Headers[name] = value
+		}
+	}
+	for name, value := range detectCDNHeaders(resp.Header) {
+		fetch.Headers[name] = value
+	}
+	return fetch
+}
+
+// cacheStatus returns the cache status a CDN reports for a response as HIT,
+// MISS or the CDN's own term, such as DYNAMIC or BYPASS on Cloudflare.
+// Fastly lists one status per cache in the path; the last one is the edge.
+func cacheStatus(header http.Header) string {
+	if status := header.Get("Cf-Cache-Status"); status != "" {
+		return strings.ToUpper(status)
+	}
+	for _, name := range []string{"X-Cache", "X-Proxy-Cache"} {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		parts := strings.Split(value, ",")
+		status := strings.ToLower(strings.TrimSpace(parts[len(parts)-1]))
+		switch {
+		case strings.Contains(status, "hit"):
+			return "HIT"
+		case strings.Contains(status, "miss"):
+			return "MISS"
+		default:
+			return strings.ToUpper(strings.Fields(status + " -")[0])
+		}
+	}
+	return ""
+}
+
+// successful reports whether an HTTP status is 2xx
+func successful(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// detectCDNProvider names the CDN that sent a response, from the headers
+// recorded by cdnResponse
+func detectCDNProvider(headers map[string]string) string {
+	lower := make(map[string]string, len(headers))
+	for name, value := range headers {
+		lower[strings.ToLower(name)] = strings.ToLower(value)
+	}
+	switch {
+	case lower["x-amz-cf-id"] != "" || strings.Contains(lower["via"], "cloudfront"):
+		return "CloudFront"
+	case lower["cf-ray"] != "" || lower["cf-cache-status"] != "":
+		return "Cloudflare"
+	case lower["x-fastly-request-id"] != "" || strings.HasPrefix(lower["x-served-by"], "cache-"):
+		return "Fastly"
+	}
+	for name := range lower {
+		if strings.HasPrefix(name, "x-akamai-") {
+			return "Akamai"
+		}
+	}
+	if lower["x-cache"] != "" || lower["via"] != "" || lower["age"] != "" {
+		return "unknown CDN"
+	}
+	return ""
+}
+
+// sameHeaderValue reports whether a header reached the client unchanged. A
+// CDN that compresses or re-chunks the object may weaken the ETag, which is
+// the same entity tag for a GET.
+func sameHeaderValue(name, origin, cdn string) bool {
+	if name == "ETag" {
+		return strings.TrimPrefix(origin, "W/") == strings.TrimPrefix(cdn, "W/")
+	}
+	return origin == cdn
+}
@@ -0,0 +1,160 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// maxAIAFetches bounds the issuer certificates fetched to complete a chain
+const maxAIAFetches = 4
+
+// maxAIACertificate bounds the size of a certificate read from a caIssuers URL
+const maxAIACertificate = 1 << 20
+
+// isUnknownAuthority reports whether a handshake failed because the chain did
+// not lead to a trusted root, the failure a missing intermediate causes
+func isUnknownAuthority(err error) bool {
+	var unknown x509.UnknownAuthorityError
+	return errors.As(err, &unknown)
+}
+
+// analyzeChain tells whether the certificates a server sent, leaf first, form
+// a chain to a trusted root. When they do not, it follows the caIssuers URLs
+// of the Authority Information Access extension from the top of the served
+// chain, so a server that leaves out an intermediate can be told apart from
+// one whose certificate comes from an untrusted CA. Only the chain is judged:
+// the name and validity period of the leaf are reported elsewhere.
+func analyzeChain(ctx context.Context, config output.Config, certs []*x509.Certificate) *output.ChainAnalysis {
+	leaf := certs[0]
+	analysis := &output.ChainAnalysis{Sent: len(certs)}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	opts := x509.VerifyOptions{
+		Roots:         rootCAs(config),
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	if now := time.Now(); now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		opts.CurrentTime = leaf.NotBefore.Add(leaf.NotAfter.Sub(leaf.NotBefore) / 2)
+	}
+
+	_, err := leaf.Verify(opts)
+	if err == nil {
+		analysis.Status = output.ChainComplete
+		return analysis
+	}
+	if isSelfSigned(leaf) {
+		analysis.Status = output.ChainSelfSigned
+		analysis.Error = err.Error()
+		return analysis
+	}
+
+	top := topOfChain(certs)
+	var fetched []*x509.Certificate
+	for len(fetched) < maxAIAFetches && len(top.IssuingCertificateURL) > 0 && !isSelfSigned(top) {
+		url := top.IssuingCertificateURL[0]
+		fetch := output.AIAFetch{URL: url}
+		issuer, fetchErr := fetchIssuer(ctx, config, url, top)
+		if fetchErr != nil {
+			fetch.Error = fetchErr.Error()
+			analysis.AIAFetches = append(analysis.AIAFetches, fetch)
+			break
+		}
+		fetch.Subject = issuer.Subject.String()
+		analysis.AIAFetches = append(analysis.AIAFetches, fetch)
+
+		fetched = append(fetched, issuer)
+		intermediates.AddCert(issuer)
+		if _, err = leaf.Verify(opts); err == nil {
+			analysis.Status = output.ChainIncomplete
+			for _, cert := range fetched {
+				// A root need not be sent, only the intermediates below it
+				if !isSelfSigned(cert) {
+					analysis.Missing = append(analysis.Missing, cert.Subject.String())
+				}
+			}
+			return analysis
+		}
+		top = issuer
+	}
+
+	analysis.Status = output.ChainUntrusted
+	analysis.Error = err.Error()
+	return analysis
+}
+
+// topOfChain follows the issuers of the served certificates from the leaf and
+// returns the last one the server sent
+func topOfChain(certs []*x509.Certificate) *x509.Certificate {
+	top := certs[0]
+	for range certs[1:] {
+		next := servedIssuer(top, certs[1:])
+		if next == nil {
+			break
+		}
+		top = next
+	}
+	return top
+}
+
+// servedIssuer returns the certificate among certs that issued cert, or nil
+func servedIssuer(cert *x509.Certificate, certs []*x509.Certificate) *x509.Certificate {
+	for _, candidate := range certs {
+		if candidate != cert && bytes.Equal(candidate.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(candidate) == nil {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// isSelfSigned reports whether a certificate is its own issuer
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(cert) == nil
+}
+
+// fetchIssuer downloads the certificate at a caIssuers URL, in DER or PEM
+// form, and returns it once it is shown to have issued cert
+func fetchIssuer(ctx context.Context, config output.Config, url string, cert *x509.Certificate) (*x509.Certificate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid caIssuers URL: %w", err)
+	}
+
+	resp, err := newPlainHTTPClient(config).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("caIssuers URL unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("caIssuers URL returned HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAIACertificate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issuer certificate: %w", err)
+	}
+
+	if block, _ := pem.Decode(data); block != nil && block.Type == "CERTIFICATE" {
+		data = block.Bytes
+	}
+	issuer, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issuer certificate (PKCS#7 bundles are not supported): %w", err)
+	}
+	if err := cert.CheckSignatureFrom(issuer); err != nil {
+		return nil, fmt.Errorf("the certificate at the caIssuers URL did not issue %s: %w", cert.Subject, err)
+	}
+	return issuer, nil
+}
@@ -1,6 +1,8 @@
 package checker
 
 import (
+	"context"
+
 	"github.com/s3-bucket-tester/s3tester/pkg/output"
 )
 
@@ -9,8 +11,9 @@ type Checker interface {
 	// Name returns the name of the checker
 	Name() string
 
-	// Check performs the check and returns a TestResult
-	Check() output.TestResult
+	// Check performs the check and returns a TestResult. Cancelling ctx
+	// aborts the check's in-flight network operations.
+	Check(ctx context.Context) output.TestResult
 }
 
 // BaseChecker provides common functionality for all checkers
@@ -0,0 +1,284 @@
+package checker
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// Integrity mechanism outcomes as reported by the checksum check
+const (
+	ChecksumEndToEnd = "end to end"
+	ChecksumVerified = "verified"
+	ChecksumIgnored  = "ignored"
+	ChecksumRejected = "rejected"
+	ChecksumBroken   = "broken"
+	ChecksumETagMD5  = "MD5 of the content"
+	ChecksumOpaque   = "opaque"
+)
+
+// checksumAlgorithm is one of the additional checksums of the S3 API, sent
+// in its x-amz-checksum-* header
+type checksumAlgorithm struct {
+	name   string
+	header string
+	sum    func([]byte) string
+}
+
+// checksumAlgorithms are the checksums the check uploads with: SHA-256 and
+// CRC32C, which current AWS SDKs send by default
+var checksumAlgorithms = []checksumAlgorithm{
+	{
+		name:   "SHA-256",
+		header: "X-Amz-Checksum-Sha256",
+		sum: func(b []byte) string {
+			sum := sha256.Sum256(b)
+			return base64.StdEncoding.EncodeToString(sum[:])
+		},
+	},
+	{
+		name:   "CRC32C",
+		header: "X-Amz-Checksum-Crc32c",
+		sum: func(b []byte) string {
+			sum := binary.BigEndian.AppendUint32(nil, crc32.Checksum(b, crc32.MakeTable(crc32.Castagnoli)))
+			return base64.StdEncoding.EncodeToString(sum)
+		},
+	},
+}
+
+// ChecksumChecker verifies which integrity mechanisms the provider honors:
+// Content-MD5, an MD5 ETag and the x-amz-checksum-* headers on upload and
+// download
+type ChecksumChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewChecksumChecker creates a new checksum checker
+func NewChecksumChecker(config output.Config) *ChecksumChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &ChecksumChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *ChecksumChecker) Name() string {
+	return "Checksum Check"
+}
+
+// checksumResponse is the part of a response the steps compare
+type checksumResponse struct {
+	status int
+	code   string
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// details is what the step reports of the response besides its status
+func (r checksumResponse) details() string {
+	if r.etag == "" || r.status >= 300 {
+		return ""
+	}
+	return ", ETag " + r.etag
+}
+
+// Check uploads a test object with a correct and with a wrong digest for
+// Content-MD5, SHA-256 and CRC32C, and reads the SHA-256 and CRC32C objects
+// back with x-amz-checksum-mode. A mechanism is verified when the wrong
+// digest is rejected and end to end when the download also returns the
+// checksum. The check fails when a wrong digest is accepted, since the
+// provider then stores data it cannot vouch for, or when Content-MD5 is
+// refused. It warns when a checksum is refused or not returned on download.
+func (c *ChecksumChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Checksum Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	key := c.client.testObjectKey("checksum")
+	checksums := output.ChecksumResult{Key: key}
+
+	body := []byte("s3tester checksum test\n")
+	altered := []byte("s3tester checksum test, altered\n")
+
+	// Whatever is stored under the key is removed at the end, including an
+	// object written with a wrong digest
+	defer c.client.cleanup(func() {
+		if err := c.client.deleteObject(key); err != nil {
+			c.verbose.LogMessage("Failed to delete %s: %v", key, err)
+		}
+	})
+
+	md5Sum := func(b []byte) string {
+		sum := md5.Sum(b)
+		return base64.StdEncoding.EncodeToString(sum[:])
+	}
+	put, err := c.send("PUT", key, body, http.Header{"Content-Md5": {md5Sum(body)}})
+	putStep := newStep(c.verbose, "PUT with Content-MD5", "2xx", put.status, put.code, err, put.status < 300, put.details())
+	checksums.Steps = append(checksums.Steps, putStep)
+
+	contentMD5 := output.ChecksumMechanism{Name: "Content-MD5", Outcome: ChecksumRejected}
+	etag := output.ChecksumMechanism{Name: "ETag", Outcome: ChecksumOpaque}
+	if err == nil && put.status < 300 {
+		sum := md5.Sum(body)
+		if strings.EqualFold(strings.Trim(put.etag, `"`), hex.EncodeToString(sum[:])) {
+			etag.Outcome = ChecksumETagMD5
+		}
+		c.verbose.LogMessage("ETag %s, MD5 of the content %x", put.etag, sum)
+
+		wrong, err := c.send("PUT", key, body, http.Header{"Content-Md5": {md5Sum(altered)}})
+		checksums.Steps = append(checksums.Steps, newStep(c.verbose, "PUT with wrong Content-MD5", "400 BadDigest", wrong.status, wrong.code, err,
+			wrong.status == http.StatusBadRequest, wrong.details()))
+		contentMD5.Outcome = c.mismatchOutcome(wrong, err)
+	}
+	checksums.Mechanisms = append(checksums.Mechanisms, contentMD5)
+	if contentMD5.Outcome != ChecksumRejected {
+		checksums.Mechanisms = append(checksums.Mechanisms, etag)
+	}
+
+	for _, algorithm := range checksumAlgorithms {
+		checksums.Mechanisms = append(checksums.Mechanisms, c.testAlgorithm(algorithm, key, body, altered, &checksums))
+	}
+
+	var ignored, broken, weak []string
+	for _, mechanism := range checksums.Mechanisms {
+		switch mechanism.Outcome {
+		case ChecksumIgnored:
+			ignored = append(ignored, mechanism.Name)
+		case ChecksumBroken:
+			broken = append(broken, mechanism.Name)
+		case ChecksumRejected:
+			weak = append(weak, mechanism.Name+" "+mechanism.Outcome)
+		case ChecksumVerified:
+			// Content-MD5 only covers the upload; a checksum should also be
+			// returned on download
+			if mechanism.Name != contentMD5.Name {
+				weak = append(weak, mechanism.Name+" "+mechanism.Outcome+" on upload only")
+			}
+		}
+	}
+
+	switch {
+	case len(ignored) > 0:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the provider accepted uploads whose %s did not match the content: corrupted uploads are stored without an error",
+			strings.Join(ignored, ", "))
+	case contentMD5.Outcome == ChecksumRejected:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the provider rejected an upload with a correct Content-MD5: %s", putStep.Observed)
+	case len(broken) > 0:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the download returned another checksum or content than was uploaded: %s", strings.Join(broken, ", "))
+	case len(weak) > 0:
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("not every checksum is honored end to end: %s", strings.Join(weak, ", "))
+	}
+
+	result.Details = checksums
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Checksum check completed in %v", result.Duration)
+
+	return result
+}
+
+// testAlgorithm uploads the test object with a correct checksum, reads it
+// back with x-amz-checksum-mode and uploads it with a wrong checksum
+func (c *ChecksumChecker) testAlgorithm(algorithm checksumAlgorithm, key string, body, altered []byte, checksums *output.ChecksumResult) output.ChecksumMechanism {
+	mechanism := output.ChecksumMechanism{Name: algorithm.name, Outcome: ChecksumRejected}
+	checksum := algorithm.sum(body)
+
+	put, err := c.send("PUT", key, body, http.Header{algorithm.header: {checksum}})
+	checksums.Steps = append(checksums.Steps, newStep(c.verbose, "PUT with "+algorithm.name, "2xx", put.status, put.code, err, put.status < 300, put.details()))
+	if err != nil || put.status >= 300 {
+		return mechanism
+	}
+
+	get, err := c.send("GET", key, nil, http.Header{"X-Amz-Checksum-Mode": {"ENABLED"}})
+	returned := get.header.Get(algorithm.header)
+	getStep := newStep(c.verbose, "GET "+algorithm.name+" checksum", "200 with "+strings.ToLower(algorithm.header), get.status, get.code, err,
+		get.status == http.StatusOK && returned == checksum, get.details())
+	if returned != "" {
+		getStep.Observed += ", " + returned
+	}
+	checksums.Steps = append(checksums.Steps, getStep)
+
+	wrong, err := c.send("PUT", key, body, http.Header{algorithm.header: {algorithm.sum(altered)}})
+	checksums.Steps = append(checksums.Steps, newStep(c.verbose, "PUT with wrong "+algorithm.name, "400 BadDigest", wrong.status, wrong.code, err,
+		wrong.status == http.StatusBadRequest, wrong.details()))
+	mechanism.Outcome = c.mismatchOutcome(wrong, err)
+
+	switch {
+	case get.status != http.StatusOK, mechanism.Outcome == ChecksumIgnored:
+	case string(get.body) != string(body) || (returned != "" && returned != checksum):
+		mechanism.Outcome = ChecksumBroken
+	case returned != "" && mechanism.Outcome == ChecksumVerified:
+		mechanism.Outcome = ChecksumEndToEnd
+	}
+	return mechanism
+}
+
+// mismatchOutcome classifies the response to an upload with a wrong digest:
+// a 400 means the digest was verified, a success that it was ignored
+func (c *ChecksumChecker) mismatchOutcome(resp checksumResponse, err error) string {
+	switch {
+	case err != nil:
+		return ChecksumBroken
+	case resp.status < 300:
+		return ChecksumIgnored
+	case resp.status == http.StatusBadRequest:
+		return ChecksumVerified
+	default:
+		return ChecksumBroken
+	}
+}
+
+// send sends a request for the test object with the given extra headers
+func (c *ChecksumChecker) send(method, key string, body []byte, header http.Header) (checksumResponse, error) {
+	req, err := c.client.newRequest(method, key, nil, body)
+	if err != nil {
+		return checksumResponse{}, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, respBody, err := c.client.do(req, body)
+	if err != nil {
+		return checksumResponse{}, err
+	}
+
+	response := checksumResponse{
+		status: resp.StatusCode,
+		code:   errorCode(respBody),
+		etag:   resp.Header.Get("ETag"),
+		header: resp.Header,
+	}
+	if resp.StatusCode < 300 {
+		response.body = respBody
+	}
+	return response, nil
+}
@@ -0,0 +1,69 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// cleanupKey is the context key that marks the requests of a check's cleanup
+type cleanupKey struct{}
+
+// cleanupFailures holds the failed cleanup requests of all checkers by
+// operation label until TakeCleanupFailures hands them to the check's result
+var cleanupFailures struct {
+	sync.Mutex
+	byLabel map[string][]string
+}
+
+// TakeCleanupFailures returns the cleanup requests recorded under label that
+// failed, such as a DELETE of a test object, and forgets them
+func TakeCleanupFailures(label string) []string {
+	cleanupFailures.Lock()
+	defer cleanupFailures.Unlock()
+
+	failures := cleanupFailures.byLabel[label]
+	delete(cleanupFailures.byLabel, label)
+	return failures
+}
+
+// recordCleanupFailure keeps a failed cleanup request under label
+func recordCleanupFailure(label, failure string) {
+	cleanupFailures.Lock()
+	defer cleanupFailures.Unlock()
+
+	if cleanupFailures.byLabel == nil {
+		cleanupFailures.byLabel = make(map[string][]string)
+	}
+	cleanupFailures.byLabel[label] = append(cleanupFailures.byLabel[label], failure)
+}
+
+// withCleanup marks the requests sent with ctx as cleanup, see cleanupTransport
+func withCleanup(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cleanupKey{}, true)
+}
+
+// cleanupTransport records the cleanup requests that failed, so a check
+// stopped by its --budget share can report what it left behind
+type cleanupTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends the request and records it when it is part of a cleanup
+// and failed. A 404 is not a failure: there was nothing left to remove.
+func (t *cleanupTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if cleaning, _ := req.Context().Value(cleanupKey{}).(bool); !cleaning {
+		return resp, err
+	}
+
+	label, _ := req.Context().Value(operationLabelKey{}).(string)
+	switch {
+	case err != nil:
+		recordCleanupFailure(label, fmt.Sprintf("%s %s: %v", req.Method, operationPath(req), err))
+	case resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound:
+		recordCleanupFailure(label, fmt.Sprintf("%s %s: HTTP %d", req.Method, operationPath(req), resp.StatusCode))
+	}
+	return resp, err
+}
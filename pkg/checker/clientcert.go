@@ -0,0 +1,31 @@
+package checker
+
+import (
+	"crypto/tls"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/options"
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// clientCertificate returns the configured client certificate, or nil when
+// mutual TLS is not configured
+func clientCertificate(config output.Config) *tls.Certificate {
+	if config.ClientCert == "" {
+		return nil
+	}
+	cert, err := options.LoadClientCertificate(config.ClientCert, config.ClientKey)
+	if err != nil {
+		// Validated when the configuration was loaded
+		return nil
+	}
+	return cert
+}
+
+// clientCertificates returns the configured client certificate for
+// tls.Config.Certificates
+func clientCertificates(config output.Config) []tls.Certificate {
+	if cert := clientCertificate(config); cert != nil {
+		return []tls.Certificate{*cert}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// conditionalObjectSize is the size of the test object of the range and
+// conditional GET check
+const conditionalObjectSize = 1024
+
+// ConditionalChecker verifies that ranged GETs return the requested bytes
+// and that the If-* preconditions are evaluated as RFC 9110 specifies, which
+// resumable downloads and backup tools depend on
+type ConditionalChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewConditionalChecker creates a new range and conditional GET checker
+func NewConditionalChecker(config output.Config) *ConditionalChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &ConditionalChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *ConditionalChecker) Name() string {
+	return "Range and Conditional GET Check"
+}
+
+// conditionalCase is one GET of the check: its request headers, the status
+// S3 returns and, for a 206, the bytes of the test object it must contain
+type conditionalCase struct {
+	name       string
+	header     http.Header
+	status     int
+	start, end int64
+}
+
+// Check uploads a test object and sends GETs with Range, If-Match,
+// If-None-Match, If-Modified-Since and If-Unmodified-Since headers. Every
+// response must have the status S3 returns; a 206 must also carry the
+// requested bytes and a matching Content-Range, and a 200 the whole object.
+// Any difference fails the check.
+func (c *ConditionalChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Range and Conditional GET Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	key := c.client.testObjectKey("conditional")
+	conditional := output.ConditionalResult{Key: key, Size: conditionalObjectSize}
+
+	content := make([]byte, conditionalObjectSize)
+	if _, err := rand.Read(content); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to generate test content: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	req, err := c.client.newRequest("PUT", key, nil, content)
+	if err == nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+		var resp *http.Response
+		var body []byte
+		resp, body, err = c.client.do(req, content)
+		if err == nil && resp.StatusCode >= 300 {
+			err = fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, body))
+		}
+	}
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to upload the test object: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	defer c.client.cleanup(func() {
+		if err := c.client.deleteObject(key); err != nil {
+			c.verbose.LogMessage("Failed to delete %s: %v", key, err)
+		}
+	})
+
+	// The validators come from the provider itself, so clock skew between
+	// this host and the provider does not matter
+	resp, body, err := c.get(key, nil)
+	if err != nil || resp.StatusCode != http.StatusOK || !bytes.Equal(body, content) {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to read the test object back: %s", observedGet(resp, body, err))
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	conditional.ETag = resp.Header.Get("ETag")
+	conditional.LastModified = resp.Header.Get("Last-Modified")
+	lastModified, err := http.ParseTime(conditional.LastModified)
+	if conditional.ETag == "" || err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the GET response lacks a validator: ETag %q, Last-Modified %q", conditional.ETag, conditional.LastModified)
+		result.Details = conditional
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	before := lastModified.Add(-time.Hour).UTC().Format(http.TimeFormat)
+	otherETag := `"00000000000000000000000000000000"`
+
+	size := int64(conditionalObjectSize)
+	cases := []conditionalCase{
+		{name: "Range first 100 bytes", header: http.Header{"Range": {"bytes=0-99"}}, status: http.StatusPartialContent, start: 0, end: 99},
+		{name: "Range open end", header: http.Header{"Range": {"bytes=1000-"}}, status: http.StatusPartialContent, start: 1000, end: size - 1},
+		{name: "Range suffix", header: http.Header{"Range": {"bytes=-24"}}, status: http.StatusPartialContent, start: size - 24, end: size - 1},
+		{name: "Range past the end", header: http.Header{"Range": {fmt.Sprintf("bytes=%d-", size*2)}}, status: http.StatusRequestedRangeNotSatisfiable},
+		{name: "If-Match current ETag", header: http.Header{"If-Match": {conditional.ETag}}, status: http.StatusOK},
+		{name: "If-Match other ETag", header: http.Header{"If-Match": {otherETag}}, status: http.StatusPreconditionFailed},
+		{name: "If-None-Match current ETag", header: http.Header{"If-None-Match": {conditional.ETag}}, status: http.StatusNotModified},
+		{name: "If-None-Match other ETag", header: http.Header{"If-None-Match": {otherETag}}, status: http.StatusOK},
+		{name: "If-Modified-Since upload", header: http.Header{"If-Modified-Since": {conditional.LastModified}}, status: http.StatusNotModified},
+		{name: "If-Modified-Since earlier", header: http.Header{"If-Modified-Since": {before}}, status: http.StatusOK},
+		{name: "If-Unmodified-Since earlier", header: http.Header{"If-Unmodified-Since": {before}}, status: http.StatusPreconditionFailed},
+		{name: "Range with If-Match", header: http.Header{"Range": {"bytes=100-199"}, "If-Match": {conditional.ETag}}, status: http.StatusPartialContent, start: 100, end: 199},
+	}
+
+	var mismatched []string
+	for _, tc := range cases {
+		step := c.runCase(key, tc, content)
+		if !step.Match {
+			mismatched = append(mismatched, step.Step)
+		}
+		conditional.Steps = append(conditional.Steps, step)
+	}
+
+	if len(mismatched) > 0 {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("range or conditional GETs do not behave like S3: %s", strings.Join(mismatched, ", "))
+	}
+
+	result.Details = conditional
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Range and conditional GET check completed in %v", result.Duration)
+
+	return result
+}
+
+// runCase sends the GET of one case and compares the response with it
+func (c *ConditionalChecker) runCase(key string, tc conditionalCase, content []byte) output.Step {
+	step := output.Step{Step: tc.name, Expected: fmt.Sprintf("%d", tc.status)}
+	var contentRange string
+	if tc.status == http.StatusPartialContent {
+		contentRange = fmt.Sprintf("bytes %d-%d/%d", tc.start, tc.end, len(content))
+		step.Expected += ", Content-Range: " + contentRange
+	}
+
+	resp, body, err := c.get(key, tc.header)
+	step.Observed = observedGet(resp, body, err)
+	if err == nil {
+		switch resp.StatusCode {
+		case http.StatusPartialContent:
+			observedRange := resp.Header.Get("Content-Range")
+			step.Observed += ", Content-Range: " + observedRange
+			step.Match = tc.status == http.StatusPartialContent && observedRange == contentRange && bytes.Equal(body, content[tc.start:tc.end+1])
+			if tc.status == http.StatusPartialContent && observedRange == contentRange && !step.Match {
+				step.Observed += fmt.Sprintf(", %d bytes that differ from the object", len(body))
+			}
+		case http.StatusOK:
+			step.Match = tc.status == http.StatusOK && bytes.Equal(body, content)
+			if tc.status == http.StatusOK && !step.Match {
+				step.Observed += fmt.Sprintf(", %d bytes that differ from the object", len(body))
+			}
+		default:
+			step.Match = resp.StatusCode == tc.status
+		}
+	}
+
+	c.verbose.LogMessage("%s: expected %s, observed %s", step.Step, step.Expected, step.Observed)
+
+	return step
+}
+
+// get sends a GET for the test object with the given extra headers
+func (c *ConditionalChecker) get(key string, header http.Header) (*http.Response, []byte, error) {
+	req, err := c.client.newRequest("GET", key, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	return c.client.do(req, nil)
+}
+
+// observedGet describes a GET response by its status and error code
+func observedGet(resp *http.Response, body []byte, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	observed := fmt.Sprintf("%d", resp.StatusCode)
+	if code := errorCode(body); resp.StatusCode >= 300 && code != "" {
+		observed += " " + code
+	}
+	return observed
+}
@@ -0,0 +1,329 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// Outcomes of an operation of the copy and batch delete checks
+const (
+	OperationSupported    = "supported"
+	OperationNotSupported = "not supported"
+	OperationDenied       = "denied"
+	OperationBroken       = "broken"
+)
+
+// copyObjectSize is the size of the copy source; UploadPartCopy copies its
+// first half as the only, and so last, part, which may be smaller than 5 MiB
+const copyObjectSize = 1024
+
+// CopyChecker verifies server-side copies: CopyObject with the x-amz-copy-source
+// header and UploadPartCopy, the way SDKs copy objects larger than 5 GiB
+type CopyChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewCopyChecker creates a new copy checker
+func NewCopyChecker(config output.Config) *CopyChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &CopyChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *CopyChecker) Name() string {
+	return "Copy Object Check"
+}
+
+// operationResponse is the part of a response the copy and batch delete
+// steps compare
+type operationResponse struct {
+	status int
+	code   string
+	header http.Header
+	body   []byte
+}
+
+// Check uploads a source object with user metadata and copies it with
+// CopyObject, with CopyObject and the REPLACE metadata directive, with a
+// failing x-amz-copy-source-if-match, and with UploadPartCopy. It fails when
+// CopyObject is not supported or either copy differs from the source, and
+// warns when UploadPartCopy is not supported or on a quirk: metadata lost or
+// not replaced, a precondition ignored, or a 200 response carrying an error.
+func (c *CopyChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Copy Object Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	sourceKey := c.client.testObjectKey("copy-source")
+	targetKey := c.client.testObjectKey("copy-target")
+	partKey := c.client.testObjectKey("copy-part")
+	copyResult := output.CopyResult{SourceKey: sourceKey}
+
+	content := make([]byte, copyObjectSize)
+	if _, err := rand.Read(content); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to generate test content: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	put, err := c.send("PUT", sourceKey, nil, content, http.Header{
+		"Content-Type":        {"application/octet-stream"},
+		"X-Amz-Meta-S3tester": {"source"},
+	})
+	if err == nil && put.status >= 300 {
+		err = fmt.Errorf("%s", parseErrorResponse(put.status, put.body))
+	}
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to upload the copy source: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	defer c.client.cleanup(func() {
+		for _, key := range []string{sourceKey, targetKey, partKey} {
+			if err := c.client.deleteObject(key); err != nil {
+				c.verbose.LogMessage("Failed to delete %s: %v", key, err)
+			}
+		}
+	})
+
+	source := "/" + c.Config.Bucket + "/" + awsURIEncode(sourceKey, false)
+	copyResult.CopyObject = c.copyObject(source, targetKey, content, &copyResult)
+	copyResult.UploadPartCopy = c.uploadPartCopy(source, partKey, content[:copyObjectSize/2], &copyResult)
+
+	var warnings []string
+	switch copyResult.CopyObject {
+	case OperationSupported:
+	case OperationDenied:
+		warnings = append(warnings, "CopyObject denied")
+	default:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("CopyObject is %s: server-side copies and renames fail", copyResult.CopyObject)
+	}
+	switch copyResult.UploadPartCopy {
+	case OperationSupported:
+	case OperationBroken:
+		if result.Status != output.StatusFail {
+			result.Status = output.StatusFail
+			result.Error = "UploadPartCopy is broken: copies of objects larger than 5 GiB fail or are corrupted"
+		}
+	default:
+		warnings = append(warnings, "UploadPartCopy "+copyResult.UploadPartCopy)
+	}
+	warnings = append(warnings, copyResult.Quirks...)
+
+	if result.Status != output.StatusFail && len(warnings) > 0 {
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("copies work with quirks: %s", strings.Join(warnings, ", "))
+	}
+
+	result.Details = copyResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Copy object check completed in %v", result.Duration)
+
+	return result
+}
+
+// copyObject copies the source with CopyObject, reads the copy back, copies
+// it again replacing its metadata and once more with a failing precondition
+func (c *CopyChecker) copyObject(source, key string, content []byte, copyResult *output.CopyResult) string {
+	copied, err := c.send("PUT", key, nil, nil, http.Header{"X-Amz-Copy-Source": {source}})
+	step := newStep(c.verbose, "CopyObject", "200 with CopyObjectResult", copied.status, copied.code, err, resultElement(copied.body) == "CopyObjectResult")
+	copyResult.Steps = append(copyResult.Steps, step)
+	if outcome := c.outcome(copied, err, "CopyObjectResult", copyResult); outcome != OperationSupported {
+		return outcome
+	}
+
+	get, err := c.send("GET", key, nil, nil, nil)
+	same := err == nil && get.status == http.StatusOK && bytes.Equal(get.body, content)
+	metadata := get.header.Get("X-Amz-Meta-S3tester")
+	getStep := newStep(c.verbose, "GET copy", "200 with the source content and metadata", get.status, get.code, err, same && metadata == "source")
+	getStep.Observed += fmt.Sprintf(", x-amz-meta-s3tester %q", metadata)
+	copyResult.Steps = append(copyResult.Steps, getStep)
+	if !same {
+		return OperationBroken
+	}
+	if metadata != "source" {
+		copyResult.Quirks = append(copyResult.Quirks, "metadata not copied")
+	}
+
+	replaced, err := c.send("PUT", key, nil, nil, http.Header{
+		"X-Amz-Copy-Source":        {source},
+		"X-Amz-Metadata-Directive": {"REPLACE"},
+		"X-Amz-Meta-S3tester":      {"replaced"},
+	})
+	copyResult.Steps = append(copyResult.Steps, newStep(c.verbose, "CopyObject REPLACE metadata", "200 with CopyObjectResult", replaced.status, replaced.code, err,
+		resultElement(replaced.body) == "CopyObjectResult"))
+	if err == nil && replaced.status == http.StatusOK {
+		head, err := c.send("HEAD", key, nil, nil, nil)
+		metadata := head.header.Get("X-Amz-Meta-S3tester")
+		headStep := newStep(c.verbose, "HEAD replaced copy", `x-amz-meta-s3tester "replaced"`, head.status, head.code, err, metadata == "replaced")
+		headStep.Observed += fmt.Sprintf(", x-amz-meta-s3tester %q", metadata)
+		copyResult.Steps = append(copyResult.Steps, headStep)
+		if err == nil && metadata != "replaced" {
+			copyResult.Quirks = append(copyResult.Quirks, "REPLACE metadata directive ignored")
+		}
+	}
+
+	conditional, err := c.send("PUT", key, nil, nil, http.Header{
+		"X-Amz-Copy-Source":          {source},
+		"X-Amz-Copy-Source-If-Match": {`"00000000000000000000000000000000"`},
+	})
+	copyResult.Steps = append(copyResult.Steps, newStep(c.verbose, "CopyObject If-Match other ETag", "412", conditional.status, conditional.code, err,
+		conditional.status == http.StatusPreconditionFailed))
+	if err == nil && conditional.status < 300 {
+		copyResult.Quirks = append(copyResult.Quirks, "x-amz-copy-source-if-match ignored")
+	}
+
+	return OperationSupported
+}
+
+// uploadPartCopy copies part of the source into a multipart upload of one
+// part, completes it and reads the object back. An upload that does not
+// complete is aborted.
+func (c *CopyChecker) uploadPartCopy(source, key string, content []byte, copyResult *output.CopyResult) string {
+	created, err := c.send("POST", key, url.Values{"uploads": {""}}, nil, nil)
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err == nil && created.status == http.StatusOK {
+		xml.Unmarshal(created.body, &initiated)
+	}
+	copyResult.Steps = append(copyResult.Steps, newStep(c.verbose, "CreateMultipartUpload", "200 with an UploadId", created.status, created.code, err, initiated.UploadID != ""))
+	if initiated.UploadID == "" {
+		if outcome := c.outcome(created, err, "InitiateMultipartUploadResult", copyResult); outcome != OperationSupported {
+			return outcome
+		}
+		return OperationBroken
+	}
+	uploadID := url.Values{"uploadId": {initiated.UploadID}}
+
+	completed := false
+	defer c.client.cleanup(func() {
+		if completed {
+			return
+		}
+		if abort, err := c.send("DELETE", key, uploadID, nil, nil); err != nil || abort.status >= 300 {
+			c.verbose.LogMessage("Failed to abort upload %s: %v %d", initiated.UploadID, err, abort.status)
+		}
+	})
+
+	part, err := c.send("PUT", key, url.Values{"partNumber": {"1"}, "uploadId": {initiated.UploadID}}, nil, http.Header{
+		"X-Amz-Copy-Source":       {source},
+		"X-Amz-Copy-Source-Range": {fmt.Sprintf("bytes=0-%d", len(content)-1)},
+	})
+	var copied struct {
+		ETag string `xml:"ETag"`
+	}
+	if err == nil && part.status == http.StatusOK {
+		xml.Unmarshal(part.body, &copied)
+	}
+	copyResult.Steps = append(copyResult.Steps, newStep(c.verbose, "UploadPartCopy", "200 with CopyPartResult", part.status, part.code, err,
+		resultElement(part.body) == "CopyPartResult" && copied.ETag != ""))
+	if outcome := c.outcome(part, err, "CopyPartResult", copyResult); outcome != OperationSupported {
+		return outcome
+	}
+
+	complete := []byte(fmt.Sprintf("<CompleteMultipartUpload><Part><PartNumber>1</PartNumber><ETag>%s</ETag></Part></CompleteMultipartUpload>", copied.ETag))
+	done, err := c.send("POST", key, uploadID, complete, http.Header{"Content-Type": {"application/xml"}})
+	copyResult.Steps = append(copyResult.Steps, newStep(c.verbose, "CompleteMultipartUpload", "200 with CompleteMultipartUploadResult", done.status, done.code, err,
+		resultElement(done.body) == "CompleteMultipartUploadResult"))
+	if resultElement(done.body) != "CompleteMultipartUploadResult" {
+		if err == nil && done.status == http.StatusOK {
+			copyResult.Quirks = append(copyResult.Quirks, "200 with an error body")
+		}
+		return OperationBroken
+	}
+	completed = true
+
+	get, err := c.send("GET", key, nil, nil, nil)
+	same := err == nil && get.status == http.StatusOK && bytes.Equal(get.body, content)
+	copyResult.Steps = append(copyResult.Steps, newStep(c.verbose, "GET part copy", "200 with the copied range", get.status, get.code, err, same))
+	if !same {
+		return OperationBroken
+	}
+	return OperationSupported
+}
+
+// outcome classifies the response to an operation whose success response is
+// the XML element want. S3 may answer a copy with 200 and an error in the
+// body, which is recorded as a quirk.
+func (c *CopyChecker) outcome(resp operationResponse, err error, want string, copyResult *output.CopyResult) string {
+	switch {
+	case err != nil:
+		return OperationBroken
+	case resp.status == http.StatusNotImplemented || resp.code == "NotImplemented":
+		return OperationNotSupported
+	case resp.status == http.StatusForbidden:
+		return OperationDenied
+	case resp.status == http.StatusOK && resultElement(resp.body) == want:
+		return OperationSupported
+	case resp.status == http.StatusOK && resp.code != "":
+		copyResult.Quirks = append(copyResult.Quirks, "200 with an error body")
+	}
+	return OperationBroken
+}
+
+// send sends a request with the given extra headers
+func (c *CopyChecker) send(method, key string, query url.Values, body []byte, header http.Header) (operationResponse, error) {
+	return sendOperation(c.client, method, key, query, body, header)
+}
+
+// sendOperation sends a request with the given extra headers and keeps the
+// parts of the response the copy and batch delete checks compare
+func sendOperation(client *s3Client, method, key string, query url.Values, body []byte, header http.Header) (operationResponse, error) {
+	req, err := client.newRequest(method, key, query, body)
+	if err != nil {
+		return operationResponse{}, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, respBody, err := client.do(req, body)
+	if err != nil {
+		return operationResponse{}, err
+	}
+
+	return operationResponse{
+		status: resp.StatusCode,
+		code:   errorCode(respBody),
+		header: resp.Header,
+		body:   respBody,
+	}, nil
+}
+
+// resultElement returns the name of the root element of an XML response
+func resultElement(body []byte) string {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(body, &root); err != nil {
+		return ""
+	}
+	return root.XMLName.Local
+}
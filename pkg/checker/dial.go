@@ -0,0 +1,104 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+	"github.com/s3-bucket-tester/s3tester/pkg/proxy"
+)
+
+// dialProxy returns the proxy that raw TCP and TLS connections to the
+// endpoint go through: --proxy, or HTTP_PROXY/HTTPS_PROXY for the endpoint's
+// scheme unless NO_PROXY matches. nil means the connection is direct.
+func dialProxy(config output.Config) *url.URL {
+	target, err := url.Parse(config.Endpoint)
+	if err != nil || target.Host == "" {
+		return nil
+	}
+	proxyURL, err := proxy.ForTarget(config.Proxy, target)
+	if err != nil {
+		// Validated when the configuration was loaded
+		return nil
+	}
+	return proxyURL
+}
+
+// newDialer returns a dialer for raw connections to the endpoint
+func newDialer(config output.Config, timeout time.Duration) *proxy.Dialer {
+	return &proxy.Dialer{Proxy: dialProxy(config), Timeout: timeout}
+}
+
+// tcpNetwork returns the network of TCP connections: "tcp", or "tcp4" or
+// "tcp6" with --ipv4 or --ipv6
+func tcpNetwork(config output.Config) string {
+	return output.FamilyNetwork("tcp", config.IPFamily)
+}
+
+// lookupIPAddr resolves host, querying only the forced address family with
+// --ipv4 or --ipv6
+func lookupIPAddr(ctx context.Context, config output.Config, host string) ([]net.IPAddr, error) {
+	ips, err := net.DefaultResolver.LookupIP(ctx, output.FamilyNetwork("ip", config.IPFamily), host)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]net.IPAddr, len(ips))
+	for i, ip := range ips {
+		addrs[i] = net.IPAddr{IP: ip}
+	}
+	return addrs, nil
+}
+
+// lookupCNAME returns the canonical name of host. With --ipv4 or --ipv6 the
+// name server is queried over that address family only, as every other
+// connection of the run is.
+func lookupCNAME(ctx context.Context, config output.Config, host string) (string, error) {
+	if config.IPFamily == "" {
+		return net.DefaultResolver.LookupCNAME(ctx, host)
+	}
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, output.FamilyNetwork(network, config.IPFamily), address)
+		},
+	}
+	return resolver.LookupCNAME(ctx, host)
+}
+
+// serverName returns the TLS server name for host: the --sni override, if
+// any, or else the host itself
+func serverName(config output.Config, host string) string {
+	if config.SNI != "" {
+		return config.SNI
+	}
+	return host
+}
+
+// dialTCP opens a TCP connection to the endpoint, through the proxy if any
+func dialTCP(ctx context.Context, config output.Config, address string, timeout time.Duration) (net.Conn, error) {
+	return newDialer(config, timeout).DialContext(ctx, tcpNetwork(config), address)
+}
+
+// dialTLS opens a TCP connection to the endpoint, through the proxy if any,
+// and completes the TLS handshake
+func dialTLS(ctx context.Context, config output.Config, address string, timeout time.Duration, tlsConfig *tls.Config) (*tls.Conn, error) {
+	rawConn, err := dialTCP(ctx, config, address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := tls.Client(rawConn, tlsConfig)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
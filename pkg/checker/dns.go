@@ -32,7 +32,7 @@ func (c *DNSChecker) Name() string {
 }
 
 // Check performs the DNS resolution check
-func (c *DNSChecker) Check() output.TestResult {
+func (c *DNSChecker) Check(ctx context.Context) output.TestResult {
 	startTime := time.Now()
 
 	c.verbose.LogSection("Starting DNS Resolution Check")
@@ -40,7 +40,7 @@ func (c *DNSChecker) Check() output.TestResult {
 	// Handle IP addresses directly
 	if c.isIPAddress(c.Hostname) {
 		c.verbose.LogMessage("Hostname is an IP address: %s", c.Hostname)
-		return c.handleIPCheck(startTime)
+		return c.handleIPCheck(ctx, startTime)
 	}
 
 	c.verbose.LogMessage("Resolving hostname: %s", c.Hostname)
@@ -53,12 +53,12 @@ func (c *DNSChecker) Check() output.TestResult {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Config.Timeout)*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.Config.DNSTimeout)*time.Second)
 	defer cancel()
 
 	// Resolve hostname
 	resolver := &net.Resolver{}
-	ips, err := resolver.LookupIPAddr(ctx, c.Hostname)
+	ips, err := lookupIPAddr(ctx, c.Config, c.Hostname)
 	if err != nil {
 		c.verbose.LogMessage("DNS resolution failed: %v", err)
 		result.Status = output.StatusFail
@@ -82,7 +82,7 @@ func (c *DNSChecker) Check() output.TestResult {
 	// Perform reverse DNS lookup for first IP
 	var reverseDNS string
 	if len(ips) > 0 {
-		names, err := net.LookupAddr(ips[0].IP.String())
+		names, err := resolver.LookupAddr(ctx, ips[0].IP.String())
 		if err == nil && len(names) > 0 {
 			reverseDNS = names[0]
 			c.verbose.LogMessage("Reverse DNS for %s: %s", ips[0].IP.String(), reverseDNS)
@@ -113,7 +113,7 @@ func (c *DNSChecker) isIPAddress(s string) bool {
 }
 
 // handleIPCheck handles the case when hostname is an IP address
-func (c *DNSChecker) handleIPCheck(startTime time.Time) output.TestResult {
+func (c *DNSChecker) handleIPCheck(ctx context.Context, startTime time.Time) output.TestResult {
 	c.verbose.LogMessage("No DNS resolution needed - hostname is already an IP address")
 
 	result := output.TestResult{
@@ -134,7 +134,7 @@ func (c *DNSChecker) handleIPCheck(startTime time.Time) output.TestResult {
 	c.verbose.LogMessage("Using IP address directly: %s", c.Hostname)
 
 	// Perform reverse DNS lookup
-	names, err := net.LookupAddr(c.Hostname)
+	names, err := net.DefaultResolver.LookupAddr(ctx, c.Hostname)
 	var reverseDNS string
 	if err == nil && len(names) > 0 {
 		reverseDNS = names[0]
@@ -0,0 +1,157 @@
+package checker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// ContentEncodingChecker verifies that objects stored with Content-Encoding: gzip
+// are returned byte-identically with the header intact
+type ContentEncodingChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewContentEncodingChecker creates a new Content-Encoding passthrough checker
+func NewContentEncodingChecker(config output.Config) *ContentEncodingChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &ContentEncodingChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *ContentEncodingChecker) Name() string {
+	return "Content-Encoding Passthrough Check"
+}
+
+// Check performs the Content-Encoding passthrough check
+func (c *ContentEncodingChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Content-Encoding Passthrough Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+		Duration: time.Since(startTime),
+	}
+
+	// Compress a test payload
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(bytes.Repeat([]byte("s3tester gzip passthrough\n"), 256))
+	gz.Close()
+	payload := compressed.Bytes()
+
+	key := c.client.testObjectKey("gzip")
+	encodingResult := output.ContentEncodingResult{
+		Key:           key,
+		UploadedBytes: len(payload),
+	}
+
+	// Upload with Content-Encoding: gzip
+	req, err := c.client.newRequest("PUT", key, nil, payload)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	c.verbose.LogMessage("Uploading %d gzip-compressed bytes to %s", len(payload), key)
+
+	resp, respBody, err := c.client.do(req, payload)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("PUT failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("PUT failed: %s", parseErrorResponse(resp.StatusCode, respBody))
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	// Download advertising gzip support and advertising none; setting the
+	// header explicitly stops net/http from decompressing transparently
+	for _, acceptEncoding := range []string{"gzip", "identity"} {
+		encodingResult.Downloads = append(encodingResult.Downloads, c.download(key, acceptEncoding, payload))
+	}
+
+	c.client.cleanup(func() {
+		if err := c.client.deleteObject(key); err != nil {
+			c.verbose.LogMessage("Failed to delete test object %s: %v", key, err)
+		}
+	})
+
+	var corrupted, headerLost []string
+	for _, d := range encodingResult.Downloads {
+		switch {
+		case d.Error != "":
+			corrupted = append(corrupted, fmt.Sprintf("Accept-Encoding %s: %s", d.AcceptEncoding, d.Error))
+		case !d.ByteIdentical:
+			corrupted = append(corrupted, fmt.Sprintf("Accept-Encoding %s: body altered (%d bytes received)", d.AcceptEncoding, d.ReceivedBytes))
+		case !d.HeaderPreserved:
+			headerLost = append(headerLost, fmt.Sprintf("Accept-Encoding %s: Content-Encoding returned as %q", d.AcceptEncoding, d.ContentEncoding))
+		}
+	}
+
+	switch {
+	case len(corrupted) > 0:
+		result.Status = output.StatusFail
+		result.Error = strings.Join(corrupted, "; ")
+	case len(headerLost) > 0:
+		result.Status = output.StatusWarn
+		result.Error = strings.Join(headerLost, "; ")
+	}
+
+	result.Details = encodingResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Content-Encoding passthrough check completed in %v", result.Duration)
+
+	return result
+}
+
+// download fetches the object with the given Accept-Encoding and compares it to the upload
+func (c *ContentEncodingChecker) download(key, acceptEncoding string, expected []byte) output.ContentEncodingDownload {
+	d := output.ContentEncodingDownload{AcceptEncoding: acceptEncoding}
+
+	header := http.Header{}
+	header.Set("Accept-Encoding", acceptEncoding)
+
+	resp, body, err := c.client.getObjectWithHeaders(key, header)
+	if resp != nil {
+		d.StatusCode = resp.StatusCode
+		d.ContentEncoding = resp.Header.Get("Content-Encoding")
+	}
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+
+	d.ReceivedBytes = len(body)
+	d.ByteIdentical = bytes.Equal(body, expected)
+	d.HeaderPreserved = strings.EqualFold(d.ContentEncoding, "gzip")
+
+	c.verbose.LogMessage("Accept-Encoding %s: %d bytes, Content-Encoding %q, identical: %v",
+		acceptEncoding, d.ReceivedBytes, d.ContentEncoding, d.ByteIdentical)
+
+	return d
+}
@@ -0,0 +1,150 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+const (
+	// expectContinueWait is how long the client waits for the interim
+	// response before sending the body anyway
+	expectContinueWait = 3 * time.Second
+
+	// expectContinueBodySize is the size of the uploaded test object
+	expectContinueBodySize = 64 * 1024
+)
+
+// ExpectContinueChecker verifies that PUT requests with Expect: 100-continue
+// receive a timely interim response
+type ExpectContinueChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewExpectContinueChecker creates a new Expect: 100-continue checker
+func NewExpectContinueChecker(config output.Config) *ExpectContinueChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	client := newS3Client(config, verbose)
+	if transport := httpTransport(client.httpClient); transport != nil {
+		transport.ExpectContinueTimeout = expectContinueWait
+	}
+
+	return &ExpectContinueChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      client,
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *ExpectContinueChecker) Name() string {
+	return "Expect: 100-continue Check"
+}
+
+// Check performs the Expect: 100-continue check
+func (c *ExpectContinueChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Expect: 100-continue Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+		Duration: time.Since(startTime),
+	}
+
+	key := c.client.testObjectKey("expect-continue")
+	body := bytes.Repeat([]byte("s3tester"), expectContinueBodySize/8)
+
+	req, err := c.client.newRequest("PUT", key, nil, body)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	req.Header.Set("Expect", "100-continue")
+
+	// Trace when headers go out, when 100 Continue arrives and when the body is sent
+	var wroteHeaders, got100, wroteRequest, firstByte time.Time
+	trace := &httptrace.ClientTrace{
+		WroteHeaders:         func() { wroteHeaders = time.Now() },
+		Got100Continue:       func() { got100 = time.Now() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { wroteRequest = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	c.verbose.LogMessage("Uploading %d bytes to %s with Expect: 100-continue", len(body), key)
+
+	resp, respBody, err := c.client.do(req, body)
+	if err != nil {
+		c.verbose.LogMessage("PUT failed: %v", err)
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("PUT with Expect: 100-continue failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	expectResult := output.ExpectContinueResult{
+		Key:           key,
+		StatusCode:    resp.StatusCode,
+		Got100:        !got100.IsZero(),
+		WaitTimeoutMs: expectContinueWait.Milliseconds(),
+	}
+
+	var bodyDelay time.Duration
+	if !wroteHeaders.IsZero() {
+		if expectResult.Got100 {
+			expectResult.InterimMs = durationToMs(got100.Sub(wroteHeaders))
+		}
+		if !wroteRequest.IsZero() {
+			bodyDelay = wroteRequest.Sub(wroteHeaders)
+			expectResult.BodyDelayMs = durationToMs(bodyDelay)
+		}
+		if !firstByte.IsZero() {
+			expectResult.FinalResponseMs = durationToMs(firstByte.Sub(wroteHeaders))
+		}
+	}
+
+	switch {
+	case resp.StatusCode >= 300:
+		expectResult.Behavior = "PUT rejected by server"
+		result.Status = output.StatusFail
+		result.Error = parseErrorResponse(resp.StatusCode, respBody)
+	case expectResult.Got100:
+		expectResult.Behavior = "100 Continue received before body upload"
+	case bodyDelay >= expectContinueWait*9/10:
+		expectResult.Behavior = fmt.Sprintf("No interim response; client stalled %v before sending the body", expectContinueWait)
+		result.Status = output.StatusWarn
+	default:
+		expectResult.Behavior = "Body sent without interim response"
+	}
+
+	c.verbose.LogMessage("Behavior: %s", expectResult.Behavior)
+
+	// Clean up the test object
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		c.client.cleanup(func() {
+			if err := c.client.deleteObject(key); err != nil {
+				c.verbose.LogMessage("Failed to delete test object %s: %v", key, err)
+			} else {
+				expectResult.CleanedUp = true
+			}
+		})
+	}
+
+	result.Details = expectResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Expect: 100-continue check completed in %v", result.Duration)
+
+	return result
+}
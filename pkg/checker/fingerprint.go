@@ -0,0 +1,264 @@
+package checker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// States of a fingerprinted access setting
+const (
+	FingerprintPresent     = "present"
+	FingerprintNone        = "none"
+	FingerprintDenied      = "denied"
+	FingerprintUnsupported = "unsupported"
+)
+
+// policyFingerprints holds the last fingerprint of each watched bucket, so a
+// watch cycle can compare against the one before it
+var policyFingerprints struct {
+	sync.Mutex
+	byTarget map[string]*output.PolicyFingerprintResult
+}
+
+// PolicyFingerprintChecker hashes the bucket policy, ACL grants and Block
+// Public Access settings of the bucket, and reports which of them changed
+// since the previous watch cycle
+type PolicyFingerprintChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewPolicyFingerprintChecker creates a new policy fingerprint checker
+func NewPolicyFingerprintChecker(config output.Config) *PolicyFingerprintChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &PolicyFingerprintChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *PolicyFingerprintChecker) Name() string {
+	return "Policy Fingerprint Check"
+}
+
+// fingerprintSource is a bucket sub-resource and how to put its content in a
+// canonical form, so formatting and ordering do not count as changes
+type fingerprintSource struct {
+	name      string
+	resource  string
+	canonical func(body []byte) (string, error)
+}
+
+var fingerprintSources = []fingerprintSource{
+	{name: "policy", resource: "policy", canonical: canonicalPolicy},
+	{name: "acl", resource: "acl", canonical: canonicalACL},
+	{name: "publicAccessBlock", resource: "publicAccessBlock", canonical: canonicalPublicAccessBlock},
+}
+
+// Check fingerprints the access settings. The first cycle records the
+// baseline; a later one warns when a setting changed, and the new
+// fingerprint becomes the baseline. A setting that could not be read this
+// cycle, such as after a timeout, keeps its previous value.
+func (c *PolicyFingerprintChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Policy Fingerprint Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	target := c.Config.Target()
+	policyFingerprints.Lock()
+	previous := policyFingerprints.byTarget[target]
+	policyFingerprints.Unlock()
+
+	fingerprint := &output.PolicyFingerprintResult{}
+	for _, source := range fingerprintSources {
+		component := c.fingerprint(source)
+		if component.Error != "" && previous != nil {
+			if before := previous.Component(source.name); before != nil && before.State != "" {
+				c.verbose.LogMessage("%s could not be read, keeping the previous fingerprint: %s", source.name, component.Error)
+				kept := *before
+				kept.Error = component.Error
+				component = kept
+			}
+		}
+		c.verbose.LogMessage("%s: %s %s", component.Name, component.State, component.Hash)
+		fingerprint.Components = append(fingerprint.Components, component)
+	}
+	fingerprint.Fingerprint = combinedFingerprint(fingerprint.Components)
+
+	if previous == nil {
+		fingerprint.Baseline = true
+	} else {
+		fingerprint.PreviousFingerprint = previous.Fingerprint
+		for _, component := range fingerprint.Components {
+			// A setting never read yet has nothing to compare with
+			before := previous.Component(component.Name)
+			if before == nil || before.State == "" || component.State == "" ||
+				(before.State == component.State && before.Hash == component.Hash) {
+				continue
+			}
+			fingerprint.Changes = append(fingerprint.Changes, output.FingerprintChange{
+				Component: component.Name,
+				Before:    before.Describe(),
+				After:     component.Describe(),
+			})
+		}
+	}
+
+	policyFingerprints.Lock()
+	if policyFingerprints.byTarget == nil {
+		policyFingerprints.byTarget = make(map[string]*output.PolicyFingerprintResult)
+	}
+	policyFingerprints.byTarget[target] = fingerprint
+	policyFingerprints.Unlock()
+
+	var unread []string
+	for _, component := range fingerprint.Components {
+		if component.Error != "" {
+			unread = append(unread, component.Name)
+		}
+	}
+	switch {
+	case len(fingerprint.Changes) > 0:
+		changed := make([]string, 0, len(fingerprint.Changes))
+		for _, change := range fingerprint.Changes {
+			changed = append(changed, fmt.Sprintf("%s (%s -> %s)", change.Component, change.Before, change.After))
+		}
+		result.Status = output.StatusWarn
+		result.Error = "bucket access settings changed: " + strings.Join(changed, ", ")
+	case len(unread) > 0:
+		result.Status = output.StatusWarn
+		result.Error = "could not read " + strings.Join(unread, ", ")
+	}
+
+	result.Details = *fingerprint
+	result.Duration = time.Since(startTime)
+	return result
+}
+
+// fingerprint reads one sub-resource and hashes its canonical form. Settings
+// that are absent, denied or not implemented are stable states of their own;
+// other failures leave the setting unread.
+func (c *PolicyFingerprintChecker) fingerprint(source fingerprintSource) output.FingerprintComponent {
+	component := output.FingerprintComponent{Name: source.name}
+
+	req, err := c.client.newRequest("GET", "", url.Values{source.resource: {""}}, nil)
+	if err != nil {
+		component.Error = err.Error()
+		return component
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		component.Error = err.Error()
+		return component
+	}
+
+	code := errorCode(body)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		canonical, err := source.canonical(body)
+		if err != nil {
+			component.Error = err.Error()
+			return component
+		}
+		sum := sha256.Sum256([]byte(canonical))
+		component.State = FingerprintPresent
+		component.Hash = hex.EncodeToString(sum[:])
+	case resp.StatusCode == http.StatusNotFound && code != "" && code != "NoSuchBucket":
+		// Nothing configured, e.g. NoSuchBucketPolicy
+		component.State = FingerprintNone
+	case resp.StatusCode == http.StatusForbidden:
+		component.State = FingerprintDenied
+	case resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusMethodNotAllowed ||
+		code == "NotImplemented" || code == "MethodNotAllowed":
+		component.State = FingerprintUnsupported
+	default:
+		component.Error = parseErrorResponse(resp.StatusCode, body)
+	}
+	return component
+}
+
+// combinedFingerprint hashes the states and hashes of all components
+func combinedFingerprint(components []output.FingerprintComponent) string {
+	h := sha256.New()
+	for _, component := range components {
+		fmt.Fprintf(h, "%s=%s:%s\n", component.Name, component.State, component.Hash)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonicalPolicy re-encodes a bucket policy with sorted keys and without
+// whitespace
+func canonicalPolicy(body []byte) (string, error) {
+	var policy interface{}
+	if err := json.Unmarshal(body, &policy); err != nil {
+		return "", fmt.Errorf("invalid bucket policy: %w", err)
+	}
+	canonical, err := json.Marshal(policy)
+	return string(canonical), err
+}
+
+// accessControlPolicy is the owner and grants of a GetBucketAcl response
+type accessControlPolicy struct {
+	Owner  string `xml:"Owner>ID"`
+	Grants []struct {
+		Grantee struct {
+			Type         string `xml:"http://www.w3.org/2001/XMLSchema-instance type,attr"`
+			ID           string `xml:"ID"`
+			URI          string `xml:"URI"`
+			EmailAddress string `xml:"EmailAddress"`
+		} `xml:"Grantee"`
+		Permission string `xml:"Permission"`
+	} `xml:"AccessControlList>Grant"`
+}
+
+// canonicalACL lists the owner and the sorted grants of an ACL, leaving out
+// display names, which the owner can change without changing access
+func canonicalACL(body []byte) (string, error) {
+	var acl accessControlPolicy
+	if err := xml.Unmarshal(body, &acl); err != nil {
+		return "", fmt.Errorf("invalid ACL: %w", err)
+	}
+
+	grants := make([]string, 0, len(acl.Grants))
+	for _, g := range acl.Grants {
+		grants = append(grants, strings.Join([]string{g.Grantee.Type, g.Grantee.ID, g.Grantee.URI, g.Grantee.EmailAddress, g.Permission}, "|"))
+	}
+	sort.Strings(grants)
+	return "owner=" + acl.Owner + "\n" + strings.Join(grants, "\n"), nil
+}
+
+// canonicalPublicAccessBlock lists the four Block Public Access settings
+func canonicalPublicAccessBlock(body []byte) (string, error) {
+	var pab struct {
+		BlockPublicAcls       bool `xml:"BlockPublicAcls"`
+		IgnorePublicAcls      bool `xml:"IgnorePublicAcls"`
+		BlockPublicPolicy     bool `xml:"BlockPublicPolicy"`
+		RestrictPublicBuckets bool `xml:"RestrictPublicBuckets"`
+	}
+	if err := xml.Unmarshal(body, &pab); err != nil {
+		return "", fmt.Errorf("invalid public access block configuration: %w", err)
+	}
+	return fmt.Sprintf("BlockPublicAcls=%t IgnorePublicAcls=%t BlockPublicPolicy=%t RestrictPublicBuckets=%t",
+		pab.BlockPublicAcls, pab.IgnorePublicAcls, pab.BlockPublicPolicy, pab.RestrictPublicBuckets), nil
+}
@@ -0,0 +1,172 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// connectionAttemptDelay is the RFC 8305 recommended delay before starting
+// the IPv4 attempt while the preferred IPv6 attempt is still pending
+const connectionAttemptDelay = 250 * time.Millisecond
+
+// familyAttempt tracks the outcome of dialing one address family
+type familyAttempt struct {
+	family   string
+	addrs    []net.IP
+	started  time.Duration
+	finished time.Duration
+	address  string
+	err      error
+}
+
+// happyEyeballs races IPv6 and IPv4 connections to host:port following RFC 8305.
+// Both families are allowed to complete so the margin between them can be reported.
+func (c *TCPChecker) happyEyeballs(ctx context.Context, host string, port int) *output.HappyEyeballsResult {
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		c.verbose.LogMessage("Happy Eyeballs: resolution failed: %v", err)
+		return &output.HappyEyeballsResult{Error: err.Error()}
+	}
+
+	v6 := &familyAttempt{family: "IPv6"}
+	v4 := &familyAttempt{family: "IPv4"}
+	for _, ip := range ips {
+		if ip.IP.To4() != nil {
+			v4.addrs = append(v4.addrs, ip.IP)
+		} else {
+			v6.addrs = append(v6.addrs, ip.IP)
+		}
+	}
+
+	c.verbose.LogMessage("Happy Eyeballs: %d IPv6 and %d IPv4 address(es)", len(v6.addrs), len(v4.addrs))
+
+	start := time.Now()
+	v6Failed := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// IPv6 is preferred and starts immediately
+	if len(v6.addrs) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.dialFamily(ctx, v6, port, start)
+			if v6.err != nil {
+				close(v6Failed)
+			}
+		}()
+	} else {
+		close(v6Failed)
+	}
+
+	// IPv4 starts after the attempt delay, or as soon as IPv6 fails
+	if len(v4.addrs) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if len(v6.addrs) > 0 {
+				select {
+				case <-time.After(connectionAttemptDelay):
+				case <-v6Failed:
+				case <-ctx.Done():
+				}
+			}
+			c.dialFamily(ctx, v4, port, start)
+		}()
+	}
+
+	wg.Wait()
+
+	return buildHappyEyeballsResult(v6, v4)
+}
+
+// dialFamily tries each address of the family in turn until one connects
+func (c *TCPChecker) dialFamily(ctx context.Context, attempt *familyAttempt, port int, start time.Time) {
+	attempt.started = time.Since(start)
+	dialer := &net.Dialer{}
+
+	for _, ip := range attempt.addrs {
+		address := net.JoinHostPort(ip.String(), strconv.Itoa(port))
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			c.verbose.LogMessage("Happy Eyeballs: %s attempt to %s failed: %v", attempt.family, address, err)
+			attempt.err = err
+			continue
+		}
+		conn.Close()
+
+		attempt.finished = time.Since(start)
+		attempt.address = address
+		attempt.err = nil
+		c.verbose.LogMessage("Happy Eyeballs: %s connected to %s at +%v", attempt.family, address, attempt.finished)
+		return
+	}
+	attempt.finished = time.Since(start)
+}
+
+// buildHappyEyeballsResult determines the winning family and its margin
+func buildHappyEyeballsResult(v6, v4 *familyAttempt) *output.HappyEyeballsResult {
+	result := &output.HappyEyeballsResult{
+		IPv6: familyOutcome(v6),
+		IPv4: familyOutcome(v4),
+	}
+
+	v6ok := len(v6.addrs) > 0 && v6.err == nil
+	v4ok := len(v4.addrs) > 0 && v4.err == nil
+
+	switch {
+	case v6ok && (!v4ok || v6.finished <= v4.finished):
+		result.WinningFamily = v6.family
+		result.WinningAddress = v6.address
+		if v4ok {
+			result.MarginMs = durationToMs(v4.finished - v6.finished)
+		}
+	case v4ok:
+		result.WinningFamily = v4.family
+		result.WinningAddress = v4.address
+		if v6ok {
+			result.MarginMs = durationToMs(v6.finished - v4.finished)
+		}
+	default:
+		result.Error = "no address family could connect"
+	}
+
+	return result
+}
+
+// familyOutcome converts an attempt into its report form; nil when the family has no addresses
+func familyOutcome(attempt *familyAttempt) *output.AddressFamilyOutcome {
+	if len(attempt.addrs) == 0 {
+		return nil
+	}
+
+	outcome := &output.AddressFamilyOutcome{
+		Addresses:     len(attempt.addrs),
+		StartOffsetMs: durationToMs(attempt.started),
+	}
+	if attempt.err != nil {
+		outcome.Error = attempt.err.Error()
+		return outcome
+	}
+
+	outcome.Connected = true
+	outcome.Address = attempt.address
+	outcome.ConnectMs = durationToMs(attempt.finished - attempt.started)
+	return outcome
+}
+
+// happyEyeballsWarning describes dual-stack problems worth surfacing to the user
+func happyEyeballsWarning(he *output.HappyEyeballsResult) string {
+	if he == nil || he.IPv6 == nil || he.IPv4 == nil {
+		return ""
+	}
+	if !he.IPv6.Connected && he.IPv4.Connected {
+		return fmt.Sprintf("IPv6 is advertised but unreachable; dual-stack clients fall back to IPv4 after %v", connectionAttemptDelay)
+	}
+	return ""
+}
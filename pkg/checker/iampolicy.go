@@ -0,0 +1,297 @@
+package checker
+
+import (
+	"sort"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/options"
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// corePermissions are needed by every run: the authentication check sends a
+// HEAD bucket request, which is authorized by s3:ListBucket
+var corePermissions = []Permission{
+	{Action: "s3:ListBucket"},
+}
+
+// policyCheckPermissions are needed by the bucket policy and ACL check
+var policyCheckPermissions = []Permission{
+	{Action: "s3:GetBucketPolicy"},
+	{Action: "s3:GetBucketAcl"},
+}
+
+// publicAccessBlockPermissions read the bucket and, on AWS, the account
+// Block Public Access settings; sts:GetCallerIdentity needs no permission
+func publicAccessBlockPermissions(config output.Config) []Permission {
+	permissions := []Permission{{Action: "s3:GetBucketPublicAccessBlock"}}
+	if config.Provider == "aws" {
+		permissions = append(permissions, Permission{Action: "s3:GetAccountPublicAccessBlock", Account: true})
+	}
+	return permissions
+}
+
+// policyFingerprintPermissions read the settings fingerprinted by
+// --watch-policy
+var policyFingerprintPermissions = []Permission{
+	{Action: "s3:GetBucketPolicy"},
+	{Action: "s3:GetBucketAcl"},
+	{Action: "s3:GetBucketPublicAccessBlock"},
+}
+
+// capabilityActions maps each --require capability to the IAM action that
+// authorizes reading its configuration
+var capabilityActions = map[string]string{
+	"versioning":  "s3:GetBucketVersioning",
+	"encryption":  "s3:GetEncryptionConfiguration",
+	"object-lock": "s3:GetBucketObjectLockConfiguration",
+	"policy":      "s3:GetBucketPolicy",
+	"acl":         "s3:GetBucketAcl",
+}
+
+// versionedDeletePermissions are needed to read the versioning status and to
+// write, read and delete individual versions of a test object
+var versionedDeletePermissions = []Permission{
+	{Action: "s3:GetBucketVersioning"},
+	{Action: "s3:PutObject", Object: true},
+	{Action: "s3:GetObject", Object: true},
+	{Action: "s3:GetObjectVersion", Object: true},
+	{Action: "s3:DeleteObject", Object: true},
+	{Action: "s3:DeleteObjectVersion", Object: true},
+}
+
+// versioningPermissions reads the versioning status and, unless the run is
+// read-only, writes, lists, reads and deletes versions of a test object
+func versioningPermissions(config output.Config) []Permission {
+	permissions := []Permission{{Action: "s3:GetBucketVersioning"}}
+	if !config.ReadOnly {
+		permissions = append(permissions,
+			Permission{Action: "s3:ListBucketVersions"},
+			Permission{Action: "s3:PutObject", Object: true},
+			Permission{Action: "s3:GetObject", Object: true},
+			Permission{Action: "s3:GetObjectVersion", Object: true},
+			Permission{Action: "s3:DeleteObjectVersion", Object: true})
+	}
+	return permissions
+}
+
+// objectLockPermissions reads the Object Lock configuration and, for the
+// retention test, writes a retained test object, reads its retention and
+// deletes it by bypassing the governance retention
+func objectLockPermissions(config output.Config) []Permission {
+	permissions := []Permission{{Action: "s3:GetBucketObjectLockConfiguration"}}
+	if config.TestRetention && !config.ReadOnly {
+		permissions = append(permissions,
+			Permission{Action: "s3:PutObject", Object: true},
+			Permission{Action: "s3:PutObjectRetention", Object: true},
+			Permission{Action: "s3:GetObjectRetention", Object: true},
+			Permission{Action: "s3:DeleteObjectVersion", Object: true},
+			Permission{Action: "s3:BypassGovernanceRetention", Object: true})
+	}
+	return permissions
+}
+
+// replicationPermissions reads the replication rules and, for the canary,
+// writes, polls and deletes a test object
+func replicationPermissions(config output.Config) []Permission {
+	permissions := []Permission{{Action: "s3:GetReplicationConfiguration"}}
+	if config.TestReplication && !config.ReadOnly {
+		permissions = append(permissions, objectRoundTrip...)
+	}
+	return permissions
+}
+
+// notificationPermissions reads the notification targets and, to verify
+// delivery, writes and deletes a canary object
+func notificationPermissions(config output.Config) []Permission {
+	permissions := []Permission{{Action: "s3:GetBucketNotification"}}
+	if config.VerifyNotifications != "" && !config.ReadOnly {
+		permissions = append(permissions,
+			Permission{Action: "s3:PutObject", Object: true},
+			Permission{Action: "s3:DeleteObject", Object: true})
+	}
+	return permissions
+}
+
+// artifactPermissions lists the artifact inventory, which also deletes stale
+// artifacts when purging
+func artifactPermissions(config output.Config) []Permission {
+	permissions := []Permission{{Action: "s3:ListBucket"}}
+	if config.PurgeArtifacts {
+		permissions = append(permissions, Permission{Action: "s3:DeleteObject", Object: true})
+	}
+	return permissions
+}
+
+// sdkParityPermissions covers the bucket operations of the SDK parity check
+// and, unless the run is read-only, its object round trip
+func sdkParityPermissions(config output.Config) []Permission {
+	permissions := []Permission{{Action: "s3:ListBucket"}}
+	if !config.ReadOnly {
+		permissions = append(permissions, objectRoundTrip...)
+	}
+	return permissions
+}
+
+// cdnPermissions covers the test object of the CDN delivery check, or reading
+// the --object-key object
+func cdnPermissions(config output.Config) []Permission {
+	if config.ObjectKey != "" {
+		return []Permission{{Action: "s3:GetObject", Object: true, Key: config.ObjectKey}}
+	}
+	return objectRoundTrip
+}
+
+// capabilityProbePermissions reads the bucket listing and the policy, ACL
+// and versioning configuration, and starts and aborts a multipart upload
+// unless the run is read-only
+func capabilityProbePermissions(config output.Config) []Permission {
+	permissions := []Permission{
+		{Action: "s3:ListBucket"},
+		{Action: "s3:GetBucketPolicy"},
+		{Action: "s3:GetBucketAcl"},
+		{Action: "s3:GetBucketVersioning"},
+	}
+	if !config.ReadOnly {
+		permissions = append(permissions,
+			Permission{Action: "s3:PutObject", Object: true},
+			Permission{Action: "s3:AbortMultipartUpload", Object: true})
+	}
+	return permissions
+}
+
+// permissionMatrixPermissions grants every operation of the matrix, so a run
+// with the generated policy reports all of them as allowed
+func permissionMatrixPermissions(output.Config) []Permission {
+	permissions := make([]Permission, 0, len(PermissionOperations))
+	for _, op := range PermissionOperations {
+		permissions = append(permissions, Permission{Action: op.Action, Object: op.Object})
+	}
+	return permissions
+}
+
+// objectAccessPermissions reads the object given with --object-key
+func objectAccessPermissions(config output.Config) []Permission {
+	permissions := make([]Permission, 0, len(ObjectAccessOperations))
+	for _, op := range ObjectAccessOperations {
+		permissions = append(permissions, Permission{Action: op.Action, Object: true, Key: config.ObjectKey})
+	}
+	return permissions
+}
+
+// capabilityPermissions reads the configuration of each required capability
+func capabilityPermissions(config output.Config) []Permission {
+	requirements, err := options.ParseRequirements(config.Require)
+	if err != nil {
+		return nil
+	}
+	var permissions []Permission
+	for _, req := range requirements {
+		if action, ok := capabilityActions[req.Capability]; ok {
+			permissions = append(permissions, Permission{Action: action})
+		}
+	}
+	return permissions
+}
+
+// objectTaggingPermissions are needed by the object tagging round trip, which
+// tags its test object on upload and again with PUT ?tagging
+var objectTaggingPermissions = []Permission{
+	{Action: "s3:PutObject", Object: true},
+	{Action: "s3:PutObjectTagging", Object: true},
+	{Action: "s3:GetObjectTagging", Object: true},
+	{Action: "s3:GetObject", Object: true},
+	{Action: "s3:DeleteObject", Object: true},
+}
+
+// copyPermissions are needed by the copy check. A copy needs s3:GetObject on
+// the source and s3:PutObject on the target, both under the test prefix; an
+// UploadPartCopy that fails is aborted.
+var copyPermissions = []Permission{
+	{Action: "s3:PutObject", Object: true},
+	{Action: "s3:GetObject", Object: true},
+	{Action: "s3:DeleteObject", Object: true},
+	{Action: "s3:AbortMultipartUpload", Object: true},
+}
+
+// RequiredPolicy returns the least-privilege IAM policy covering exactly the
+// requests the configured checks send. Bucket actions are granted on the
+// bucket, object actions only on keys under the test prefix and actions on
+// the --object-key object only on that key. Checks that are disabled by
+// read-only mode are left out. Account actions are granted on all resources,
+// as IAM does not scope them any further.
+func RequiredPolicy(config output.Config, checkPolicy bool) *output.IAMPolicy {
+	permissions := append([]Permission{}, corePermissions...)
+	if checkPolicy {
+		permissions = append(permissions, policyCheckPermissions...)
+	}
+	for _, reg := range Registry {
+		if !reg.Enabled(config) || (config.ReadOnly && reg.Mutates(config)) {
+			continue
+		}
+		permissions = append(permissions, reg.Permissions(config)...)
+	}
+
+	bucketActions := make(map[string]bool)
+	objectActions := make(map[string]bool)
+	keyActions := make(map[string]map[string]bool)
+	accountActions := make(map[string]bool)
+	for _, p := range permissions {
+		if p.Account {
+			accountActions[p.Action] = true
+		} else if p.Key != "" {
+			if keyActions[p.Key] == nil {
+				keyActions[p.Key] = make(map[string]bool)
+			}
+			keyActions[p.Key][p.Action] = true
+		} else if p.Object {
+			objectActions[p.Action] = true
+		} else {
+			bucketActions[p.Action] = true
+		}
+	}
+
+	bucketARN := "arn:aws:s3:::" + config.Bucket
+	policy := &output.IAMPolicy{Version: output.IAMPolicyVersion}
+	policy.Statement = append(policy.Statement, output.IAMStatement{
+		Sid:      "S3TesterBucket",
+		Effect:   "Allow",
+		Action:   sortedActions(bucketActions),
+		Resource: []string{bucketARN},
+	})
+	if len(objectActions) > 0 {
+		policy.Statement = append(policy.Statement, output.IAMStatement{
+			Sid:      "S3TesterObjects",
+			Effect:   "Allow",
+			Action:   sortedActions(objectActions),
+			Resource: []string{bucketARN + "/" + ArtifactResource(config)},
+		})
+	}
+	for key, actions := range keyActions {
+		policy.Statement = append(policy.Statement, output.IAMStatement{
+			Sid:      "S3TesterObjectKey",
+			Effect:   "Allow",
+			Action:   sortedActions(actions),
+			Resource: []string{bucketARN + "/" + key},
+		})
+	}
+	if len(accountActions) > 0 {
+		// Account-level S3 actions do not support resource-level permissions
+		policy.Statement = append(policy.Statement, output.IAMStatement{
+			Sid:      "S3TesterAccount",
+			Effect:   "Allow",
+			Action:   sortedActions(accountActions),
+			Resource: []string{"*"},
+		})
+	}
+
+	return policy
+}
+
+// sortedActions returns the actions of a set in sorted order
+func sortedActions(set map[string]bool) []string {
+	actions := make([]string, 0, len(set))
+	for action := range set {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
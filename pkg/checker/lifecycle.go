@@ -0,0 +1,242 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// LifecycleChecker reads the bucket's lifecycle configuration, validates its
+// rules and warns when nothing cleans up expired objects or incomplete
+// multipart uploads
+type LifecycleChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewLifecycleChecker creates a new lifecycle checker
+func NewLifecycleChecker(config output.Config) *LifecycleChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &LifecycleChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *LifecycleChecker) Name() string {
+	return "Lifecycle Configuration Check"
+}
+
+// lifecycleConfiguration is the GetBucketLifecycleConfiguration response
+type lifecycleConfiguration struct {
+	Rules []lifecycleRule `xml:"Rule"`
+}
+
+// lifecycleRule is one rule of a lifecycle configuration; Prefix is the
+// rule's prefix in the legacy form without a Filter
+type lifecycleRule struct {
+	ID     string `xml:"ID"`
+	Status string `xml:"Status"`
+	Prefix string `xml:"Prefix"`
+	Filter struct {
+		Prefix string `xml:"Prefix"`
+		And    struct {
+			Prefix string `xml:"Prefix"`
+		} `xml:"And"`
+	} `xml:"Filter"`
+	Expiration *struct {
+		Days                      int    `xml:"Days"`
+		Date                      string `xml:"Date"`
+		ExpiredObjectDeleteMarker bool   `xml:"ExpiredObjectDeleteMarker"`
+	} `xml:"Expiration"`
+	NoncurrentVersionExpiration *struct {
+		NoncurrentDays int `xml:"NoncurrentDays"`
+	} `xml:"NoncurrentVersionExpiration"`
+	AbortIncompleteMultipartUpload *struct {
+		DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+	} `xml:"AbortIncompleteMultipartUpload"`
+	Transitions []struct {
+		Days         int    `xml:"Days"`
+		Date         string `xml:"Date"`
+		StorageClass string `xml:"StorageClass"`
+	} `xml:"Transition"`
+}
+
+// Check sends GET ?lifecycle and fails when a rule is invalid. It warns when
+// no enabled rule expires objects or aborts incomplete multipart uploads,
+// whose parts are billed until they are aborted, and when the bucket has no
+// lifecycle configuration at all. A denied request is a warning, and an
+// endpoint that does not implement lifecycle configuration skips the check.
+func (c *LifecycleChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Lifecycle Configuration Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"lifecycle": {""}}, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketLifecycleConfiguration failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	lifecycle := output.LifecycleResult{StatusCode: resp.StatusCode}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case errorCode(body) == "NoSuchLifecycleConfiguration":
+		result.Status = output.StatusWarn
+		result.Error = "the bucket has no lifecycle configuration: expired objects and incomplete multipart uploads are never cleaned up"
+		result.Details = lifecycle
+		result.Duration = time.Since(startTime)
+		return result
+	case resp.StatusCode == http.StatusForbidden:
+		result.Status = output.StatusWarn
+		result.Error = "GetBucketLifecycleConfiguration denied, the lifecycle rules could not be read: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	case resp.StatusCode == http.StatusNotImplemented || errorCode(body) == "NotImplemented":
+		result.Status = output.StatusSkip
+		result.Error = "the endpoint does not implement lifecycle configuration"
+		result.Duration = time.Since(startTime)
+		return result
+	default:
+		result.Status = output.StatusFail
+		result.Error = "GetBucketLifecycleConfiguration failed: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	var configuration lifecycleConfiguration
+	if err := xml.Unmarshal(body, &configuration); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the lifecycle configuration does not parse: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	lifecycle.Configured = true
+
+	var invalid []string
+	for i, rule := range configuration.Rules {
+		summary := summarizeLifecycleRule(rule)
+		if summary.ID == "" {
+			summary.ID = fmt.Sprintf("rule %d", i+1)
+		}
+		if summary.Problem != "" {
+			invalid = append(invalid, fmt.Sprintf("%s: %s", summary.ID, summary.Problem))
+		} else if summary.Status == "Enabled" {
+			lifecycle.Expires = lifecycle.Expires || rule.Expiration != nil || rule.NoncurrentVersionExpiration != nil
+			lifecycle.AbortsMultipart = lifecycle.AbortsMultipart || rule.AbortIncompleteMultipartUpload != nil
+		}
+		c.verbose.LogMessage("Rule %s (%s, prefix %q): %s %s", summary.ID, summary.Status, summary.Prefix, strings.Join(summary.Actions, ", "), summary.Problem)
+		lifecycle.Rules = append(lifecycle.Rules, summary)
+	}
+
+	var missing []string
+	if !lifecycle.Expires {
+		missing = append(missing, "no enabled rule expires objects or noncurrent versions")
+	}
+	if !lifecycle.AbortsMultipart {
+		missing = append(missing, "no enabled rule aborts incomplete multipart uploads, whose parts are billed until they are aborted")
+	}
+
+	switch {
+	case len(invalid) > 0:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("invalid lifecycle rules: %s", strings.Join(invalid, "; "))
+	case len(missing) > 0:
+		result.Status = output.StatusWarn
+		result.Error = strings.Join(missing, "; ")
+	}
+
+	result.Details = lifecycle
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Lifecycle configuration check completed in %v", result.Duration)
+
+	return result
+}
+
+// summarizeLifecycleRule describes a rule's actions and what makes it
+// invalid, if anything
+func summarizeLifecycleRule(rule lifecycleRule) output.LifecycleRule {
+	summary := output.LifecycleRule{ID: rule.ID, Status: rule.Status, Prefix: rule.Prefix}
+	if rule.Filter.Prefix != "" {
+		summary.Prefix = rule.Filter.Prefix
+	} else if rule.Filter.And.Prefix != "" {
+		summary.Prefix = rule.Filter.And.Prefix
+	}
+
+	var problems []string
+	if rule.Status != "Enabled" && rule.Status != "Disabled" {
+		problems = append(problems, fmt.Sprintf("status %q is neither Enabled nor Disabled", rule.Status))
+	}
+
+	if e := rule.Expiration; e != nil {
+		switch {
+		case e.Days > 0:
+			summary.Actions = append(summary.Actions, fmt.Sprintf("expire after %d days", e.Days))
+		case e.Date != "":
+			summary.Actions = append(summary.Actions, "expire on "+e.Date)
+		case e.ExpiredObjectDeleteMarker:
+			summary.Actions = append(summary.Actions, "remove expired delete markers")
+		default:
+			problems = append(problems, "expiration without days, date or delete marker cleanup")
+		}
+	}
+	if e := rule.NoncurrentVersionExpiration; e != nil {
+		if e.NoncurrentDays > 0 {
+			summary.Actions = append(summary.Actions, fmt.Sprintf("expire noncurrent versions after %d days", e.NoncurrentDays))
+		} else {
+			problems = append(problems, "noncurrent version expiration without days")
+		}
+	}
+	if a := rule.AbortIncompleteMultipartUpload; a != nil {
+		if a.DaysAfterInitiation > 0 {
+			summary.Actions = append(summary.Actions, fmt.Sprintf("abort incomplete multipart uploads after %d days", a.DaysAfterInitiation))
+		} else {
+			problems = append(problems, "multipart upload abort without days")
+		}
+	}
+	for _, t := range rule.Transitions {
+		switch {
+		case t.StorageClass == "":
+			problems = append(problems, "transition without a storage class")
+		case t.Days > 0:
+			summary.Actions = append(summary.Actions, fmt.Sprintf("transition to %s after %d days", t.StorageClass, t.Days))
+		case t.Date != "":
+			summary.Actions = append(summary.Actions, fmt.Sprintf("transition to %s on %s", t.StorageClass, t.Date))
+		default:
+			// Days 0 moves objects as soon as they are written
+			summary.Actions = append(summary.Actions, "transition to "+t.StorageClass+" immediately")
+		}
+	}
+
+	if len(summary.Actions) == 0 && len(problems) == 0 {
+		problems = append(problems, "no action")
+	}
+	summary.Problem = strings.Join(problems, ", ")
+	return summary
+}
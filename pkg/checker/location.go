@@ -0,0 +1,138 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// BucketLocationChecker reads the bucket's region with GetBucketLocation and
+// compares it with --region
+type BucketLocationChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewBucketLocationChecker creates a new bucket location checker
+func NewBucketLocationChecker(config output.Config) *BucketLocationChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &BucketLocationChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *BucketLocationChecker) Name() string {
+	return "Bucket Location Check"
+}
+
+// Check sends GET ?location and fails when the bucket is in another region
+// than --region. A redirect or region error naming the bucket's region
+// counts as its location too. The check warns when the location may not be
+// read and is skipped when the endpoint does not implement it.
+func (c *BucketLocationChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Bucket Location Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	// The auth check may have corrected the region for the run; compare
+	// with the one that was configured
+	configured := c.Config.Region
+	if c.Config.ConfiguredRegion != "" {
+		configured = c.Config.ConfiguredRegion
+	}
+	location := output.BucketLocationResult{ConfiguredRegion: configured}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"location": {""}}, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketLocation failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	location.StatusCode = resp.StatusCode
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		var constraint struct {
+			Value string `xml:",chardata"`
+		}
+		if err := xml.Unmarshal(body, &constraint); err != nil {
+			result.Status = output.StatusFail
+			result.Error = fmt.Sprintf("GetBucketLocation returned an invalid response: %v", err)
+			break
+		}
+		location.LocationConstraint = constraint.Value
+		location.Region = normalizeLocation(constraint.Value, c.Config.Provider)
+	case bucketRegion(resp.StatusCode, resp.Header, body) != "":
+		location.Region = bucketRegion(resp.StatusCode, resp.Header, body)
+		c.verbose.LogMessage("GetBucketLocation was answered with HTTP %d naming region %s", resp.StatusCode, location.Region)
+	case resp.StatusCode == http.StatusForbidden:
+		result.Status = output.StatusWarn
+		result.Error = "GetBucketLocation denied, the region could not be verified: " + parseErrorResponse(resp.StatusCode, body)
+	case resp.StatusCode == http.StatusNotImplemented || errorCode(body) == "NotImplemented":
+		result.Status = output.StatusSkip
+		result.Error = "the endpoint does not implement GetBucketLocation"
+	default:
+		result.Status = output.StatusFail
+		result.Error = "GetBucketLocation failed: " + parseErrorResponse(resp.StatusCode, body)
+	}
+
+	if result.Error == "" {
+		switch {
+		case location.Region == "":
+			c.verbose.LogMessage("The endpoint reports no region for the bucket")
+		case location.Region != configured:
+			result.Status = output.StatusFail
+			result.Error = fmt.Sprintf("the bucket is in region %s, but --region is %s: set --region %s",
+				location.Region, configured, location.Region)
+		default:
+			location.Match = true
+		}
+	}
+	c.verbose.LogMessage("Location constraint %q, region %q, configured region %q", location.LocationConstraint, location.Region, configured)
+
+	result.Details = location
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Bucket location check completed in %v", result.Duration)
+
+	return result
+}
+
+// normalizeLocation returns the region of a location constraint. AWS reports
+// us-east-1 as an empty constraint and eu-west-1 of old buckets as EU; other
+// providers that send no constraint report no region.
+func normalizeLocation(constraint, provider string) string {
+	switch constraint {
+	case "":
+		if provider == "aws" {
+			return "us-east-1"
+		}
+		return ""
+	case "EU":
+		return "eu-west-1"
+	}
+	return constraint
+}
@@ -0,0 +1,123 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// LoggingChecker reads the bucket's server access logging configuration and
+// reports where the access logs are delivered
+type LoggingChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewLoggingChecker creates a new bucket logging checker
+func NewLoggingChecker(config output.Config) *LoggingChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &LoggingChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *LoggingChecker) Name() string {
+	return "Bucket Logging Check"
+}
+
+// Check sends GET ?logging and warns when server access logging is disabled,
+// since requests to the bucket then leave no audit trail, and when the logs
+// are delivered to the bucket itself, where every delivery is logged again. A
+// denied request is a warning too, and an endpoint that does not implement
+// bucket logging skips the check.
+func (c *LoggingChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Bucket Logging Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"logging": {""}}, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketLogging failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusForbidden:
+		result.Status = output.StatusWarn
+		result.Error = "GetBucketLogging denied, whether access logging is enabled could not be read: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	case resp.StatusCode == http.StatusNotImplemented || errorCode(body) == "NotImplemented":
+		result.Status = output.StatusSkip
+		result.Error = "the endpoint does not implement bucket logging"
+		result.Duration = time.Since(startTime)
+		return result
+	default:
+		result.Status = output.StatusFail
+		result.Error = "GetBucketLogging failed: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	var status struct {
+		LoggingEnabled *struct {
+			TargetBucket string `xml:"TargetBucket"`
+			TargetPrefix string `xml:"TargetPrefix"`
+		} `xml:"LoggingEnabled"`
+	}
+	if err := xml.Unmarshal(body, &status); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketLogging returned an invalid response: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	logging := output.LoggingResult{StatusCode: resp.StatusCode}
+	if enabled := status.LoggingEnabled; enabled != nil {
+		logging.Enabled = true
+		logging.TargetBucket = enabled.TargetBucket
+		logging.TargetPrefix = enabled.TargetPrefix
+	}
+	c.verbose.LogMessage("Access logging enabled: %v, target bucket %q, prefix %q", logging.Enabled, logging.TargetBucket, logging.TargetPrefix)
+
+	switch {
+	case !logging.Enabled:
+		result.Status = output.StatusWarn
+		result.Error = "server access logging is disabled: requests to the bucket leave no audit trail"
+	case logging.TargetBucket == c.Config.Bucket:
+		result.Status = output.StatusWarn
+		result.Error = "access logs are delivered to the bucket itself, where each log delivery is logged again; deliver them to a separate bucket"
+	}
+
+	result.Details = logging
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Bucket logging check completed in %v", result.Duration)
+
+	return result
+}
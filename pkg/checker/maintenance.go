@@ -0,0 +1,124 @@
+package checker
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// Error codes reported for responses that are not S3 API responses. They
+// take the place of the S3 error code in result errors.
+const (
+	// MaintenancePageCode marks a maintenance or downtime page
+	MaintenancePageCode = "MaintenancePage"
+
+	// HTMLPageCode marks any other HTML page, typically an error page or
+	// banner of a proxy or load balancer in front of the endpoint
+	HTMLPageCode = "HTMLPage"
+)
+
+// maintenancePhrases are lower-case fragments of maintenance and downtime
+// pages
+var maintenancePhrases = []string{
+	"maintenance",
+	"scheduled downtime",
+	"planned downtime",
+	"temporarily unavailable",
+	"temporarily down",
+	"be back soon",
+	"back shortly",
+	"upgrade in progress",
+	"service window",
+}
+
+var (
+	htmlTitle   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlHeading = regexp.MustCompile(`(?is)<h1[^>]*>(.*?)</h1>`)
+	htmlTag     = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// describeNonS3Response returns an error for a response that is an HTML page
+// rather than an S3 XML response, classified as a maintenance page or as a
+// generic HTML page, or for a response the endpoint throttled, or "" for
+// anything else. A 503 with Retry-After counts as maintenance even without a
+// page, unless rate-limit headers show it was throttled. header may be nil
+// when only the body is known.
+func describeNonS3Response(statusCode int, header http.Header, body []byte) string {
+	text := strings.ToLower(string(body))
+	if info := parseRateLimit(statusCode, header); info != nil && info.Exhausted && !strings.Contains(text, "<error>") {
+		return fmt.Sprintf("%s: HTTP %d, %s", RateLimitedCode, statusCode, info.Summary())
+	}
+
+	isHTML := strings.Contains(strings.ToLower(header.Get("Content-Type")), "text/html") ||
+		strings.Contains(text, "<html") || strings.Contains(text, "<!doctype html")
+	retryAfter := header.Get("Retry-After")
+
+	maintenance := false
+	if isHTML || statusCode == http.StatusServiceUnavailable {
+		for _, phrase := range maintenancePhrases {
+			if strings.Contains(text, phrase) {
+				maintenance = true
+				break
+			}
+		}
+	}
+	if statusCode == http.StatusServiceUnavailable && retryAfter != "" && !strings.Contains(text, "<error>") {
+		maintenance = true
+	}
+	if !maintenance && !isHTML {
+		return ""
+	}
+
+	code := HTMLPageCode
+	if maintenance {
+		code = MaintenancePageCode
+	}
+	desc := fmt.Sprintf("%s: HTTP %d", code, statusCode)
+	if title := pageTitle(body); title != "" {
+		desc += fmt.Sprintf(" %q", title)
+	}
+	if retryAfter != "" {
+		desc += ", Retry-After " + retryAfter
+	}
+	return desc
+}
+
+// pageTitle returns the title of an HTML page, or its first heading
+func pageTitle(body []byte) string {
+	for _, re := range []*regexp.Regexp{htmlTitle, htmlHeading} {
+		if m := re.FindSubmatch(body); m != nil {
+			title := html.UnescapeString(htmlTag.ReplaceAllString(string(m[1]), ""))
+			if title = strings.Join(strings.Fields(title), " "); title != "" {
+				if len(title) > 80 {
+					title = title[:77] + "..."
+				}
+				return title
+			}
+		}
+	}
+	return ""
+}
+
+// ClassifyFailure returns the output failure kind of a result that failed
+// because the endpoint served a maintenance or other HTML page instead of an
+// S3 response, or because it throttled the check's requests, or "" for any
+// other result
+func ClassifyFailure(result output.TestResult) string {
+	if result.Status == output.StatusPass {
+		return ""
+	}
+	switch {
+	case strings.Contains(result.Error, RateLimitedCode+":"),
+		result.RateLimit != nil && result.RateLimit.Exhausted:
+		return output.FailureRateLimited
+	case strings.Contains(result.Error, MaintenancePageCode+":"):
+		return output.FailureMaintenance
+	case strings.Contains(result.Error, HTMLPageCode+":"):
+		return output.FailureNonS3Response
+	}
+	return ""
+}
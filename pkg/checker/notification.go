@@ -0,0 +1,270 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// NotificationWait is how long --verify-notifications waits for the event
+// of the canary object
+const NotificationWait = time.Minute
+
+// NotificationChecker reads the bucket's event notification configuration
+// and, with --verify-notifications, verifies that an upload is delivered to
+// a webhook the tool listens on
+type NotificationChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewNotificationChecker creates a new event notification checker
+func NewNotificationChecker(config output.Config) *NotificationChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &NotificationChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *NotificationChecker) Name() string {
+	return "Event Notification Check"
+}
+
+// notificationTarget is one target of a notification configuration; the
+// element it came from names its type
+type notificationTarget struct {
+	ID            string   `xml:"Id"`
+	Topic         string   `xml:"Topic"`
+	Queue         string   `xml:"Queue"`
+	CloudFunction string   `xml:"CloudFunction"`
+	Events        []string `xml:"Event"`
+}
+
+// arn returns the ARN of the target, whatever its type
+func (t notificationTarget) arn() string {
+	return strings.TrimSpace(t.Topic + t.Queue + t.CloudFunction)
+}
+
+// notificationConfiguration is the GetBucketNotificationConfiguration
+// response. AWS writes Lambda targets as CloudFunctionConfiguration.
+type notificationConfiguration struct {
+	Topics      []notificationTarget `xml:"TopicConfiguration"`
+	Queues      []notificationTarget `xml:"QueueConfiguration"`
+	Functions   []notificationTarget `xml:"CloudFunctionConfiguration"`
+	Lambdas     []notificationTarget `xml:"LambdaFunctionConfiguration"`
+	EventBridge *struct{}            `xml:"EventBridgeConfiguration"`
+}
+
+// Check sends GET ?notification and lists the configured targets by type:
+// SNS, SQS, Lambda, EventBridge, or a webhook on providers such as MinIO
+// that name webhooks in the queue ARN. It warns when no target is
+// configured. With --verify-notifications it listens on the given URL,
+// uploads a canary object and fails when no event naming the canary
+// arrives within NotificationWait.
+func (c *NotificationChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Event Notification Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"notification": {""}}, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketNotificationConfiguration failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusForbidden:
+		result.Status = output.StatusWarn
+		result.Error = "GetBucketNotificationConfiguration denied, the notification targets could not be read: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	case resp.StatusCode == http.StatusNotImplemented || errorCode(body) == "NotImplemented":
+		result.Status = output.StatusSkip
+		result.Error = "the endpoint does not implement event notifications"
+		result.Duration = time.Since(startTime)
+		return result
+	default:
+		result.Status = output.StatusFail
+		result.Error = "GetBucketNotificationConfiguration failed: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	var configuration notificationConfiguration
+	if err := xml.Unmarshal(body, &configuration); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketNotificationConfiguration returned an invalid response: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	notifications := output.NotificationResult{StatusCode: resp.StatusCode}
+	for _, group := range []struct {
+		kind    string
+		targets []notificationTarget
+	}{
+		{"sns", configuration.Topics},
+		{"sqs", configuration.Queues},
+		{"lambda", configuration.Functions},
+		{"lambda", configuration.Lambdas},
+	} {
+		for _, t := range group.targets {
+			target := output.NotificationTarget{ID: t.ID, Type: group.kind, ARN: t.arn(), Events: t.Events}
+			if strings.Contains(target.ARN, ":webhook") {
+				target.Type = "webhook"
+			}
+			c.verbose.LogMessage("%s target %s: %s for %s", target.Type, target.ID, target.ARN, strings.Join(target.Events, ", "))
+			notifications.Targets = append(notifications.Targets, target)
+		}
+	}
+	if configuration.EventBridge != nil {
+		notifications.Targets = append(notifications.Targets, output.NotificationTarget{Type: "eventbridge", Events: []string{"all"}})
+	}
+
+	switch {
+	case len(notifications.Targets) == 0:
+		notifications.Verification = "not run: no notification target is configured"
+		result.Status = output.StatusWarn
+		result.Error = "no event notifications are configured: uploads to the bucket trigger nothing"
+	case c.Config.VerifyNotifications == "":
+		notifications.Verification = "not run without --verify-notifications"
+	case c.Config.ReadOnly:
+		notifications.Verification = "not run with --read-only"
+	}
+	if notifications.Verification != "" {
+		result.Details = notifications
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	return c.verify(ctx, result, notifications, startTime)
+}
+
+// verify listens on the --verify-notifications URL, uploads a canary object
+// and waits for an event that names it
+func (c *NotificationChecker) verify(ctx context.Context, result output.TestResult, notifications output.NotificationResult, startTime time.Time) output.TestResult {
+	webhook, err := url.Parse(c.Config.VerifyNotifications)
+	if err != nil {
+		// Validated when the configuration was loaded
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("invalid --verify-notifications URL: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	notifications.WebhookURL = webhook.String()
+
+	key := c.client.testObjectKey("notification")
+	notifications.Key = key
+	// Providers URL-encode the key in events; its last segment needs no
+	// encoding and is unique to this run
+	marker := path.Base(key)
+
+	listener, err := net.Listen("tcp", ":"+webhook.Port())
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("cannot listen for notifications on port %s: %v", webhook.Port(), err)
+		result.Details = notifications
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	delivered := make(chan string, 1)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+			c.verbose.LogMessage("Webhook received %s %s from %s, %d bytes", r.Method, r.URL.Path, r.RemoteAddr, len(body))
+			if r.URL.Path == webhook.Path || webhook.Path == "" {
+				if strings.Contains(string(body), marker) {
+					select {
+					case delivered <- r.RemoteAddr:
+					default:
+					}
+				}
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	body := []byte("s3tester notification canary\n")
+	req, err := c.client.newRequest("PUT", key, nil, body)
+	if err == nil {
+		req.Header.Set("Content-Type", "text/plain")
+		var resp *http.Response
+		var respBody []byte
+		resp, respBody, err = c.client.do(req, body)
+		if err == nil && resp.StatusCode >= 300 {
+			err = fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, respBody))
+		}
+	}
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to write the notification canary: %v", err)
+		result.Details = notifications
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	written := time.Now()
+
+	defer c.client.cleanup(func() {
+		if err := c.client.deleteObject(key); err != nil {
+			c.verbose.LogMessage("Failed to delete %s: %v", key, err)
+		}
+	})
+
+	wait := NotificationWait
+	if d, ok := ctx.Deadline(); ok && time.Until(d) < wait {
+		// Leave time to report the outcome within --budget
+		wait = time.Until(d) / 2
+	}
+	select {
+	case from := <-delivered:
+		notifications.Verification = "delivered"
+		notifications.DeliveryMs = time.Since(written).Milliseconds()
+		notifications.DeliveredFrom = from
+	case <-time.After(wait):
+		notifications.Verification = "not delivered"
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("no event for the canary object reached %s within %v: check the webhook target of the bucket and that the provider can reach this host",
+			notifications.WebhookURL, wait.Round(time.Second))
+	case <-ctx.Done():
+		notifications.Verification = "interrupted"
+	}
+
+	result.Details = notifications
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Event notification check completed in %v", result.Duration)
+
+	return result
+}
@@ -0,0 +1,154 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// objectTestSize is the size of the round-trip test object
+const objectTestSize = 1024
+
+// ObjectChecker performs an object write/read/delete round trip
+type ObjectChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewObjectChecker creates a new object round-trip checker
+func NewObjectChecker(config output.Config) *ObjectChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &ObjectChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *ObjectChecker) Name() string {
+	return "Object Read/Write Check"
+}
+
+// Check performs the PUT, GET, compare and DELETE round trip
+func (c *ObjectChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Object Read/Write Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+		Duration: time.Since(startTime),
+	}
+
+	key := c.client.testObjectKey("roundtrip")
+	content := make([]byte, objectTestSize)
+	if _, err := rand.Read(content); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to generate test content: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	objectResult := output.ObjectResult{
+		Key:  key,
+		Size: len(content),
+	}
+
+	c.verbose.LogMessage("Test object key: %s (%d bytes)", key, len(content))
+
+	// Phase 1: PUT
+	put := c.runPhase("PUT", key, content)
+	objectResult.Phases = append(objectResult.Phases, put.phase)
+	objectResult.WriteAccess = put.phase.Success
+	if !put.phase.Success {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("PUT failed: %s", put.phase.Error)
+		result.Details = objectResult
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	// Phase 2: GET and compare
+	get := c.runPhase("GET", key, nil)
+	objectResult.Phases = append(objectResult.Phases, get.phase)
+	objectResult.ReadAccess = get.phase.Success
+	if get.phase.Success {
+		objectResult.ContentMatch = bytes.Equal(get.body, content)
+		c.verbose.LogMessage("Content matches: %v", objectResult.ContentMatch)
+	}
+
+	// Phase 3: DELETE (always attempted so no test object is left behind)
+	var del phaseOutcome
+	c.client.cleanup(func() {
+		del = c.runPhase("DELETE", key, nil)
+	})
+	objectResult.Phases = append(objectResult.Phases, del.phase)
+	objectResult.DeleteAccess = del.phase.Success
+
+	switch {
+	case !get.phase.Success:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GET failed: %s", get.phase.Error)
+	case !objectResult.ContentMatch:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("content mismatch: uploaded %d bytes, downloaded %d bytes with different content", len(content), len(get.body))
+	case !del.phase.Success:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("DELETE failed: %s", del.phase.Error)
+	}
+
+	result.Details = objectResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Object round-trip check completed in %v", result.Duration)
+
+	return result
+}
+
+// phaseOutcome holds a phase result together with the response body
+type phaseOutcome struct {
+	phase output.ObjectPhaseResult
+	body  []byte
+}
+
+// runPhase performs one operation of the round trip and measures its latency
+func (c *ObjectChecker) runPhase(method, key string, body []byte) phaseOutcome {
+	outcome := phaseOutcome{phase: output.ObjectPhaseResult{Operation: method}}
+
+	req, err := c.client.newRequest(method, key, nil, body)
+	if err != nil {
+		outcome.phase.Error = err.Error()
+		return outcome
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	phaseStart := time.Now()
+	resp, respBody, err := c.client.do(req, body)
+	outcome.phase.LatencyMs = time.Since(phaseStart).Milliseconds()
+	if err != nil {
+		c.verbose.LogMessage("%s failed: %v", method, err)
+		outcome.phase.Error = err.Error()
+		return outcome
+	}
+
+	outcome.phase.StatusCode = resp.StatusCode
+	outcome.phase.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !outcome.phase.Success {
+		outcome.phase.Error = parseErrorResponse(resp.StatusCode, respBody)
+	}
+	outcome.body = respBody
+
+	c.verbose.LogMessage("%s %s: HTTP %d in %dms", method, key, resp.StatusCode, outcome.phase.LatencyMs)
+
+	return outcome
+}
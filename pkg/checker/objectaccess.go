@@ -0,0 +1,221 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// presignExpiry is the lifetime of the presigned URL probed by the object
+// access check
+const presignExpiry = 5 * time.Minute
+
+// ObjectAccessOperations lists the read operations attempted on the
+// --object-key object, in the order they are attempted
+var ObjectAccessOperations = []PermissionOperation{
+	{Operation: "HeadObject", Action: "s3:GetObject", Object: true},
+	{Operation: "GetObject", Action: "s3:GetObject", Object: true},
+	{Operation: "GetObjectAcl", Action: "s3:GetObjectAcl", Object: true},
+	{Operation: "GetObjectTagging", Action: "s3:GetObjectTagging", Object: true},
+	{Operation: "PresignedGetObject", Action: "s3:GetObject", Object: true},
+}
+
+// ObjectAccessChecker verifies read access to an existing object without
+// writing anything to the bucket
+type ObjectAccessChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewObjectAccessChecker creates a new object access checker
+func NewObjectAccessChecker(config output.Config) *ObjectAccessChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &ObjectAccessChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *ObjectAccessChecker) Name() string {
+	return "Object Access Check"
+}
+
+// Check attempts each read operation on the object. The GETs request only the
+// first byte, so large production objects are not downloaded. The check fails
+// when the object cannot be read and warns when the ACL, tagging or a
+// presigned URL is denied.
+func (c *ObjectAccessChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Object Access Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	key := c.Config.ObjectKey
+	accessResult := output.ObjectAccessResult{Key: key}
+	firstByte := http.Header{"Range": {"bytes=0-0"}}
+
+	for _, op := range ObjectAccessOperations {
+		var probe output.PermissionProbe
+		switch op.Operation {
+		case "HeadObject":
+			var resp *http.Response
+			probe, resp = c.probe(op, "HEAD", key, nil, nil)
+			if resp != nil && probe.Outcome == PermissionAllowed {
+				accessResult.Size = resp.ContentLength
+				accessResult.ContentType = resp.Header.Get("Content-Type")
+				accessResult.ETag = resp.Header.Get("ETag")
+				accessResult.LastModified = resp.Header.Get("Last-Modified")
+				accessResult.StorageClass = resp.Header.Get("x-amz-storage-class")
+				accessResult.VersionID = resp.Header.Get("x-amz-version-id")
+			}
+		case "GetObject":
+			probe, _ = c.probe(op, "GET", key, nil, firstByte)
+		case "GetObjectAcl":
+			probe, _ = c.probe(op, "GET", key, url.Values{"acl": {""}}, nil)
+		case "GetObjectTagging":
+			probe, _ = c.probe(op, "GET", key, url.Values{"tagging": {""}}, nil)
+		case "PresignedGetObject":
+			probe = c.probePresigned(op, key, firstByte)
+		}
+
+		c.verbose.LogMessage("%s (%s): %s %s", op.Operation, op.Action, probe.Outcome, probe.Detail)
+		accessResult.Operations = append(accessResult.Operations, probe)
+	}
+
+	var unreadable, denied []string
+	for _, probe := range accessResult.Operations {
+		switch probe.Outcome {
+		case PermissionAllowed:
+			accessResult.Allowed++
+			continue
+		case PermissionDenied:
+			accessResult.Denied++
+		case PermissionUnsupported:
+			continue
+		}
+		if probe.Operation == "HeadObject" || probe.Operation == "GetObject" {
+			unreadable = append(unreadable, fmt.Sprintf("%s %s", probe.Operation, probe.Detail))
+		} else {
+			denied = append(denied, probe.Operation)
+		}
+	}
+
+	switch {
+	case len(unreadable) > 0:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("object %s cannot be read: %s", key, strings.Join(unreadable, "; "))
+	case len(denied) > 0:
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("%d of %d operations not permitted: %s", len(denied), len(accessResult.Operations), strings.Join(denied, ", "))
+	}
+
+	result.Details = accessResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Object access check completed in %v", result.Duration)
+
+	return result
+}
+
+// probe sends one signed request for the object and classifies the response.
+// A missing object is reported as an error rather than allowed.
+func (c *ObjectAccessChecker) probe(op PermissionOperation, method, key string, query url.Values, header http.Header) (output.PermissionProbe, *http.Response) {
+	probe := output.PermissionProbe{
+		Operation: op.Operation,
+		Action:    op.Action,
+	}
+
+	req, err := c.client.newRequest(method, key, query, nil)
+	if err != nil {
+		probe.Outcome = PermissionError
+		probe.Detail = err.Error()
+		return probe, nil
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		probe.Outcome = PermissionError
+		probe.Detail = err.Error()
+		return probe, nil
+	}
+
+	probe.StatusCode = resp.StatusCode
+	probe.Outcome, probe.Detail = classifyObjectAccess(resp.StatusCode, body)
+
+	return probe, resp
+}
+
+// probePresigned fetches the object through a presigned URL without any
+// credentials on the request itself
+func (c *ObjectAccessChecker) probePresigned(op PermissionOperation, key string, header http.Header) output.PermissionProbe {
+	probe := output.PermissionProbe{
+		Operation: op.Operation,
+		Action:    op.Action,
+	}
+
+	presigned, err := c.client.presign("GET", key, presignExpiry)
+	if err != nil {
+		probe.Outcome = PermissionError
+		probe.Detail = err.Error()
+		return probe
+	}
+
+	req, err := http.NewRequestWithContext(c.client.ctx, "GET", presigned, nil)
+	if err != nil {
+		probe.Outcome = PermissionError
+		probe.Detail = err.Error()
+		return probe
+	}
+	req.Header = header.Clone()
+	req.Header.Set("User-Agent", "s3-bucket-tester/1.0")
+	c.verbose.LogRequest(req)
+
+	resp, err := c.client.httpClient.Do(req)
+	if err != nil {
+		probe.Outcome = PermissionError
+		probe.Detail = err.Error()
+		return probe
+	}
+	defer resp.Body.Close()
+	c.verbose.LogResponse(resp)
+
+	body, _ := io.ReadAll(resp.Body)
+	probe.StatusCode = resp.StatusCode
+	probe.Outcome, probe.Detail = classifyObjectAccess(resp.StatusCode, body)
+	if probe.Outcome == PermissionAllowed {
+		probe.Detail += fmt.Sprintf(", URL valid for %v", presignExpiry)
+	}
+
+	return probe
+}
+
+// classifyObjectAccess maps a response for the object to a permission
+// outcome. Unlike the permission matrix, NoSuchKey means the object is
+// missing, which is an error rather than proof of access.
+func classifyObjectAccess(statusCode int, body []byte) (string, string) {
+	if statusCode == http.StatusNotFound {
+		// Some providers answer GetObjectTagging with 404 when there are no tags
+		if code := errorCode(body); code == "NoSuchTagSet" {
+			return PermissionAllowed, code
+		}
+		return PermissionError, parseErrorResponse(statusCode, body)
+	}
+	return classifyPermission(statusCode, body)
+}
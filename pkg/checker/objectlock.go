@@ -0,0 +1,277 @@
+package checker
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// RetentionPeriod is how long the retention test locks its test object. It
+// uses governance mode, so the object can be removed early by bypassing the
+// retention; without that permission it stays until the period ends.
+const RetentionPeriod = time.Minute
+
+// ObjectLockChecker reads the bucket's Object Lock configuration and, with
+// --test-retention, verifies that a retained object cannot be deleted
+type ObjectLockChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewObjectLockChecker creates a new Object Lock checker
+func NewObjectLockChecker(config output.Config) *ObjectLockChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &ObjectLockChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *ObjectLockChecker) Name() string {
+	return "Object Lock Check"
+}
+
+// objectLockConfiguration is the GetObjectLockConfiguration response
+type objectLockConfiguration struct {
+	ObjectLockEnabled string `xml:"ObjectLockEnabled"`
+	Rule              struct {
+		DefaultRetention struct {
+			Mode  string `xml:"Mode"`
+			Days  int    `xml:"Days"`
+			Years int    `xml:"Years"`
+		} `xml:"DefaultRetention"`
+	} `xml:"Rule"`
+}
+
+// lockResponse is the part of a response the retention test compares
+type lockResponse struct {
+	status    int
+	versionID string
+	code      string
+	body      []byte
+}
+
+// Check sends GET ?object-lock and reports whether Object Lock is enabled and
+// the default retention. It warns when Object Lock is not enabled, since the
+// bucket's objects can then be deleted or overwritten at any time. With
+// --test-retention, on a bucket with Object Lock enabled, it writes a test
+// object under a short governance retention and fails when the locked
+// version can be deleted.
+func (c *ObjectLockChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Object Lock Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"object-lock": {""}}, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetObjectLockConfiguration failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	lock := output.ObjectLockResult{StatusCode: resp.StatusCode}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		var configuration objectLockConfiguration
+		if err := xml.Unmarshal(body, &configuration); err != nil {
+			result.Status = output.StatusFail
+			result.Error = fmt.Sprintf("GetObjectLockConfiguration returned an invalid response: %v", err)
+			result.Duration = time.Since(startTime)
+			return result
+		}
+		lock.Enabled = configuration.ObjectLockEnabled == "Enabled"
+		lock.DefaultMode = configuration.Rule.DefaultRetention.Mode
+		lock.DefaultDays = configuration.Rule.DefaultRetention.Days
+		lock.DefaultYears = configuration.Rule.DefaultRetention.Years
+	case errorCode(body) == "ObjectLockConfigurationNotFoundError":
+	case resp.StatusCode == http.StatusForbidden:
+		result.Status = output.StatusWarn
+		result.Error = "GetObjectLockConfiguration denied, whether Object Lock is enabled could not be read: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	case resp.StatusCode == http.StatusNotImplemented || errorCode(body) == "NotImplemented":
+		result.Status = output.StatusSkip
+		result.Error = "the endpoint does not implement Object Lock"
+		result.Duration = time.Since(startTime)
+		return result
+	default:
+		result.Status = output.StatusFail
+		result.Error = "GetObjectLockConfiguration failed: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	c.verbose.LogMessage("Object Lock enabled: %v, default retention %q %d days %d years", lock.Enabled, lock.DefaultMode, lock.DefaultDays, lock.DefaultYears)
+
+	switch {
+	case !lock.Enabled:
+		lock.RetentionTest = "not run: Object Lock is not enabled"
+		result.Status = output.StatusWarn
+		result.Error = "Object Lock is not enabled on the bucket: its objects can be deleted or overwritten at any time"
+	case !c.Config.TestRetention:
+		lock.RetentionTest = "not run without --test-retention"
+	case c.Config.ReadOnly:
+		lock.RetentionTest = "not run with --read-only"
+	}
+	if lock.RetentionTest != "" {
+		result.Details = lock
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	return c.retentionTest(result, lock, startTime)
+}
+
+// retentionTest writes a test object under a governance retention, reads the
+// retention back, tries to delete the locked version and finally removes it
+// by bypassing the governance retention
+func (c *ObjectLockChecker) retentionTest(result output.TestResult, lock output.ObjectLockResult, startTime time.Time) output.TestResult {
+	key := c.client.testObjectKey("object-lock")
+	retainUntil := time.Now().UTC().Add(RetentionPeriod).Truncate(time.Second)
+	lock.Key = key
+	lock.RetainUntil = retainUntil.Format(time.RFC3339)
+
+	body := []byte("s3tester object lock test\n")
+	sum := md5.Sum(body)
+	put, err := c.send("PUT", key, nil, body, http.Header{
+		"Content-Md5":                         {base64.StdEncoding.EncodeToString(sum[:])},
+		"X-Amz-Object-Lock-Mode":              {"GOVERNANCE"},
+		"X-Amz-Object-Lock-Retain-Until-Date": {lock.RetainUntil},
+	})
+	lock.VersionID = put.versionID
+	putStep := newStep(c.verbose, "PUT with retention", "2xx with x-amz-version-id", put.status, put.code, err, put.status < 300 && put.versionID != "", versionDetail(put.versionID))
+	lock.Steps = append(lock.Steps, putStep)
+	if err != nil || put.status >= 300 {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the provider rejected an upload with a retention: %s", putStep.Observed)
+		result.Details = lock
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	if put.versionID == "" {
+		// Without a version ID the locked version cannot be addressed; a
+		// DELETE by key would only add a delete marker
+		return c.finish(result, lock, startTime)
+	}
+	version := url.Values{"versionId": {put.versionID}}
+
+	get, err := c.send("GET", key, url.Values{"retention": {""}, "versionId": {put.versionID}}, nil, nil)
+	var retention struct {
+		Mode            string `xml:"Mode"`
+		RetainUntilDate string `xml:"RetainUntilDate"`
+	}
+	if err == nil && get.status == http.StatusOK {
+		xml.Unmarshal(get.body, &retention)
+	}
+	until, _ := time.Parse(time.RFC3339, retention.RetainUntilDate)
+	getStep := newStep(c.verbose, "GET retention", "200 with GOVERNANCE until "+lock.RetainUntil, get.status, get.code, err,
+		get.status == http.StatusOK && retention.Mode == "GOVERNANCE" && until.Equal(retainUntil), versionDetail(get.versionID))
+	if retention.Mode != "" {
+		getStep.Observed += ", " + retention.Mode + " until " + retention.RetainUntilDate
+	}
+	lock.Steps = append(lock.Steps, getStep)
+
+	locked, err := c.send("DELETE", key, version, nil, nil)
+	lock.Steps = append(lock.Steps, newStep(c.verbose, "DELETE locked version", "403, the version is retained", locked.status, locked.code, err,
+		locked.status == http.StatusForbidden, versionDetail(locked.versionID)))
+	if err == nil && locked.status < 300 {
+		// The version is gone already; there is nothing left to remove
+		return c.finish(result, lock, startTime)
+	}
+
+	var bypass lockResponse
+	c.client.cleanup(func() {
+		bypass, err = c.send("DELETE", key, version, nil, http.Header{"X-Amz-Bypass-Governance-Retention": {"true"}})
+	})
+	lock.Removed = err == nil && bypass.status < 300
+	if !lock.Removed {
+		c.verbose.LogMessage("Failed to delete version %s of %s bypassing governance retention: %v %d %s", put.versionID, key, err, bypass.status, bypass.code)
+	}
+
+	return c.finish(result, lock, startTime)
+}
+
+// finish sets the status from the compared steps and from whether the
+// locked test version was removed
+func (c *ObjectLockChecker) finish(result output.TestResult, lock output.ObjectLockResult, startTime time.Time) output.TestResult {
+	var mismatched []string
+	for _, step := range lock.Steps {
+		if !step.Match {
+			mismatched = append(mismatched, step.Step)
+		}
+	}
+	lock.Works = len(mismatched) == 0
+	lock.RetentionTest = "passed"
+
+	switch {
+	case !lock.Works:
+		lock.RetentionTest = "failed"
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("Object Lock does not protect objects as S3 does: %s", strings.Join(mismatched, ", "))
+	case !lock.Removed:
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("the locked test version of %s could not be removed by bypassing its governance retention (needs s3:BypassGovernanceRetention); it can be deleted after %s",
+			lock.Key, lock.RetainUntil)
+	}
+
+	result.Details = lock
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Object Lock check completed in %v", result.Duration)
+
+	return result
+}
+
+// send sends a request for the test object with the given extra headers
+func (c *ObjectLockChecker) send(method, key string, query url.Values, body []byte, header http.Header) (lockResponse, error) {
+	req, err := c.client.newRequest(method, key, query, body)
+	if err != nil {
+		return lockResponse{}, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, respBody, err := c.client.do(req, body)
+	if err != nil {
+		return lockResponse{}, err
+	}
+
+	response := lockResponse{
+		status:    resp.StatusCode,
+		versionID: resp.Header.Get("x-amz-version-id"),
+		code:      errorCode(respBody),
+	}
+	if resp.StatusCode < 300 {
+		response.body = respBody
+	}
+	return response, nil
+}
@@ -0,0 +1,164 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+	"golang.org/x/crypto/ocsp"
+)
+
+// maxOCSPResponse bounds the size of an OCSP response read from a responder
+const maxOCSPResponse = 1 << 20
+
+// revocationReasons names the CRL reason codes of RFC 5280
+var revocationReasons = map[int]string{
+	ocsp.Unspecified:          "unspecified",
+	ocsp.KeyCompromise:        "key compromise",
+	ocsp.CACompromise:         "CA compromise",
+	ocsp.AffiliationChanged:   "affiliation changed",
+	ocsp.Superseded:           "superseded",
+	ocsp.CessationOfOperation: "cessation of operation",
+	ocsp.CertificateHold:      "certificate hold",
+	ocsp.RemoveFromCRL:        "remove from CRL",
+	ocsp.PrivilegeWithdrawn:   "privilege withdrawn",
+	ocsp.AACompromise:         "AA compromise",
+}
+
+// checkRevocation determines the OCSP status of the leaf certificate of a
+// handshake: from the response the server stapled, if it sent a valid one,
+// and otherwise from the OCSP responder named in the certificate
+func checkRevocation(ctx context.Context, config output.Config, state tls.ConnectionState) *output.RevocationStatus {
+	leaf := state.PeerCertificates[0]
+	issuer := issuerCertificate(state)
+
+	if len(state.OCSPResponse) > 0 && issuer != nil {
+		resp, err := ocsp.ParseResponseForCert(state.OCSPResponse, leaf, issuer)
+		if err == nil {
+			return revocationStatus(resp, "stapled", "")
+		}
+		if len(leaf.OCSPServer) == 0 {
+			return &output.RevocationStatus{
+				Status: output.RevocationUnavailable,
+				Source: "stapled",
+				Error:  fmt.Sprintf("invalid stapled OCSP response: %v", err),
+			}
+		}
+	}
+
+	switch {
+	case len(leaf.OCSPServer) == 0:
+		return &output.RevocationStatus{Status: output.RevocationNotChecked, Error: "the certificate names no OCSP responder"}
+	case config.SkipOCSP:
+		return &output.RevocationStatus{Status: output.RevocationNotChecked, Error: "disabled by --skip-ocsp"}
+	case issuer == nil:
+		return &output.RevocationStatus{
+			Status:    output.RevocationUnavailable,
+			Responder: leaf.OCSPServer[0],
+			Error:     "the server did not send the issuer certificate needed for the OCSP request",
+		}
+	}
+
+	responder := leaf.OCSPServer[0]
+	resp, err := queryOCSP(ctx, config, responder, leaf, issuer)
+	if err != nil {
+		return &output.RevocationStatus{
+			Status:    output.RevocationUnavailable,
+			Source:    "responder",
+			Responder: responder,
+			Error:     err.Error(),
+		}
+	}
+	return revocationStatus(resp, "responder", responder)
+}
+
+// issuerCertificate returns the certificate that issued the leaf, from the
+// verified chain or else from the certificates the server sent, or nil
+func issuerCertificate(state tls.ConnectionState) *x509.Certificate {
+	if len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 1 {
+		return state.VerifiedChains[0][1]
+	}
+	leaf := state.PeerCertificates[0]
+	for _, cert := range state.PeerCertificates[1:] {
+		if bytes.Equal(cert.RawSubject, leaf.RawIssuer) && leaf.CheckSignatureFrom(cert) == nil {
+			return cert
+		}
+	}
+	return nil
+}
+
+// queryOCSP sends an OCSP request for the leaf to the responder and returns
+// the response once its signature has been verified against the issuer
+func queryOCSP(ctx context.Context, config output.Config, responder string, leaf, issuer *x509.Certificate) (*ocsp.Response, error) {
+	body, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCSP request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responder, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCSP responder URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+	req.Header.Set("Accept", "application/ocsp-response")
+
+	resp, err := newPlainHTTPClient(config).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OCSP responder unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCSP responder returned HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxOCSPResponse))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OCSP response: %w", err)
+	}
+
+	parsed, err := ocsp.ParseResponseForCert(data, leaf, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCSP response: %w", err)
+	}
+	return parsed, nil
+}
+
+// revocationStatus converts a verified OCSP response. A response past its
+// next update time cannot vouch for the certificate any more and is reported
+// as unavailable.
+func revocationStatus(resp *ocsp.Response, source, responder string) *output.RevocationStatus {
+	status := &output.RevocationStatus{
+		Source:     source,
+		Responder:  responder,
+		ProducedAt: &resp.ProducedAt,
+	}
+	if !resp.NextUpdate.IsZero() {
+		status.NextUpdate = &resp.NextUpdate
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		status.Status = output.RevocationGood
+	case ocsp.Revoked:
+		status.Status = output.RevocationRevoked
+		status.RevokedAt = &resp.RevokedAt
+		status.Reason = revocationReasons[resp.RevocationReason]
+		return status
+	default:
+		status.Status = output.RevocationUnknown
+		status.Error = "the OCSP responder does not know the certificate"
+		return status
+	}
+
+	if !resp.NextUpdate.IsZero() && time.Now().After(resp.NextUpdate) {
+		status.Status = output.RevocationUnavailable
+		status.Error = fmt.Sprintf("the %s OCSP response is stale (next update was due %s)", source, resp.NextUpdate.UTC().Format(time.RFC3339))
+	}
+	return status
+}
@@ -0,0 +1,131 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// Object Ownership settings of a bucket
+const (
+	OwnershipBucketOwnerEnforced  = "BucketOwnerEnforced"
+	OwnershipBucketOwnerPreferred = "BucketOwnerPreferred"
+	OwnershipObjectWriter         = "ObjectWriter"
+)
+
+// ACLsDisabledReason explains why ACL checks are skipped on a bucket with
+// ACLs disabled
+const ACLsDisabledReason = "ACLs are disabled (Object Ownership: BucketOwnerEnforced): they neither grant nor deny access"
+
+// OwnershipChecker reads the bucket's Object Ownership setting, which decides
+// whether ACLs take part in access decisions at all
+type OwnershipChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewOwnershipChecker creates a new Object Ownership checker
+func NewOwnershipChecker(config output.Config) *OwnershipChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &OwnershipChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *OwnershipChecker) Name() string {
+	return "Object Ownership Check"
+}
+
+// Check sends GET ?ownershipControls and reports whether ACLs are disabled.
+// A bucket without ownership controls has the ObjectWriter default, with ACLs
+// enabled. Every setting passes; the check warns when the setting may not be
+// read and is skipped when the endpoint does not implement it.
+func (c *OwnershipChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Object Ownership Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"ownershipControls": {""}}, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketOwnershipControls failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	ownership := output.OwnershipResult{StatusCode: resp.StatusCode}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		var controls struct {
+			Rules []struct {
+				ObjectOwnership string `xml:"ObjectOwnership"`
+			} `xml:"Rule"`
+		}
+		if err := xml.Unmarshal(body, &controls); err != nil || len(controls.Rules) == 0 {
+			result.Status = output.StatusFail
+			result.Error = fmt.Sprintf("GetBucketOwnershipControls returned an invalid response: %s", invalidOwnership(err))
+			result.Duration = time.Since(startTime)
+			return result
+		}
+		ownership.Configured = true
+		ownership.ObjectOwnership = controls.Rules[0].ObjectOwnership
+	case errorCode(body) == "OwnershipControlsNotFoundError":
+		ownership.ObjectOwnership = OwnershipObjectWriter
+	case resp.StatusCode == http.StatusForbidden:
+		result.Status = output.StatusWarn
+		result.Error = "GetBucketOwnershipControls denied, whether ACLs are enabled could not be read: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	case resp.StatusCode == http.StatusNotImplemented || errorCode(body) == "NotImplemented":
+		result.Status = output.StatusSkip
+		result.Error = "the endpoint does not implement Object Ownership"
+		result.Duration = time.Since(startTime)
+		return result
+	default:
+		result.Status = output.StatusFail
+		result.Error = "GetBucketOwnershipControls failed: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	ownership.ACLsDisabled = ownership.ObjectOwnership == OwnershipBucketOwnerEnforced
+	c.verbose.LogMessage("Object Ownership %s (configured %v), ACLs disabled: %v", ownership.ObjectOwnership, ownership.Configured, ownership.ACLsDisabled)
+
+	result.Details = ownership
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Object Ownership check completed in %v", result.Duration)
+
+	return result
+}
+
+// invalidOwnership describes an ownership controls response without a rule
+func invalidOwnership(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "no ownership rule"
+}
@@ -0,0 +1,240 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// Permission outcomes
+const (
+	PermissionAllowed     = "allowed"
+	PermissionDenied      = "denied"
+	PermissionUnsupported = "unsupported"
+	PermissionNotTested   = "not tested"
+	PermissionError       = "error"
+)
+
+// PermissionOperation is one S3 operation of the permission matrix
+type PermissionOperation struct {
+	// Operation is the S3 API name
+	Operation string
+
+	// Action is the IAM action that authorizes the operation
+	Action string
+
+	// Object is set for operations on the test object rather than the bucket
+	Object bool
+
+	// Writes is set for operations that modify the bucket or an object
+	Writes bool
+}
+
+// PermissionOperations lists the operations probed by the permission matrix,
+// in the order they are attempted
+var PermissionOperations = []PermissionOperation{
+	{Operation: "ListObjectsV2", Action: "s3:ListBucket"},
+	{Operation: "GetBucketLocation", Action: "s3:GetBucketLocation"},
+	{Operation: "PutObject", Action: "s3:PutObject", Object: true, Writes: true},
+	{Operation: "GetObject", Action: "s3:GetObject", Object: true},
+	{Operation: "GetObjectAcl", Action: "s3:GetObjectAcl", Object: true},
+	{Operation: "DeleteObject", Action: "s3:DeleteObject", Object: true, Writes: true},
+	{Operation: "ListMultipartUploads", Action: "s3:ListBucketMultipartUploads"},
+	{Operation: "GetBucketVersioning", Action: "s3:GetBucketVersioning"},
+	{Operation: "GetBucketEncryption", Action: "s3:GetEncryptionConfiguration"},
+	{Operation: "GetBucketPolicy", Action: "s3:GetBucketPolicy"},
+	{Operation: "GetBucketAcl", Action: "s3:GetBucketAcl"},
+	{Operation: "PutBucketAcl", Action: "s3:PutBucketAcl", Writes: true},
+}
+
+// notConfiguredCodes are 404 error codes returned after authorization
+// succeeded, when the requested configuration simply does not exist
+var notConfiguredCodes = map[string]bool{
+	"NoSuchKey":                    true,
+	"NoSuchBucketPolicy":           true,
+	"NoSuchLifecycleConfiguration": true,
+	"ServerSideEncryptionConfigurationNotFoundError": true,
+}
+
+// PermissionsChecker attempts a matrix of S3 operations and reports which
+// ones the credentials are allowed to perform
+type PermissionsChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewPermissionsChecker creates a new permission matrix checker
+func NewPermissionsChecker(config output.Config) *PermissionsChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &PermissionsChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *PermissionsChecker) Name() string {
+	return "Permission Matrix Check"
+}
+
+// Check attempts every operation of the matrix. Object operations use a test
+// object under the test prefix. PutBucketAcl writes back the ACL just read, so
+// the bucket is left unchanged; it is not tested when the ACL cannot be read
+// or when the Object Ownership Check found ACLs disabled.
+func (c *PermissionsChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Permission Matrix Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+		Duration: time.Since(startTime),
+	}
+
+	key := c.client.testObjectKey("permissions")
+	body := []byte("s3tester permission matrix test\n")
+	var bucketACL []byte
+
+	permResult := output.PermissionsResult{Key: key}
+	for _, op := range PermissionOperations {
+		var probe output.PermissionProbe
+		switch op.Operation {
+		case "ListObjectsV2":
+			probe, _ = c.probe(op, "GET", "", url.Values{"list-type": {"2"}, "max-keys": {"1"}}, nil)
+		case "GetBucketLocation":
+			probe, _ = c.probe(op, "GET", "", url.Values{"location": {""}}, nil)
+		case "PutObject":
+			probe, _ = c.probe(op, "PUT", key, nil, body)
+		case "GetObject":
+			probe, _ = c.probe(op, "GET", key, nil, nil)
+		case "GetObjectAcl":
+			probe, _ = c.probe(op, "GET", key, url.Values{"acl": {""}}, nil)
+		case "DeleteObject":
+			probe, _ = c.probe(op, "DELETE", key, nil, nil)
+		case "ListMultipartUploads":
+			probe, _ = c.probe(op, "GET", "", url.Values{"uploads": {""}}, nil)
+		case "GetBucketVersioning":
+			probe, _ = c.probe(op, "GET", "", url.Values{"versioning": {""}}, nil)
+		case "GetBucketEncryption":
+			probe, _ = c.probe(op, "GET", "", url.Values{"encryption": {""}}, nil)
+		case "GetBucketPolicy":
+			probe, _ = c.probe(op, "GET", "", url.Values{"policy": {""}}, nil)
+		case "GetBucketAcl":
+			var aclBody []byte
+			probe, aclBody = c.probe(op, "GET", "", url.Values{"acl": {""}}, nil)
+			if probe.Outcome == PermissionAllowed {
+				bucketACL = aclBody
+			}
+		case "PutBucketAcl":
+			if c.Config.ObjectOwnership == OwnershipBucketOwnerEnforced {
+				probe = output.PermissionProbe{
+					Operation: op.Operation,
+					Action:    op.Action,
+					Outcome:   PermissionNotTested,
+					Detail:    ACLsDisabledReason,
+				}
+				break
+			}
+			if bucketACL == nil {
+				probe = output.PermissionProbe{
+					Operation: op.Operation,
+					Action:    op.Action,
+					Outcome:   PermissionNotTested,
+					Detail:    "current bucket ACL could not be read to write it back unchanged",
+				}
+				break
+			}
+			probe, _ = c.probe(op, "PUT", "", url.Values{"acl": {""}}, bucketACL)
+		}
+
+		c.verbose.LogMessage("%s (%s): %s %s", op.Operation, op.Action, probe.Outcome, probe.Detail)
+		permResult.Operations = append(permResult.Operations, probe)
+	}
+
+	var denied []string
+	for _, probe := range permResult.Operations {
+		switch probe.Outcome {
+		case PermissionAllowed:
+			permResult.Allowed++
+		case PermissionDenied:
+			permResult.Denied++
+			denied = append(denied, probe.Action)
+		}
+	}
+
+	if len(denied) > 0 {
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("%d of %d operations denied: %s", len(denied), len(permResult.Operations), strings.Join(denied, ", "))
+	}
+
+	result.Details = permResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Permission matrix check completed in %v", result.Duration)
+
+	return result
+}
+
+// probe sends one request of the matrix and classifies the response. The
+// response body is returned for probes whose output is reused.
+func (c *PermissionsChecker) probe(op PermissionOperation, method, key string, query url.Values, body []byte) (output.PermissionProbe, []byte) {
+	probe := output.PermissionProbe{
+		Operation: op.Operation,
+		Action:    op.Action,
+	}
+
+	req, err := c.client.newRequest(method, key, query, body)
+	if err != nil {
+		probe.Outcome = PermissionError
+		probe.Detail = err.Error()
+		return probe, nil
+	}
+	if body != nil {
+		contentType := "application/xml"
+		if op.Object {
+			contentType = "text/plain"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, respBody, err := c.client.do(req, body)
+	if err != nil {
+		probe.Outcome = PermissionError
+		probe.Detail = err.Error()
+		return probe, nil
+	}
+
+	probe.StatusCode = resp.StatusCode
+	probe.Outcome, probe.Detail = classifyPermission(resp.StatusCode, respBody)
+
+	return probe, respBody
+}
+
+// classifyPermission maps a response to a permission outcome
+func classifyPermission(statusCode int, body []byte) (string, string) {
+	code := errorCode(body)
+
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return PermissionAllowed, fmt.Sprintf("HTTP %d", statusCode)
+	case statusCode == http.StatusNotFound && notConfiguredCodes[code]:
+		return PermissionAllowed, code
+	case statusCode == http.StatusForbidden || statusCode == http.StatusUnauthorized:
+		return PermissionDenied, parseErrorResponse(statusCode, body)
+	case statusCode == http.StatusNotImplemented || code == "NotImplemented":
+		return PermissionUnsupported, parseErrorResponse(statusCode, body)
+	case code == "AccessControlListNotSupported":
+		return PermissionUnsupported, ACLsDisabledReason
+	default:
+		return PermissionError, parseErrorResponse(statusCode, body)
+	}
+}
@@ -0,0 +1,230 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// postPolicyMaxSize is the largest file the policy of the POST policy check
+// allows
+const postPolicyMaxSize = 1024
+
+// PostPolicyChecker verifies browser-based uploads: a multipart/form-data
+// POST to the bucket carrying a SigV4-signed policy instead of an
+// Authorization header
+type PostPolicyChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewPostPolicyChecker creates a new POST policy checker
+func NewPostPolicyChecker(config output.Config) *PostPolicyChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &PostPolicyChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *PostPolicyChecker) Name() string {
+	return "POST Policy Upload Check"
+}
+
+// Check signs a policy that allows keys under a fresh prefix and files of up
+// to 1 KiB, uploads a file with it as a browser form would and reads the
+// object back. It then submits a key outside the policy, a file that is too
+// large and the form with a tampered signature, all of which S3 refuses. The
+// check fails when the upload does not work or a violation is accepted,
+// since a web app then cannot rely on the policy to limit what browsers
+// upload.
+func (c *PostPolicyChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting POST Policy Upload Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	// The policy prefix is a fresh directory, so a key outside it can still
+	// be under the test prefix
+	allowedPrefix := c.client.testObjectKey("post-policy") + "/"
+	key := allowedPrefix + "upload.txt"
+	largeKey := allowedPrefix + "too-large.txt"
+	outsideKey := c.client.testObjectKey("post-policy-outside")
+	post := output.PostPolicyResult{KeyPrefix: allowedPrefix, MaxSize: postPolicyMaxSize}
+
+	content := make([]byte, 256)
+	large := make([]byte, 2*postPolicyMaxSize)
+	if _, err := rand.Read(large); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to generate test content: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	copy(content, large)
+
+	form, err := c.client.postPolicyForm(allowedPrefix, postPolicyMaxSize, presignExpiry)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to sign the POST policy: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	defer c.client.cleanup(func() {
+		for _, key := range []string{key, largeKey, outsideKey} {
+			if err := c.client.deleteObject(key); err != nil {
+				c.verbose.LogMessage("Failed to delete %s: %v", key, err)
+			}
+		}
+	})
+
+	status, body, err := c.submit(form, key, content)
+	uploadOK := err == nil && status == http.StatusCreated && resultElement(body) == "PostResponse"
+	post.Steps = append(post.Steps, newStep(c.verbose, "POST form upload", "201 with PostResponse", status, errorCode(body), err, uploadOK))
+
+	var resp *http.Response
+	req, err := c.client.newRequest("GET", key, nil, nil)
+	if err == nil {
+		resp, body, err = c.client.do(req, nil)
+	}
+	getOK := err == nil && resp.StatusCode == http.StatusOK && bytes.Equal(body, content)
+	getStep := output.Step{Step: "GET uploaded object", Expected: "200 with the uploaded content", Observed: observedGet(resp, body, err), Match: getOK}
+	if err == nil && resp.StatusCode == http.StatusOK && !getOK {
+		getStep.Observed += fmt.Sprintf(", %d bytes that differ from the upload", len(body))
+	}
+	post.Steps = append(post.Steps, getStep)
+
+	// Violations only mean something once the upload is known to work
+	var accepted []string
+	if uploadOK && getOK {
+		tampered := url.Values{}
+		for name, values := range form {
+			tampered[name] = values
+		}
+		tampered.Set("x-amz-signature", tamperedSignature(form.Get("x-amz-signature")))
+
+		for _, violation := range []struct {
+			name, expected string
+			status         int
+			form           url.Values
+			key            string
+			file           []byte
+		}{
+			{"Key outside the policy", "403", http.StatusForbidden, form, outsideKey, content},
+			{"File too large", "400 EntityTooLarge", http.StatusBadRequest, form, largeKey, large},
+			{"Tampered signature", "403", http.StatusForbidden, tampered, key, content},
+		} {
+			status, body, err := c.submit(violation.form, violation.key, violation.file)
+			post.Steps = append(post.Steps, newStep(c.verbose, violation.name, violation.expected, status, errorCode(body), err, status == violation.status))
+			if err == nil && status < 300 {
+				accepted = append(accepted, violation.name)
+			}
+		}
+	}
+
+	var mismatched []string
+	for _, step := range post.Steps {
+		if !step.Match {
+			mismatched = append(mismatched, step.Step)
+		}
+	}
+
+	// The outcomes are those of presigned URLs, the other way to let clients
+	// without credentials upload
+	switch {
+	case !uploadOK:
+		post.Outcome = PresignedNotSupported
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("browser-based POST uploads do not work: the form upload returned %s", post.Steps[0].Observed)
+	case len(accepted) > 0:
+		post.Outcome = PresignedNotVerified
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the provider accepted uploads that violate the POST policy: %s; browsers can upload what the policy forbids",
+			strings.Join(accepted, ", "))
+	case len(mismatched) > 0:
+		post.Outcome = PresignedBroken
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("POST uploads do not behave like S3: %s", strings.Join(mismatched, ", "))
+	default:
+		post.Outcome = PresignedSupported
+	}
+
+	result.Details = post
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("POST policy upload check completed in %v", result.Duration)
+
+	return result
+}
+
+// submit posts the form with the key and file to the bucket as a browser
+// would, without any credentials on the request itself
+func (c *PostPolicyChecker) submit(form url.Values, key string, file []byte) (int, []byte, error) {
+	u, err := bucketBaseURL(c.Config.Endpoint, c.Config.Bucket, c.Config.PathStyle)
+	if err != nil {
+		return 0, nil, err
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writer.WriteField("key", key)
+	for _, name := range names {
+		writer.WriteField(name, form.Get(name))
+	}
+	// S3 ignores every field after the file
+	part, err := writer.CreateFormFile("file", "upload.txt")
+	if err != nil {
+		return 0, nil, err
+	}
+	part.Write(file)
+	if err := writer.Close(); err != nil {
+		return 0, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(c.client.ctx, "POST", u.String(), &body)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("User-Agent", "s3-bucket-tester/1.0")
+	c.verbose.LogRequest(req)
+
+	resp, err := c.client.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	c.verbose.LogResponse(resp)
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, respBody, nil
+}
@@ -0,0 +1,224 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// Presigned URL outcomes as reported by the presigned URL check
+const (
+	PresignedSupported    = "supported"
+	PresignedNotSupported = "not supported"
+	PresignedNotVerified  = "not verified"
+	PresignedBroken       = "broken"
+)
+
+// PresignedChecker verifies query-string SigV4 authentication: a presigned
+// PUT and GET sent without credentials must succeed, and a tampered, expired
+// or misused URL must be refused
+type PresignedChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewPresignedChecker creates a new presigned URL checker
+func NewPresignedChecker(config output.Config) *PresignedChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &PresignedChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *PresignedChecker) Name() string {
+	return "Presigned URL Check"
+}
+
+// Check uploads a test object through a presigned PUT URL and reads it
+// through a presigned GET URL, both sent by a client without credentials.
+// It then uses the GET URL with a tampered signature, a GET URL that expired
+// ten minutes ago and the GET URL for a PUT, all of which S3 refuses with a
+// 403. The check fails when the presigned requests do not work, and when a
+// forged URL is accepted, since anyone could then extend or alter a shared
+// link.
+func (c *PresignedChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Presigned URL Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	key := c.client.testObjectKey("presigned")
+	presigned := output.PresignedResult{Key: key, Expires: presignExpiry.String()}
+
+	content := make([]byte, 256)
+	if _, err := rand.Read(content); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to generate test content: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	putURL, err := c.client.presign("PUT", key, presignExpiry)
+	var getURL, expiredURL string
+	if err == nil {
+		getURL, err = c.client.presign("GET", key, presignExpiry)
+	}
+	if err == nil {
+		expiredURL, err = c.client.presignAt("GET", key, time.Minute, time.Now().Add(-10*time.Minute))
+	}
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to presign a URL: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	// The presigned PUT is not confined to the test prefix by newRequest, but
+	// its key is the test key; the object is removed with signed requests
+	defer c.client.cleanup(func() {
+		if err := c.client.deleteObject(key); err != nil {
+			c.verbose.LogMessage("Failed to delete %s: %v", key, err)
+		}
+	})
+
+	status, body, err := c.fetch("PUT", putURL, content)
+	presigned.Steps = append(presigned.Steps, newStep(c.verbose, "Presigned PUT", "200", status, errorCode(body), err, status == http.StatusOK))
+	putOK := err == nil && status == http.StatusOK
+
+	status, body, err = c.fetch("GET", getURL, nil)
+	getOK := err == nil && status == http.StatusOK && bytes.Equal(body, content)
+	getStep := newStep(c.verbose, "Presigned GET", "200 with the uploaded content", status, errorCode(body), err, getOK)
+	if err == nil && status == http.StatusOK && !getOK {
+		getStep.Observed += fmt.Sprintf(", %d bytes that differ from the upload", len(body))
+	}
+	presigned.Steps = append(presigned.Steps, getStep)
+
+	// Refusals only mean something once the URLs are known to work
+	var forged []string
+	if putOK && getOK {
+		for _, misuse := range []struct {
+			name, method, url string
+		}{
+			{"Tampered signature", "GET", tamperSignature(getURL)},
+			{"Expired URL", "GET", expiredURL},
+			{"GET URL used for PUT", "PUT", getURL},
+		} {
+			var upload []byte
+			if misuse.method == "PUT" {
+				upload = content
+			}
+			status, body, err := c.fetch(misuse.method, misuse.url, upload)
+			step := newStep(c.verbose, misuse.name, "403", status, errorCode(body), err, status == http.StatusForbidden)
+			presigned.Steps = append(presigned.Steps, step)
+			if err == nil && status < 300 {
+				forged = append(forged, misuse.name)
+			}
+		}
+	}
+
+	var mismatched []string
+	for _, step := range presigned.Steps {
+		if !step.Match {
+			mismatched = append(mismatched, step.Step)
+		}
+	}
+
+	switch {
+	case !putOK && !getOK:
+		presigned.Outcome = PresignedNotSupported
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("presigned URLs do not work: %s", strings.Join(mismatched, ", "))
+	case len(forged) > 0:
+		presigned.Outcome = PresignedNotVerified
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the provider accepted forged presigned URLs: %s; anyone holding a shared link can alter or extend it",
+			strings.Join(forged, ", "))
+	case len(mismatched) > 0:
+		presigned.Outcome = PresignedBroken
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("presigned URLs do not behave like S3: %s", strings.Join(mismatched, ", "))
+	default:
+		presigned.Outcome = PresignedSupported
+	}
+
+	result.Details = presigned
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Presigned URL check completed in %v", result.Duration)
+
+	return result
+}
+
+// fetch sends a request to a presigned URL without any credentials on the
+// request itself and returns the status and at most a megabyte of the body
+func (c *PresignedChecker) fetch(method, presigned string, body []byte) (int, []byte, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(c.client.ctx, method, presigned, bodyReader)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("User-Agent", "s3-bucket-tester/1.0")
+	c.verbose.LogRequest(req)
+
+	resp, err := c.client.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	c.verbose.LogResponse(resp)
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, respBody, nil
+}
+
+// tamperedSignature returns the signature with its first digit changed, or
+// an empty signature unchanged
+func tamperedSignature(signature string) string {
+	if signature == "" {
+		return signature
+	}
+	if strings.HasPrefix(signature, "0") {
+		return "1" + signature[1:]
+	}
+	return "0" + signature[1:]
+}
+
+// tamperSignature returns the presigned URL with the first digit of its
+// signature changed
+func tamperSignature(presigned string) string {
+	u, err := url.Parse(presigned)
+	if err != nil {
+		return presigned
+	}
+	query := u.Query()
+	signature := query.Get("X-Amz-Signature")
+	if signature == "" {
+		return presigned
+	}
+	query.Set("X-Amz-Signature", tamperedSignature(signature))
+	u.RawQuery = canonicalQueryString(query)
+	return u.String()
+}
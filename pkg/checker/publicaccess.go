@@ -0,0 +1,246 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+	"github.com/s3-bucket-tester/s3tester/pkg/proxy"
+	"github.com/s3-bucket-tester/s3tester/pkg/remediation"
+	"github.com/s3-bucket-tester/s3tester/pkg/sts"
+)
+
+// Outcomes of a Block Public Access lookup
+const (
+	PublicAccessConfigured    = "configured"
+	PublicAccessNotConfigured = "not configured"
+	PublicAccessDenied        = "denied"
+	PublicAccessError         = "error"
+	PublicAccessNotSupported  = "not supported"
+	PublicAccessNotApplicable = "not applicable"
+)
+
+// PublicAccessBlockChecker reads the Block Public Access settings of the
+// bucket and of the AWS account, so a 403 can be traced to the bucket, the
+// account or the organization, and assesses whether they block public access
+type PublicAccessBlockChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewPublicAccessBlockChecker creates a new public access block checker
+func NewPublicAccessBlockChecker(config output.Config) *PublicAccessBlockChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &PublicAccessBlockChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *PublicAccessBlockChecker) Name() string {
+	return "Public Access Block Check"
+}
+
+// Check reads the bucket-level configuration with GetPublicAccessBlock, looks
+// up the account with sts:GetCallerIdentity and reads the account-level
+// configuration from S3 Control. The effective setting is the union of both.
+// Other providers have no account level. The check fails when no setting
+// blocks public access and warns when only some do, or when a configuration
+// cannot be read, naming the policy type that denied it when AWS reports one.
+// It is skipped when the endpoint does not implement Block Public Access.
+func (c *PublicAccessBlockChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Public Access Block Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	pabResult := output.PublicAccessBlockResult{}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"publicAccessBlock": {""}}, nil)
+	if err != nil {
+		pabResult.Bucket = output.PublicAccessBlockLookup{Status: PublicAccessError, Error: err.Error()}
+	} else {
+		pabResult.Bucket = c.lookup(req)
+	}
+	if pabResult.Bucket.Status == PublicAccessNotSupported {
+		result.Status = output.StatusSkip
+		result.Error = "the endpoint does not implement Block Public Access"
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	// Only AWS has account-level settings
+	if c.Config.Provider != "aws" {
+		pabResult.Account = output.PublicAccessBlockLookup{Status: PublicAccessNotApplicable}
+	} else if identity, err := c.callerIdentity(); err != nil {
+		pabResult.Account = output.PublicAccessBlockLookup{
+			Status: PublicAccessError,
+			Error:  fmt.Sprintf("account ID unknown: %v", err),
+		}
+	} else {
+		pabResult.AccountID = identity.Account
+		c.verbose.LogMessage("Credentials belong to %s in account %s", identity.Arn, identity.Account)
+
+		req, err := c.accountRequest(identity.Account)
+		if err != nil {
+			pabResult.Account = output.PublicAccessBlockLookup{Status: PublicAccessError, Error: err.Error()}
+		} else {
+			pabResult.Account = c.lookup(req)
+		}
+	}
+
+	for _, lookup := range []output.PublicAccessBlockLookup{pabResult.Bucket, pabResult.Account} {
+		if lookup.Settings == nil {
+			continue
+		}
+		pabResult.Effective.BlockPublicAcls = pabResult.Effective.BlockPublicAcls || lookup.Settings.BlockPublicAcls
+		pabResult.Effective.IgnorePublicAcls = pabResult.Effective.IgnorePublicAcls || lookup.Settings.IgnorePublicAcls
+		pabResult.Effective.BlockPublicPolicy = pabResult.Effective.BlockPublicPolicy || lookup.Settings.BlockPublicPolicy
+		pabResult.Effective.RestrictPublicBuckets = pabResult.Effective.RestrictPublicBuckets || lookup.Settings.RestrictPublicBuckets
+	}
+
+	var unread []string
+	for _, level := range []struct {
+		name   string
+		lookup output.PublicAccessBlockLookup
+	}{{"bucket", pabResult.Bucket}, {"account", pabResult.Account}} {
+		switch level.lookup.Status {
+		case PublicAccessDenied:
+			if level.lookup.DeniedBy != "" {
+				unread = append(unread, fmt.Sprintf("%s setting denied by %s", level.name, level.lookup.DeniedBy))
+			} else {
+				unread = append(unread, fmt.Sprintf("%s setting denied", level.name))
+			}
+		case PublicAccessError:
+			unread = append(unread, fmt.Sprintf("%s setting: %s", level.name, level.lookup.Error))
+		}
+	}
+
+	effective := pabResult.Effective
+	for _, setting := range []struct {
+		name string
+		on   bool
+	}{
+		{"BlockPublicAcls", effective.BlockPublicAcls},
+		{"IgnorePublicAcls", effective.IgnorePublicAcls},
+		{"BlockPublicPolicy", effective.BlockPublicPolicy},
+		{"RestrictPublicBuckets", effective.RestrictPublicBuckets},
+	} {
+		if !setting.on {
+			pabResult.Missing = append(pabResult.Missing, setting.name)
+		}
+	}
+
+	// Settings that could not be read may be the ones that block access, so
+	// only a complete reading is assessed
+	switch {
+	case len(unread) > 0:
+		result.Status = output.StatusWarn
+		result.Error = "Block Public Access could not be read: " + strings.Join(unread, "; ")
+	case len(pabResult.Missing) == 4:
+		result.Status = output.StatusFail
+		result.Error = "Block Public Access is off: no setting blocks public ACLs or policies on this bucket"
+	case len(pabResult.Missing) > 0:
+		result.Status = output.StatusWarn
+		result.Error = "Block Public Access is partly off: " + strings.Join(pabResult.Missing, ", ") + " not enabled"
+	}
+
+	result.Details = pabResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Public access block check completed in %v", result.Duration)
+
+	return result
+}
+
+// callerIdentity returns the identity of the credentials from the regional
+// STS endpoint
+func (c *PublicAccessBlockChecker) callerIdentity() (*sts.CallerIdentity, error) {
+	client := sts.NewClient(sts.DefaultEndpoint(c.Config.Region), c.Config.Region,
+		c.Config.AccessKey, c.Config.SecretKey, c.Config.SessionToken,
+		c.Config.Insecure, time.Duration(c.Config.HTTPTimeout)*time.Second)
+	if c.Config.Proxy != "" {
+		client.SetProxy(proxy.Func(c.Config.Proxy))
+	}
+	if c.Config.IPFamily != "" {
+		client.SetDialNetwork(output.FamilyNetwork("tcp", c.Config.IPFamily))
+	}
+	return client.GetCallerIdentity()
+}
+
+// accountRequest creates the S3 Control GetPublicAccessBlock request for the
+// account
+func (c *PublicAccessBlockChecker) accountRequest(accountID string) (*http.Request, error) {
+	u := fmt.Sprintf("https://%s.s3-control.%s.amazonaws.com/v20180820/configuration/publicAccessBlock",
+		accountID, c.Config.Region)
+	req, err := http.NewRequestWithContext(c.client.ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "s3-bucket-tester/1.0")
+	req.Header.Set("x-amz-account-id", accountID)
+	return req, nil
+}
+
+// lookup sends a GetPublicAccessBlock request and classifies the response
+func (c *PublicAccessBlockChecker) lookup(req *http.Request) output.PublicAccessBlockLookup {
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		return output.PublicAccessBlockLookup{Status: PublicAccessError, Error: err.Error()}
+	}
+
+	lookup := output.PublicAccessBlockLookup{StatusCode: resp.StatusCode}
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		var settings output.PublicAccessBlockSettings
+		if err := xml.Unmarshal(body, &settings); err != nil {
+			lookup.Status = PublicAccessError
+			lookup.Error = fmt.Sprintf("invalid response: %v", err)
+			break
+		}
+		lookup.Status = PublicAccessConfigured
+		lookup.Settings = &settings
+	case resp.StatusCode == http.StatusNotFound && strings.Contains(string(body), "NoSuchPublicAccessBlockConfiguration"):
+		lookup.Status = PublicAccessNotConfigured
+	case resp.StatusCode == http.StatusNotImplemented || errorCode(body) == "NotImplemented":
+		lookup.Status = PublicAccessNotSupported
+	case resp.StatusCode == http.StatusForbidden:
+		lookup.Status = PublicAccessDenied
+		lookup.Error = controlErrorResponse(resp.StatusCode, body)
+		lookup.DeniedBy = remediation.DenialSource(lookup.Error)
+	default:
+		lookup.Status = PublicAccessError
+		lookup.Error = controlErrorResponse(resp.StatusCode, body)
+	}
+
+	c.verbose.LogMessage("%s %s: %s %s", req.Method, req.URL.Host, lookup.Status, lookup.Error)
+
+	return lookup
+}
+
+// controlErrorResponse extracts the error code and message from an S3 or an
+// S3 Control error response; S3 Control wraps the Error element in an
+// ErrorResponse element
+func controlErrorResponse(statusCode int, body []byte) string {
+	var wrapped struct {
+		Code    string `xml:"Error>Code"`
+		Message string `xml:"Error>Message"`
+	}
+	if err := xml.Unmarshal(body, &wrapped); err == nil && wrapped.Code != "" {
+		return fmt.Sprintf("%s: %s", wrapped.Code, wrapped.Message)
+	}
+	return parseErrorResponse(statusCode, body)
+}
@@ -0,0 +1,248 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// rangedGetPartSize is the part size of the parallel download; 8 MiB is the
+// default multipart chunk size of the AWS CLI and SDK transfer managers
+const rangedGetPartSize = 8 * 1024 * 1024
+
+// RangedGetChecker downloads a test object with concurrent ranged GETs, the
+// way SDK transfer managers read large objects, and verifies the reassembled
+// content
+type RangedGetChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewRangedGetChecker creates a new parallel ranged GET checker
+func NewRangedGetChecker(config output.Config) *RangedGetChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	client := newS3Client(config, verbose)
+
+	// Keep one connection per worker alive between parts
+	if transport := httpTransport(client.httpClient); transport != nil {
+		transport.MaxIdleConnsPerHost = config.RangedGetConcurrency
+	}
+
+	return &RangedGetChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      client,
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *RangedGetChecker) Name() string {
+	return "Parallel Ranged GET Check"
+}
+
+// Check uploads a test object, downloads it in parts with concurrent ranged
+// GETs, compares the SHA-256 of the reassembled object and deletes it
+func (c *RangedGetChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Parallel Ranged GET Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+		Duration: time.Since(startTime),
+	}
+
+	size := int64(c.Config.RangedGetSizeMB) * 1024 * 1024
+	content := make([]byte, size)
+	if _, err := rand.Read(content); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to generate test content: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	sum := sha256.Sum256(content)
+
+	// Objects no larger than one part are still split across all workers
+	partSize := int64(rangedGetPartSize)
+	if size <= partSize {
+		partSize = (size + int64(c.Config.RangedGetConcurrency) - 1) / int64(c.Config.RangedGetConcurrency)
+	}
+
+	key := c.client.testObjectKey("ranged-get")
+	rangedResult := output.RangedGetResult{
+		Key:         key,
+		Size:        size,
+		PartSize:    partSize,
+		Concurrency: c.Config.RangedGetConcurrency,
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+
+	c.verbose.LogMessage("Test object key: %s (%d bytes, %d-byte parts, %d workers)", key, size, partSize, rangedResult.Concurrency)
+
+	// Upload the test object
+	req, err := c.client.newRequest("PUT", key, nil, content)
+	if err == nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+		uploadStart := time.Now()
+		var resp *http.Response
+		var body []byte
+		resp, body, err = c.client.do(req, content)
+		rangedResult.UploadMs = time.Since(uploadStart).Milliseconds()
+		if err == nil && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+			err = fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, body))
+		}
+	}
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("PUT failed: %v", err)
+		result.Details = rangedResult
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	// Download the parts concurrently into one buffer
+	downloaded := make([]byte, size)
+	rangedResult.Parts = c.downloadParts(key, size, partSize, downloaded)
+	for _, part := range rangedResult.Parts {
+		if part.Error != "" {
+			rangedResult.FailedParts++
+		}
+		if part.EndMs > rangedResult.DownloadMs {
+			rangedResult.DownloadMs = part.EndMs
+		}
+	}
+	if rangedResult.FailedParts == 0 {
+		downloadedSum := sha256.Sum256(downloaded)
+		rangedResult.IntegrityOK = bytes.Equal(downloadedSum[:], sum[:])
+		if rangedResult.DownloadMs > 0 {
+			rangedResult.ThroughputMiBps = float64(size) / (1024 * 1024) / (float64(rangedResult.DownloadMs) / 1000)
+		}
+	}
+
+	c.verbose.LogMessage("Downloaded %d part(s) in %dms, %d failed, integrity ok: %v",
+		len(rangedResult.Parts), rangedResult.DownloadMs, rangedResult.FailedParts, rangedResult.IntegrityOK)
+
+	// Always delete so no test object is left behind
+	c.client.cleanup(func() {
+		if err := c.client.deleteObject(key); err != nil {
+			rangedResult.DeleteError = err.Error()
+		}
+	})
+
+	switch {
+	case rangedResult.FailedParts > 0:
+		result.Status = output.StatusFail
+		for _, part := range rangedResult.Parts {
+			if part.Error != "" {
+				result.Error = fmt.Sprintf("%d of %d ranged GETs failed: %s: %s",
+					rangedResult.FailedParts, len(rangedResult.Parts), part.Range, part.Error)
+				break
+			}
+		}
+	case !rangedResult.IntegrityOK:
+		result.Status = output.StatusFail
+		result.Error = "reassembled object does not match the uploaded content (SHA-256 mismatch)"
+	case rangedResult.DeleteError != "":
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("DELETE failed: %s", rangedResult.DeleteError)
+	}
+
+	result.Details = rangedResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Parallel ranged GET check completed in %v", result.Duration)
+
+	return result
+}
+
+// downloadParts fetches every part of the object with the configured number
+// of workers, copying each part to its offset in buf
+func (c *RangedGetChecker) downloadParts(key string, size, partSize int64, buf []byte) []output.RangedGetPart {
+	count := int((size + partSize - 1) / partSize)
+	parts := make([]output.RangedGetPart, count)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	downloadStart := time.Now()
+
+	for w := 0; w < c.Config.RangedGetConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				start := int64(i) * partSize
+				end := start + partSize - 1
+				if end >= size {
+					end = size - 1
+				}
+				parts[i] = c.downloadPart(key, start, end, size, buf, downloadStart)
+				parts[i].Index = i + 1
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return parts
+}
+
+// downloadPart fetches one byte range and checks that the server honored it
+func (c *RangedGetChecker) downloadPart(key string, start, end, size int64, buf []byte, downloadStart time.Time) output.RangedGetPart {
+	part := output.RangedGetPart{
+		Range: fmt.Sprintf("bytes=%d-%d", start, end),
+	}
+
+	partStart := time.Now()
+	resp, body, err := c.client.getObjectRange(key, part.Range)
+	part.LatencyMs = time.Since(partStart).Milliseconds()
+	part.EndMs = time.Since(downloadStart).Milliseconds()
+	if err != nil {
+		part.Error = err.Error()
+		return part
+	}
+	part.StatusCode = resp.StatusCode
+
+	wantRange := fmt.Sprintf("bytes %d-%d/%d", start, end, size)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		part.Error = "server ignored the Range header and returned the whole object (HTTP 200)"
+	case resp.StatusCode != http.StatusPartialContent:
+		part.Error = parseErrorResponse(resp.StatusCode, body)
+	case resp.Header.Get("Content-Range") != wantRange:
+		part.Error = fmt.Sprintf("Content-Range %q, expected %q", resp.Header.Get("Content-Range"), wantRange)
+	case int64(len(body)) != end-start+1:
+		part.Error = fmt.Sprintf("received %d bytes, expected %d", len(body), end-start+1)
+	default:
+		copy(buf[start:end+1], body)
+	}
+
+	c.verbose.LogMessage("Part %s: HTTP %d in %dms", part.Range, resp.StatusCode, part.LatencyMs)
+
+	return part
+}
+
+// getObjectRange downloads a byte range of an object
+func (s *s3Client) getObjectRange(key, byteRange string) (*http.Response, []byte, error) {
+	req, err := s.newRequest("GET", key, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Range", byteRange)
+
+	return s.do(req, nil)
+}
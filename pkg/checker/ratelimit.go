@@ -0,0 +1,200 @@
+package checker
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// RateLimitedCode marks a response throttled by the endpoint that carried no
+// S3 error, in place of the S3 error code in result errors
+const RateLimitedCode = "RateLimited"
+
+// rateLimitKeywords mark the names of rate-limit and quota headers, such as
+// X-RateLimit-Remaining, RateLimit-Reset, X-Rate-Limit-Limit or
+// x-amz-quota-remaining. What follows the keyword names the field and,
+// optionally, the window: X-RateLimit-Remaining-Minute.
+var rateLimitKeywords = []string{"ratelimit", "rate-limit", "quota"}
+
+// unixTimeThreshold separates reset values given as a Unix time from those
+// given in seconds from now
+const unixTimeThreshold = 1000000000
+
+// rateLimits holds the rate-limit information of the HTTP responses of all
+// checkers by operation label until TakeRateLimit hands it to the check's
+// result
+var rateLimits struct {
+	sync.Mutex
+	byLabel map[string]*output.RateLimitInfo
+}
+
+// TakeRateLimit returns the rate-limit information recorded under label and
+// forgets it
+func TakeRateLimit(label string) *output.RateLimitInfo {
+	rateLimits.Lock()
+	defer rateLimits.Unlock()
+
+	info := rateLimits.byLabel[label]
+	delete(rateLimits.byLabel, label)
+	return info
+}
+
+// recordRateLimit keeps the information of a response under label: the
+// first throttled response, or else the latest
+func recordRateLimit(label string, info *output.RateLimitInfo) {
+	rateLimits.Lock()
+	defer rateLimits.Unlock()
+
+	if rateLimits.byLabel == nil {
+		rateLimits.byLabel = make(map[string]*output.RateLimitInfo)
+	}
+	if previous := rateLimits.byLabel[label]; previous != nil && previous.Exhausted {
+		return
+	}
+	rateLimits.byLabel[label] = info
+}
+
+// rateLimitTransport records the rate-limit headers of every response
+type rateLimitTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends the request and records the rate-limit headers of the
+// response, if it has any
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if info := parseRateLimit(resp.StatusCode, resp.Header); info != nil {
+		label, _ := req.Context().Value(operationLabelKey{}).(string)
+		recordRateLimit(label, info)
+	}
+	return resp, nil
+}
+
+// rateLimitWindow is the limit, remaining count and reset of one window
+type rateLimitWindow struct {
+	limit, remaining, reset *int64
+}
+
+// parseRateLimit extracts the rate-limit information of a response, or
+// returns nil when it has no rate-limit, quota or Retry-After header
+func parseRateLimit(statusCode int, header http.Header) *output.RateLimitInfo {
+	info := &output.RateLimitInfo{StatusCode: statusCode, Headers: map[string]string{}}
+	windows := map[string]*rateLimitWindow{}
+	window := func(name string) *rateLimitWindow {
+		if windows[name] == nil {
+			windows[name] = &rateLimitWindow{}
+		}
+		return windows[name]
+	}
+
+	limited := false
+	for name, values := range header {
+		lower := strings.ToLower(name)
+		if lower == "retry-after" {
+			info.Headers[name] = values[0]
+			info.RetryAfterSeconds = parseRetryAfter(values[0])
+			continue
+		}
+		field, ok := rateLimitField(lower)
+		if !ok {
+			continue
+		}
+		info.Headers[name] = strings.Join(values, ", ")
+		limited = true
+
+		// The structured RateLimit header holds every field in one value:
+		// limit=100, remaining=0, reset=30
+		if field == "" {
+			for _, item := range strings.Split(values[0], ",") {
+				key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+				setRateLimitField(window(""), key, value)
+			}
+			continue
+		}
+		key, windowName, _ := strings.Cut(field, "-")
+		setRateLimitField(window(windowName), key, values[0])
+	}
+	if len(info.Headers) == 0 {
+		return nil
+	}
+
+	// Report the window closest to running out
+	names := make([]string, 0, len(windows))
+	for name := range windows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var tightest *rateLimitWindow
+	for _, name := range names {
+		w := windows[name]
+		if tightest == nil || (w.remaining != nil && (tightest.remaining == nil || *w.remaining < *tightest.remaining)) {
+			tightest = w
+			info.Window = name
+		}
+	}
+	if tightest != nil {
+		info.Limit = tightest.limit
+		info.Remaining = tightest.remaining
+		if tightest.reset != nil {
+			reset := time.Now().Add(time.Duration(*tightest.reset) * time.Second)
+			if *tightest.reset > unixTimeThreshold {
+				reset = time.Unix(*tightest.reset, 0)
+			}
+			info.ResetAt = &reset
+		}
+	}
+
+	info.Exhausted = statusCode == http.StatusTooManyRequests ||
+		(info.Remaining != nil && *info.Remaining <= 0) ||
+		(statusCode == http.StatusServiceUnavailable && limited)
+	return info
+}
+
+// rateLimitField returns what follows the rate-limit keyword in a lower-case
+// header name, without the leading dash, and whether it is a rate-limit
+// header at all
+func rateLimitField(lower string) (string, bool) {
+	for _, keyword := range rateLimitKeywords {
+		if i := strings.Index(lower, keyword); i >= 0 {
+			return strings.TrimPrefix(lower[i+len(keyword):], "-"), true
+		}
+	}
+	return "", false
+}
+
+// setRateLimitField sets the limit, remaining count or reset of a window
+// from a header value, ignoring fields and values it does not know
+func setRateLimitField(w *rateLimitWindow, key, value string) {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return
+	}
+	switch strings.TrimSpace(key) {
+	case "limit":
+		w.limit = &n
+	case "remaining":
+		w.remaining = &n
+	case "reset":
+		w.reset = &n
+	}
+}
+
+// parseRetryAfter returns the seconds a Retry-After value asks to wait, given
+// as a number of seconds or as an HTTP date
+func parseRetryAfter(value string) int {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return seconds
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(time.Now()) {
+		return int(time.Until(at).Round(time.Second).Seconds())
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+package checker
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// PermanentRedirectCode is the S3 error code of a bucket that is served by
+// another endpoint, used for a redirect that had no error body, as the
+// responses to HEAD requests do
+const PermanentRedirectCode = "PermanentRedirect"
+
+// expectedRegionPattern finds the region in messages such as "the region
+// 'us-east-1' is wrong; expecting 'eu-west-1'"
+var expectedRegionPattern = regexp.MustCompile(`expecting '([a-z0-9-]+)'`)
+
+// awsRegionalHostPattern matches the AWS S3 endpoints that name a region,
+// s3.<region>.amazonaws.com, s3-<region>.amazonaws.com and their dualstack
+// form, and the global s3.amazonaws.com
+var awsRegionalHostPattern = regexp.MustCompile(`^s3(\.dualstack)?([.-][a-z0-9-]+)?\.amazonaws\.com$`)
+
+// bucketRegion returns the region a redirect or a region error names for the
+// bucket, from the x-amz-bucket-region header, the Region of the error body or
+// its message, or "" when the response does not name one
+func bucketRegion(statusCode int, header http.Header, body []byte) string {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusBadRequest:
+	default:
+		return ""
+	}
+
+	if region := header.Get("X-Amz-Bucket-Region"); region != "" {
+		return region
+	}
+	var errResp ErrorResponse
+	if xml.Unmarshal(body, &errResp) != nil {
+		return ""
+	}
+	if errResp.Region != "" {
+		return errResp.Region
+	}
+	if match := expectedRegionPattern.FindStringSubmatch(errResp.Message); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// regionalEndpoint returns the endpoint of region for an AWS S3 endpoint of
+// another region, or the global one. Other providers take the region from
+// the signature only, so their endpoint is returned unchanged.
+func regionalEndpoint(endpoint, region string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	current := strings.ToLower(u.Hostname())
+	// Transfer Acceleration endpoints serve every region
+	if !awsRegionalHostPattern.MatchString(current) || strings.HasPrefix(current, "s3-accelerate") {
+		return endpoint
+	}
+	host := "s3." + region + ".amazonaws.com"
+	if strings.HasPrefix(current, "s3.dualstack.") {
+		host = "s3.dualstack." + region + ".amazonaws.com"
+	}
+	if port := u.Port(); port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+	return u.String()
+}
+
+// describeRedirect describes a redirect the request was not, or could not
+// be, followed for: S3 answers a bucket of another region with a 301 without
+// a Location
+func describeRedirect(statusCode int, header http.Header, body []byte) string {
+	code, message := PermanentRedirectCode, "the bucket is served by another endpoint"
+	var errResp ErrorResponse
+	if xml.Unmarshal(body, &errResp) == nil && errResp.Code != "" {
+		code, message = errResp.Code, errResp.Message
+	}
+	desc := fmt.Sprintf("%s: HTTP %d, %s", code, statusCode, message)
+	if location := header.Get("Location"); location != "" {
+		desc += "; redirected to " + location
+	}
+	if region := bucketRegion(statusCode, header, body); region != "" {
+		desc += fmt.Sprintf("; the bucket is in region %s", region)
+	}
+	return desc
+}
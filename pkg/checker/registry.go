@@ -0,0 +1,435 @@
+package checker
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// Registration describes an optional checker that runs after the core
+// connectivity and authentication checks
+type Registration struct {
+	// Name is the test name the checker reports
+	Name string
+
+	// Enabled reports whether the checker was requested
+	Enabled func(config output.Config) bool
+
+	// Mutates reports whether the checker writes to or deletes from the
+	// bucket with this configuration
+	Mutates func(config output.Config) bool
+
+	// New creates the checker
+	New func(config output.Config) Checker
+
+	// Permissions lists the IAM actions the checker needs with this
+	// configuration, for --emit-policy
+	Permissions func(config output.Config) []Permission
+
+	// Requires lists the checks that must not have failed for this one to
+	// be worth running
+	Requires []string
+
+	// Weight is the check's share of --budget relative to the other optional
+	// checks; probes that transfer data or send many requests weigh more.
+	// Zero counts as 1.
+	Weight int
+}
+
+// Permission is an IAM action a checker needs. Object actions apply to keys
+// under the test prefix, or only to Key when it is set; account actions
+// apply to the AWS account; the others apply to the bucket.
+type Permission struct {
+	Action  string
+	Object  bool
+	Key     string
+	Account bool
+}
+
+// objectRoundTrip is the permission set of checks that write, read back and
+// delete a test object
+var objectRoundTrip = []Permission{
+	{Action: "s3:PutObject", Object: true},
+	{Action: "s3:GetObject", Object: true},
+	{Action: "s3:DeleteObject", Object: true},
+}
+
+// static returns a Permissions function with a fixed result
+func static(permissions ...Permission) func(output.Config) []Permission {
+	return func(output.Config) []Permission { return permissions }
+}
+
+// coreRequires lists the prerequisites of the checks every run performs.
+// Without a TCP connection there is no TLS handshake, and without both no
+// S3 request can succeed.
+var coreRequires = map[string][]string{
+	"TCP Connectivity Check":      {"DNS Resolution Check"},
+	"SSL/TLS Certificate Check":   {"TCP Connectivity Check"},
+	"Bucket Authentication Check": {"TCP Connectivity Check", "SSL/TLS Certificate Check"},
+}
+
+// connectivity and bucketAccess are the usual prerequisites of the optional
+// checks: those that send any S3 request, and those that also need the
+// credentials to reach the bucket
+var (
+	connectivity = []string{"TCP Connectivity Check", "SSL/TLS Certificate Check"}
+	bucketAccess = []string{"Bucket Authentication Check"}
+)
+
+// Requires returns the checks the named check depends on with this
+// configuration. A plain HTTP endpoint fails the TLS check without that
+// affecting any S3 request, so it is no prerequisite there.
+func Requires(config output.Config, name string) []string {
+	requires, ok := coreRequires[name]
+	if !ok {
+		for _, reg := range Registry {
+			if reg.Name == name {
+				requires = reg.Requires
+			}
+		}
+	}
+
+	var filtered []string
+	for _, required := range requires {
+		if required == "SSL/TLS Certificate Check" && !strings.HasPrefix(config.Endpoint, "https://") {
+			continue
+		}
+		filtered = append(filtered, required)
+	}
+	return filtered
+}
+
+// always and never are Mutates helpers
+func always(output.Config) bool { return true }
+func never(output.Config) bool  { return false }
+
+// Registry lists the optional checkers in the order they run
+var Registry = []Registration{
+	{
+		// Extends the bucket policy and ACL check; runs first, ahead of the
+		// bucket policy and ACL check too, so the later checks know whether
+		// ACLs are disabled
+		Name:        "Object Ownership Check",
+		Enabled:     func(c output.Config) bool { return c.CheckOwnership || c.CheckPolicy },
+		Mutates:     never,
+		New:         func(c output.Config) Checker { return NewOwnershipChecker(c) },
+		Permissions: static(Permission{Action: "s3:GetBucketOwnershipControls"}),
+		Requires:    bucketAccess,
+	},
+	{
+		// Extends the bucket policy and ACL check on AWS
+		Name: "Public Access Block Check",
+		Enabled: func(c output.Config) bool {
+			return c.CheckPublicAccess || (c.CheckPolicy && c.Provider == "aws")
+		},
+		Mutates:     never,
+		New:         func(c output.Config) Checker { return NewPublicAccessBlockChecker(c) },
+		Permissions: publicAccessBlockPermissions,
+	},
+	{
+		Name:        "Policy Fingerprint Check",
+		Enabled:     func(c output.Config) bool { return c.WatchPolicy },
+		Mutates:     never,
+		New:         func(c output.Config) Checker { return NewPolicyFingerprintChecker(c) },
+		Permissions: static(policyFingerprintPermissions...),
+		Requires:    bucketAccess,
+	},
+	{
+		// Depends on connectivity only: the configured style may be the
+		// one that fails the authentication check
+		Name:        "Addressing Style Check",
+		Enabled:     func(c output.Config) bool { return c.TestBothStyles },
+		Mutates:     never,
+		New:         func(c output.Config) Checker { return NewAddressingStyleChecker(c) },
+		Permissions: static(Permission{Action: "s3:ListBucket"}),
+		Requires:    connectivity,
+	},
+	{
+		// Depends on connectivity only: a wrong region is what fails the
+		// authentication check
+		Name:        "Bucket Location Check",
+		Enabled:     func(c output.Config) bool { return c.CheckLocation },
+		Mutates:     never,
+		New:         func(c output.Config) Checker { return NewBucketLocationChecker(c) },
+		Permissions: static(Permission{Action: "s3:GetBucketLocation"}),
+		Requires:    connectivity,
+	},
+	{
+		Name:        "Lifecycle Configuration Check",
+		Enabled:     func(c output.Config) bool { return c.CheckLifecycle },
+		Mutates:     never,
+		New:         func(c output.Config) Checker { return NewLifecycleChecker(c) },
+		Permissions: static(Permission{Action: "s3:GetLifecycleConfiguration"}),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Bucket Logging Check",
+		Enabled:     func(c output.Config) bool { return c.CheckLogging },
+		Mutates:     never,
+		New:         func(c output.Config) Checker { return NewLoggingChecker(c) },
+		Permissions: static(Permission{Action: "s3:GetBucketLogging"}),
+		Requires:    bucketAccess,
+	},
+	{
+		// Weighs more for the delivery, which waits up to NotificationWait
+		Name:        "Event Notification Check",
+		Enabled:     func(c output.Config) bool { return c.CheckNotifications },
+		Mutates:     func(c output.Config) bool { return c.VerifyNotifications != "" && !c.ReadOnly },
+		New:         func(c output.Config) Checker { return NewNotificationChecker(c) },
+		Permissions: notificationPermissions,
+		Requires:    bucketAccess,
+		Weight:      2,
+	},
+	{
+		// The index document's key is only known once the configuration is
+		// read, so it may be any key of the bucket
+		Name:    "Static Website Check",
+		Enabled: func(c output.Config) bool { return c.CheckWebsite },
+		Mutates: never,
+		New:     func(c output.Config) Checker { return NewWebsiteChecker(c) },
+		Permissions: static(Permission{Action: "s3:GetBucketWebsite"},
+			Permission{Action: "s3:GetObject", Object: true, Key: "*"}),
+		Requires: bucketAccess,
+	},
+	{
+		Name:        "Bucket Tagging Check",
+		Enabled:     func(c output.Config) bool { return c.CheckTagging },
+		Mutates:     never,
+		New:         func(c output.Config) Checker { return NewBucketTaggingChecker(c) },
+		Permissions: static(Permission{Action: "s3:GetBucketTagging"}),
+		Requires:    bucketAccess,
+	},
+	{
+		// Weighs more for the canary, which polls for up to ReplicationWait
+		Name:        "Replication Check",
+		Enabled:     func(c output.Config) bool { return c.CheckReplication },
+		Mutates:     func(c output.Config) bool { return c.TestReplication && !c.ReadOnly },
+		New:         func(c output.Config) Checker { return NewReplicationChecker(c) },
+		Permissions: replicationPermissions,
+		Requires:    bucketAccess,
+		Weight:      4,
+	},
+	{
+		Name:        "Anonymous Access Check",
+		Enabled:     func(c output.Config) bool { return !c.SkipAnonymous },
+		Mutates:     never,
+		New:         func(c output.Config) Checker { return NewAnonymousAccessChecker(c) },
+		Permissions: static(Permission{Action: "s3:ListBucket"}),
+		Requires:    connectivity,
+	},
+	{
+		// Runs before this run writes its own objects
+		Name:        "Test Artifact Inventory",
+		Enabled:     func(c output.Config) bool { return c.CheckArtifacts },
+		Mutates:     func(c output.Config) bool { return c.PurgeArtifacts },
+		New:         func(c output.Config) Checker { return NewArtifactChecker(c) },
+		Permissions: artifactPermissions,
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Permission Matrix Check",
+		Enabled:     func(c output.Config) bool { return c.CheckPermissions },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewPermissionsChecker(c) },
+		Permissions: permissionMatrixPermissions,
+		Requires:    connectivity,
+		Weight:      2,
+	},
+	{
+		Name:        "Object Read/Write Check",
+		Enabled:     func(c output.Config) bool { return c.CheckObject },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewObjectChecker(c) },
+		Permissions: static(objectRoundTrip...),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Expect: 100-continue Check",
+		Enabled:     func(c output.Config) bool { return c.CheckExpect },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewExpectContinueChecker(c) },
+		Permissions: static(Permission{Action: "s3:PutObject", Object: true}, Permission{Action: "s3:DeleteObject", Object: true}),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Transfer Encoding Probe",
+		Enabled:     func(c output.Config) bool { return c.ProbeTransfer },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewTransferEncodingChecker(c) },
+		Permissions: static(objectRoundTrip...),
+		Requires:    bucketAccess,
+		Weight:      2,
+	},
+	{
+		Name:        "Content-Encoding Passthrough Check",
+		Enabled:     func(c output.Config) bool { return c.CheckEncoding },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewContentEncodingChecker(c) },
+		Permissions: static(objectRoundTrip...),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Cache Header Check",
+		Enabled:     func(c output.Config) bool { return c.CheckCache },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewCacheHeaderChecker(c) },
+		Permissions: static(objectRoundTrip...),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Versioned Delete Check",
+		Enabled:     func(c output.Config) bool { return c.CheckVersionedDelete },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewVersionedDeleteChecker(c) },
+		Permissions: static(versionedDeletePermissions...),
+		Requires:    bucketAccess,
+		Weight:      2,
+	},
+	{
+		Name:        "Versioning Check",
+		Enabled:     func(c output.Config) bool { return c.CheckVersioning },
+		Mutates:     func(c output.Config) bool { return !c.ReadOnly },
+		New:         func(c output.Config) Checker { return NewVersioningChecker(c) },
+		Permissions: versioningPermissions,
+		Requires:    bucketAccess,
+		Weight:      2,
+	},
+	{
+		Name:        "Object Lock Check",
+		Enabled:     func(c output.Config) bool { return c.CheckObjectLock },
+		Mutates:     func(c output.Config) bool { return c.TestRetention && !c.ReadOnly },
+		New:         func(c output.Config) Checker { return NewObjectLockChecker(c) },
+		Permissions: objectLockPermissions,
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Object Tagging Check",
+		Enabled:     func(c output.Config) bool { return c.CheckTagging },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewObjectTaggingChecker(c) },
+		Permissions: static(objectTaggingPermissions...),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Presigned URL Check",
+		Enabled:     func(c output.Config) bool { return c.CheckPresigned },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewPresignedChecker(c) },
+		Permissions: static(objectRoundTrip...),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "POST Policy Upload Check",
+		Enabled:     func(c output.Config) bool { return c.CheckPostPolicy },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewPostPolicyChecker(c) },
+		Permissions: static(objectRoundTrip...),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Copy Object Check",
+		Enabled:     func(c output.Config) bool { return c.CheckCopy },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewCopyChecker(c) },
+		Permissions: static(copyPermissions...),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Batch Delete Check",
+		Enabled:     func(c output.Config) bool { return c.CheckBatchDelete },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewBatchDeleteChecker(c) },
+		Permissions: static(objectRoundTrip...),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Range and Conditional GET Check",
+		Enabled:     func(c output.Config) bool { return c.CheckConditional },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewConditionalChecker(c) },
+		Permissions: static(objectRoundTrip...),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Checksum Check",
+		Enabled:     func(c output.Config) bool { return c.CheckChecksums },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewChecksumChecker(c) },
+		Permissions: static(objectRoundTrip...),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "SSE-C Check",
+		Enabled:     func(c output.Config) bool { return c.CheckSSEC },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewSSECChecker(c) },
+		Permissions: static(objectRoundTrip...),
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Parallel Ranged GET Check",
+		Enabled:     func(c output.Config) bool { return c.CheckRangedGet },
+		Mutates:     always,
+		New:         func(c output.Config) Checker { return NewRangedGetChecker(c) },
+		Permissions: static(objectRoundTrip...),
+		Requires:    bucketAccess,
+		Weight:      4,
+	},
+	{
+		// Reads the --object-key object when set, else writes a test object
+		Name:        "CDN Delivery Check",
+		Enabled:     func(c output.Config) bool { return c.CDN != "" },
+		Mutates:     func(c output.Config) bool { return c.ObjectKey == "" },
+		New:         func(c output.Config) Checker { return NewCDNChecker(c) },
+		Permissions: cdnPermissions,
+		Requires:    bucketAccess,
+	},
+	{
+		Name:        "Object Access Check",
+		Enabled:     func(c output.Config) bool { return c.ObjectKey != "" },
+		Mutates:     never,
+		New:         func(c output.Config) Checker { return NewObjectAccessChecker(c) },
+		Permissions: objectAccessPermissions,
+		Requires:    connectivity,
+	},
+	{
+		// Depends on connectivity only: the configured addressing style may
+		// be the one that does not work
+		Name:        "Capability Probe",
+		Enabled:     func(c output.Config) bool { return c.ProbeCapabilities },
+		Mutates:     func(c output.Config) bool { return !c.ReadOnly },
+		New:         func(c output.Config) Checker { return NewCapabilityProbeChecker(c) },
+		Permissions: capabilityProbePermissions,
+		Requires:    connectivity,
+		Weight:      2,
+	},
+	{
+		Name:        "Capability Requirements Check",
+		Enabled:     func(c output.Config) bool { return len(c.Require) > 0 },
+		Mutates:     never,
+		New:         func(c output.Config) Checker { return NewCapabilityChecker(c) },
+		Permissions: capabilityPermissions,
+		Requires:    bucketAccess,
+	},
+	{
+		// Depends on connectivity only: comparing the clients is most useful
+		// when s3tester's authentication fails
+		Name:        "SDK Parity Check",
+		Enabled:     func(c output.Config) bool { return c.SDKParity },
+		Mutates:     func(c output.Config) bool { return !c.ReadOnly },
+		New:         func(c output.Config) Checker { return NewSDKParityChecker(c) },
+		Permissions: sdkParityPermissions,
+		Requires:    connectivity,
+		Weight:      2,
+	},
+}
+
+// writeRequests counts write requests sent to the bucket by any checker
+var writeRequests uint64
+
+// WriteRequests returns the number of write requests sent so far. A read-only
+// run reports zero, which the report records as evidence it had no side effects.
+func WriteRequests() uint64 {
+	return atomic.LoadUint64(&writeRequests)
+}
@@ -0,0 +1,298 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// ReplicationWait is how long the replication test polls the canary object
+// for a final replication status, and ReplicationPollInterval how often
+const (
+	ReplicationWait         = 2 * time.Minute
+	ReplicationPollInterval = 5 * time.Second
+)
+
+// Canary outcomes as reported by the replication check
+const (
+	CanaryCompleted     = "completed"
+	CanaryFailed        = "failed"
+	CanaryPending       = "pending"
+	CanaryNotReplicated = "not replicated"
+)
+
+// ReplicationChecker reads the bucket's replication rules and, with
+// --test-replication, verifies that a canary object is replicated
+type ReplicationChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewReplicationChecker creates a new replication checker
+func NewReplicationChecker(config output.Config) *ReplicationChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &ReplicationChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *ReplicationChecker) Name() string {
+	return "Replication Check"
+}
+
+// replicationConfiguration is the GetBucketReplication response; Prefix is
+// the rule's prefix in the legacy form without a Filter
+type replicationConfiguration struct {
+	Role  string `xml:"Role"`
+	Rules []struct {
+		ID       string `xml:"ID"`
+		Status   string `xml:"Status"`
+		Priority int    `xml:"Priority"`
+		Prefix   string `xml:"Prefix"`
+		Filter   struct {
+			Prefix string `xml:"Prefix"`
+			And    struct {
+				Prefix string `xml:"Prefix"`
+			} `xml:"And"`
+		} `xml:"Filter"`
+		Destination struct {
+			Bucket       string `xml:"Bucket"`
+			StorageClass string `xml:"StorageClass"`
+		} `xml:"Destination"`
+		DeleteMarkerReplication struct {
+			Status string `xml:"Status"`
+		} `xml:"DeleteMarkerReplication"`
+	} `xml:"Rule"`
+}
+
+// Check sends GET ?replication and reports each rule with its destination.
+// It warns when the bucket has no replication configuration or no enabled
+// rule. With --test-replication it writes a canary object under the test
+// prefix and polls its x-amz-replication-status: the check fails when
+// replication fails, and warns when the canary is still pending after
+// ReplicationWait or no rule covers it.
+func (c *ReplicationChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Replication Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"replication": {""}}, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketReplication failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	replication := output.ReplicationResult{StatusCode: resp.StatusCode}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case errorCode(body) == "ReplicationConfigurationNotFoundError":
+		replication.Canary = "not run: the bucket has no replication configuration"
+		result.Status = output.StatusWarn
+		result.Error = "the bucket has no replication configuration: its objects are not copied to another bucket"
+		result.Details = replication
+		result.Duration = time.Since(startTime)
+		return result
+	case resp.StatusCode == http.StatusForbidden:
+		result.Status = output.StatusWarn
+		result.Error = "GetBucketReplication denied, the replication rules could not be read: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	case resp.StatusCode == http.StatusNotImplemented || errorCode(body) == "NotImplemented":
+		result.Status = output.StatusSkip
+		result.Error = "the endpoint does not implement bucket replication"
+		result.Duration = time.Since(startTime)
+		return result
+	default:
+		result.Status = output.StatusFail
+		result.Error = "GetBucketReplication failed: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	var configuration replicationConfiguration
+	if err := xml.Unmarshal(body, &configuration); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the replication configuration does not parse: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	replication.Configured = true
+	replication.Role = configuration.Role
+
+	enabled := 0
+	for i, r := range configuration.Rules {
+		rule := output.ReplicationRule{
+			ID:            r.ID,
+			Status:        r.Status,
+			Priority:      r.Priority,
+			Prefix:        r.Prefix,
+			Destination:   strings.TrimPrefix(r.Destination.Bucket, "arn:aws:s3:::"),
+			StorageClass:  r.Destination.StorageClass,
+			DeleteMarkers: r.DeleteMarkerReplication.Status,
+		}
+		if r.Filter.Prefix != "" {
+			rule.Prefix = r.Filter.Prefix
+		} else if r.Filter.And.Prefix != "" {
+			rule.Prefix = r.Filter.And.Prefix
+		}
+		if rule.ID == "" {
+			rule.ID = fmt.Sprintf("rule %d", i+1)
+		}
+		if rule.Status == "Enabled" {
+			enabled++
+		}
+		c.verbose.LogMessage("Rule %s (%s, prefix %q): to %s", rule.ID, rule.Status, rule.Prefix, rule.Destination)
+		replication.Rules = append(replication.Rules, rule)
+	}
+
+	switch {
+	case enabled == 0:
+		replication.Canary = "not run: no replication rule is enabled"
+		result.Status = output.StatusWarn
+		result.Error = "no replication rule is enabled: objects are not copied to another bucket"
+	case !c.Config.TestReplication:
+		replication.Canary = "not run without --test-replication"
+	case c.Config.ReadOnly:
+		replication.Canary = "not run with --read-only"
+	}
+	if replication.Canary != "" {
+		result.Details = replication
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	return c.canary(ctx, result, replication, startTime)
+}
+
+// canary writes the canary object and polls its replication status until it
+// is final, ReplicationWait has passed or the check runs out of time. The
+// canary is deleted from the bucket afterwards; its replica is left in the
+// destination.
+func (c *ReplicationChecker) canary(ctx context.Context, result output.TestResult, replication output.ReplicationResult, startTime time.Time) output.TestResult {
+	key := c.client.testObjectKey("replication")
+	replication.Key = key
+
+	body := []byte("s3tester replication canary\n")
+	req, err := c.client.newRequest("PUT", key, nil, body)
+	if err == nil {
+		req.Header.Set("Content-Type", "text/plain")
+		var resp *http.Response
+		var respBody []byte
+		resp, respBody, err = c.client.do(req, body)
+		if err == nil && resp.StatusCode >= 300 {
+			err = fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, respBody))
+		}
+	}
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to write the replication canary: %v", err)
+		result.Details = replication
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	defer c.client.cleanup(func() {
+		if err := c.client.deleteObject(key); err != nil {
+			c.verbose.LogMessage("Failed to delete %s: %v", key, err)
+		}
+	})
+
+	// Stop polling early enough to report the last status within --budget
+	deadline := time.Now().Add(ReplicationWait)
+	if d, ok := ctx.Deadline(); ok && d.Add(-ReplicationPollInterval).Before(deadline) {
+		deadline = d.Add(-ReplicationPollInterval)
+	}
+
+	written := time.Now()
+	for {
+		status, err := c.replicationStatus(key)
+		if err != nil {
+			result.Status = output.StatusFail
+			result.Error = fmt.Sprintf("failed to read the replication status of the canary: %v", err)
+			break
+		}
+		replication.ReplicationStatus = status
+		c.verbose.LogMessage("Replication status of %s after %v: %q", key, time.Since(written).Round(time.Second), status)
+		if status == "COMPLETED" || status == "FAILED" || status == "" || !time.Now().Add(ReplicationPollInterval).Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(ReplicationPollInterval):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	replication.WaitMs = time.Since(written).Milliseconds()
+
+	if result.Error == "" {
+		switch replication.ReplicationStatus {
+		case "COMPLETED":
+			replication.Canary = CanaryCompleted
+		case "FAILED":
+			replication.Canary = CanaryFailed
+			result.Status = output.StatusFail
+			result.Error = "replication of the canary object failed: the destination bucket, its permissions or the replication role may be misconfigured"
+		case "":
+			replication.Canary = CanaryNotReplicated
+			result.Status = output.StatusWarn
+			result.Error = fmt.Sprintf("the canary object %s has no replication status: no enabled rule covers the test prefix", key)
+		default:
+			replication.Canary = CanaryPending
+			result.Status = output.StatusWarn
+			result.Error = fmt.Sprintf("the canary object is still %s after %v: replication is slow or not flowing",
+				replication.ReplicationStatus, time.Since(written).Round(time.Second))
+		}
+	}
+
+	result.Details = replication
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Replication check completed in %v", result.Duration)
+
+	return result
+}
+
+// replicationStatus sends HEAD for the canary and returns its
+// x-amz-replication-status
+func (c *ReplicationChecker) replicationStatus(key string) (string, error) {
+	req, err := c.client.newRequest("HEAD", key, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, body))
+	}
+	return resp.Header.Get("x-amz-replication-status"), nil
+}
@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// maxRetryBackoff caps the exponential backoff between attempts
+const maxRetryBackoff = time.Minute
+
+// transientErrors are fragments of the errors worth retrying: throttling,
+// server-side errors and dropped connections
+var transientErrors = []string{
+	"SlowDown",
+	"ServiceUnavailable",
+	"InternalError",
+	"RequestTimeout",
+	"HTTP 429",
+	"HTTP 500",
+	"HTTP 502",
+	"HTTP 503",
+	"HTTP 504",
+	"connection reset",
+	"broken pipe",
+	"unexpected EOF",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"temporary failure in name resolution",
+}
+
+// IsTransient reports whether a result failed in a way that may succeed when
+// the check is run again
+func IsTransient(result output.TestResult) bool {
+	if result.Status != output.StatusFail {
+		return false
+	}
+	for _, fragment := range transientErrors {
+		if strings.Contains(result.Error, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryBackoff returns the wait before the given retry, counted from 1: the
+// base delay, doubled for each earlier retry and capped at maxRetryBackoff
+func RetryBackoff(delay time.Duration, retry int) time.Duration {
+	for i := 1; i < retry && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
@@ -0,0 +1,614 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/options"
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+	"github.com/s3-bucket-tester/s3tester/pkg/proxy"
+)
+
+const (
+	// emptyPayloadHash is the SHA256 hash of an empty payload
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	// unsignedPayload marks a SigV4 request whose body is not part of the signature
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+
+	// cleanupTimeout bounds the cleanup of a check once it was cancelled
+	cleanupTimeout = 30 * time.Second
+)
+
+// s3Client sends SigV4-signed requests to the bucket under test
+type s3Client struct {
+	config     output.Config
+	httpClient *http.Client
+	verbose    *VerboseLogger
+
+	// writeScope is the key prefix object writes and deletes must stay under
+	writeScope string
+
+	// ctx is the context of the running check, see bind
+	ctx context.Context
+
+	// cleanupCtx is ctx without its cancellation, for the cleanup that must
+	// still run when the check is interrupted, see cleanup
+	cleanupCtx context.Context
+}
+
+// newS3Client creates a new S3 client for the configured bucket
+func newS3Client(config output.Config, verbose *VerboseLogger) *s3Client {
+	return &s3Client{
+		config:     config,
+		httpClient: newHTTPClient(config),
+		verbose:    verbose,
+		writeScope: config.TestPrefix,
+		ctx:        context.Background(),
+		cleanupCtx: context.Background(),
+	}
+}
+
+// bind makes the client's requests part of a check's context, so they are
+// aborted when the run is cancelled
+func (s *s3Client) bind(ctx context.Context) {
+	s.ctx = ctx
+	s.cleanupCtx = context.WithoutCancel(ctx)
+}
+
+// cleanup runs fn, which removes what a check left in the bucket, with the
+// client's requests detached from the check's cancellation: an interrupt or
+// an exhausted --budget would otherwise abort the deletes at once and leave
+// the test objects behind. The cleanup is bounded by cleanupTimeout instead.
+func (s *s3Client) cleanup(fn func()) {
+	ctx, cancel := context.WithTimeout(withCleanup(s.cleanupCtx), cleanupTimeout)
+	defer cancel()
+
+	checkCtx := s.ctx
+	s.ctx = ctx
+	defer func() { s.ctx = checkCtx }()
+
+	fn()
+}
+
+// defaultPrefixPattern matches the run directory of options.DefaultTestPrefix, whose
+// run ID is a UTC timestamp and six hex digits
+var defaultPrefixPattern = regexp.MustCompile(`^` + options.ReservedPrefix + `-[0-9]{8}T[0-9]{6}-[0-9a-f]{6}/`)
+
+// ArtifactPrefix returns the prefix listed for artifacts of earlier runs: the
+// configured test prefix, or the one shared by all default run directories
+func ArtifactPrefix(config output.Config) string {
+	if config.CustomTestPrefix {
+		return config.TestPrefix
+	}
+	return options.ReservedPrefix + "-"
+}
+
+// artifactRunPrefix returns the test prefix of the run that wrote a listed
+// key, or an empty string when the key is not a test artifact. Keys that only
+// share the listed prefix, such as s3tester-data/..., belong to the user.
+func artifactRunPrefix(config output.Config, key string) string {
+	if config.CustomTestPrefix {
+		if strings.HasPrefix(key, config.TestPrefix) {
+			return config.TestPrefix
+		}
+		return ""
+	}
+	return defaultPrefixPattern.FindString(key)
+}
+
+// ArtifactResource returns the IAM resource pattern of the keys the tool
+// writes: the test prefix, or every default run directory. IAM patterns
+// have no character classes, so each character of the run ID is a ?.
+func ArtifactResource(config output.Config) string {
+	if config.CustomTestPrefix {
+		return config.TestPrefix + "*"
+	}
+	return options.ReservedPrefix + "-????????T??????-??????/*"
+}
+
+// newHTTPClient creates the HTTP client shared by all HTTP-based checkers
+func newHTTPClient(config output.Config) *http.Client {
+	transport := &http.Transport{
+		Proxy: proxy.Func(config.Proxy),
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: config.Insecure,
+			ServerName:         config.SNI,
+			RootCAs:            rootCAs(config),
+			Certificates:       clientCertificates(config),
+		},
+	}
+	if config.IPFamily != "" {
+		dialer := &net.Dialer{Timeout: time.Duration(config.TCPTimeout) * time.Second, KeepAlive: 30 * time.Second}
+		transport.DialContext = func(ctx context.Context, _, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, tcpNetwork(config), address)
+		}
+	}
+
+	// Trace every request when slow operations are to be reported
+	var roundTripper http.RoundTripper = transport
+	if config.SlowThresholdMs > 0 {
+		roundTripper = &tracingTransport{
+			base:      transport,
+			threshold: time.Duration(config.SlowThresholdMs) * time.Millisecond,
+		}
+	}
+
+	// Record every exchange for the support bundle
+	if config.SupportBundle != "" {
+		roundTripper = &transcriptTransport{base: roundTripper}
+	}
+
+	// Record rate-limit headers and failed cleanup requests for the result
+	// of the check
+	roundTripper = &rateLimitTransport{base: roundTripper}
+	roundTripper = &cleanupTransport{base: roundTripper}
+
+	return &http.Client{
+		Timeout:   time.Duration(config.HTTPTimeout) * time.Second,
+		Transport: roundTripper,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !config.FollowRedirect {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= config.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", config.MaxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// newPlainHTTPClient creates the client for requests to third parties, such
+// as OCSP responders and caIssuers URLs. It honors only the proxy and the
+// address family: --sni, the trusted CAs, the client certificate and the
+// recording transports of newHTTPClient are about the S3 endpoint.
+func newPlainHTTPClient(config output.Config) *http.Client {
+	dialer := &net.Dialer{Timeout: time.Duration(config.TCPTimeout) * time.Second, KeepAlive: 30 * time.Second}
+	return &http.Client{
+		Timeout: time.Duration(config.HTTPTimeout) * time.Second,
+		Transport: &http.Transport{
+			Proxy: proxy.Func(config.Proxy),
+			DialContext: func(ctx context.Context, _, address string) (net.Conn, error) {
+				return dialer.DialContext(ctx, tcpNetwork(config), address)
+			},
+		},
+	}
+}
+
+// httpTransport returns the transport of a client created by newHTTPClient,
+// beneath the cleanup, rate-limit, tracing and transcript transports
+func httpTransport(client *http.Client) *http.Transport {
+	roundTripper := client.Transport
+	for {
+		switch transport := roundTripper.(type) {
+		case *http.Transport:
+			return transport
+		case *tracingTransport:
+			return transport.base
+		case *transcriptTransport:
+			roundTripper = transport.base
+		case *rateLimitTransport:
+			roundTripper = transport.base
+		case *cleanupTransport:
+			roundTripper = transport.base
+		default:
+			return nil
+		}
+	}
+}
+
+// bucketBaseURL returns the URL addressing the bucket itself
+func bucketBaseURL(endpoint, bucket string, pathStyle bool) (*url.URL, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	host := cleanHost(endpointURL.Host, endpointURL.Scheme)
+
+	// An endpoint behind a reverse proxy may serve the S3 API under a path
+	// prefix, which precedes the bucket or the object key and is signed
+	prefix := strings.TrimSuffix(endpointURL.Path, "/")
+
+	if pathStyle {
+		// Path-style addressing: https://endpoint/prefix/bucket
+		return &url.URL{Scheme: endpointURL.Scheme, Host: host, Path: prefix + "/" + bucket}, nil
+	}
+
+	// Virtual-hosted addressing (default): https://bucket.endpoint/prefix
+	return &url.URL{Scheme: endpointURL.Scheme, Host: bucket + "." + host, Path: prefix}, nil
+}
+
+// newRequest creates a signed request for an object key (empty for the bucket)
+// with optional sub-resource query parameters and body
+func (s *s3Client) newRequest(method, key string, query url.Values, body []byte) (*http.Request, error) {
+	u, err := bucketBaseURL(s.config.Endpoint, s.config.Bucket, s.config.PathStyle)
+	if err != nil {
+		return nil, err
+	}
+
+	if key != "" {
+		if isWriteMethod(method) && (s.writeScope == "" || !strings.HasPrefix(key, s.writeScope)) {
+			return nil, fmt.Errorf("refusing to %s %q outside the test prefix %q", method, key, s.writeScope)
+		}
+		u.Path += "/" + key
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawPath = awsURIEncode(u.Path, false)
+	u.RawQuery = canonicalQueryString(query)
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	// Read-only runs never send a request that can modify the bucket
+	if s.config.ReadOnly && isWriteMethod(method) {
+		return nil, fmt.Errorf("refusing to %s %s in read-only mode", method, u.Path)
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, method, u.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "s3-bucket-tester/1.0")
+
+	return req, nil
+}
+
+// sign adds AWS Signature Version 4 authentication to the request and
+// returns the canonical request and string-to-sign it signed.
+// The payload hash is taken from X-Amz-Content-Sha256 when already set.
+func (s *s3Client) sign(req *http.Request, body []byte) (string, string) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		if len(body) == 0 {
+			payloadHash = emptyPayloadHash
+		} else {
+			sum := sha256.Sum256(body)
+			payloadHash = hex.EncodeToString(sum[:])
+		}
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+
+	canonicalHeaders, signedHeaders := canonicalHeaderBlock(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsURIEncode(req.URL.Path, false),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, s.config.Region)
+	stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s\n%s",
+		amzDate,
+		credentialScope,
+		hashSHA256(canonicalRequest))
+
+	signingKey := deriveSigningKey(s.config.SecretKey, dateStamp, s.config.Region, "s3")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	s.verbose.LogSigning(canonicalRequest, stringToSign, credentialScope)
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey,
+		credentialScope,
+		signedHeaders,
+		signature))
+
+	return canonicalRequest, stringToSign
+}
+
+// presign returns a SigV4 query-string presigned URL for a request of an
+// object key that is valid for expires. Only the host header is signed and
+// the payload is unsigned, as SDK presigners do.
+func (s *s3Client) presign(method, key string, expires time.Duration) (string, error) {
+	return s.presignAt(method, key, expires, time.Now())
+}
+
+// presignAt returns a presigned URL signed at the given time, so a URL that
+// has already expired can be made without waiting
+func (s *s3Client) presignAt(method, key string, expires time.Duration, signedAt time.Time) (string, error) {
+	u, err := bucketBaseURL(s.config.Endpoint, s.config.Bucket, s.config.PathStyle)
+	if err != nil {
+		return "", err
+	}
+	u.Path += "/" + key
+	u.RawPath = awsURIEncode(u.Path, false)
+
+	now := signedAt.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, s.config.Region)
+
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {s.config.AccessKey + "/" + credentialScope},
+		"X-Amz-Date":          {amzDate},
+		"X-Amz-Expires":       {fmt.Sprintf("%d", int(expires.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	if s.config.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		awsURIEncode(u.Path, false),
+		canonicalQueryString(query),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s\n%s",
+		amzDate,
+		credentialScope,
+		hashSHA256(canonicalRequest))
+
+	signingKey := deriveSigningKey(s.config.SecretKey, dateStamp, s.config.Region, "s3")
+	query.Set("X-Amz-Signature", hex.EncodeToString(hmacSHA256(signingKey, stringToSign)))
+	u.RawQuery = canonicalQueryString(query)
+	s.verbose.LogSigning(canonicalRequest, stringToSign, credentialScope)
+
+	return u.String(), nil
+}
+
+// postPolicyForm returns the form fields of a browser-based POST upload whose
+// SigV4-signed policy allows keys starting with keyPrefix and files of up to
+// maxSize bytes for expires. The key and file fields are left to the caller.
+func (s *s3Client) postPolicyForm(keyPrefix string, maxSize int, expires time.Duration) (url.Values, error) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	credential := fmt.Sprintf("%s/%s/%s/s3/aws4_request", s.config.AccessKey, dateStamp, s.config.Region)
+
+	conditions := []interface{}{
+		map[string]string{"bucket": s.config.Bucket},
+		[]interface{}{"starts-with", "$key", keyPrefix},
+		[]interface{}{"content-length-range", 1, maxSize},
+		map[string]string{"success_action_status": "201"},
+		map[string]string{"x-amz-algorithm": "AWS4-HMAC-SHA256"},
+		map[string]string{"x-amz-credential": credential},
+		map[string]string{"x-amz-date": amzDate},
+	}
+	if s.config.SessionToken != "" {
+		conditions = append(conditions, map[string]string{"x-amz-security-token": s.config.SessionToken})
+	}
+	policy, err := json.Marshal(map[string]interface{}{
+		"expiration": now.Add(expires).Format("2006-01-02T15:04:05.000Z"),
+		"conditions": conditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(policy)
+
+	signingKey := deriveSigningKey(s.config.SecretKey, dateStamp, s.config.Region, "s3")
+	form := url.Values{
+		"policy":                {encoded},
+		"success_action_status": {"201"},
+		"x-amz-algorithm":       {"AWS4-HMAC-SHA256"},
+		"x-amz-credential":      {credential},
+		"x-amz-date":            {amzDate},
+		"x-amz-signature":       {hex.EncodeToString(hmacSHA256(signingKey, encoded))},
+	}
+	if s.config.SessionToken != "" {
+		form.Set("x-amz-security-token", s.config.SessionToken)
+	}
+	return form, nil
+}
+
+// do signs and sends the request, returning the response with its body read
+func (s *s3Client) do(req *http.Request, body []byte) (*http.Response, []byte, error) {
+	canonicalRequest, stringToSign := s.sign(req, body)
+	s.verbose.LogRequest(req)
+
+	if isWriteMethod(req.Method) {
+		atomic.AddUint64(&writeRequests, 1)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	s.verbose.LogResponse(resp)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, nil, err
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		s.verbose.LogSignatureMismatch(canonicalRequest, stringToSign, respBody)
+	}
+
+	return resp, respBody, nil
+}
+
+// getObject downloads an object, returning an error unless the server returns it
+func (s *s3Client) getObject(key string) (*http.Response, []byte, error) {
+	return s.getObjectWithHeaders(key, nil)
+}
+
+// getObjectWithHeaders downloads an object sending additional request headers
+func (s *s3Client) getObjectWithHeaders(key string, header http.Header) (*http.Response, []byte, error) {
+	req, err := s.newRequest("GET", key, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	resp, body, err := s.do(req, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, body, fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, body))
+	}
+
+	return resp, body, nil
+}
+
+// deleteObject deletes an object, returning an error unless the server confirms it
+func (s *s3Client) deleteObject(key string) error {
+	req, err := s.newRequest("DELETE", key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, body, err := s.do(req, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, body))
+	}
+
+	return nil
+}
+
+// canonicalHeaderBlock returns the SigV4 canonical headers and signed header list.
+// Host, Content-Type, Content-MD5 and all x-amz-* headers are signed.
+func canonicalHeaderBlock(req *http.Request) (string, string) {
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "content-md5" || strings.HasPrefix(lower, "x-amz-") {
+			trimmed := make([]string, len(values))
+			for i, v := range values {
+				trimmed[i] = strings.Join(strings.Fields(v), " ")
+			}
+			headers[lower] = strings.Join(trimmed, ",")
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name)
+		sb.WriteString(":")
+		sb.WriteString(headers[name])
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), strings.Join(names, ";")
+}
+
+// canonicalQueryString returns the sorted, URI-encoded SigV4 query string
+func canonicalQueryString(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, awsURIEncode(key, true)+"="+awsURIEncode(value, true))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// awsURIEncode encodes a string as specified for SigV4; slashes are kept
+// unless encodeSlash is set
+func awsURIEncode(s string, encodeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch >= 'A' && ch <= 'Z', ch >= 'a' && ch <= 'z', ch >= '0' && ch <= '9',
+			ch == '-', ch == '_', ch == '.', ch == '~':
+			sb.WriteByte(ch)
+		case ch == '/' && !encodeSlash:
+			sb.WriteByte(ch)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", ch)
+		}
+	}
+	return sb.String()
+}
+
+// deriveSigningKey derives the SigV4 signing key for a service
+func deriveSigningKey(secretKey, dateStamp, region, service string) []byte {
+	kDate := hmacSHA256([]byte("AWS4"+secretKey), dateStamp)
+	kRegion := hmacSHA256(kDate, region)
+	kService := hmacSHA256(kRegion, service)
+	return hmacSHA256(kService, "aws4_request")
+}
+
+// testObjectKey returns a unique key under the run's test prefix
+func (s *s3Client) testObjectKey(name string) string {
+	return fmt.Sprintf("%s%s-%d", s.config.TestPrefix, name, time.Now().UnixNano())
+}
+
+// isWriteMethod reports whether a request method can modify an object
+func isWriteMethod(method string) bool {
+	switch method {
+	case "PUT", "POST", "DELETE", "PATCH":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseErrorResponse extracts the S3 error code and message from a response
+// body; maintenance and other HTML pages are reported as such
+func parseErrorResponse(statusCode int, body []byte) string {
+	var errResp ErrorResponse
+	if err := xml.Unmarshal(body, &errResp); err == nil && errResp.Code != "" {
+		return fmt.Sprintf("%s: %s", errResp.Code, errResp.Message)
+	}
+	if desc := describeNonS3Response(statusCode, nil, body); desc != "" {
+		return desc
+	}
+	return fmt.Sprintf("HTTP %d", statusCode)
+}
@@ -0,0 +1,315 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// SDKParityChecker sends key operations both with s3tester's hand-rolled
+// signer and with the official AWS SDK for Go and compares the outcomes, so
+// a failure can be placed on the endpoint's side or on this tool's
+type SDKParityChecker struct {
+	BaseChecker
+	client  *s3Client
+	sdk     *s3.Client
+	verbose *VerboseLogger
+}
+
+// NewSDKParityChecker creates a new SDK parity checker
+func NewSDKParityChecker(config output.Config) *SDKParityChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &SDKParityChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		sdk:         newSDKClient(config),
+		verbose:     verbose,
+	}
+}
+
+// newSDKClient creates an AWS SDK S3 client for the configured endpoint and
+// credentials. It shares the HTTP client settings of the other checkers, so
+// proxy, TLS and timeouts are the same, and never retries, so each outcome
+// is that of a single request like s3tester's.
+func newSDKClient(config output.Config) *s3.Client {
+	var credentials aws.CredentialsProvider = aws.AnonymousCredentials{}
+	if config.AccessKey != "" {
+		credentials = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{
+				AccessKeyID:     config.AccessKey,
+				SecretAccessKey: config.SecretKey,
+				SessionToken:    config.SessionToken,
+				Source:          "s3tester",
+			}, nil
+		})
+	}
+
+	return s3.New(s3.Options{
+		Region:       config.Region,
+		BaseEndpoint: aws.String(config.Endpoint),
+		UsePathStyle: config.PathStyle,
+		Credentials:  credentials,
+		HTTPClient:   newHTTPClient(config),
+		Retryer:      aws.NopRetryer{},
+	})
+}
+
+// Name returns the name of the checker
+func (c *SDKParityChecker) Name() string {
+	return "SDK Parity Check"
+}
+
+// parityOperation is an operation sent with both clients. Object operations
+// work on a test object, which read-only runs do not write.
+type parityOperation struct {
+	name   string
+	method string
+	key    string
+	query  url.Values
+	body   []byte
+	sdk    func(ctx context.Context) (middleware.Metadata, error)
+	object bool
+}
+
+// Check performs the SDK parity check
+func (c *SDKParityChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting SDK Parity Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	bucket := aws.String(c.Config.Bucket)
+	key := c.client.testObjectKey("sdk-parity")
+	body := []byte("s3tester SDK parity test\n")
+
+	operations := []parityOperation{
+		{
+			name:   "HeadBucket",
+			method: "HEAD",
+			sdk: func(ctx context.Context) (middleware.Metadata, error) {
+				out, err := c.sdk.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: bucket})
+				if err != nil {
+					return middleware.Metadata{}, err
+				}
+				return out.ResultMetadata, nil
+			},
+		},
+		{
+			name:   "ListObjectsV2",
+			method: "GET",
+			query:  url.Values{"list-type": {"2"}, "max-keys": {"1"}, "prefix": {c.Config.TestPrefix}},
+			sdk: func(ctx context.Context) (middleware.Metadata, error) {
+				out, err := c.sdk.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+					Bucket:  bucket,
+					MaxKeys: aws.Int32(1),
+					Prefix:  aws.String(c.Config.TestPrefix),
+				})
+				if err != nil {
+					return middleware.Metadata{}, err
+				}
+				return out.ResultMetadata, nil
+			},
+		},
+		{
+			name:   "PutObject",
+			method: "PUT",
+			key:    key,
+			body:   body,
+			object: true,
+			sdk: func(ctx context.Context) (middleware.Metadata, error) {
+				out, err := c.sdk.PutObject(ctx, &s3.PutObjectInput{
+					Bucket:        bucket,
+					Key:           aws.String(key),
+					Body:          bytes.NewReader(body),
+					ContentLength: aws.Int64(int64(len(body))),
+				})
+				if err != nil {
+					return middleware.Metadata{}, err
+				}
+				return out.ResultMetadata, nil
+			},
+		},
+		{
+			name:   "GetObject",
+			method: "GET",
+			key:    key,
+			object: true,
+			sdk: func(ctx context.Context) (middleware.Metadata, error) {
+				out, err := c.sdk.GetObject(ctx, &s3.GetObjectInput{Bucket: bucket, Key: aws.String(key)})
+				if err != nil {
+					return middleware.Metadata{}, err
+				}
+				defer out.Body.Close()
+				if _, err := io.Copy(io.Discard, out.Body); err != nil {
+					return middleware.Metadata{}, err
+				}
+				return out.ResultMetadata, nil
+			},
+		},
+		{
+			name:   "DeleteObject",
+			method: "DELETE",
+			key:    key,
+			object: true,
+			sdk: func(ctx context.Context) (middleware.Metadata, error) {
+				out, err := c.sdk.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: bucket, Key: aws.String(key)})
+				if err != nil {
+					return middleware.Metadata{}, err
+				}
+				return out.ResultMetadata, nil
+			},
+		},
+	}
+
+	parityResult := output.SDKParityResult{Verdict: output.ParityConsistent}
+	var put parityOperation
+	for _, op := range operations {
+		if op.object && c.Config.ReadOnly {
+			continue
+		}
+
+		internal := c.internalOutcome(op)
+
+		// s3tester's DELETE removed the test object; write it again so the
+		// SDK's DELETE finds the same state
+		switch op.method {
+		case "PUT":
+			put = op
+		case "DELETE":
+			c.internalOutcome(put)
+		}
+		if isWriteMethod(op.method) {
+			atomic.AddUint64(&writeRequests, 1)
+		}
+		metadata, err := op.sdk(ctx)
+		sdk := sdkOutcome(metadata, err)
+
+		comparison := output.ParityOperation{
+			Operation: op.name,
+			S3Tester:  internal,
+			SDK:       sdk,
+			Match:     outcomesMatch(internal, sdk),
+		}
+		c.verbose.LogMessage("%s: s3tester %s, AWS SDK %s", op.name, internal, sdk)
+		parityResult.Operations = append(parityResult.Operations, comparison)
+
+		if !comparison.Match && parityResult.Verdict == output.ParityConsistent {
+			switch {
+			case sdk.Succeeded():
+				parityResult.Verdict = output.ParityS3TesterArtifact
+			case internal.Succeeded():
+				parityResult.Verdict = output.ParitySDKOnly
+			default:
+				parityResult.Verdict = output.ParityDifferent
+			}
+			result.Error = fmt.Sprintf("%s: s3tester got %s, the AWS SDK got %s", op.name, internal, sdk)
+		}
+	}
+
+	switch parityResult.Verdict {
+	case output.ParityS3TesterArtifact:
+		result.Status = output.StatusFail
+		result.Error += "; the failure is an artifact of s3tester's signing, not of the endpoint"
+	case output.ParitySDKOnly:
+		result.Status = output.StatusWarn
+		result.Error += "; applications using the AWS SDK may fail where s3tester passes"
+	case output.ParityDifferent:
+		result.Status = output.StatusWarn
+	}
+
+	// Remove the test object in case the SDK's DELETE failed
+	if !c.Config.ReadOnly {
+		c.client.cleanup(func() {
+			if err := c.client.deleteObject(key); err != nil {
+				c.verbose.LogMessage("Failed to delete test object %s: %v", key, err)
+			}
+		})
+	}
+
+	result.Details = parityResult
+	result.Duration = time.Since(startTime)
+	return result
+}
+
+// internalOutcome sends an operation with s3tester's own client
+func (c *SDKParityChecker) internalOutcome(op parityOperation) output.ParityOutcome {
+	req, err := c.client.newRequest(op.method, op.key, op.query, op.body)
+	if err != nil {
+		return output.ParityOutcome{Error: err.Error()}
+	}
+	if op.body != nil {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+
+	resp, respBody, err := c.client.do(req, op.body)
+	if err != nil {
+		return output.ParityOutcome{Error: err.Error()}
+	}
+
+	outcome := output.ParityOutcome{StatusCode: resp.StatusCode}
+	if resp.StatusCode >= 300 {
+		var errResp ErrorResponse
+		if xml.Unmarshal(respBody, &errResp) == nil {
+			outcome.ErrorCode = errResp.Code
+		}
+		outcome.Error = parseErrorResponse(resp.StatusCode, respBody)
+	}
+	return outcome
+}
+
+// sdkOutcome converts the result of an AWS SDK operation
+func sdkOutcome(metadata middleware.Metadata, err error) output.ParityOutcome {
+	if err == nil {
+		outcome := output.ParityOutcome{StatusCode: http.StatusOK}
+		if raw, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+			outcome.StatusCode = raw.StatusCode
+		}
+		return outcome
+	}
+
+	outcome := output.ParityOutcome{Error: err.Error()}
+	var responseErr *smithyhttp.ResponseError
+	if errors.As(err, &responseErr) {
+		outcome.StatusCode = responseErr.HTTPStatusCode()
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && outcome.StatusCode != 0 {
+		outcome.ErrorCode = apiErr.ErrorCode()
+	}
+	return outcome
+}
+
+// outcomesMatch reports whether two clients got the same outcome: the same
+// status, and the same error code when both parsed one. The SDK names the
+// errors of bodiless HEAD responses, such as NotFound, which s3tester does
+// not, so a code on one side only does not count as a difference.
+func outcomesMatch(internal, sdk output.ParityOutcome) bool {
+	if internal.Succeeded() != sdk.Succeeded() || internal.StatusCode != sdk.StatusCode {
+		return false
+	}
+	if internal.ErrorCode != "" && sdk.ErrorCode != "" && !strings.EqualFold(internal.ErrorCode, sdk.ErrorCode) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,248 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// slowestKept is the number of slowest operations kept for the report
+const slowestKept = 10
+
+// operationLabelKey is the context key of the operation label
+type operationLabelKey struct{}
+
+// WithOperationLabel labels the HTTP requests sent with ctx, so traced
+// operations can be attributed to the check that sent them
+func WithOperationLabel(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, operationLabelKey{}, label)
+}
+
+// operations collects the traced HTTP operations of all checkers
+var operations struct {
+	sync.Mutex
+
+	// slowest holds the slowest operations seen so far, slowest first
+	slowest []output.HTTPOperation
+
+	// slow holds the operations over the threshold by label until
+	// TakeSlowOperations hands them to the check's result
+	slow map[string][]output.HTTPOperation
+}
+
+// SlowestOperations returns the slowest traced HTTP operations of the
+// process, slowest first
+func SlowestOperations() []output.HTTPOperation {
+	operations.Lock()
+	defer operations.Unlock()
+
+	return append([]output.HTTPOperation(nil), operations.slowest...)
+}
+
+// TakeSlowOperations returns the operations over the threshold recorded under
+// label and forgets them
+func TakeSlowOperations(label string) []output.HTTPOperation {
+	operations.Lock()
+	defer operations.Unlock()
+
+	slow := operations.slow[label]
+	delete(operations.slow, label)
+	return slow
+}
+
+// recordOperation adds a finished operation to the slowest list and, when it
+// is slow, to the slow operations of its label
+func recordOperation(op output.HTTPOperation) {
+	operations.Lock()
+	defer operations.Unlock()
+
+	if op.Slow {
+		if operations.slow == nil {
+			operations.slow = make(map[string][]output.HTTPOperation)
+		}
+		operations.slow[op.Check] = append(operations.slow[op.Check], op)
+	}
+
+	operations.slowest = append(operations.slowest, op)
+	sort.SliceStable(operations.slowest, func(i, j int) bool {
+		return operations.slowest[i].TotalMs > operations.slowest[j].TotalMs
+	})
+	if len(operations.slowest) > slowestKept {
+		operations.slowest = operations.slowest[:slowestKept]
+	}
+}
+
+// tracingTransport times every request it sends with httptrace, including
+// reading the response body, and records the operation
+type tracingTransport struct {
+	base      *http.Transport
+	threshold time.Duration
+}
+
+// RoundTrip sends the request and wraps the response body, so the operation
+// is recorded once the body has been read or closed
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	label, _ := req.Context().Value(operationLabelKey{}).(string)
+	op := &tracedOperation{
+		threshold: t.threshold,
+		op: output.HTTPOperation{
+			Check:  label,
+			Method: req.Method,
+			Host:   req.URL.Host,
+			Path:   operationPath(req),
+		},
+	}
+
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), op.trace()))
+	op.start = time.Now()
+	op.op.StartTime = op.start.UTC()
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		op.finish(err)
+		return nil, err
+	}
+	op.op.StatusCode = resp.StatusCode
+	resp.Body = &tracedBody{ReadCloser: resp.Body, op: op}
+
+	return resp, nil
+}
+
+// operationPath returns the request path with the names of its sub-resource
+// query parameters, without values that may carry signatures
+func operationPath(req *http.Request) string {
+	query := req.URL.Query()
+	if len(query) == 0 {
+		return req.URL.Path
+	}
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return req.URL.Path + "?" + strings.Join(names, "&")
+}
+
+// tracedOperation accumulates the phase timestamps of one request
+type tracedOperation struct {
+	threshold time.Duration
+	op        output.HTTPOperation
+	once      sync.Once
+
+	// mu guards the timestamps, which httptrace may set from other goroutines
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	firstByte    time.Time
+}
+
+// trace returns the httptrace hooks recording the phase timestamps
+func (o *tracedOperation) trace() *httptrace.ClientTrace {
+	at := func(t *time.Time) {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		*t = time.Now()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { at(&o.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { at(&o.dnsDone) },
+		ConnectStart: func(string, string) {
+			// Happy eyeballs may start several dials; time from the first
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			if o.connectStart.IsZero() {
+				o.connectStart = time.Now()
+			}
+		},
+		ConnectDone:       func(string, string, error) { at(&o.connectDone) },
+		TLSHandshakeStart: func() { at(&o.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { at(&o.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			o.op.ConnectionReused = info.Reused
+		},
+		GotFirstResponseByte: func() { at(&o.firstByte) },
+	}
+}
+
+// finish computes the phase durations, logs the operation when it exceeds
+// the threshold and records it; only the first call has an effect
+func (o *tracedOperation) finish(err error) {
+	o.once.Do(func() {
+		done := time.Now()
+
+		o.mu.Lock()
+		op := o.op
+		if !o.dnsDone.IsZero() {
+			op.DNSMs = durationToMs(o.dnsDone.Sub(o.dnsStart))
+		}
+		if !o.connectDone.IsZero() {
+			op.ConnectMs = durationToMs(o.connectDone.Sub(o.connectStart))
+		}
+		if !o.tlsDone.IsZero() {
+			op.TLSHandshakeMs = durationToMs(o.tlsDone.Sub(o.tlsStart))
+		}
+		if !o.firstByte.IsZero() {
+			op.TTFBMs = durationToMs(o.firstByte.Sub(o.start))
+			op.TransferMs = durationToMs(done.Sub(o.firstByte))
+		}
+		o.mu.Unlock()
+
+		total := done.Sub(o.start)
+		op.TotalMs = durationToMs(total)
+		if err != nil && err != io.EOF {
+			op.Error = err.Error()
+		}
+		op.Slow = total >= o.threshold
+
+		if op.Slow {
+			label := op.Check
+			if label == "" {
+				label = "-"
+			}
+			fmt.Fprintf(os.Stderr, "Slow operation [%s]: %s %s%s %d, %s\n",
+				label, op.Method, op.Host, op.Path, op.StatusCode, op.Breakdown())
+		}
+
+		recordOperation(op)
+	})
+}
+
+// tracedBody finishes the operation once the response body is exhausted or
+// closed
+type tracedBody struct {
+	io.ReadCloser
+	op *tracedOperation
+}
+
+// Read reads from the body and finishes the operation at the end of it
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.op.finish(err)
+	}
+	return n, err
+}
+
+// Close closes the body and finishes the operation if reading did not
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.op.finish(nil)
+	return err
+}
@@ -0,0 +1,124 @@
+package checker
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// probeSNIVariants connects without SNI, both by hostname and by each resolved
+// IP address family, and reports which certificate the server presents
+func (c *TLSChecker) probeSNIVariants(ctx context.Context, sniCert *x509.Certificate) []output.SNIProbeResult {
+	c.verbose.LogMessage("Probing certificate selection without SNI...")
+
+	timeout := time.Duration(c.Config.TCPTimeout) * time.Second
+	sniFingerprint := certFingerprint(sniCert)
+	port := strconv.Itoa(c.Port)
+
+	var probes []output.SNIProbeResult
+
+	// No SNI, connecting via the hostname
+	if net.ParseIP(c.Host) == nil {
+		probes = append(probes, c.probeCertificate(ctx, "No SNI", net.JoinHostPort(c.Host, port), sniFingerprint, timeout))
+	}
+
+	// No SNI, connecting by raw IP (what SDKs configured with an IP endpoint do)
+	for _, ip := range c.probeIPs(ctx, timeout) {
+		probes = append(probes, c.probeCertificate(ctx, "Raw IP", net.JoinHostPort(ip.String(), port), sniFingerprint, timeout))
+	}
+
+	return probes
+}
+
+// probeIPs returns the first resolved address of each family for the host
+func (c *TLSChecker) probeIPs(ctx context.Context, timeout time.Duration) []net.IP {
+	if ip := net.ParseIP(c.Host); ip != nil {
+		return []net.IP{ip}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addrs, err := lookupIPAddr(ctx, c.Config, c.Host)
+	if err != nil {
+		c.verbose.LogMessage("Could not resolve %s for IP probe: %v", c.Host, err)
+		return nil
+	}
+
+	var v4, v6 net.IP
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil && v4 == nil {
+			v4 = addr.IP
+		} else if addr.IP.To4() == nil && v6 == nil {
+			v6 = addr.IP
+		}
+	}
+
+	var ips []net.IP
+	for _, ip := range []net.IP{v4, v6} {
+		if ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// probeCertificate performs a handshake to address without sending SNI
+func (c *TLSChecker) probeCertificate(ctx context.Context, variant, address, sniFingerprint string, timeout time.Duration) output.SNIProbeResult {
+	probe := output.SNIProbeResult{
+		Variant: variant,
+		Address: address,
+	}
+
+	// An empty ServerName means no SNI extension is sent; verification is
+	// done manually below against the hostname and the IP
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS12,
+		Certificates:       clientCertificates(c.Config),
+	}
+
+	conn, err := dialTLS(ctx, c.Config, address, timeout, tlsConfig)
+	if err != nil {
+		c.verbose.LogMessage("%s probe to %s failed: %v", variant, address, err)
+		probe.Error = err.Error()
+		return probe
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		probe.Error = "no certificate presented"
+		return probe
+	}
+
+	cert := state.PeerCertificates[0]
+	probe.Subject = cert.Subject.String()
+	probe.Fingerprint = certFingerprint(cert)
+	probe.MatchesSNICert = probe.Fingerprint == sniFingerprint
+	probe.CoversHostname = cert.VerifyHostname(serverName(c.Config, c.Host)) == nil
+
+	if host, _, err := net.SplitHostPort(address); err == nil && net.ParseIP(host) != nil {
+		probe.CoversIP = cert.VerifyHostname(host) == nil
+	}
+
+	c.verbose.LogMessage("%s probe to %s: %s (same as SNI certificate: %v)", variant, address, probe.Subject, probe.MatchesSNICert)
+
+	return probe
+}
+
+// certFingerprint returns the SHA-256 fingerprint of a certificate
+func certFingerprint(cert *x509.Certificate) string {
+	if cert == nil {
+		return ""
+	}
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
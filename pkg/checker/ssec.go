@@ -0,0 +1,199 @@
+package checker
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// SSE-C outcomes as reported by the SSE-C check
+const (
+	SSECSupported = "supported"
+	SSECIgnored   = "ignored"
+	SSECRejected  = "rejected"
+	SSECBroken    = "broken"
+)
+
+// ssecAlgorithm is the only algorithm S3 accepts for customer-provided keys
+const ssecAlgorithm = "AES256"
+
+// SSECChecker verifies that the provider encrypts an object with a
+// customer-provided key (SSE-C) and serves it only with that key
+type SSECChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewSSECChecker creates a new SSE-C checker
+func NewSSECChecker(config output.Config) *SSECChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &SSECChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *SSECChecker) Name() string {
+	return "SSE-C Check"
+}
+
+// ssecResponse is the part of a response the steps compare
+type ssecResponse struct {
+	status    int
+	algorithm string
+	keyMD5    string
+	body      []byte
+	code      string
+}
+
+// details is what the step reports of the response besides its status
+func (r ssecResponse) details() string {
+	if r.algorithm == "" {
+		return ""
+	}
+	return ", " + r.algorithm
+}
+
+// Check uploads a test object encrypted with a random customer key and reads
+// it back without the key, with another key and with the right one. Only the
+// last read may succeed: a provider that serves the object without the key
+// has ignored the SSE-C headers and stored it unencrypted. The check is
+// skipped on plain HTTP endpoints, which providers refuse to send keys over.
+func (c *SSECChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting SSE-C Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	if !strings.HasPrefix(c.Config.Endpoint, "https://") {
+		result.Status = output.StatusSkip
+		result.Error = "SSE-C needs an HTTPS endpoint: providers refuse customer keys sent over plain HTTP"
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	key := c.client.testObjectKey("sse-c")
+	ssecResult := output.SSECResult{Key: key, Algorithm: ssecAlgorithm}
+
+	// A fresh key per run: the object is deleted again, so it never needs
+	// to be kept
+	customerKey, otherKey := make([]byte, 32), make([]byte, 32)
+	rand.Read(customerKey)
+	rand.Read(otherKey)
+	body := []byte("s3tester SSE-C test\n")
+
+	put, err := c.send("PUT", key, customerKey, body)
+	putStep := newStep(c.verbose, "PUT with customer key", "2xx echoing the algorithm and key MD5", put.status, put.code, err,
+		put.status < 300 && put.algorithm == ssecAlgorithm && put.keyMD5 == keyMD5(customerKey), put.details())
+	ssecResult.Steps = append(ssecResult.Steps, putStep)
+	if err != nil || put.status >= 300 {
+		ssecResult.Outcome = SSECRejected
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the provider rejected the SSE-C upload: %s", putStep.Observed)
+		result.Details = ssecResult
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	// The object exists from here on; remove it whatever step the check stops at
+	defer c.client.cleanup(func() {
+		if err := c.client.deleteObject(key); err != nil {
+			c.verbose.LogMessage("Failed to delete %s: %v", key, err)
+		}
+	})
+
+	plain, err := c.send("GET", key, nil, nil)
+	ssecResult.Steps = append(ssecResult.Steps, newStep(c.verbose, "GET without key", "400, the object needs its key", plain.status, plain.code, err,
+		plain.status == http.StatusBadRequest, plain.details()))
+
+	wrong, err := c.send("GET", key, otherKey, nil)
+	ssecResult.Steps = append(ssecResult.Steps, newStep(c.verbose, "GET with another key", "403, the key does not match", wrong.status, wrong.code, err,
+		wrong.status == http.StatusForbidden, wrong.details()))
+
+	get, err := c.send("GET", key, customerKey, nil)
+	getStep := newStep(c.verbose, "GET with customer key", "200 with the uploaded content", get.status, get.code, err,
+		get.status == http.StatusOK && string(get.body) == string(body) && get.algorithm == ssecAlgorithm, get.details())
+	ssecResult.Steps = append(ssecResult.Steps, getStep)
+
+	var mismatched []string
+	for _, step := range ssecResult.Steps {
+		if !step.Match {
+			mismatched = append(mismatched, step.Step)
+		}
+	}
+
+	switch {
+	case plain.status == http.StatusOK && string(plain.body) == string(body):
+		ssecResult.Outcome = SSECIgnored
+		result.Status = output.StatusFail
+		result.Error = "the provider ignored the SSE-C headers: the object was served without its key, so it is not encrypted with the customer key"
+	case len(mismatched) > 0:
+		ssecResult.Outcome = SSECBroken
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("SSE-C does not behave like S3: %s", strings.Join(mismatched, ", "))
+	default:
+		ssecResult.Outcome = SSECSupported
+	}
+
+	result.Details = ssecResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("SSE-C check completed in %v", result.Duration)
+
+	return result
+}
+
+// send sends a request for the test object with the SSE-C headers of
+// customerKey, or without them when it is nil
+func (c *SSECChecker) send(method, key string, customerKey, body []byte) (ssecResponse, error) {
+	req, err := c.client.newRequest(method, key, nil, body)
+	if err != nil {
+		return ssecResponse{}, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+	if customerKey != nil {
+		req.Header.Set("X-Amz-Server-Side-Encryption-Customer-Algorithm", ssecAlgorithm)
+		req.Header.Set("X-Amz-Server-Side-Encryption-Customer-Key", base64.StdEncoding.EncodeToString(customerKey))
+		req.Header.Set("X-Amz-Server-Side-Encryption-Customer-Key-Md5", keyMD5(customerKey))
+	}
+
+	resp, respBody, err := c.client.do(req, body)
+	if err != nil {
+		return ssecResponse{}, err
+	}
+
+	response := ssecResponse{
+		status:    resp.StatusCode,
+		algorithm: resp.Header.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm"),
+		keyMD5:    resp.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5"),
+		code:      errorCode(respBody),
+	}
+	if resp.StatusCode < 300 {
+		response.body = respBody
+	}
+	return response, nil
+}
+
+// keyMD5 returns the base64 MD5 digest of a customer key, which S3 uses to
+// verify the key arrived intact
+func keyMD5(customerKey []byte) string {
+	sum := md5.Sum(customerKey)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,38 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// newStep records the outcome of one request of a check against its
+// expectation. The request error is observed if there was one, or else the
+// status code and S3 error code followed by the details, which are appended
+// as given, such as ", version 3HL4kqtJ".
+func newStep(verbose *VerboseLogger, name, expected string, status int, code string, err error, match bool, details ...string) output.Step {
+	step := output.Step{Step: name, Expected: expected, Match: err == nil && match}
+	if err != nil {
+		step.Observed = err.Error()
+	} else {
+		step.Observed = fmt.Sprintf("%d", status)
+		if code != "" {
+			step.Observed += " " + code
+		}
+		for _, detail := range details {
+			step.Observed += detail
+		}
+	}
+
+	verbose.LogMessage("%s: expected %s, observed %s", name, expected, step.Observed)
+
+	return step
+}
+
+// versionDetail is the step detail of a response that carried a version ID
+func versionDetail(versionID string) string {
+	if versionID == "" {
+		return ""
+	}
+	return ", version " + versionID
+}
@@ -0,0 +1,278 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+const (
+	// maxTranscriptExchanges bounds the HTTP exchanges kept for the support
+	// bundle
+	maxTranscriptExchanges = 500
+
+	// maxTranscriptBody is the number of response body bytes kept per exchange
+	maxTranscriptBody = 4096
+)
+
+// Sensitive headers and query parameters, replaced in transcripts
+var (
+	redactedHeaders = []string{
+		"X-Amz-Security-Token",
+		"X-Amz-Server-Side-Encryption-Customer-Key",
+		"X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key",
+		"Proxy-Authorization",
+		"Cookie",
+		"Set-Cookie",
+	}
+	redactedQuery = []string{
+		"X-Amz-Signature",
+		"X-Amz-Security-Token",
+		"Signature",
+		"x-amz-security-token",
+	}
+	credentialQuery = []string{
+		"X-Amz-Credential",
+		"AWSAccessKeyId",
+	}
+
+	sigV4Signature  = regexp.MustCompile(`Signature=[^,\s]+`)
+	sigV4Credential = regexp.MustCompile(`Credential=([^/,\s]+)`)
+	sigV2Auth       = regexp.MustCompile(`^AWS ([^:\s]+):\S+$`)
+)
+
+// transcript collects the HTTP exchanges of all checkers for the support
+// bundle
+var transcript struct {
+	sync.Mutex
+	entries []*transcriptEntry
+	dropped int
+}
+
+// transcriptEntry is a recorded exchange and the start of its response body
+type transcriptEntry struct {
+	mu       sync.Mutex
+	exchange output.HTTPExchange
+	body     bytes.Buffer
+	textBody bool
+}
+
+// transcriptTransport records every request it sends, sanitized, with the
+// response headers and the start of a text response body
+type transcriptTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends the request and records the exchange
+func (t *transcriptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	label, _ := req.Context().Value(operationLabelKey{}).(string)
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	entry := &transcriptEntry{exchange: output.HTTPExchange{
+		Check:          label,
+		StartTime:      time.Now().UTC(),
+		Method:         req.Method,
+		URL:            sanitizeURL(req),
+		Host:           host,
+		RequestHeaders: sanitizeHeaders(req.Header),
+	}}
+
+	transcript.Lock()
+	if len(transcript.entries) < maxTranscriptExchanges {
+		transcript.entries = append(transcript.entries, entry)
+	} else {
+		transcript.dropped++
+		entry = nil
+	}
+	transcript.Unlock()
+
+	resp, err := t.base.RoundTrip(req)
+	if entry == nil {
+		return resp, err
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if err != nil {
+		entry.exchange.Error = err.Error()
+		return nil, err
+	}
+	entry.exchange.Proto = resp.Proto
+	entry.exchange.Status = resp.Status
+	entry.exchange.ResponseHeaders = sanitizeHeaders(resp.Header)
+	entry.textBody = isTextContent(resp.Header.Get("Content-Type"))
+	resp.Body = &transcriptBody{ReadCloser: resp.Body, entry: entry}
+
+	return resp, nil
+}
+
+// transcriptBody copies the start of a text response body into its entry
+type transcriptBody struct {
+	io.ReadCloser
+	entry *transcriptEntry
+}
+
+// Read reads from the body and keeps the first bytes of a text body
+func (b *transcriptBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.entry.mu.Lock()
+		if b.entry.textBody && b.entry.body.Len() < maxTranscriptBody {
+			b.entry.body.Write(p[:min(n, maxTranscriptBody-b.entry.body.Len())])
+		}
+		b.entry.mu.Unlock()
+	}
+	return n, err
+}
+
+// isTextContent reports whether a response body is worth recording: XML
+// errors, JSON policies and HTML pages, but not object data
+func isTextContent(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, kind := range []string{"xml", "json", "html", "text/"} {
+		if strings.Contains(contentType, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeHeaders returns a copy of the headers with signatures, tokens and
+// encryption keys removed and the access key masked
+func sanitizeHeaders(header http.Header) http.Header {
+	clean := header.Clone()
+	if auth := clean.Get("Authorization"); auth != "" {
+		clean.Set("Authorization", sanitizeAuthorization(auth))
+	}
+	for _, name := range redactedHeaders {
+		if clean.Get(name) != "" {
+			clean.Set(name, "REDACTED")
+		}
+	}
+	return clean
+}
+
+// sanitizeAuthorization masks the access key and removes the signature of a
+// SigV4 or SigV2 Authorization header
+func sanitizeAuthorization(auth string) string {
+	if m := sigV2Auth.FindStringSubmatch(auth); m != nil {
+		return "AWS " + maskAccessKey(m[1]) + ":REDACTED"
+	}
+	auth = sigV4Signature.ReplaceAllString(auth, "Signature=REDACTED")
+	return sigV4Credential.ReplaceAllStringFunc(auth, func(credential string) string {
+		return "Credential=" + maskAccessKey(strings.TrimPrefix(credential, "Credential="))
+	})
+}
+
+// sanitizeURL returns the request URL with presigned signatures and tokens
+// removed and the access key masked
+func sanitizeURL(req *http.Request) string {
+	u := *req.URL
+	query := u.Query()
+	if len(query) == 0 {
+		return u.String()
+	}
+	for _, name := range redactedQuery {
+		if query.Has(name) {
+			query.Set(name, "REDACTED")
+		}
+	}
+	for _, name := range credentialQuery {
+		if value := query.Get(name); value != "" {
+			key, scope, _ := strings.Cut(value, "/")
+			if scope != "" {
+				scope = "/" + scope
+			}
+			query.Set(name, maskAccessKey(key)+scope)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// maskAccessKey masks an access key the way reports do
+func maskAccessKey(key string) string {
+	return output.Config{AccessKey: key}.Redacted().AccessKey
+}
+
+// CollectSupportData gathers the support bundle contents besides the
+// report: the recorded HTTP exchanges, fresh DNS answers for the endpoint
+// and bucket hosts, and the certificate chain the endpoint serves, fetched
+// without verification so a chain that fails to verify is included too
+func CollectSupportData(ctx context.Context, config output.Config) *output.SupportData {
+	data := &output.SupportData{}
+
+	transcript.Lock()
+	entries := append([]*transcriptEntry(nil), transcript.entries...)
+	data.DroppedExchanges = transcript.dropped
+	transcript.Unlock()
+	for _, entry := range entries {
+		entry.mu.Lock()
+		exchange := entry.exchange
+		exchange.ResponseBody = entry.body.String()
+		entry.mu.Unlock()
+		data.Exchanges = append(data.Exchanges, exchange)
+	}
+
+	hosts := []string{ParseHostname(config.Endpoint)}
+	if u, err := bucketBaseURL(config.Endpoint, config.Bucket, config.PathStyle); err == nil && u.Hostname() != hosts[0] {
+		hosts = append(hosts, u.Hostname())
+	}
+	for _, host := range hosts {
+		data.DNS = append(data.DNS, collectDNSAnswers(ctx, config, host))
+	}
+
+	if strings.HasPrefix(config.Endpoint, "https://") {
+		host := ParseHostname(config.Endpoint)
+		timeout := time.Duration(config.TCPTimeout) * time.Second
+		conn, err := dialTLS(ctx, config, net.JoinHostPort(host, strconv.Itoa(config.Port)), timeout, &tls.Config{
+			ServerName:         serverName(config, host),
+			InsecureSkipVerify: true,
+		})
+		if err != nil {
+			data.CertificateError = "could not fetch certificates: " + err.Error()
+		} else {
+			data.Certificates = conn.ConnectionState().PeerCertificates
+			conn.Close()
+		}
+	}
+
+	return data
+}
+
+// collectDNSAnswers resolves the CNAME and addresses of a host
+func collectDNSAnswers(ctx context.Context, config output.Config, host string) output.DNSAnswers {
+	answers := output.DNSAnswers{Host: host}
+	if net.ParseIP(host) != nil {
+		answers.Addresses = []string{host}
+		return answers
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.DNSTimeout)*time.Second)
+	defer cancel()
+
+	if cname, err := net.DefaultResolver.LookupCNAME(ctx, host); err == nil && strings.TrimSuffix(cname, ".") != host {
+		answers.CNAME = cname
+	}
+	addrs, err := lookupIPAddr(ctx, config, host)
+	if err != nil {
+		answers.Error = err.Error()
+		return answers
+	}
+	for _, addr := range addrs {
+		answers.Addresses = append(answers.Addresses, addr.IP.String())
+	}
+	return answers
+}
@@ -0,0 +1,314 @@
+package checker
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// tagging is the TagSet of a GetBucketTagging or GetObjectTagging response,
+// and the body of PutObjectTagging
+type tagging struct {
+	XMLName xml.Name   `xml:"Tagging"`
+	Tags    []tagEntry `xml:"TagSet>Tag"`
+}
+
+// tagEntry is one tag of a TagSet
+type tagEntry struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// tagList returns the tags of a TagSet sorted by key
+func (t tagging) tagList() []output.Tag {
+	tags := make([]output.Tag, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		tags = append(tags, output.Tag{Key: tag.Key, Value: tag.Value})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+	return tags
+}
+
+// BucketTaggingChecker reads the bucket's tags, which cost allocation
+// reports attribute the bucket's costs by
+type BucketTaggingChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewBucketTaggingChecker creates a new bucket tagging checker
+func NewBucketTaggingChecker(config output.Config) *BucketTaggingChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &BucketTaggingChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *BucketTaggingChecker) Name() string {
+	return "Bucket Tagging Check"
+}
+
+// Check sends GET ?tagging for the bucket and lists its tags. It warns when
+// the bucket has no tags, so its costs cannot be attributed, and when the
+// tags may not be read. An endpoint that does not implement bucket tagging
+// fails the check: tags are then not available for chargeback at all.
+func (c *BucketTaggingChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Bucket Tagging Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"tagging": {""}}, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketTagging failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	bucketTags := output.BucketTaggingResult{StatusCode: resp.StatusCode}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		var tags tagging
+		if err := xml.Unmarshal(body, &tags); err != nil {
+			result.Status = output.StatusFail
+			result.Error = fmt.Sprintf("GetBucketTagging returned an invalid response: %v", err)
+			result.Duration = time.Since(startTime)
+			return result
+		}
+		bucketTags.Tags = tags.tagList()
+	case errorCode(body) == "NoSuchTagSet" || errorCode(body) == "NoSuchTagSetError":
+	case resp.StatusCode == http.StatusForbidden:
+		result.Status = output.StatusWarn
+		result.Error = "GetBucketTagging denied, the bucket's tags could not be read: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	case resp.StatusCode == http.StatusNotImplemented || errorCode(body) == "NotImplemented":
+		result.Status = output.StatusFail
+		result.Error = "the endpoint does not implement bucket tagging: the bucket cannot carry cost allocation tags"
+		result.Duration = time.Since(startTime)
+		return result
+	default:
+		result.Status = output.StatusFail
+		result.Error = "GetBucketTagging failed: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	c.verbose.LogMessage("Bucket tags: %v", bucketTags.Tags)
+
+	if len(bucketTags.Tags) == 0 {
+		result.Status = output.StatusWarn
+		result.Error = "the bucket has no tags: its costs cannot be attributed by tag"
+	}
+
+	result.Details = bucketTags
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Bucket tagging check completed in %v", result.Duration)
+
+	return result
+}
+
+// ObjectTaggingChecker verifies that the provider stores, replaces and
+// reports the tags of an object
+type ObjectTaggingChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewObjectTaggingChecker creates a new object tagging checker
+func NewObjectTaggingChecker(config output.Config) *ObjectTaggingChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &ObjectTaggingChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *ObjectTaggingChecker) Name() string {
+	return "Object Tagging Check"
+}
+
+// taggingResponse is the part of a response the round trip compares
+type taggingResponse struct {
+	status int
+	code   string
+	tags   []output.Tag
+	count  string
+}
+
+// details is what the step reports of the response besides its status
+func (r taggingResponse) details() string {
+	if r.tags == nil {
+		return ""
+	}
+	return " with " + formatTags(r.tags)
+}
+
+// Check uploads a test object tagged with x-amz-tagging, reads the tags back,
+// replaces them with PUT ?tagging and reads them again, and finally compares
+// the x-amz-tagging-count of a HEAD. It fails when any step differs from S3;
+// the test object is deleted again.
+func (c *ObjectTaggingChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Object Tagging Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	key := c.client.testObjectKey("tagging")
+	tagResult := output.ObjectTaggingResult{Key: key}
+	uploadTags := []output.Tag{{Key: "s3tester", Value: "upload"}}
+	replacedTags := []output.Tag{{Key: "cost-center", Value: "s3tester"}, {Key: "run", Value: c.Config.RunID}}
+
+	header := url.Values{}
+	for _, tag := range uploadTags {
+		header.Set(tag.Key, tag.Value)
+	}
+	put, err := c.send("PUT", key, nil, []byte("s3tester tagging test\n"), http.Header{"X-Amz-Tagging": {header.Encode()}})
+	putStep := newStep(c.verbose, "PUT with x-amz-tagging", "2xx", put.status, put.code, err, put.status < 300, put.details())
+	tagResult.Steps = append(tagResult.Steps, putStep)
+	if err != nil || put.status >= 300 {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("PUT failed: %s", putStep.Observed)
+		result.Details = tagResult
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	// The object exists from here on; remove it whatever step the check stops at
+	defer c.client.cleanup(func() {
+		if err := c.client.deleteObject(key); err != nil {
+			c.verbose.LogMessage("Failed to delete %s: %v", key, err)
+		}
+	})
+
+	get, err := c.send("GET", key, url.Values{"tagging": {""}}, nil, nil)
+	tagResult.Steps = append(tagResult.Steps, newStep(c.verbose, "GET tagging", "200 with "+formatTags(uploadTags), get.status, get.code, err,
+		get.status == http.StatusOK && formatTags(get.tags) == formatTags(uploadTags), get.details()))
+
+	var body tagging
+	for _, tag := range replacedTags {
+		body.Tags = append(body.Tags, tagEntry{Key: tag.Key, Value: tag.Value})
+	}
+	document, _ := xml.Marshal(body)
+	replace, err := c.send("PUT", key, url.Values{"tagging": {""}}, document, nil)
+	tagResult.Steps = append(tagResult.Steps, newStep(c.verbose, "PUT tagging", "2xx", replace.status, replace.code, err, replace.status < 300, replace.details()))
+
+	replaced, err := c.send("GET", key, url.Values{"tagging": {""}}, nil, nil)
+	tagResult.Steps = append(tagResult.Steps, newStep(c.verbose, "GET replaced tagging", "200 with "+formatTags(replacedTags), replaced.status, replaced.code, err,
+		replaced.status == http.StatusOK && formatTags(replaced.tags) == formatTags(replacedTags), replaced.details()))
+	tagResult.Tags = replaced.tags
+
+	head, err := c.send("HEAD", key, nil, nil, nil)
+	headStep := newStep(c.verbose, "HEAD", fmt.Sprintf("x-amz-tagging-count: %d", len(replacedTags)), head.status, head.code, err,
+		head.status == http.StatusOK && head.count == fmt.Sprintf("%d", len(replacedTags)), head.details())
+	if err == nil && head.count != "" {
+		headStep.Observed += ", x-amz-tagging-count: " + head.count
+	} else if err == nil {
+		headStep.Observed += ", no x-amz-tagging-count"
+	}
+	tagResult.Steps = append(tagResult.Steps, headStep)
+
+	var mismatched []string
+	for _, step := range tagResult.Steps {
+		if !step.Match {
+			mismatched = append(mismatched, step.Step)
+		}
+	}
+	tagResult.Works = len(mismatched) == 0
+
+	if !tagResult.Works {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("object tagging does not behave like S3: %s", strings.Join(mismatched, ", "))
+	}
+
+	result.Details = tagResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Object tagging check completed in %v", result.Duration)
+
+	return result
+}
+
+// send sends a request for the test object and parses a returned TagSet
+func (c *ObjectTaggingChecker) send(method, key string, query url.Values, body []byte, header http.Header) (taggingResponse, error) {
+	req, err := c.client.newRequest(method, key, query, body)
+	if err != nil {
+		return taggingResponse{}, err
+	}
+	if body != nil && query != nil {
+		// S3 requires a checksum of tagging documents
+		sum := md5.Sum(body)
+		req.Header.Set("Content-Type", "application/xml")
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	} else if body != nil {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, respBody, err := c.client.do(req, body)
+	if err != nil {
+		return taggingResponse{}, err
+	}
+
+	response := taggingResponse{
+		status: resp.StatusCode,
+		code:   errorCode(respBody),
+		count:  resp.Header.Get("x-amz-tagging-count"),
+	}
+	if method == "GET" && resp.StatusCode == http.StatusOK {
+		var tags tagging
+		if err := xml.Unmarshal(respBody, &tags); err == nil {
+			response.tags = tags.tagList()
+		}
+	}
+	return response, nil
+}
+
+// formatTags lists tags as key=value pairs
+func formatTags(tags []output.Tag) string {
+	if len(tags) == 0 {
+		return "no tags"
+	}
+	pairs := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		pairs = append(pairs, tag.Key+"="+tag.Value)
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -1,11 +1,24 @@
 package checker
 
 import (
-	"fmt"
+	"context"
+	"math"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/s3-bucket-tester/s3tester/pkg/output"
+	"github.com/s3-bucket-tester/s3tester/pkg/proxy"
+)
+
+const (
+	// tcpSampleInterval is the pause between consecutive handshake samples
+	tcpSampleInterval = 100 * time.Millisecond
+
+	// tcpInitialRTO is the initial SYN retransmission timeout used by most
+	// TCP stacks; a handshake slower than the fastest sample by at least
+	// this much most likely needed a retransmitted SYN
+	tcpInitialRTO = time.Second
 )
 
 // TCPChecker performs TCP connectivity checks
@@ -32,7 +45,7 @@ func (c *TCPChecker) Name() string {
 }
 
 // Check performs the TCP connectivity check
-func (c *TCPChecker) Check() output.TestResult {
+func (c *TCPChecker) Check(ctx context.Context) output.TestResult {
 	startTime := time.Now()
 
 	c.verbose.LogSection("Starting TCP Connectivity Check")
@@ -44,16 +57,24 @@ func (c *TCPChecker) Check() output.TestResult {
 	}
 
 	// Create address
-	address := fmt.Sprintf("%s:%d", c.Host, c.Port)
+	address := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
 
 	c.verbose.LogMessage("Attempting TCP connection to: %s", address)
-	c.verbose.LogMessage("Timeout: %ds", c.Config.Timeout)
+	c.verbose.LogMessage("Timeout: %ds", c.Config.TCPTimeout)
+
+	// Through a proxy the connection is a tunnel opened by the proxy
+	proxyURL := dialProxy(c.Config)
+	if proxyURL != nil {
+		c.verbose.LogMessage("Connecting through proxy: %s", proxy.Redact(proxyURL))
+	}
 
 	// Set dial timeout
-	timeout := time.Duration(c.Config.Timeout) * time.Second
+	timeout := time.Duration(c.Config.TCPTimeout) * time.Second
 
 	// Attempt connection
-	conn, err := net.DialTimeout("tcp", address, timeout)
+	dialStart := time.Now()
+	conn, err := dialTCP(ctx, c.Config, address, timeout)
+	dialDuration := time.Since(dialStart)
 	if err != nil {
 		c.verbose.LogMessage("TCP connection failed: %v", err)
 		result.Status = output.StatusFail
@@ -80,6 +101,37 @@ func (c *TCPChecker) Check() output.TestResult {
 		ConnectionTime: time.Since(startTime).Milliseconds(),
 		LocalAddr:      localAddr,
 		RemoteAddr:     remoteAddr,
+		Proxy:          proxy.Redact(proxyURL),
+	}
+
+	// Take additional handshake samples if requested
+	if c.Config.TCPSamples > 1 {
+		c.verbose.LogMessage("Collecting %d handshake samples", c.Config.TCPSamples)
+		stats := c.sampleHandshakes(ctx, address, timeout, dialDuration)
+		tcpResult.Samples = &stats
+
+		c.verbose.LogMessage("Samples: min=%.2fms avg=%.2fms max=%.2fms jitter=%.2fms loss=%.1f%%",
+			stats.MinMs, stats.AvgMs, stats.MaxMs, stats.JitterMs, stats.PacketLossPercent)
+
+		if stats.PacketLossPercent > 0 {
+			result.Status = output.StatusWarn
+		}
+	}
+
+	// Race IPv6 against IPv4 the way dual-stack SDKs do. Through a proxy
+	// the proxy picks the address family, so there is nothing to race.
+	if c.Config.HappyEyeballs && proxyURL != nil {
+		tcpResult.HappyEyeballs = &output.HappyEyeballsResult{Error: "not run: connections go through a proxy"}
+	} else if c.Config.HappyEyeballs && net.ParseIP(c.Host) == nil {
+		c.verbose.LogMessage("Running Happy Eyeballs (RFC 8305) dual-stack race")
+		raceCtx, cancel := context.WithTimeout(ctx, timeout)
+		tcpResult.HappyEyeballs = c.happyEyeballs(raceCtx, c.Host, c.Port)
+		cancel()
+
+		if warning := happyEyeballsWarning(tcpResult.HappyEyeballs); warning != "" {
+			tcpResult.HappyEyeballs.Warning = warning
+			result.Status = output.StatusWarn
+		}
 	}
 
 	result.Details = tcpResult
@@ -89,3 +141,94 @@ func (c *TCPChecker) Check() output.TestResult {
 
 	return result
 }
+
+// sampleHandshakes performs repeated TCP handshakes and computes latency statistics.
+// The initial connection counts as the first sample.
+func (c *TCPChecker) sampleHandshakes(ctx context.Context, address string, timeout, first time.Duration) output.TCPSampleStats {
+	samples := []time.Duration{first}
+	failed := 0
+
+	for i := 1; i < c.Config.TCPSamples; i++ {
+		select {
+		case <-ctx.Done():
+			return computeTCPSampleStats(samples, failed)
+		case <-time.After(tcpSampleInterval):
+		}
+
+		dialStart := time.Now()
+		conn, err := dialTCP(ctx, c.Config, address, timeout)
+		elapsed := time.Since(dialStart)
+		if err != nil {
+			c.verbose.LogMessage("  Sample %d: failed after %v: %v", i+1, elapsed, err)
+			failed++
+			continue
+		}
+		conn.Close()
+
+		c.verbose.LogMessage("  Sample %d: %v", i+1, elapsed)
+		samples = append(samples, elapsed)
+	}
+
+	return computeTCPSampleStats(samples, failed)
+}
+
+// computeTCPSampleStats derives min/avg/max, jitter and a packet-loss estimate
+// from successful handshake durations and the number of failed attempts
+func computeTCPSampleStats(samples []time.Duration, failed int) output.TCPSampleStats {
+	stats := output.TCPSampleStats{
+		Count:      len(samples) + failed,
+		Successful: len(samples),
+		Failed:     failed,
+	}
+
+	if len(samples) == 0 {
+		stats.PacketLossPercent = 100
+		return stats
+	}
+
+	minDur, maxDur := samples[0], samples[0]
+	var total, jitterTotal time.Duration
+	for i, sample := range samples {
+		if sample < minDur {
+			minDur = sample
+		}
+		if sample > maxDur {
+			maxDur = sample
+		}
+		total += sample
+
+		// Jitter is the mean absolute difference between consecutive samples
+		if i > 0 {
+			diff := sample - samples[i-1]
+			if diff < 0 {
+				diff = -diff
+			}
+			jitterTotal += diff
+		}
+	}
+
+	// Handshakes that took at least one retransmission timeout longer than
+	// the fastest one indicate a lost SYN or SYN-ACK
+	for _, sample := range samples {
+		if sample-minDur >= tcpInitialRTO {
+			stats.SuspectedRetransmits++
+		}
+	}
+
+	stats.MinMs = durationToMs(minDur)
+	stats.MaxMs = durationToMs(maxDur)
+	stats.AvgMs = durationToMs(total / time.Duration(len(samples)))
+	if len(samples) > 1 {
+		stats.JitterMs = durationToMs(jitterTotal / time.Duration(len(samples)-1))
+	}
+
+	lost := stats.Failed + stats.SuspectedRetransmits
+	stats.PacketLossPercent = math.Round(float64(lost)/float64(stats.Count)*1000) / 10
+
+	return stats
+}
+
+// durationToMs converts a duration to fractional milliseconds rounded to 0.01ms
+func durationToMs(d time.Duration) float64 {
+	return math.Round(float64(d.Microseconds())/10) / 100
+}
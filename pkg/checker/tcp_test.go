@@ -0,0 +1,60 @@
+package checker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+func TestComputeTCPSampleStats(t *testing.T) {
+	ms := time.Millisecond
+
+	tests := []struct {
+		name    string
+		samples []time.Duration
+		failed  int
+		want    output.TCPSampleStats
+	}{
+		{
+			name:   "every handshake failed",
+			failed: 3,
+			want:   output.TCPSampleStats{Count: 3, Failed: 3, PacketLossPercent: 100},
+		},
+		{
+			name:    "single sample",
+			samples: []time.Duration{10 * ms},
+			want:    output.TCPSampleStats{Count: 1, Successful: 1, MinMs: 10, AvgMs: 10, MaxMs: 10},
+		},
+		{
+			name:    "jitter between consecutive samples",
+			samples: []time.Duration{10 * ms, 20 * ms, 15 * ms},
+			want:    output.TCPSampleStats{Count: 3, Successful: 3, MinMs: 10, AvgMs: 15, MaxMs: 20, JitterMs: 7.5},
+		},
+		{
+			name:    "failed handshake counts as loss",
+			samples: []time.Duration{10 * ms, 10 * ms, 10 * ms},
+			failed:  1,
+			want:    output.TCPSampleStats{Count: 4, Successful: 3, Failed: 1, MinMs: 10, AvgMs: 10, MaxMs: 10, PacketLossPercent: 25},
+		},
+		{
+			name:    "retransmission suspected one RTO above the fastest",
+			samples: []time.Duration{10 * ms, 1010 * ms, 12 * ms},
+			want: output.TCPSampleStats{Count: 3, Successful: 3, MinMs: 10, AvgMs: 344, MaxMs: 1010, JitterMs: 999,
+				SuspectedRetransmits: 1, PacketLossPercent: 33.3},
+		},
+		{
+			name:    "rounded to hundredths of a millisecond",
+			samples: []time.Duration{1234567 * time.Nanosecond},
+			want:    output.TCPSampleStats{Count: 1, Successful: 1, MinMs: 1.23, AvgMs: 1.23, MaxMs: 1.23},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeTCPSampleStats(tt.samples, tt.failed); got != tt.want {
+				t.Errorf("computeTCPSampleStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
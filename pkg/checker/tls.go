@@ -1,9 +1,9 @@
 package checker
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
-	"net"
 	"time"
 
 	"github.com/s3-bucket-tester/s3tester/pkg/output"
@@ -33,7 +33,7 @@ func (c *TLSChecker) Name() string {
 }
 
 // Check performs the TLS certificate check
-func (c *TLSChecker) Check() output.TestResult {
+func (c *TLSChecker) Check(ctx context.Context) output.TestResult {
 	startTime := time.Now()
 
 	c.verbose.LogSection("Starting SSL/TLS Certificate Check")
@@ -48,27 +48,39 @@ func (c *TLSChecker) Check() output.TestResult {
 	address := fmt.Sprintf("%s:%d", c.Host, c.Port)
 
 	c.verbose.LogMessage("Attempting TLS connection to: %s", address)
-	c.verbose.LogMessage("Server name: %s", c.Host)
+	c.verbose.LogMessage("Server name: %s", serverName(c.Config, c.Host))
 	c.verbose.LogMessage("Insecure skip verify: %v", c.Config.Insecure)
 	c.verbose.LogMessage("Minimum TLS version: TLS 1.2")
+	if c.Config.CACert != "" {
+		c.verbose.LogMessage("Custom CA certificates: %s", c.Config.CACert)
+	}
+	clientCert := clientCertificate(c.Config)
+	if clientCert != nil {
+		c.verbose.LogMessage("Client certificate: %s", c.Config.ClientCert)
+	}
 
-	// Create TLS config
+	// Create TLS config. GetClientCertificate records whether the server asks
+	// for a client certificate, and presents the configured one if any.
+	clientCertRequested := false
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: c.Config.Insecure,
-		ServerName:         c.Host,
+		ServerName:         serverName(c.Config, c.Host),
 		MinVersion:         tls.VersionTLS12,
+		RootCAs:            rootCAs(c.Config),
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			clientCertRequested = true
+			if clientCert != nil {
+				return clientCert, nil
+			}
+			return &tls.Certificate{}, nil
+		},
 	}
 
 	// Set dial timeout
-	timeout := time.Duration(c.Config.Timeout) * time.Second
+	timeout := time.Duration(c.Config.TCPTimeout) * time.Second
 
 	// Create connection
-	conn, err := tls.DialWithDialer(
-		&net.Dialer{Timeout: timeout},
-		"tcp",
-		address,
-		tlsConfig,
-	)
+	conn, err := dialTLS(ctx, c.Config, address, timeout, tlsConfig)
 
 	if err != nil {
 		c.verbose.LogMessage("TLS connection failed: %v", err)
@@ -78,7 +90,7 @@ func (c *TLSChecker) Check() output.TestResult {
 		result.Duration = time.Since(startTime)
 
 		// Try to get some certificate info even on failure
-		if certErr := c.tryGetCertificateInfo(address, &result); certErr != nil {
+		if certErr := c.tryGetCertificateInfo(ctx, address, err, &result); certErr != nil {
 			// If we can't get any info, return with the original error
 			c.verbose.LogMessage("Could not retrieve certificate info: %v", certErr)
 			return result
@@ -112,7 +124,13 @@ func (c *TLSChecker) Check() output.TestResult {
 		TLSVersion:  tlsVersionToString(state.Version),
 		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
 		PeerCerts:   peerCerts,
+
+		ClientCertRequested: clientCertRequested,
 	}
+	if clientCert != nil && clientCert.Leaf != nil {
+		tlsResult.ClientCertificate = clientCert.Leaf.Subject.String()
+	}
+	c.verbose.LogMessage("Client certificate requested: %v", clientCertRequested)
 
 	c.verbose.LogMessage("Certificate Subject: %s", tlsResult.Certificate.Subject)
 	c.verbose.LogMessage("Certificate Issuer: %s", tlsResult.Certificate.Issuer)
@@ -131,6 +149,90 @@ func (c *TLSChecker) Check() output.TestResult {
 		tlsResult.Certificate.Chain = chain
 	}
 
+	// With --insecure the chain was not verified; still report a server
+	// that leaves out intermediates, which other clients will refuse
+	if c.Config.Insecure {
+		tlsResult.ChainAnalysis = analyzeChain(ctx, c.Config, state.PeerCertificates)
+		c.verbose.LogMessage("Certificate chain: %s", tlsResult.ChainAnalysis.Status)
+		if tlsResult.ChainAnalysis.Status == output.ChainIncomplete {
+			result.Status = output.StatusWarn
+			result.Error = chainError(tlsResult.ChainAnalysis)
+		}
+	}
+
+	// Virtual-hosted requests go to bucket.host, which the certificate must
+	// cover as well; --insecure requests do not verify it, so only warn
+	if host := c.bucketHost(); host != "" {
+		tlsResult.BucketHost = c.checkBucketHost(ctx, address, host, state.PeerCertificates[0])
+		if !tlsResult.BucketHost.Covered && result.Status != output.StatusFail {
+			result.Status = output.StatusFail
+			if c.Config.Insecure {
+				result.Status = output.StatusWarn
+			}
+			result.Error = bucketHostError(tlsResult.BucketHost, c.Config.Bucket)
+		}
+	}
+
+	// Test session ticket resumption if requested
+	if c.Config.TLSResumption {
+		tlsResult.Resumption = c.checkResumption(ctx, address, tlsConfig)
+		if tlsResult.Resumption.TicketIssued && !tlsResult.Resumption.Resumed {
+			result.Status = output.StatusWarn
+		}
+	}
+
+	// Probe which certificate is served without SNI and by raw IP
+	if c.Config.SNIProbe {
+		tlsResult.SNIProbes = c.probeSNIVariants(ctx, state.PeerCertificates[0])
+	}
+
+	// Check the leaf certificate for revocation; a revoked certificate fails
+	// the check, an unknown status only warns since many networks block
+	// OCSP responders
+	revocation := checkRevocation(ctx, c.Config, state)
+	tlsResult.Certificate.Revocation = revocation
+	c.verbose.LogMessage("OCSP status: %s (%s)", revocation.Status, revocation.Source)
+	switch revocation.Status {
+	case output.RevocationRevoked:
+		// Clients do not check OCSP during the handshake, so S3 requests
+		// still work unless the check failed already
+		result.PolicyFailure = result.Status != output.StatusFail
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("certificate was revoked on %s (reason: %s)", revocation.RevokedAt.Format("2006-01-02"), revocation.Reason)
+	case output.RevocationUnavailable, output.RevocationUnknown:
+		if result.Status == output.StatusPass {
+			result.Status = output.StatusWarn
+			result.Error = "revocation status unavailable: " + revocation.Error
+		}
+	}
+
+	// Compare the expiry with the --cert-warn-days and --cert-crit-days
+	// thresholds; --fail-on-cert-expiry turns an expiring certificate into a
+	// failure. An expired one only gets here with --insecure.
+	tlsResult.Expiry = certificateExpiry(c.Config, tlsResult.Certificate)
+	if message := expiryMessage(c.Config, tlsResult.Expiry, tlsResult.Certificate); message != "" {
+		c.verbose.LogMessage("Certificate expiry: %s", tlsResult.Expiry)
+		switch {
+		case result.Status == output.StatusFail:
+		case c.Config.FailOnCertExpiry:
+			result.Status = output.StatusFail
+			result.Error = message
+			result.PolicyFailure = true
+		case result.Status == output.StatusWarn:
+			result.Error += "; " + message
+		default:
+			result.Status = output.StatusWarn
+			result.Error = message
+		}
+	}
+
+	// With TLS 1.3 the handshake completes before the server checks the
+	// client certificate, so a missing one only fails the first request
+	if clientCertRequested && clientCert == nil && result.Status == output.StatusPass {
+		result.Status = output.StatusWarn
+		result.Error = "server requested a client certificate but none is configured (use --client-cert and --client-key)"
+	}
+
 	result.Details = tlsResult
 	result.Duration = time.Since(startTime)
 
@@ -139,18 +241,21 @@ func (c *TLSChecker) Check() output.TestResult {
 	return result
 }
 
-// tryGetCertificateInfo attempts to get certificate info even on connection failure
-func (c *TLSChecker) tryGetCertificateInfo(address string, result *output.TestResult) error {
+// tryGetCertificateInfo attempts to get certificate info even on connection
+// failure. When the chain could not be verified, it also tells a server that
+// sends an incomplete chain apart from a certificate from an untrusted CA.
+func (c *TLSChecker) tryGetCertificateInfo(ctx context.Context, address string, dialErr error, result *output.TestResult) error {
 	c.verbose.LogMessage("Attempting to retrieve certificate info with insecure connection...")
 
 	// Try with a more permissive config
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: true,
-		ServerName:         c.Host,
+		ServerName:         serverName(c.Config, c.Host),
 		MinVersion:         tls.VersionTLS10,
+		Certificates:       clientCertificates(c.Config),
 	}
 
-	conn, err := tls.Dial("tcp", address, tlsConfig)
+	conn, err := dialTLS(ctx, c.Config, address, time.Duration(c.Config.TCPTimeout)*time.Second, tlsConfig)
 	if err != nil {
 		return err
 	}
@@ -168,6 +273,16 @@ func (c *TLSChecker) tryGetCertificateInfo(address string, result *output.TestRe
 			TLSVersion:  tlsVersionToString(state.Version),
 			CipherSuite: tls.CipherSuiteName(state.CipherSuite),
 		}
+		if isUnknownAuthority(dialErr) {
+			for _, cert := range state.PeerCertificates {
+				tlsResult.PeerCerts = append(tlsResult.PeerCerts, output.NewCertificateInfo(cert))
+			}
+			tlsResult.ChainAnalysis = analyzeChain(ctx, c.Config, state.PeerCertificates)
+			c.verbose.LogMessage("Certificate chain: %s", tlsResult.ChainAnalysis.Status)
+			if message := chainError(tlsResult.ChainAnalysis); message != "" {
+				result.Error = message
+			}
+		}
 		result.Details = tlsResult
 		c.verbose.LogMessage("Retrieved certificate info (unverified)")
 	}
@@ -175,6 +290,23 @@ func (c *TLSChecker) tryGetCertificateInfo(address string, result *output.TestRe
 	return nil
 }
 
+// chainError describes a chain that does not verify, distinguishing a server
+// that sends an incomplete chain, or an empty string to keep the handshake's
+// error
+func chainError(analysis *output.ChainAnalysis) string {
+	switch analysis.Status {
+	case output.ChainIncomplete:
+		missing := "an intermediate certificate"
+		if len(analysis.Missing) > 0 {
+			missing = fmt.Sprintf("intermediate %q", analysis.Missing[0])
+		}
+		return fmt.Sprintf("server sends incomplete chain: %s is missing (found via AIA at %s); configure the server to send the full chain", missing, analysis.AIAFetches[0].URL)
+	case output.ChainSelfSigned:
+		return "certificate signed by unknown authority: the server certificate is self-signed"
+	}
+	return ""
+}
+
 // tlsVersionToString converts TLS version number to string
 func tlsVersionToString(version uint16) string {
 	switch version {
@@ -191,6 +323,38 @@ func tlsVersionToString(version uint16) string {
 	}
 }
 
+// certificateExpiry classifies the certificate's remaining validity by the
+// configured thresholds; a threshold of 0 disables it
+func certificateExpiry(config output.Config, cert output.CertificateInfo) string {
+	days := cert.DaysUntilExpiry
+	switch {
+	case cert.IsExpired:
+		return output.ExpiryExpired
+	case days < config.CertCritDays:
+		return output.ExpiryCritical
+	case days < config.CertWarnDays:
+		return output.ExpiryWarning
+	default:
+		return output.ExpiryValid
+	}
+}
+
+// expiryMessage describes an expired or expiring certificate, or returns ""
+func expiryMessage(config output.Config, expiry string, cert output.CertificateInfo) string {
+	switch expiry {
+	case output.ExpiryExpired:
+		return fmt.Sprintf("certificate expired on %s", cert.NotAfter.Format("2006-01-02"))
+	case output.ExpiryCritical:
+		return fmt.Sprintf("certificate expires in %d days (on %s), within the critical threshold of %d days; renew immediately",
+			cert.DaysUntilExpiry, cert.NotAfter.Format("2006-01-02"), config.CertCritDays)
+	case output.ExpiryWarning:
+		return fmt.Sprintf("certificate expires in %d days (on %s), within the warning threshold of %d days; plan for renewal",
+			cert.DaysUntilExpiry, cert.NotAfter.Format("2006-01-02"), config.CertWarnDays)
+	default:
+		return ""
+	}
+}
+
 // GetCertificateWarnings returns warnings for certificate issues
 func (c *TLSChecker) GetCertificateWarnings(tlsResult output.TLSResult) []string {
 	var warnings []string
@@ -201,12 +365,11 @@ func (c *TLSChecker) GetCertificateWarnings(tlsResult output.TLSResult) []string
 	}
 
 	// Check for certificate expiring soon
-	if tlsResult.Certificate.DaysUntilExpiry > 0 && tlsResult.Certificate.DaysUntilExpiry < 30 {
-		if tlsResult.Certificate.DaysUntilExpiry < 7 {
-			warnings = append(warnings, fmt.Sprintf("Certificate expires in %d days! Renew immediately.", tlsResult.Certificate.DaysUntilExpiry))
-		} else {
-			warnings = append(warnings, fmt.Sprintf("Certificate expires in %d days. Plan for renewal.", tlsResult.Certificate.DaysUntilExpiry))
-		}
+	switch certificateExpiry(c.Config, tlsResult.Certificate) {
+	case output.ExpiryCritical:
+		warnings = append(warnings, fmt.Sprintf("Certificate expires in %d days! Renew immediately.", tlsResult.Certificate.DaysUntilExpiry))
+	case output.ExpiryWarning:
+		warnings = append(warnings, fmt.Sprintf("Certificate expires in %d days. Plan for renewal.", tlsResult.Certificate.DaysUntilExpiry))
 	}
 
 	// Check for weak TLS version
@@ -0,0 +1,115 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// recordingSessionCache wraps a client session cache and records whether the
+// server issued a session ticket
+type recordingSessionCache struct {
+	tls.ClientSessionCache
+	mu      sync.Mutex
+	tickets int
+}
+
+// Put stores the session and counts the issued ticket
+func (r *recordingSessionCache) Put(sessionKey string, cs *tls.ClientSessionState) {
+	if cs != nil {
+		r.mu.Lock()
+		r.tickets++
+		r.mu.Unlock()
+	}
+	r.ClientSessionCache.Put(sessionKey, cs)
+}
+
+// ticketCount returns the number of tickets received so far
+func (r *recordingSessionCache) ticketCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tickets
+}
+
+// checkResumption performs a full handshake followed by a resumed one and
+// reports whether session resumption works
+func (c *TLSChecker) checkResumption(ctx context.Context, address string, baseConfig *tls.Config) *output.TLSResumptionResult {
+	c.verbose.LogMessage("Testing TLS session resumption...")
+
+	cache := &recordingSessionCache{ClientSessionCache: tls.NewLRUClientSessionCache(4)}
+	tlsConfig := baseConfig.Clone()
+	tlsConfig.ClientSessionCache = cache
+
+	result := &output.TLSResumptionResult{
+		// The Go TLS client cannot send early data, and the ticket's
+		// max_early_data_size is not exposed outside of QUIC
+		EarlyData: "not tested",
+	}
+
+	// First connection: full handshake, then exchange data so that TLS 1.3
+	// post-handshake NewSessionTicket messages are processed
+	fullTime, state, err := c.timedHandshake(ctx, address, tlsConfig, true)
+	if err != nil {
+		result.Error = fmt.Sprintf("initial handshake failed: %v", err)
+		return result
+	}
+	result.TLSVersion = tlsVersionToString(state.Version)
+	result.FullHandshakeMs = durationToMs(fullTime)
+	result.TicketIssued = cache.ticketCount() > 0
+
+	c.verbose.LogMessage("Full handshake: %v (%s), session ticket issued: %v", fullTime, result.TLSVersion, result.TicketIssued)
+
+	if !result.TicketIssued {
+		return result
+	}
+
+	// Second connection: should resume using the cached ticket
+	resumedTime, state, err := c.timedHandshake(ctx, address, tlsConfig, false)
+	if err != nil {
+		result.Error = fmt.Sprintf("resumption handshake failed: %v", err)
+		return result
+	}
+	result.Resumed = state.DidResume
+	result.ResumedHandshakeMs = durationToMs(resumedTime)
+
+	c.verbose.LogMessage("Second handshake: %v, resumed: %v", resumedTime, result.Resumed)
+
+	return result
+}
+
+// timedHandshake dials the address and measures the TLS handshake alone.
+// When exchange is set, a minimal HTTP request is sent so the server's
+// session tickets are received before the connection is closed.
+func (c *TLSChecker) timedHandshake(ctx context.Context, address string, tlsConfig *tls.Config, exchange bool) (time.Duration, tls.ConnectionState, error) {
+	timeout := time.Duration(c.Config.TCPTimeout) * time.Second
+
+	rawConn, err := dialTCP(ctx, c.Config, address, timeout)
+	if err != nil {
+		return 0, tls.ConnectionState{}, err
+	}
+
+	conn := tls.Client(rawConn, tlsConfig)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	handshakeStart := time.Now()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return 0, tls.ConnectionState{}, err
+	}
+	elapsed := time.Since(handshakeStart)
+
+	if exchange {
+		conn.SetDeadline(time.Now().Add(timeout))
+		fmt.Fprintf(conn, "HEAD / HTTP/1.1\r\nHost: %s\r\nUser-Agent: s3-bucket-tester/1.0\r\nConnection: close\r\n\r\n", tlsConfig.ServerName)
+		buf := make([]byte, 4096)
+		conn.Read(buf)
+	}
+
+	return elapsed, conn.ConnectionState(), nil
+}
@@ -0,0 +1,149 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// TransferEncodingChecker probes how the endpoint handles chunked uploads
+// without Content-Length and zero-length PUTs
+type TransferEncodingChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewTransferEncodingChecker creates a new transfer encoding probe
+func NewTransferEncodingChecker(config output.Config) *TransferEncodingChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &TransferEncodingChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *TransferEncodingChecker) Name() string {
+	return "Transfer Encoding Probe"
+}
+
+// Check performs the transfer encoding probe
+func (c *TransferEncodingChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Transfer Encoding Probe")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+		Duration: time.Since(startTime),
+	}
+
+	chunkedBody := bytes.Repeat([]byte("chunked-upload-"), 1024)
+
+	probe := output.TransferEncodingResult{
+		Cases: []output.TransferCaseResult{
+			c.probeCase("Chunked (no Content-Length)", chunkedBody, true),
+			c.probeCase("Content-Length: 0", nil, false),
+		},
+	}
+
+	var transportErrors, problems []string
+	for _, tc := range probe.Cases {
+		switch {
+		case tc.TransportError:
+			transportErrors = append(transportErrors, fmt.Sprintf("%s: %s", tc.Name, tc.Error))
+		case tc.Accepted && !tc.Verified:
+			problems = append(problems, fmt.Sprintf("%s: stored object does not match upload", tc.Name))
+		case !tc.Accepted && !tc.Chunked:
+			problems = append(problems, fmt.Sprintf("%s: rejected (%s)", tc.Name, tc.Error))
+		}
+	}
+
+	switch {
+	case len(transportErrors) > 0:
+		result.Status = output.StatusFail
+		result.Error = strings.Join(transportErrors, "; ")
+	case len(problems) > 0:
+		result.Status = output.StatusWarn
+		result.Error = strings.Join(problems, "; ")
+	}
+
+	result.Details = probe
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Transfer encoding probe completed in %v", result.Duration)
+
+	return result
+}
+
+// probeCase uploads a test object using the given framing, then verifies
+// what was stored and removes it
+func (c *TransferEncodingChecker) probeCase(name string, body []byte, chunked bool) output.TransferCaseResult {
+	tc := output.TransferCaseResult{
+		Name:    name,
+		Chunked: chunked,
+		Sent:    len(body),
+	}
+
+	key := c.client.testObjectKey("transfer")
+	c.verbose.LogMessage("%s: uploading %d bytes to %s", name, len(body), key)
+
+	req, err := c.client.newRequest("PUT", key, nil, nil)
+	if err != nil {
+		tc.Error = err.Error()
+		return tc
+	}
+
+	if chunked {
+		// A reader of unknown length makes net/http use chunked Transfer-Encoding
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = -1
+		req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	}
+
+	requestStart := time.Now()
+	resp, respBody, err := c.client.do(req, nil)
+	tc.LatencyMs = time.Since(requestStart).Milliseconds()
+	if err != nil {
+		c.verbose.LogMessage("%s: request failed: %v", name, err)
+		tc.TransportError = true
+		tc.Error = err.Error()
+		return tc
+	}
+
+	tc.StatusCode = resp.StatusCode
+	tc.Accepted = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !tc.Accepted {
+		tc.Error = parseErrorResponse(resp.StatusCode, respBody)
+		c.verbose.LogMessage("%s: rejected with %s", name, tc.Error)
+		return tc
+	}
+
+	// Verify the stored object
+	_, stored, err := c.client.getObject(key)
+	if err != nil {
+		tc.Error = fmt.Sprintf("verification GET failed: %v", err)
+	} else {
+		tc.StoredSize = len(stored)
+		tc.Verified = bytes.Equal(stored, body)
+	}
+
+	c.client.cleanup(func() {
+		if err := c.client.deleteObject(key); err != nil {
+			c.verbose.LogMessage("Failed to delete test object %s: %v", key, err)
+		}
+	})
+
+	c.verbose.LogMessage("%s: accepted, stored %d bytes, verified: %v", name, tc.StoredSize, tc.Verified)
+
+	return tc
+}
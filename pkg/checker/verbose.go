@@ -2,6 +2,7 @@ package checker
 
 import (
 	"bytes"
+	"encoding/xml"
 	"fmt"
 	"io"
 	"net/http"
@@ -111,3 +112,161 @@ func (v *VerboseLogger) LogSection(title string) {
 	fmt.Println(title)
 	fmt.Println(strings.Repeat("=", 70))
 }
+
+// LogSigning logs what a request signature was computed over: the canonical
+// request, the string-to-sign and the scope of the signing key. The signing
+// key itself is derived from the secret key and never logged. SigV2 has no
+// canonical request or scope; empty parts are left out.
+func (v *VerboseLogger) LogSigning(canonicalRequest, stringToSign, scope string) {
+	if !v.enabled {
+		return
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Println("REQUEST SIGNING")
+	fmt.Println(strings.Repeat("=", 70))
+	if canonicalRequest != "" {
+		fmt.Println("Canonical Request:")
+		fmt.Println(canonicalRequest)
+		fmt.Println(strings.Repeat("-", 70))
+	}
+	fmt.Println("String to Sign:")
+	fmt.Println(stringToSign)
+	if scope != "" {
+		fmt.Println(strings.Repeat("-", 70))
+		fmt.Printf("Signing Key: derived from the secret key for %s\n", scope)
+	}
+	fmt.Println(strings.Repeat("=", 70))
+}
+
+// signatureMismatch is the error S3 returns for a wrong signature, which
+// includes what the server computed the signature over
+type signatureMismatch struct {
+	Code             string `xml:"Code"`
+	StringToSign     string `xml:"StringToSign"`
+	CanonicalRequest string `xml:"CanonicalRequest"`
+}
+
+// LogSignatureMismatch compares the canonical request and string-to-sign of
+// a request with those in a SignatureDoesNotMatch error body and logs where
+// they first differ. Bodies of other errors are ignored.
+func (v *VerboseLogger) LogSignatureMismatch(canonicalRequest, stringToSign string, body []byte) {
+	if !v.enabled {
+		return
+	}
+	var mismatch signatureMismatch
+	if err := xml.Unmarshal(body, &mismatch); err != nil || mismatch.Code != "SignatureDoesNotMatch" {
+		return
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Println("SIGNATURE MISMATCH")
+	fmt.Println(strings.Repeat("=", 70))
+	defer fmt.Println(strings.Repeat("=", 70))
+
+	if mismatch.StringToSign == "" {
+		fmt.Println("The server did not return its string-to-sign; compare the canonical")
+		fmt.Println("request logged above with the request the server received.")
+		return
+	}
+
+	line, ours, theirs, ok := firstDivergence(stringToSign, mismatch.StringToSign)
+	if !ok {
+		// Both sides signed the same string, so the keys differ
+		fmt.Println("The string-to-sign matches the server's, so the signing key differs:")
+		fmt.Println("check the secret key, and that the region and date of the scope are")
+		fmt.Println("the ones the server expects.")
+		return
+	}
+	fmt.Printf("The string-to-sign differs from the server's in the %s:\n", stringToSignLine(line, canonicalRequest != ""))
+	printDivergence(ours, theirs)
+
+	// A different hash means a different canonical request, which the
+	// server may have returned too
+	if canonicalRequest == "" || line != 3 || mismatch.CanonicalRequest == "" {
+		return
+	}
+	line, ours, theirs, ok = firstDivergence(canonicalRequest, mismatch.CanonicalRequest)
+	if !ok {
+		fmt.Println("\nThe canonical requests match; the server hashed another encoding of it.")
+		return
+	}
+	fmt.Printf("\nThe canonical request differs from the server's in the %s:\n", canonicalRequestLine(line, strings.Count(canonicalRequest, "\n")+1))
+	printDivergence(ours, theirs)
+}
+
+// firstDivergence returns the index and contents of the first line in which
+// two newline-separated strings differ
+func firstDivergence(ours, theirs string) (int, string, string, bool) {
+	ourLines := strings.Split(ours, "\n")
+	theirLines := strings.Split(theirs, "\n")
+	for i := 0; i < len(ourLines) || i < len(theirLines); i++ {
+		var our, their string
+		if i < len(ourLines) {
+			our = ourLines[i]
+		}
+		if i < len(theirLines) {
+			their = theirLines[i]
+		}
+		if our != their || i >= len(ourLines) || i >= len(theirLines) {
+			return i, our, their, true
+		}
+	}
+	return 0, "", "", false
+}
+
+// printDivergence prints two differing lines with a caret under the first
+// character that differs
+func printDivergence(ours, theirs string) {
+	column := 0
+	for column < len(ours) && column < len(theirs) && ours[column] == theirs[column] {
+		column++
+	}
+	fmt.Printf("  ours:   %q\n", ours)
+	fmt.Printf("  server: %q\n", theirs)
+	// The quote and the escapes before the column widen the quoted line
+	offset := len(fmt.Sprintf("%q", ours[:column])) - 1
+	fmt.Printf("          %s^ at character %d\n", strings.Repeat(" ", offset), column+1)
+}
+
+// stringToSignLine names a line of a SigV4 string-to-sign, or of the SigV2
+// one when there is no canonical request
+func stringToSignLine(line int, sigv4 bool) string {
+	if !sigv4 {
+		return fmt.Sprintf("line %d", line+1)
+	}
+	switch line {
+	case 0:
+		return "algorithm"
+	case 1:
+		return "request date"
+	case 2:
+		return "credential scope"
+	case 3:
+		return "canonical request hash"
+	default:
+		return fmt.Sprintf("line %d", line+1)
+	}
+}
+
+// canonicalRequestLine names a line of a SigV4 canonical request with the
+// given number of lines; the headers take a variable number of lines and end
+// with an empty one
+func canonicalRequestLine(line, lines int) string {
+	switch {
+	case line == 0:
+		return "HTTP method"
+	case line == 1:
+		return "canonical URI"
+	case line == 2:
+		return "canonical query string"
+	case line == lines-1:
+		return "payload hash"
+	case line == lines-2:
+		return "signed headers"
+	case line < lines-3:
+		return fmt.Sprintf("canonical headers (header %d)", line-2)
+	default:
+		return fmt.Sprintf("line %d", line+1)
+	}
+}
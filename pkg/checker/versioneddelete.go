@@ -0,0 +1,216 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// VersionedDeleteChecker verifies that DeleteObject on a versioned bucket
+// behaves like AWS: a delete by key only hides the object behind a delete
+// marker, and a delete by versionId removes that version permanently
+type VersionedDeleteChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewVersionedDeleteChecker creates a new versioned delete checker
+func NewVersionedDeleteChecker(config output.Config) *VersionedDeleteChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &VersionedDeleteChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *VersionedDeleteChecker) Name() string {
+	return "Versioned Delete Check"
+}
+
+// versionedResponse is the part of a response the steps compare
+type versionedResponse struct {
+	status       int
+	versionID    string
+	deleteMarker bool
+	code         string
+}
+
+// details is what the step reports of the response besides its status
+func (r versionedResponse) details() string {
+	details := ""
+	if r.deleteMarker {
+		details += ", delete marker"
+	}
+	return details + versionDetail(r.versionID)
+}
+
+// Check writes a test object, deletes it by key and by versionId and compares
+// each response with the AWS semantics. Every version and delete marker it
+// creates is removed again. Buckets without versioning enabled are skipped.
+func (c *VersionedDeleteChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Versioned Delete Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	key := c.client.testObjectKey("versioned-delete")
+	vdResult := output.VersionedDeleteResult{Key: key}
+
+	versioning, err := c.versioningStatus()
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to read bucket versioning: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	vdResult.Versioning = versioning
+	if versioning != "enabled" {
+		result.Status = output.StatusSkip
+		result.Error = fmt.Sprintf("bucket versioning is %s; this check needs a bucket with versioning enabled", versioning)
+		result.Details = vdResult
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	// Remove every version this check created, whatever step it stopped at
+	defer c.client.cleanup(func() {
+		for _, versionID := range []string{vdResult.VersionID, vdResult.DeleteMarkerID} {
+			if versionID == "" {
+				continue
+			}
+			if resp, err := c.send("DELETE", key, url.Values{"versionId": {versionID}}); err != nil || (resp.status >= 300 && resp.code != "NoSuchVersion") {
+				c.verbose.LogMessage("Failed to delete version %s of %s: %v %s", versionID, key, err, resp.code)
+			}
+		}
+	})
+
+	put, err := c.send("PUT", key, nil)
+	vdResult.VersionID = put.versionID
+	putStep := newStep(c.verbose, "PUT object", "2xx with x-amz-version-id", put.status, put.code, err, put.status < 300 && put.versionID != "", put.details())
+	vdResult.Steps = append(vdResult.Steps, putStep)
+	if err != nil || put.status >= 300 {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("PUT failed: %s", putStep.Observed)
+		result.Details = vdResult
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	if put.versionID == "" {
+		return c.finish(result, vdResult, startTime)
+	}
+
+	del, err := c.send("DELETE", key, nil)
+	if del.deleteMarker {
+		vdResult.DeleteMarkerID = del.versionID
+	}
+	vdResult.Steps = append(vdResult.Steps, newStep(c.verbose, "DELETE by key", "204 with x-amz-delete-marker: true and a new version ID", del.status, del.code, err,
+		del.status < 300 && del.deleteMarker && del.versionID != "" && del.versionID != put.versionID, del.details()))
+
+	get, err := c.send("GET", key, nil)
+	vdResult.Steps = append(vdResult.Steps, newStep(c.verbose, "GET by key", "404, object hidden by the delete marker", get.status, get.code, err,
+		get.status == http.StatusNotFound, get.details()))
+
+	getVersion, err := c.send("GET", key, url.Values{"versionId": {put.versionID}})
+	vdResult.Steps = append(vdResult.Steps, newStep(c.verbose, "GET by versionId", "200, the original version is retained", getVersion.status, getVersion.code, err,
+		getVersion.status == http.StatusOK, getVersion.details()))
+
+	delVersion, err := c.send("DELETE", key, url.Values{"versionId": {put.versionID}})
+	vdResult.Steps = append(vdResult.Steps, newStep(c.verbose, "DELETE by versionId", "204 echoing the version ID, no delete marker", delVersion.status, delVersion.code, err,
+		delVersion.status < 300 && delVersion.versionID == put.versionID && !delVersion.deleteMarker, delVersion.details()))
+
+	getDeleted, err := c.send("GET", key, url.Values{"versionId": {put.versionID}})
+	vdResult.Steps = append(vdResult.Steps, newStep(c.verbose, "GET deleted versionId", "404 NoSuchVersion, the version is gone", getDeleted.status, getDeleted.code, err,
+		getDeleted.status == http.StatusNotFound, getDeleted.details()))
+
+	return c.finish(result, vdResult, startTime)
+}
+
+// finish sets the status from the compared steps
+func (c *VersionedDeleteChecker) finish(result output.TestResult, vdResult output.VersionedDeleteResult, startTime time.Time) output.TestResult {
+	var mismatched []string
+	for _, step := range vdResult.Steps {
+		if !step.Match {
+			mismatched = append(mismatched, step.Step)
+		}
+	}
+	vdResult.MatchesAWS = len(mismatched) == 0
+
+	if !vdResult.MatchesAWS {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("delete semantics differ from AWS: %s", strings.Join(mismatched, ", "))
+	}
+
+	result.Details = vdResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Versioned delete check completed in %v", result.Duration)
+
+	return result
+}
+
+// send sends a request for the test object and extracts the version headers
+func (c *VersionedDeleteChecker) send(method, key string, query url.Values) (versionedResponse, error) {
+	var body []byte
+	if method == "PUT" {
+		body = []byte("s3tester versioned delete test\n")
+	}
+
+	req, err := c.client.newRequest(method, key, query, body)
+	if err != nil {
+		return versionedResponse{}, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+
+	resp, respBody, err := c.client.do(req, body)
+	if err != nil {
+		return versionedResponse{}, err
+	}
+
+	return versionedResponse{
+		status:       resp.StatusCode,
+		versionID:    resp.Header.Get("x-amz-version-id"),
+		deleteMarker: strings.EqualFold(resp.Header.Get("x-amz-delete-marker"), "true"),
+		code:         errorCode(respBody),
+	}, nil
+}
+
+// versioningStatus returns the bucket's versioning status in lower case,
+// "disabled" when it was never enabled
+func (c *VersionedDeleteChecker) versioningStatus() (string, error) {
+	req, err := c.client.newRequest("GET", "", url.Values{"versioning": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, body))
+	}
+
+	var v struct {
+		Status string `xml:"Status"`
+	}
+	xml.Unmarshal(body, &v)
+	if v.Status == "" {
+		return "disabled", nil
+	}
+	return strings.ToLower(v.Status), nil
+}
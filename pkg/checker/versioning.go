@@ -0,0 +1,301 @@
+package checker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// VersioningChecker reads the bucket's versioning status and, when versioning
+// is enabled, verifies that the provider keeps, lists and serves the versions
+// of an object
+type VersioningChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewVersioningChecker creates a new versioning checker
+func NewVersioningChecker(config output.Config) *VersioningChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &VersioningChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *VersioningChecker) Name() string {
+	return "Versioning Check"
+}
+
+// versionResponse is the part of a response the round trip compares
+type versionResponse struct {
+	status    int
+	versionID string
+	body      string
+	code      string
+}
+
+// contentDetail notes a successful GET that did not return the content the
+// version was written with
+func (r versionResponse) contentDetail(content string) string {
+	if r.status != http.StatusOK || r.body == content {
+		return ""
+	}
+	return ", other content"
+}
+
+// listVersionsResult is the part of a ListObjectVersions response the round
+// trip compares
+type listVersionsResult struct {
+	Versions []struct {
+		Key       string `xml:"Key"`
+		VersionID string `xml:"VersionId"`
+		IsLatest  bool   `xml:"IsLatest"`
+	} `xml:"Version"`
+}
+
+// Check sends GET ?versioning and reports the status. On a bucket with
+// versioning enabled it writes two versions of a test object, lists them and
+// reads the first one back by versionId, failing when any step differs from
+// what versioning guarantees; both versions are removed again. The status
+// alone is read on other buckets and with --read-only.
+func (c *VersioningChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Versioning Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	vResult := output.VersioningResult{}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"versioning": {""}}, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketVersioning failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusForbidden:
+		result.Status = output.StatusWarn
+		result.Error = "GetBucketVersioning denied, the versioning status could not be read: " + parseErrorResponse(resp.StatusCode, body)
+	case resp.StatusCode == http.StatusNotImplemented || errorCode(body) == "NotImplemented":
+		result.Status = output.StatusSkip
+		result.Error = "the endpoint does not implement bucket versioning"
+	default:
+		result.Status = output.StatusFail
+		result.Error = "GetBucketVersioning failed: " + parseErrorResponse(resp.StatusCode, body)
+	}
+	if result.Error != "" {
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	var configuration struct {
+		Status    string `xml:"Status"`
+		MFADelete string `xml:"MfaDelete"`
+	}
+	if err := xml.Unmarshal(body, &configuration); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketVersioning returned an invalid response: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	vResult.Status = "disabled"
+	if configuration.Status != "" {
+		vResult.Status = strings.ToLower(configuration.Status)
+	}
+	vResult.MFADelete = strings.ToLower(configuration.MFADelete)
+	c.verbose.LogMessage("Versioning %s, MFA delete %q", vResult.Status, vResult.MFADelete)
+
+	switch {
+	case vResult.Status != "enabled":
+		vResult.RoundTrip = "not run: versioning is not enabled"
+	case c.Config.ReadOnly:
+		vResult.RoundTrip = "not run with --read-only"
+	}
+	if vResult.RoundTrip != "" {
+		result.Details = vResult
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	return c.roundTrip(result, vResult, startTime)
+}
+
+// roundTrip writes two versions of a test object, lists them and reads the
+// first one back by versionId and the second by key
+func (c *VersioningChecker) roundTrip(result output.TestResult, vResult output.VersioningResult, startTime time.Time) output.TestResult {
+	key := c.client.testObjectKey("versioning")
+	vResult.Key = key
+	contents := []string{"s3tester versioning test, version 1\n", "s3tester versioning test, version 2\n"}
+
+	// Remove every version this check created, whatever step it stopped at
+	defer c.client.cleanup(func() {
+		for _, versionID := range vResult.VersionIDs {
+			if resp, err := c.send("DELETE", key, url.Values{"versionId": {versionID}}, ""); err != nil || (resp.status >= 300 && resp.code != "NoSuchVersion") {
+				c.verbose.LogMessage("Failed to delete version %s of %s: %v %s", versionID, key, err, resp.code)
+			}
+		}
+	})
+
+	for i, content := range contents {
+		put, err := c.send("PUT", key, nil, content)
+		if put.versionID != "" {
+			vResult.VersionIDs = append(vResult.VersionIDs, put.versionID)
+		}
+		name := fmt.Sprintf("PUT version %d", i+1)
+		expected := "2xx with x-amz-version-id"
+		match := put.status < 300 && put.versionID != ""
+		if i > 0 {
+			expected = "2xx with a new x-amz-version-id"
+			match = match && len(vResult.VersionIDs) == 2 && vResult.VersionIDs[0] != vResult.VersionIDs[1]
+		}
+		step := newStep(c.verbose, name, expected, put.status, put.code, err, match, versionDetail(put.versionID))
+		vResult.Steps = append(vResult.Steps, step)
+		if err != nil || put.status >= 300 {
+			result.Status = output.StatusFail
+			result.Error = fmt.Sprintf("PUT failed: %s", step.Observed)
+			result.Details = vResult
+			result.Duration = time.Since(startTime)
+			return result
+		}
+	}
+	if len(vResult.VersionIDs) < 2 {
+		return c.finish(result, vResult, startTime)
+	}
+	first, second := vResult.VersionIDs[0], vResult.VersionIDs[1]
+
+	vResult.Steps = append(vResult.Steps, c.listStep(key, first, second))
+
+	get, err := c.send("GET", key, url.Values{"versionId": {first}}, "")
+	vResult.Steps = append(vResult.Steps, newStep(c.verbose, "GET version 1 by versionId", "200 with the first content", get.status, get.code, err,
+		get.status == http.StatusOK && get.body == contents[0], versionDetail(get.versionID), get.contentDetail(contents[0])))
+
+	latest, err := c.send("GET", key, nil, "")
+	vResult.Steps = append(vResult.Steps, newStep(c.verbose, "GET by key", "200 with the second content", latest.status, latest.code, err,
+		latest.status == http.StatusOK && latest.body == contents[1] && latest.versionID == second, versionDetail(latest.versionID), latest.contentDetail(contents[1])))
+
+	return c.finish(result, vResult, startTime)
+}
+
+// listStep lists the versions of the test object and compares them with the
+// two that were written
+func (c *VersioningChecker) listStep(key, first, second string) output.Step {
+	const name, expected = "List versions", "both versions, the second one latest"
+	step := output.Step{Step: name, Expected: expected}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"versions": {""}, "prefix": {key}}, nil)
+	if err == nil {
+		var resp *http.Response
+		var body []byte
+		resp, body, err = c.client.do(req, nil)
+		if err == nil && resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("%s", parseErrorResponse(resp.StatusCode, body))
+		}
+		if err == nil {
+			var listing listVersionsResult
+			if err = xml.Unmarshal(body, &listing); err == nil {
+				latest := map[string]bool{}
+				for _, version := range listing.Versions {
+					if version.Key == key {
+						latest[version.VersionID] = version.IsLatest
+					}
+				}
+				isLatest, listed := latest[second]
+				_, listedFirst := latest[first]
+				step.Match = len(latest) == 2 && listed && listedFirst && isLatest
+				step.Observed = fmt.Sprintf("%d versions listed", len(latest))
+				if listed && !isLatest {
+					step.Observed += ", version 2 not latest"
+				}
+			}
+		}
+	}
+	if err != nil {
+		step.Observed = err.Error()
+	}
+
+	c.verbose.LogMessage("%s: expected %s, observed %s", name, expected, step.Observed)
+
+	return step
+}
+
+// finish sets the status from the compared steps
+func (c *VersioningChecker) finish(result output.TestResult, vResult output.VersioningResult, startTime time.Time) output.TestResult {
+	var mismatched []string
+	for _, step := range vResult.Steps {
+		if !step.Match {
+			mismatched = append(mismatched, step.Step)
+		}
+	}
+	vResult.Works = len(mismatched) == 0
+	vResult.RoundTrip = "passed"
+
+	if !vResult.Works {
+		vResult.RoundTrip = "failed"
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("versioning does not behave correctly: %s", strings.Join(mismatched, ", "))
+	}
+
+	result.Details = vResult
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Versioning check completed in %v", result.Duration)
+
+	return result
+}
+
+// send sends a request for the test object and extracts the version header
+// and the body
+func (c *VersioningChecker) send(method, key string, query url.Values, content string) (versionResponse, error) {
+	var body []byte
+	if method == "PUT" {
+		body = []byte(content)
+	}
+
+	req, err := c.client.newRequest(method, key, query, body)
+	if err != nil {
+		return versionResponse{}, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+
+	resp, respBody, err := c.client.do(req, body)
+	if err != nil {
+		return versionResponse{}, err
+	}
+
+	response := versionResponse{
+		status:    resp.StatusCode,
+		versionID: resp.Header.Get("x-amz-version-id"),
+		code:      errorCode(respBody),
+	}
+	if resp.StatusCode < 300 {
+		response.body = string(respBody)
+	}
+	return response, nil
+}
@@ -0,0 +1,86 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// Warmup runs a throwaway HEAD of the bucket twice before the checks, each on
+// a new connection, so DNS caches and the endpoint are primed and the checks
+// measure steady-state latency. The first cycle is reported as cold and the
+// second as warm; a failed cycle is recorded but never fails the run.
+func Warmup(ctx context.Context, config output.Config) *output.WarmupResult {
+	verbose := NewVerboseLogger(config.Verbose)
+	verbose.LogSection("Warming Up")
+
+	result := &output.WarmupResult{
+		Cold: warmupCycle(ctx, config, verbose),
+		Warm: warmupCycle(ctx, config, verbose),
+	}
+
+	verbose.LogMessage("Cold: %.2fms, warm: %.2fms", result.Cold.TotalMs, result.Warm.TotalMs)
+
+	return result
+}
+
+// warmupCycle sends one HEAD of the bucket through a new client and records
+// the timing of each phase
+func warmupCycle(ctx context.Context, config output.Config, verbose *VerboseLogger) output.WarmupCycle {
+	var cycle output.WarmupCycle
+
+	// A new client per cycle, so the warm cycle dials a connection of its own
+	client := newS3Client(config, verbose)
+	client.bind(ctx)
+	defer client.httpClient.CloseIdleConnections()
+
+	req, err := client.newRequest("HEAD", "", nil, nil)
+	if err != nil {
+		cycle.Error = err.Error()
+		return cycle
+	}
+
+	var dnsStart, dnsDone, connectStart, connectDone, tlsStart, tlsDone, firstByte time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:  func(httptrace.DNSDoneInfo) { dnsDone = time.Now() },
+		ConnectStart: func(string, string) {
+			if connectStart.IsZero() {
+				connectStart = time.Now()
+			}
+		},
+		ConnectDone:          func(string, string, error) { connectDone = time.Now() },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { tlsDone = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
+
+	start := time.Now()
+	resp, _, err := client.do(req, nil)
+	cycle.TotalMs = durationToMs(time.Since(start))
+	if err != nil {
+		verbose.LogMessage("Warm-up request failed: %v", err)
+		cycle.Error = err.Error()
+	} else {
+		cycle.StatusCode = resp.StatusCode
+	}
+
+	if !dnsDone.IsZero() {
+		cycle.DNSMs = durationToMs(dnsDone.Sub(dnsStart))
+	}
+	if !connectDone.IsZero() {
+		cycle.ConnectMs = durationToMs(connectDone.Sub(connectStart))
+	}
+	if !tlsDone.IsZero() {
+		cycle.TLSHandshakeMs = durationToMs(tlsDone.Sub(tlsStart))
+	}
+	if !firstByte.IsZero() {
+		cycle.TTFBMs = durationToMs(firstByte.Sub(start))
+	}
+
+	return cycle
+}
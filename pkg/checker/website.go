@@ -0,0 +1,288 @@
+package checker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// websiteCompareLimit is the number of bytes of the index document read
+// from the website endpoint and through the S3 API
+const websiteCompareLimit = 1 << 20
+
+// websiteDashRegions are the AWS regions whose website endpoints are written
+// s3-website-<region>; the others use s3-website.<region>
+var websiteDashRegions = map[string]bool{
+	"us-east-1":      true,
+	"us-west-1":      true,
+	"us-west-2":      true,
+	"ap-southeast-1": true,
+	"ap-southeast-2": true,
+	"ap-northeast-1": true,
+	"eu-west-1":      true,
+	"sa-east-1":      true,
+	"us-gov-west-1":  true,
+}
+
+// WebsiteChecker reads the bucket's static website configuration and
+// requests the index document from the website endpoint, as a visitor would,
+// and through the S3 API
+type WebsiteChecker struct {
+	BaseChecker
+	client  *s3Client
+	verbose *VerboseLogger
+}
+
+// NewWebsiteChecker creates a new static website checker
+func NewWebsiteChecker(config output.Config) *WebsiteChecker {
+	verbose := NewVerboseLogger(config.Verbose)
+	return &WebsiteChecker{
+		BaseChecker: NewBaseChecker(config),
+		client:      newS3Client(config, verbose),
+		verbose:     verbose,
+	}
+}
+
+// Name returns the name of the checker
+func (c *WebsiteChecker) Name() string {
+	return "Static Website Check"
+}
+
+// websiteConfiguration is the GetBucketWebsite response
+type websiteConfiguration struct {
+	IndexDocument struct {
+		Suffix string `xml:"Suffix"`
+	} `xml:"IndexDocument"`
+	ErrorDocument struct {
+		Key string `xml:"Key"`
+	} `xml:"ErrorDocument"`
+	RedirectAllRequestsTo struct {
+		HostName string `xml:"HostName"`
+		Protocol string `xml:"Protocol"`
+	} `xml:"RedirectAllRequestsTo"`
+	RoutingRules []struct{} `xml:"RoutingRules>RoutingRule"`
+}
+
+// Check sends GET ?website and, on a bucket configured as a website, sends a
+// plain unsigned GET for / to the website endpoint: the index document must
+// be served with the content the S3 API returns for it, or a redirect when
+// every request is redirected. The check is skipped on buckets without a
+// website configuration, warns when the configuration may not be read, and
+// fails when visitors are not served the index document.
+func (c *WebsiteChecker) Check(ctx context.Context) output.TestResult {
+	c.client.bind(ctx)
+	startTime := time.Now()
+
+	c.verbose.LogSection("Starting Static Website Check")
+
+	result := output.TestResult{
+		TestName: c.Name(),
+		Status:   output.StatusPass,
+	}
+
+	req, err := c.client.newRequest("GET", "", url.Values{"website": {""}}, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketWebsite failed: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	website := output.WebsiteResult{StatusCode: resp.StatusCode}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case errorCode(body) == "NoSuchWebsiteConfiguration":
+		result.Status = output.StatusSkip
+		result.Error = "the bucket is not configured as a static website"
+		result.Details = website
+		result.Duration = time.Since(startTime)
+		return result
+	case resp.StatusCode == http.StatusForbidden:
+		result.Status = output.StatusWarn
+		result.Error = "GetBucketWebsite denied, the website configuration could not be read: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	case resp.StatusCode == http.StatusNotImplemented || errorCode(body) == "NotImplemented":
+		result.Status = output.StatusSkip
+		result.Error = "the endpoint does not implement static website hosting"
+		result.Duration = time.Since(startTime)
+		return result
+	default:
+		result.Status = output.StatusFail
+		result.Error = "GetBucketWebsite failed: " + parseErrorResponse(resp.StatusCode, body)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	var configuration websiteConfiguration
+	if err := xml.Unmarshal(body, &configuration); err != nil {
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GetBucketWebsite returned an invalid response: %v", err)
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	website.Configured = true
+	website.IndexDocument = configuration.IndexDocument.Suffix
+	website.ErrorDocument = configuration.ErrorDocument.Key
+	website.RedirectTo = configuration.RedirectAllRequestsTo.HostName
+	website.RoutingRules = len(configuration.RoutingRules)
+	c.verbose.LogMessage("Website index %q, error %q, redirect to %q, %d routing rules",
+		website.IndexDocument, website.ErrorDocument, website.RedirectTo, website.RoutingRules)
+
+	website.WebsiteURL = c.websiteURL()
+	if website.WebsiteURL == "" {
+		result.Details = website
+		result.Duration = time.Since(startTime)
+		result.Error = "the website endpoint of this provider is not known: set --website-endpoint to request the index document"
+		result.Status = output.StatusWarn
+		return result
+	}
+
+	website.Website = c.fetch(ctx, website.WebsiteURL)
+	c.verbose.LogMessage("Website endpoint: HTTP %d, %d bytes %s", website.Website.StatusCode, website.Website.Size, website.Website.Error)
+
+	if website.RedirectTo != "" {
+		location := website.Website.Location
+		switch {
+		case website.Website.Error != "":
+			result.Status = output.StatusFail
+			result.Error = fmt.Sprintf("GET %s failed: %s", website.WebsiteURL, website.Website.Error)
+		case website.Website.StatusCode != http.StatusMovedPermanently || !strings.Contains(location, website.RedirectTo):
+			result.Status = output.StatusFail
+			result.Error = fmt.Sprintf("the website endpoint answered HTTP %d (Location %q) instead of redirecting to %s",
+				website.Website.StatusCode, location, website.RedirectTo)
+		}
+		result.Details = website
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	// The index document as the S3 API returns it, to tell a missing
+	// document from one visitors may not read
+	api := c.apiFetch(website.IndexDocument)
+	website.API = api
+
+	switch {
+	case website.Website.Error != "":
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("GET %s failed: %s", website.WebsiteURL, website.Website.Error)
+	case website.Website.StatusCode == http.StatusOK && successful(api.StatusCode):
+		website.BodyMatch = website.Website.SHA256 == api.SHA256
+		if !website.BodyMatch {
+			result.Status = output.StatusWarn
+			result.Error = fmt.Sprintf("the website endpoint serves other content for / than the S3 API returns for %s", website.IndexDocument)
+		}
+	case website.Website.StatusCode == http.StatusOK:
+		result.Status = output.StatusWarn
+		result.Error = fmt.Sprintf("the website endpoint serves /, but %s could not be read through the S3 API to compare: %s",
+			website.IndexDocument, api.Error)
+	case api.StatusCode == http.StatusNotFound:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the index document %s does not exist in the bucket: visitors get HTTP %d", website.IndexDocument, website.Website.StatusCode)
+	case website.Website.StatusCode == http.StatusForbidden:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the website endpoint returned HTTP 403 for /: the index document %s is not publicly readable; check the bucket policy and Block Public Access", website.IndexDocument)
+	default:
+		result.Status = output.StatusFail
+		result.Error = fmt.Sprintf("the website endpoint returned HTTP %d for / instead of the index document %s", website.Website.StatusCode, website.IndexDocument)
+	}
+
+	result.Details = website
+	result.Duration = time.Since(startTime)
+
+	c.verbose.LogMessage("Static website check completed in %v", result.Duration)
+
+	return result
+}
+
+// websiteURL returns --website-endpoint, or on AWS the bucket's website
+// endpoint in its region; other providers have no known website endpoint
+func (c *WebsiteChecker) websiteURL() string {
+	if c.Config.WebsiteEndpoint != "" {
+		return strings.TrimSuffix(c.Config.WebsiteEndpoint, "/") + "/"
+	}
+	if c.Config.Provider != "aws" {
+		return ""
+	}
+	separator := "."
+	if websiteDashRegions[c.Config.Region] {
+		separator = "-"
+	}
+	return fmt.Sprintf("http://%s.s3-website%s%s.amazonaws.com/", c.Config.Bucket, separator, c.Config.Region)
+}
+
+// fetch sends an unsigned GET to the website endpoint without following
+// redirects
+func (c *WebsiteChecker) fetch(ctx context.Context, websiteURL string) output.WebsiteFetch {
+	req, err := http.NewRequestWithContext(ctx, "GET", websiteURL, nil)
+	if err != nil {
+		return output.WebsiteFetch{Error: err.Error()}
+	}
+	req.Header.Set("User-Agent", "s3-bucket-tester/1.0")
+	c.verbose.LogRequest(req)
+
+	// The website endpoint is another host than the S3 endpoint, like a CDN
+	client := newCDNClient(c.Config)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	if err != nil {
+		return output.WebsiteFetch{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+	c.verbose.LogResponse(resp)
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, websiteCompareLimit))
+	if err != nil {
+		return output.WebsiteFetch{StatusCode: resp.StatusCode, Error: err.Error()}
+	}
+	return websiteFetch(resp, body)
+}
+
+// apiFetch reads the first bytes of the index document through the S3 API
+func (c *WebsiteChecker) apiFetch(key string) output.WebsiteFetch {
+	if key == "" {
+		return output.WebsiteFetch{Error: "no index document configured"}
+	}
+	req, err := c.client.newRequest("GET", key, nil, nil)
+	if err != nil {
+		return output.WebsiteFetch{Error: err.Error()}
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", websiteCompareLimit-1))
+	resp, body, err := c.client.do(req, nil)
+	if err != nil {
+		return output.WebsiteFetch{Error: err.Error()}
+	}
+	if !successful(resp.StatusCode) {
+		return output.WebsiteFetch{StatusCode: resp.StatusCode, Error: parseErrorResponse(resp.StatusCode, body)}
+	}
+	return websiteFetch(resp, body)
+}
+
+// websiteFetch summarizes a response for the index document
+func websiteFetch(resp *http.Response, body []byte) output.WebsiteFetch {
+	sum := sha256.Sum256(body)
+	return output.WebsiteFetch{
+		StatusCode:  resp.StatusCode,
+		Size:        int64(len(body)),
+		ContentType: resp.Header.Get("Content-Type"),
+		Location:    resp.Header.Get("Location"),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+}
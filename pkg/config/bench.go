@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Limits of the bench mode; every worker holds one object in memory
+const (
+	maxBenchSize        = 1024 * 1024 * 1024
+	maxBenchCount       = 100000
+	maxBenchConcurrency = 256
+)
+
+// ParseBenchFlags parses the arguments of the bench mode. The benchmark
+// settings are added to the flags of a check run, which provide the
+// endpoint, bucket and credentials.
+func ParseBenchFlags(args []string) (*Config, error) {
+	config := GetDefaultConfig()
+	config.BenchSize, config.BenchCount, config.BenchConcurrency = 1024*1024, 100, 8
+
+	flags := newFlagSet("bench")
+	addBenchFlags(flags, config)
+	addCheckFlags(flags, config)
+
+	positional, err := flags.parse(args)
+	if err != nil {
+		return nil, exitOnHelp(err, printBenchHelp)
+	}
+	if len(positional) > 0 {
+		return nil, fmt.Errorf("unexpected argument %q: bench takes only flags", positional[0])
+	}
+
+	if err := config.resolve(flags.isSet("region")); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// addBenchFlags defines the benchmark settings on the flag set
+func addBenchFlags(f *flagSet, config *Config) {
+	f.section("BENCHMARK FLAGS")
+	f.funcVar("size", "", "bytes", "Object size, with an optional K, M or G suffix (default: 1M, max: 1G)", func(value string) error {
+		size, err := parseSize(value)
+		if err != nil {
+			return err
+		}
+		config.BenchSize = size
+		return nil
+	})
+	f.intVar(&config.BenchCount, "count", "", "n", "Number of objects (default: 100)")
+	f.intVar(&config.BenchConcurrency, "concurrency", "", "n", "Parallel requests (default: 8)")
+}
+
+// ValidateBench validates the benchmark settings; Validate must be called as well
+func (c *Config) ValidateBench() error {
+	if c.ReadOnly {
+		return fmt.Errorf("bench uploads objects and cannot run with --read-only")
+	}
+	if c.BenchSize < 1 || c.BenchSize > maxBenchSize {
+		return fmt.Errorf("invalid size: must be between 1 byte and 1G")
+	}
+	if c.BenchCount < 1 || c.BenchCount > maxBenchCount {
+		return fmt.Errorf("invalid count: must be between 1 and %d", maxBenchCount)
+	}
+	if c.BenchConcurrency < 1 || c.BenchConcurrency > maxBenchConcurrency {
+		return fmt.Errorf("invalid concurrency: must be between 1 and %d", maxBenchConcurrency)
+	}
+	return nil
+}
+
+// parseSize parses a byte count with an optional binary K, M or G suffix,
+// such as 4K or 16M
+func parseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "B"), "I")
+
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1024
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1024 * 1024
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		value = value[:len(value)-1]
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("use a byte count such as 4096, 4K, 16M or 1G")
+	}
+	return n * multiplier, nil
+}
+
+// printBenchHelp prints the help message for the bench mode
+func printBenchHelp() {
+	fmt.Print(`S3 Bucket Tester - Throughput benchmark
+
+USAGE:
+    s3tester bench [FLAGS]
+
+Uploads --count objects of --size bytes with --concurrency parallel
+workers, downloads them again and deletes them, then reports throughput,
+request rate and latency percentiles for uploads and downloads. Exits with
+code 1 when any request failed.
+
+`)
+	flags := newFlagSet("bench")
+	config := GetDefaultConfig()
+	addBenchFlags(flags, config)
+	addCheckFlags(flags, config)
+	flags.printFlags(os.Stdout)
+
+	fmt.Println(`EXAMPLES:
+    s3tester bench --endpoint wasabi --region eu-central-1 --bucket bench-bucket \
+                   --size 16M --count 64 --concurrency 16
+
+    s3tester bench --endpoint http://localhost:9000 --bucket test --path-style \
+                   --size 4K --count 2000 --concurrency 32 --output-file minio.json`)
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// CertWatchConfig holds the configuration for the cert-watch mode
+type CertWatchConfig struct {
+	Endpoints     []string
+	WarnDays      int
+	Insecure      bool
+	Timeout       int
+	Verbose       bool
+	ASCII         bool
+	OutputFile    string
+	NotifyWebhook string
+	Proxy         string
+	IPFamily      string
+	CACert        string
+	ClientCert    string
+	ClientKey     string
+	SNI           string
+}
+
+// ParseCertWatchFlags parses the arguments of the cert-watch mode
+func ParseCertWatchFlags(args []string) (*CertWatchConfig, error) {
+	config := &CertWatchConfig{
+		WarnDays: 30,
+		Timeout:  10,
+	}
+
+	flags := newFlagSet("cert-watch")
+	addCertWatchFlags(flags, config)
+
+	// Positional arguments are endpoints
+	positional, err := flags.parse(args)
+	if err != nil {
+		return nil, exitOnHelp(err, printCertWatchHelp)
+	}
+	config.Endpoints = append(config.Endpoints, positional...)
+
+	return config, nil
+}
+
+// addCertWatchFlags defines the flags of the cert-watch mode on the flag set
+func addCertWatchFlags(f *flagSet, config *CertWatchConfig) {
+	f.section("FLAGS")
+	f.funcVar("endpoint", "e", "url", "Endpoint to check (repeatable, or pass as arguments)", func(value string) error {
+		config.Endpoints = append(config.Endpoints, value)
+		return nil
+	})
+	f.intVar(&config.WarnDays, "warn-days", "", "days", "Warning threshold in days (default: 30)")
+	f.boolVar(&config.Insecure, "insecure", "k", "Do not require a trusted certificate chain")
+	f.intVar(&config.Timeout, "timeout", "", "seconds", "Connection timeout in seconds (default: 10)")
+	f.stringVar(&config.Proxy, "proxy", "", "url", "Send all connections through an http:// or socks5:// proxy (default: HTTP_PROXY/HTTPS_PROXY/NO_PROXY)")
+	addIPFamilyFlags(f, &config.IPFamily, "for every endpoint")
+	f.stringVar(&config.CACert, "ca-cert", "", "path", "Trust the PEM CA certificates in this file or directory in addition to the system roots (internal CAs)")
+	f.stringVar(&config.ClientCert, "client-cert", "", "file", "PEM client certificate for mutual TLS")
+	f.stringVar(&config.ClientKey, "client-key", "", "file", "PEM private key of --client-cert (default: read from the --client-cert file)")
+	f.stringVar(&config.SNI, "sni", "", "name", "TLS server name to send and verify instead of the endpoint host (testing by IP or behind SNI routing)")
+	f.stringVar(&config.OutputFile, "output-file", "o", "file", "Save JSON results to file")
+	f.stringVar(&config.NotifyWebhook, "notify-webhook", "", "url", "POST a JSON notification when any certificate needs attention")
+	f.boolVar(&config.Verbose, "verbose", "v", "Enable verbose output")
+	f.boolVar(&config.ASCII, "ascii", "", "Plain ASCII output without icons or color")
+	f.help()
+}
+
+// Validate validates the cert-watch configuration
+func (c *CertWatchConfig) Validate() error {
+	if len(c.Endpoints) == 0 {
+		return fmt.Errorf("at least one endpoint is required")
+	}
+	if c.WarnDays < 0 {
+		return fmt.Errorf("invalid warn-days: must be 0 or greater")
+	}
+	if c.Timeout < 1 {
+		return fmt.Errorf("invalid timeout: must be greater than 0")
+	}
+	if err := validateTransport(c.CACert, c.Proxy, c.SNI, c.ClientCert, &c.ClientKey); err != nil {
+		return err
+	}
+
+	// Normalize endpoints to HTTPS URLs
+	for i, endpoint := range c.Endpoints {
+		if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+			c.Endpoints[i] = "https://" + endpoint
+		}
+	}
+
+	return nil
+}
+
+// ToOutputConfig converts the cert-watch config to an output config for the TLS checker
+func (c *CertWatchConfig) ToOutputConfig(endpoint string) output.Config {
+	return output.Config{
+		Endpoint:    endpoint,
+		Port:        ParsePort(endpoint),
+		Insecure:    c.Insecure,
+		Timeout:     c.Timeout,
+		DNSTimeout:  c.Timeout,
+		TCPTimeout:  c.Timeout,
+		HTTPTimeout: c.Timeout,
+		OutputFile:  c.OutputFile,
+		Verbose:     c.Verbose,
+		Proxy:       c.Proxy,
+		IPFamily:    c.IPFamily,
+		CACert:      c.CACert,
+		ClientCert:  c.ClientCert,
+		ClientKey:   c.ClientKey,
+		SNI:         c.SNI,
+	}
+}
+
+// printCertWatchHelp prints the help message for the cert-watch mode
+func printCertWatchHelp() {
+	fmt.Print(`S3 Bucket Tester - Certificate expiry watch
+
+USAGE:
+    s3tester cert-watch [FLAGS] <endpoint> [<endpoint>...]
+
+Checks only the TLS certificate expiry of each endpoint and exits with
+code 1 when any certificate expires within the warning threshold or
+cannot be retrieved.
+
+`)
+	flags := newFlagSet("cert-watch")
+	addCertWatchFlags(flags, &CertWatchConfig{})
+	flags.printFlags(os.Stdout)
+
+	fmt.Println(`EXAMPLES:
+    s3tester cert-watch s3.example.com minio.internal:9000 --warn-days 21
+
+    s3tester cert-watch --endpoint https://s3.example.com \
+                        --notify-webhook https://hooks.example.com/storage`)
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCertWatchConfigValidate(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	tests := []struct {
+		name          string
+		modify        func(*CertWatchConfig)
+		wantErr       string
+		wantEndpoints []string
+	}{
+		{
+			name:          "endpoints normalized to HTTPS",
+			wantEndpoints: []string{"https://s3.example.com", "http://minio.internal:9000"},
+		},
+		{
+			name:          "proxy and SNI",
+			modify:        func(c *CertWatchConfig) { c.Proxy = "socks5://bastion"; c.SNI = "s3.example.com" },
+			wantEndpoints: []string{"https://s3.example.com", "http://minio.internal:9000"},
+		},
+		{"no endpoint", func(c *CertWatchConfig) { c.Endpoints = nil }, "at least one endpoint is required", nil},
+		{"negative warn-days", func(c *CertWatchConfig) { c.WarnDays = -1 }, "invalid warn-days", nil},
+		{"zero timeout", func(c *CertWatchConfig) { c.Timeout = 0 }, "invalid timeout", nil},
+		{"unreadable CA certificates", func(c *CertWatchConfig) { c.CACert = missing }, "invalid ca-cert", nil},
+		{"unsupported proxy scheme", func(c *CertWatchConfig) { c.Proxy = "ftp://proxy" }, "invalid proxy", nil},
+		{"client key without certificate", func(c *CertWatchConfig) { c.ClientKey = missing }, "--client-key requires --client-cert", nil},
+		{"unreadable client certificate", func(c *CertWatchConfig) { c.ClientCert = missing }, "invalid client-cert", nil},
+		{"SNI as an IP address", func(c *CertWatchConfig) { c.SNI = "10.0.0.5" }, "invalid sni", nil},
+		{"SNI with a port", func(c *CertWatchConfig) { c.SNI = "s3.example.com:443" }, "invalid sni", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &CertWatchConfig{
+				Endpoints: []string{"s3.example.com", "http://minio.internal:9000"},
+				WarnDays:  30,
+				Timeout:   10,
+			}
+			if tt.modify != nil {
+				tt.modify(config)
+			}
+
+			err := config.Validate()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if !reflect.DeepEqual(config.Endpoints, tt.wantEndpoints) {
+				t.Errorf("Validate() endpoints = %q, want %q", config.Endpoints, tt.wantEndpoints)
+			}
+		})
+	}
+}
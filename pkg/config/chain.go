@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/credentials"
+)
+
+// resolveCredentialChain looks up credentials when none were given on the
+// command line: environment, shared profile (AWS_PROFILE or default), web
+// identity, ECS task credentials and EC2 instance metadata, in that order.
+// Finding nothing is not an error here; Validate reports missing keys.
+func (c *Config) resolveCredentialChain(regionSet bool) error {
+	value, err := c.credentialChain(regionSet).Retrieve()
+	if err != nil {
+		if errors.Is(err, credentials.ErrNoCredentials) {
+			return nil
+		}
+		return fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+
+	c.AccessKey = value.AccessKey
+	c.SecretKey = value.SecretKey
+	if c.SessionToken == "" {
+		c.SessionToken = value.SessionToken
+	}
+	c.CredentialSource = value.Source
+	c.CredentialExpiration = value.Expiration
+
+	return nil
+}
+
+// credentialChain returns the default provider chain with the shared profile
+// provider inserted right after the environment, as in the AWS SDKs
+func (c *Config) credentialChain(regionSet bool) credentials.Chain {
+	defaults := credentials.DefaultChain(c.Region, time.Duration(c.Timeout)*time.Second)
+	chain := credentials.Chain{defaults[0], c.sharedProfileProvider(regionSet)}
+	return append(chain, defaults[1:]...)
+}
+
+// sharedProfileProvider returns a provider for the profile named by
+// AWS_PROFILE, or the default profile
+func (c *Config) sharedProfileProvider(regionSet bool) credentials.Provider {
+	name := os.Getenv("AWS_PROFILE")
+	explicit := name != ""
+	if !explicit {
+		name = "default"
+	}
+
+	return credentials.ProviderFunc{
+		SourceName: "shared profile",
+		Fn: func() (*credentials.Value, error) {
+			profile, err := LoadProfile(name)
+			if err != nil {
+				if explicit {
+					return nil, err
+				}
+				return nil, credentials.ErrNoCredentials
+			}
+			if profile.AccessKey == "" || profile.SecretKey == "" {
+				if explicit {
+					return nil, fmt.Errorf("profile %q does not define static credentials", name)
+				}
+				return nil, credentials.ErrNoCredentials
+			}
+
+			if !regionSet && profile.Region != "" {
+				c.Region = profile.Region
+			}
+
+			return &credentials.Value{
+				AccessKey:    profile.AccessKey,
+				SecretKey:    profile.SecretKey,
+				SessionToken: profile.SessionToken,
+				Source:       "profile " + name,
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,365 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Commands are the subcommands of s3tester; check runs when none is given
+var Commands = []struct {
+	Name        string
+	Description string
+}{
+	{"check", "Run the checks against a bucket (default)"},
+	{"bench", "Measure upload and download throughput and latency"},
+	{"cert-watch", "Check only the TLS certificate expiry of endpoints"},
+	{"compare", "Run the same checks against several endpoints side by side"},
+	{"policy", "Print the least-privilege IAM policy for the selected checks"},
+	{"history", "Show trends of the runs recorded with --history"},
+	{"providers", "List the built-in providers and their capabilities"},
+	{"version", "Show version information"},
+	{"help", "Show the help of a command"},
+}
+
+// SplitCommand returns the subcommand named by the first argument and the
+// remaining arguments. Arguments that start with a flag run the check
+// command, as s3tester did before it had subcommands.
+func SplitCommand(args []string) (string, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "check", args, nil
+	}
+	for _, command := range Commands {
+		if args[0] == command.Name {
+			return command.Name, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("unknown command %q (see s3tester help)", args[0])
+}
+
+// PrintHelp prints the help of a command, or the overview when the command
+// is empty
+func PrintHelp(command string) error {
+	switch command {
+	case "":
+		printUsage()
+	case "check":
+		printHelp()
+	case "bench":
+		printBenchHelp()
+	case "cert-watch":
+		printCertWatchHelp()
+	case "compare":
+		printCompareHelp()
+	case "policy":
+		printPolicyHelp()
+	case "history":
+		printHistoryHelp()
+	case "providers", "version", "help":
+		printUsage()
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+	return nil
+}
+
+// printUsage prints the command overview
+func printUsage() {
+	fmt.Print(`S3 Bucket Tester - Test S3-compatible storage providers
+
+USAGE:
+    s3tester [check] [FLAGS]
+    s3tester <command> [FLAGS]
+
+COMMANDS:
+`)
+	for _, command := range Commands {
+		fmt.Printf("    %-14s%s\n", command.Name, command.Description)
+	}
+	fmt.Print(`
+Flags take their value as --flag value or --flag=value, and boolean flags
+accept --flag=false. Run 's3tester help <command>' or 's3tester <command>
+--help' for the flags of a command.
+`)
+}
+
+// Layout of the generated flag help
+const (
+	helpIndent = 4
+	helpColumn = 31
+	helpWidth  = 80
+)
+
+// cliFlag describes a flag for the generated help
+type cliFlag struct {
+	name  string
+	short string
+	arg   string
+	usage string
+}
+
+// cliSection is a titled group of flags in the help, with an optional note
+// printed below them
+type cliSection struct {
+	title string
+	flags []cliFlag
+	note  string
+}
+
+// flagSet wraps flag.FlagSet with short aliases, flags and positional
+// arguments in any order, and help generated from the flag definitions
+type flagSet struct {
+	fs       *flag.FlagSet
+	shorts   map[string]string
+	sections []*cliSection
+}
+
+// newFlagSet creates an empty flag set for a command
+func newFlagSet(command string) *flagSet {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	return &flagSet{fs: fs, shorts: make(map[string]string)}
+}
+
+// section starts a new group of flags in the help
+func (f *flagSet) section(title string) {
+	f.sections = append(f.sections, &cliSection{title: title})
+}
+
+// note sets the text printed below the flags of the current section
+func (f *flagSet) note(text string) {
+	f.sections[len(f.sections)-1].note = text
+}
+
+// describe records a flag in the current section of the help
+func (f *flagSet) describe(name, short, arg, usage string) {
+	section := f.sections[len(f.sections)-1]
+	section.flags = append(section.flags, cliFlag{name: name, short: short, arg: arg, usage: usage})
+}
+
+// define registers a value under its long name and short alias
+func (f *flagSet) define(value flag.Value, name, short, arg, usage string) {
+	f.fs.Var(value, name, usage)
+	if short != "" {
+		f.fs.Var(value, short, usage)
+		f.shorts[name] = short
+	}
+	f.describe(name, short, arg, usage)
+}
+
+func (f *flagSet) stringVar(p *string, name, short, arg, usage string) {
+	f.define((*stringValue)(p), name, short, arg, usage)
+}
+
+func (f *flagSet) intVar(p *int, name, short, arg, usage string) {
+	f.define((*intValue)(p), name, short, arg, usage)
+}
+
+func (f *flagSet) boolVar(p *bool, name, short, usage string) {
+	f.define((*boolValue)(p), name, short, "", usage)
+}
+
+// funcVar registers a flag with a value whose parsing is done by fn
+func (f *flagSet) funcVar(name, short, arg, usage string, fn func(string) error) {
+	f.define(funcValue(fn), name, short, arg, usage)
+}
+
+// boolFunc registers a flag without a value that calls fn when given
+func (f *flagSet) boolFunc(name, short, usage string, fn func() error) {
+	f.define(boolFuncValue(fn), name, short, "", usage)
+}
+
+// help records --help, -h in the help; the flag package handles them itself
+func (f *flagSet) help() {
+	f.describe("help", "h", "", "Show this help message")
+}
+
+// parse parses the arguments and returns the positional ones. Flags may
+// follow positional arguments, and everything after -- is positional.
+// flag.ErrHelp is returned for --help and -h.
+func (f *flagSet) parse(args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := f.fs.Parse(args); err != nil {
+			return nil, flagError(err)
+		}
+		rest := f.fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// isSet reports whether a flag was given under its long name or alias
+func (f *flagSet) isSet(name string) bool {
+	set := false
+	f.fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == name || (fl.Name != "" && fl.Name == f.shorts[name]) {
+			set = true
+		}
+	})
+	return set
+}
+
+// printFlags prints the sections of the flag set with the flags' usage
+// wrapped into a column
+func (f *flagSet) printFlags(w io.Writer) {
+	for _, section := range f.sections {
+		fmt.Fprintf(w, "%s:\n", section.title)
+		for _, fl := range section.flags {
+			name := "    --" + fl.name
+			if fl.short != "" {
+				name = "-" + fl.short + ", --" + fl.name
+			}
+			if fl.arg != "" {
+				name += " <" + fl.arg + ">"
+			}
+			line := strings.Repeat(" ", helpIndent) + name
+			if len(line) > helpColumn-2 {
+				fmt.Fprintln(w, line)
+				line = ""
+			}
+			for _, text := range wrapText(fl.usage, helpWidth-helpColumn) {
+				fmt.Fprintf(w, "%-*s%s\n", helpColumn, line, text)
+				line = ""
+			}
+		}
+		if section.note != "" {
+			fmt.Fprintln(w)
+			for _, text := range strings.Split(section.note, "\n") {
+				fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", helpIndent), text)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// wrapText splits text into lines of at most width characters at spaces
+func wrapText(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	return append(lines, line)
+}
+
+// flagError rewrites the errors of the flag package in the wording
+// s3tester uses for its flags
+func flagError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	message := err.Error()
+	if name, ok := strings.CutPrefix(message, "flag provided but not defined: -"); ok {
+		return fmt.Errorf("unknown flag: %s", flagName(name))
+	}
+	if name, ok := strings.CutPrefix(message, "flag needs an argument: -"); ok {
+		return fmt.Errorf("%s requires a value", flagName(name))
+	}
+	if after, ok := strings.CutPrefix(message, "invalid boolean flag "); ok {
+		name, reason, _ := strings.Cut(after, ": ")
+		return fmt.Errorf("%s: %s", flagName(name), reason)
+	}
+	for _, separator := range []string{" for flag -", " for -"} {
+		if before, after, ok := strings.Cut(message, separator); ok {
+			name, reason, _ := strings.Cut(after, ": ")
+			return fmt.Errorf("%s for %s: %s", before, flagName(name), reason)
+		}
+	}
+	return err
+}
+
+// flagName returns the name of a flag as it is written on the command line
+func flagName(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// exitOnHelp prints the help and exits when parsing stopped at --help
+func exitOnHelp(err error, printHelp func()) error {
+	if errors.Is(err, flag.ErrHelp) {
+		printHelp()
+		os.Exit(0)
+	}
+	return err
+}
+
+type stringValue string
+
+func (s *stringValue) Set(value string) error {
+	*s = stringValue(value)
+	return nil
+}
+
+func (s *stringValue) String() string { return string(*s) }
+
+type intValue int
+
+func (i *intValue) Set(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("not a whole number")
+	}
+	*i = intValue(n)
+	return nil
+}
+
+func (i *intValue) String() string { return strconv.Itoa(int(*i)) }
+
+type boolValue bool
+
+func (b *boolValue) Set(value string) error {
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("must be true or false")
+	}
+	*b = boolValue(v)
+	return nil
+}
+
+func (b *boolValue) String() string { return strconv.FormatBool(bool(*b)) }
+
+func (b *boolValue) IsBoolFlag() bool { return true }
+
+type funcValue func(string) error
+
+func (f funcValue) Set(value string) error { return f(value) }
+
+func (f funcValue) String() string { return "" }
+
+type boolFuncValue func() error
+
+func (f boolFuncValue) Set(value string) error {
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("must be true or false")
+	}
+	if !v {
+		return nil
+	}
+	return f()
+}
+
+func (f boolFuncValue) String() string { return "" }
+
+func (f boolFuncValue) IsBoolFlag() bool { return true }
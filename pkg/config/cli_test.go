@@ -0,0 +1,52 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"testing"
+)
+
+func TestFlagError(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown long flag", []string{"--colour"}, "unknown flag: --colour"},
+		{"unknown short flag", []string{"-x"}, "unknown flag: -x"},
+		{"missing value", []string{"--timeout"}, "--timeout requires a value"},
+		{"missing value of short flag", []string{"-o"}, "-o requires a value"},
+		{"invalid value", []string{"--timeout", "soon"}, `invalid value "soon" for --timeout: not a whole number`},
+		{"invalid boolean value", []string{"--verbose=maybe"}, `invalid boolean value "maybe" for --verbose: must be true or false`},
+		{"boolean flag refused", []string{"--ipv4", "--ipv6"}, "--ipv6: --ipv4 and --ipv6 cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var timeout int
+			var verbose bool
+			var outputFile, family string
+			flags := newFlagSet("test")
+			flags.section("FLAGS")
+			flags.intVar(&timeout, "timeout", "", "seconds", "")
+			flags.boolVar(&verbose, "verbose", "v", "")
+			flags.stringVar(&outputFile, "output-file", "o", "file", "")
+			addIPFamilyFlags(flags, &family, "")
+
+			_, err := flags.parse(tt.args)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("parse(%q) error = %v, want %q", tt.args, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagErrorKeepsOtherErrors(t *testing.T) {
+	if err := flagError(flag.ErrHelp); err != flag.ErrHelp {
+		t.Errorf("flagError(flag.ErrHelp) = %v, want flag.ErrHelp", err)
+	}
+	other := errors.New("something else")
+	if err := flagError(other); err != other {
+		t.Errorf("flagError(%v) = %v, want it unchanged", other, err)
+	}
+}
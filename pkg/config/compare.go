@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ParseCompareFlags parses the arguments of the compare command and returns
+// one configuration per endpoint. The endpoints are the positional
+// arguments, after --endpoint when it is given; each takes the flags of a
+// check run, and an endpoint written as <profile>@<endpoint> reads its
+// credentials and region from that profile instead of the shared ones.
+func ParseCompareFlags(args []string) ([]*Config, error) {
+	base := GetDefaultConfig()
+
+	flags := newFlagSet("compare")
+	addCheckFlags(flags, base)
+
+	positional, err := flags.parse(args)
+	if err != nil {
+		return nil, exitOnHelp(err, printCompareHelp)
+	}
+
+	targets := positional
+	if base.Endpoint != "" {
+		targets = append([]string{base.Endpoint}, positional...)
+	}
+	if len(targets) < 2 {
+		return nil, fmt.Errorf("compare needs at least two endpoints, e.g. s3tester compare --bucket my-bucket aws wasabi")
+	}
+
+	configs := make([]*Config, 0, len(targets))
+	for _, target := range targets {
+		config := *base
+		profile, endpoint := splitCompareTarget(target)
+		config.Endpoint = endpoint
+		if profile != "" {
+			config.Profile = profile
+			config.AccessKey, config.SecretKey, config.SessionToken = "", "", ""
+		}
+		if err := config.resolve(flags.isSet("region")); err != nil {
+			return nil, fmt.Errorf("%s: %w", target, err)
+		}
+		configs = append(configs, &config)
+	}
+	return configs, nil
+}
+
+// splitCompareTarget splits <profile>@<endpoint> into the profile and the
+// endpoint; an @ inside a URL, after its scheme or host, is not a profile
+func splitCompareTarget(target string) (string, string) {
+	profile, endpoint, ok := strings.Cut(target, "@")
+	if !ok || profile == "" || strings.ContainsAny(profile, ":/.") {
+		return "", target
+	}
+	return profile, endpoint
+}
+
+// ValidateCompare validates the settings a compare run does not support;
+// Validate must be called for each endpoint as well
+func (c *Config) ValidateCompare() error {
+	switch {
+	case c.Watch:
+		return fmt.Errorf("--watch cannot be used with compare")
+	case c.Repeat > 1:
+		return fmt.Errorf("--repeat cannot be used with compare")
+	case c.Nagios:
+		return fmt.Errorf("--nagios cannot be used with compare")
+	case c.Format == "oneline":
+		return fmt.Errorf("--format oneline cannot be used with compare")
+	case c.OutputFormat != "" && c.OutputFormat != "json":
+		return fmt.Errorf("compare writes its report as JSON; --output-format %s is not supported", c.OutputFormat)
+	}
+	return nil
+}
+
+// printCompareHelp prints the help message for the compare command
+func printCompareHelp() {
+	fmt.Print(`S3 Bucket Tester - Endpoint comparison
+
+USAGE:
+    s3tester compare [FLAGS] <endpoint> <endpoint> [<endpoint>...]
+
+Runs the same checks against each endpoint, one after the other, and prints
+them side by side: the status and duration of every check, the TLS posture
+and the provider capabilities, with the rows that differ marked. Endpoints
+are URLs or provider shortcuts; write <profile>@<endpoint> to use the
+credentials and region of a profile for that endpoint. Exits with code 1
+when a check fails on any endpoint or its status differs between them.
+
+`)
+	flags := newFlagSet("compare")
+	addCheckFlags(flags, GetDefaultConfig())
+	flags.printFlags(os.Stdout)
+
+	fmt.Println(`EXAMPLES:
+    s3tester compare --bucket my-bucket --check-object \
+                     old@https://s3.old-provider.example \
+                     new@https://fsn1.your-objectstorage.com
+
+    s3tester compare -b my-bucket --probe-capabilities -o compare.json \
+                     http://localhost:9000 http://localhost:3900`)
+}
@@ -1,12 +1,20 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/url"
 	"strings"
+	"time"
 
+	"github.com/s3-bucket-tester/s3tester/pkg/i18n"
+	"github.com/s3-bucket-tester/s3tester/pkg/options"
 	"github.com/s3-bucket-tester/s3tester/pkg/output"
+	"github.com/s3-bucket-tester/s3tester/pkg/proxy"
+	"github.com/s3-bucket-tester/s3tester/pkg/remediation"
+	"github.com/s3-bucket-tester/s3tester/pkg/sts"
 )
 
 // ProviderCapabilities defines the capabilities of a provider
@@ -21,21 +29,120 @@ type ProviderCapabilities struct {
 
 // Config holds the application configuration
 type Config struct {
-	Endpoint       string
-	Bucket         string
-	Region         string
-	AccessKey      string
-	SecretKey      string
-	AuthType       string
-	Port           int
-	Insecure       bool
-	Timeout        int
-	OutputFormat   string
-	OutputFile     string
-	FollowRedirect bool
-	MaxRedirects   int
-	Verbose        bool
-	Warning        string
+	Endpoint         string
+	Bucket           string
+	Region           string
+	AccessKey        string
+	SecretKey        string
+	SessionToken     string
+	Profile          string
+	RoleArn          string
+	ExternalID       string
+	STSEndpoint      string
+	CredentialSource string
+	// CredentialExpiration is when temporary credentials expire; zero for
+	// long-term keys or when the source does not report it
+	CredentialExpiration time.Time
+	AuthType             string
+	Port                 int
+	Insecure             bool
+	CACert               string
+	ClientCert           string
+	ClientKey            string
+	SNI                  string
+	Proxy                string
+	Timeout              int
+	DNSTimeout           int
+	TCPTimeout           int
+	HTTPTimeout          int
+	OutputFormat         string
+	Format               string
+	OutputFile           string
+	ReportDir            string
+	EmitPolicy           string
+	Lang                 string
+	Messages             string
+	RemediationFile      string
+	ReportKeep           int
+	ReportMaxAge         int
+	FollowRedirect       bool
+	MaxRedirects         int
+	Verbose              bool
+	ASCII                bool
+	Nagios               bool
+	Warnings             []output.Warning
+	TCPSamples           int
+	HappyEyeballs        bool
+	IPFamily             string
+	SupportBundle        string
+	Anonymize            bool
+	TLSResumption        bool
+	SNIProbe             bool
+	SkipOCSP             bool
+	CertWarnDays         int
+	CertCritDays         int
+	FailOnCertExpiry     bool
+	Baseline             string
+	History              string
+	FailOnRegression     bool
+	CheckExpect          bool
+	CheckObject          bool
+	ProbeTransfer        bool
+	CheckEncoding        bool
+	CheckCache           bool
+	CheckVersionedDelete bool
+	CheckVersioning      bool
+	CheckSSEC            bool
+	CheckChecksums       bool
+	CheckConditional     bool
+	CheckCopy            bool
+	CheckBatchDelete     bool
+	CheckPresigned       bool
+	CheckPostPolicy      bool
+	CheckLifecycle       bool
+	CheckLogging         bool
+	CheckTagging         bool
+	CheckWebsite         bool
+	CheckNotifications   bool
+	VerifyNotifications  string
+	WebsiteEndpoint      string
+	CheckReplication     bool
+	TestReplication      bool
+	CheckOwnership       bool
+	CheckObjectLock      bool
+	TestRetention        bool
+	CheckRangedGet       bool
+	SDKParity            bool
+	ProbeCapabilities    bool
+	CDN                  string
+	RangedGetSizeMB      int
+	RangedGetConcurrency int
+	BenchSize            int64
+	BenchCount           int
+	BenchConcurrency     int
+	CheckArtifacts       bool
+	CheckPermissions     bool
+	ReadOnly             bool
+	IncludeCredentials   bool
+	TestPrefix           string
+	ObjectKey            string
+	RunID                string
+	SkipAnonymous        bool
+	PurgeArtifacts       bool
+	Repeat               int
+	Watch                bool
+	WatchPolicy          bool
+	NotifyWebhook        string
+	Warmup               bool
+	SlowThresholdMs      int
+	Retries              int
+	RetryDelayMs         int
+	Budget               time.Duration
+	Interval             int
+	Require              []string
+	ConfigFile           string
+	SLO                  *output.SLOThresholds
+	ProvidersFile        string
 
 	// New fields
 	Provider             string
@@ -43,7 +150,14 @@ type Config struct {
 	VirtualHosted        bool
 	PathStyle            bool
 	CheckPolicy          bool // Enable bucket policy and ACL check
+	CheckPublicAccess    bool // Enable Block Public Access assessment
+	CheckLocation        bool // Enable GetBucketLocation region check
+	TestBothStyles       bool // Run the auth check with both addressing styles
 	ProviderCapabilities *ProviderCapabilities
+
+	customTestPrefix bool
+	policyOnly       bool // policy command: no credentials are needed
+	listProviders    bool
 }
 
 // ProviderEndpoint defines endpoint templates for built-in providers
@@ -142,6 +256,102 @@ var ProviderCapabilitiesMap = map[string]*ProviderCapabilities{
 		PathStyleSupport:   true,
 		Notes:              "Enterprise S3-compatible",
 	},
+	"scaleway": {
+		Name:               "Scaleway Object Storage",
+		PolicySupport:      "Full",
+		ACLSupport:         "Full",
+		VirtualHostSupport: true,
+		PathStyleSupport:   true,
+		Notes:              "AWS-like behavior",
+	},
+	"ovh": {
+		Name:               "OVHcloud Object Storage",
+		PolicySupport:      "Partial",
+		ACLSupport:         "Full",
+		VirtualHostSupport: true,
+		PathStyleSupport:   true,
+		Notes:              "Access is granted by user policies; bucket policies are limited",
+	},
+	"linode": {
+		Name:               "Linode/Akamai Object Storage",
+		PolicySupport:      "Full",
+		ACLSupport:         "Full",
+		VirtualHostSupport: true,
+		PathStyleSupport:   true,
+		Notes:              "Ceph-based, AWS-like behavior",
+	},
+	"vultr": {
+		Name:               "Vultr Object Storage",
+		PolicySupport:      "Full",
+		ACLSupport:         "Full",
+		VirtualHostSupport: true,
+		PathStyleSupport:   true,
+		Notes:              "Ceph-based, AWS-like behavior",
+	},
+	"exoscale": {
+		Name:               "Exoscale SOS",
+		PolicySupport:      "Partial",
+		ACLSupport:         "Full",
+		VirtualHostSupport: true,
+		PathStyleSupport:   true,
+		Notes:              "Access is granted by IAM roles; no bucket policies",
+	},
+	"oci": {
+		Name:               "Oracle OCI Object Storage",
+		PolicySupport:      "IAM only",
+		ACLSupport:         "None",
+		VirtualHostSupport: false,
+		PathStyleSupport:   true,
+		Notes:              "Path-style only; the tenancy namespace is the first host label",
+	},
+	"alibaba": {
+		Name:               "Alibaba Cloud OSS",
+		PolicySupport:      "Full",
+		ACLSupport:         "Full",
+		VirtualHostSupport: true,
+		PathStyleSupport:   false,
+		Notes:              "Path-style not supported",
+	},
+	"tencent": {
+		Name:               "Tencent Cloud COS",
+		PolicySupport:      "Full",
+		ACLSupport:         "Full",
+		VirtualHostSupport: true,
+		PathStyleSupport:   true,
+		Notes:              "Bucket names carry the APPID suffix (name-1250000000)",
+	},
+	"storj": {
+		Name:               "Storj (S3 gateway)",
+		PolicySupport:      "None",
+		ACLSupport:         "None",
+		VirtualHostSupport: true,
+		PathStyleSupport:   true,
+		Notes:              "Access grants instead of policies/ACLs",
+	},
+	"garage": {
+		Name:               "Garage",
+		PolicySupport:      "None",
+		ACLSupport:         "None",
+		VirtualHostSupport: true,
+		PathStyleSupport:   true,
+		Notes:              "Permissions are per key; virtual-hosted needs root_domain",
+	},
+	"seaweedfs": {
+		Name:               "SeaweedFS",
+		PolicySupport:      "Partial",
+		ACLSupport:         "None",
+		VirtualHostSupport: true,
+		PathStyleSupport:   true,
+		Notes:              "Identities and actions are configured on the server",
+	},
+	"localstack": {
+		Name:               "LocalStack",
+		PolicySupport:      "Full",
+		ACLSupport:         "Full",
+		VirtualHostSupport: true,
+		PathStyleSupport:   true,
+		Notes:              "Local AWS emulator; credentials are not verified by default",
+	},
 	"custom": {
 		Name:               "Custom/Unknown S3-Compatible",
 		PolicySupport:      "Unknown",
@@ -186,12 +396,65 @@ var Providers = map[string]ProviderEndpoint{
 		Template:    "<bucket>.<region>.digitaloceanspaces.com",
 		Description: "DigitalOcean Spaces (virtual-hosted)",
 	},
+	"scaleway": {
+		Template:    "<bucket>.s3.<region>.scw.cloud",
+		Description: "Scaleway Object Storage (virtual-hosted)",
+	},
+	"ovh": {
+		Template:    "<bucket>.s3.<region>.io.cloud.ovh.net",
+		Description: "OVHcloud Object Storage (virtual-hosted)",
+	},
+	"linode": {
+		Template:    "<bucket>.<region>.linodeobjects.com",
+		Description: "Linode/Akamai Object Storage (virtual-hosted)",
+	},
+	"vultr": {
+		Template:    "<bucket>.<region>.vultrobjects.com",
+		Description: "Vultr Object Storage (virtual-hosted)",
+	},
+	"exoscale": {
+		Template:    "<bucket>.sos-<region>.exo.io",
+		Description: "Exoscale SOS (virtual-hosted)",
+	},
+	"oci": {
+		Template:    "<namespace>.compat.objectstorage.<region>.oraclecloud.com/<bucket>",
+		Description: "Oracle OCI (path-style; needs the namespace in the endpoint URL)",
+	},
+	"alibaba": {
+		Template:    "<bucket>.oss-<region>.aliyuncs.com",
+		Description: "Alibaba Cloud OSS (virtual-hosted)",
+	},
+	"tencent": {
+		Template:    "<bucket>.cos.<region>.myqcloud.com",
+		Description: "Tencent Cloud COS (virtual-hosted)",
+	},
+	"storj": {
+		Template:    "<bucket>.gateway.storjshare.io",
+		Description: "Storj hosted S3 gateway (virtual-hosted)",
+	},
+	"garage": {
+		Template:    "http://localhost:3900/<bucket>",
+		Description: "Garage, local (path-style)",
+	},
+	"seaweedfs": {
+		Template:    "http://localhost:8333/<bucket>",
+		Description: "SeaweedFS S3 gateway, local (path-style)",
+	},
+	"localstack": {
+		Template:    "http://<bucket>.s3.localhost.localstack.cloud:4566",
+		Description: "LocalStack, local (virtual-hosted)",
+	},
 }
 
 // DetectProvider detects the provider from the endpoint URL
 func DetectProvider(endpoint string) string {
 	endpoint = strings.ToLower(endpoint)
 
+	// Providers from a providers file take precedence over the built-in ones
+	if name := detectUserProvider(endpoint); name != "" {
+		return name
+	}
+
 	// Check for known providers by their domain patterns
 	if strings.Contains(endpoint, "amazonaws.com") {
 		return "aws"
@@ -208,6 +471,42 @@ func DetectProvider(endpoint string) string {
 	if strings.Contains(endpoint, "digitaloceanspaces.com") {
 		return "do"
 	}
+	if strings.Contains(endpoint, "scw.cloud") {
+		return "scaleway"
+	}
+	if strings.Contains(endpoint, "cloud.ovh.net") {
+		return "ovh"
+	}
+	if strings.Contains(endpoint, "linodeobjects.com") {
+		return "linode"
+	}
+	if strings.Contains(endpoint, "vultrobjects.com") {
+		return "vultr"
+	}
+	if strings.Contains(endpoint, "exo.io") {
+		return "exoscale"
+	}
+	if strings.Contains(endpoint, "oraclecloud.com") {
+		return "oci"
+	}
+	if strings.Contains(endpoint, "aliyuncs.com") {
+		return "alibaba"
+	}
+	if strings.Contains(endpoint, "myqcloud.com") {
+		return "tencent"
+	}
+	if strings.Contains(endpoint, "storjshare.io") {
+		return "storj"
+	}
+	if strings.Contains(endpoint, "localstack") {
+		return "localstack"
+	}
+	if strings.Contains(endpoint, "garage") {
+		return "garage"
+	}
+	if strings.Contains(endpoint, "seaweed") {
+		return "seaweedfs"
+	}
 	if strings.Contains(endpoint, "minio") || strings.Contains(endpoint, "aistor") {
 		return "minio"
 	}
@@ -233,20 +532,31 @@ func DetectProvider(endpoint string) string {
 // GetDefaultConfig returns the default configuration
 func GetDefaultConfig() *Config {
 	return &Config{
-		Endpoint:       "",
-		Bucket:         "",
-		Region:         "us-east-1",
-		AccessKey:      "",
-		SecretKey:      "",
-		AuthType:       "sigv4",
-		Port:           0,
-		Insecure:       false,
-		Timeout:        30,
-		OutputFormat:   "",
-		OutputFile:     "",
-		FollowRedirect: true,
-		MaxRedirects:   10,
-		Verbose:        false,
+		Endpoint:             "",
+		Bucket:               "",
+		Region:               "us-east-1",
+		AccessKey:            "",
+		SecretKey:            "",
+		AuthType:             "sigv4",
+		Port:                 0,
+		Insecure:             false,
+		Timeout:              30,
+		CertWarnDays:         30,
+		CertCritDays:         7,
+		OutputFormat:         "",
+		Format:               "console",
+		Lang:                 "en",
+		OutputFile:           "",
+		ReportKeep:           30,
+		FollowRedirect:       true,
+		MaxRedirects:         10,
+		Verbose:              false,
+		TCPSamples:           1,
+		RangedGetSizeMB:      64,
+		RangedGetConcurrency: 10,
+		Repeat:               1,
+		Interval:             60,
+		RetryDelayMs:         1000,
 
 		// New fields
 		Provider:             "",
@@ -258,19 +568,31 @@ func GetDefaultConfig() *Config {
 	}
 }
 
+// maxRetries caps --retries, so a persistent failure cannot stall a run
+const maxRetries = 10
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	// Check required fields
 	if c.Endpoint == "" && c.Provider == "" {
 		return fmt.Errorf("endpoint or provider is required")
 	}
+	if err := c.splitBucketEndpoint(); err != nil {
+		return err
+	}
 	if c.Bucket == "" {
 		return fmt.Errorf("bucket is required")
 	}
-	if c.AccessKey == "" {
+	if c.AccessKey == "" && !c.policyOnly {
+		if c.Profile != "" {
+			return fmt.Errorf("profile %q does not define aws_access_key_id", c.Profile)
+		}
 		return fmt.Errorf("access-key is required")
 	}
-	if c.SecretKey == "" {
+	if c.SecretKey == "" && !c.policyOnly {
+		if c.Profile != "" {
+			return fmt.Errorf("profile %q does not define aws_secret_access_key", c.Profile)
+		}
 		return fmt.Errorf("secret-key is required")
 	}
 
@@ -310,22 +632,311 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid port: must be between 0 and 65535 (0 = auto-detect)")
 	}
 
+	// Validate the TLS and proxy options
+	if err := validateTransport(c.CACert, c.Proxy, c.SNI, c.ClientCert, &c.ClientKey); err != nil {
+		return err
+	}
+	if c.SNI != "" && !strings.HasPrefix(c.Endpoint, "https://") {
+		c.addWarning(output.WarningSeverityWarning, "sni", "--sni has no effect on a plain HTTP endpoint")
+	}
+
+	// Select the console language; a --messages catalog is layered on top and
+	// may also provide a language without a built-in catalog
+	if err := i18n.SetLanguage(c.Lang); err != nil && c.Messages == "" {
+		return fmt.Errorf("invalid lang: %w (supported: %s, or add a catalog with --messages)", err, strings.Join(i18n.Languages(), ", "))
+	}
+	if c.Messages != "" {
+		if err := i18n.LoadFile(c.Messages); err != nil {
+			return fmt.Errorf("invalid messages: %w", err)
+		}
+	}
+	if c.RemediationFile != "" {
+		if err := remediation.LoadFile(c.RemediationFile); err != nil {
+			return fmt.Errorf("invalid remediation-file: %w", err)
+		}
+	}
+
+	// Validate certificate expiry thresholds
+	if c.CertWarnDays < 0 || c.CertCritDays < 0 {
+		return fmt.Errorf("invalid cert-warn-days or cert-crit-days: must be 0 or greater")
+	}
+	if c.CertCritDays > c.CertWarnDays {
+		return fmt.Errorf("invalid cert-crit-days: must not be greater than cert-warn-days (%d)", c.CertWarnDays)
+	}
+
 	// Validate timeout
 	if c.Timeout < 1 {
 		return fmt.Errorf("invalid timeout: must be greater than 0")
 	}
 
+	if c.Retries < 0 || c.Retries > maxRetries {
+		return fmt.Errorf("invalid retries: must be between 0 and %d", maxRetries)
+	}
+	if c.RetryDelayMs < 0 {
+		return fmt.Errorf("invalid retry-delay: must not be negative")
+	}
+
+	if c.Budget < 0 {
+		return fmt.Errorf("invalid budget: must not be negative")
+	}
+	if c.Budget > 0 && c.Budget < options.MinBudgetShare {
+		return fmt.Errorf("invalid budget: must be at least %s", options.MinBudgetShare)
+	}
+
+	if c.SlowThresholdMs < 0 {
+		return fmt.Errorf("invalid slow-threshold: must be greater than 0")
+	}
+
+	// Per-check timeouts default to --timeout
+	for _, t := range []struct {
+		flag  string
+		value *int
+	}{
+		{"dns-timeout", &c.DNSTimeout},
+		{"tcp-timeout", &c.TCPTimeout},
+		{"http-timeout", &c.HTTPTimeout},
+	} {
+		if *t.value < 0 {
+			return fmt.Errorf("invalid %s: must be greater than 0", t.flag)
+		}
+		if *t.value == 0 {
+			*t.value = c.Timeout
+		}
+	}
+
+	// Validate output format
+	if c.Format != "console" && c.Format != "oneline" {
+		return fmt.Errorf("invalid format: must be 'console' or 'oneline'")
+	}
+	switch c.OutputFormat {
+	case "", "console", "json", "yaml", "junit":
+	default:
+		return fmt.Errorf("invalid output-format: must be 'console', 'json', 'yaml' or 'junit'")
+	}
+	if c.OutputFile != "" && c.OutputFormat == "console" {
+		return fmt.Errorf("invalid output-format: the console report cannot be written to --output-file")
+	}
+	if c.OutputFile == "" && c.OutputFormat == "junit" {
+		return fmt.Errorf("--output-format junit requires --output-file")
+	}
+	if c.Nagios {
+		if c.Format == "oneline" || c.ReportToStdout() || c.Verbose {
+			return fmt.Errorf("--nagios prints only the plugin status line and cannot be combined with --format oneline, --verbose or a report on stdout")
+		}
+		if c.Watch {
+			return fmt.Errorf("--nagios runs the checks once and cannot be combined with --watch")
+		}
+	}
+	if c.ReportToStdout() {
+		if c.Format == "oneline" {
+			return fmt.Errorf("--format oneline and --output-format %s both write to stdout; add --output-file", c.OutputFormat)
+		}
+		if c.Verbose {
+			return fmt.Errorf("--verbose writes to stdout and cannot be combined with --output-format %s without --output-file", c.OutputFormat)
+		}
+	}
+
+	// Validate report retention
+	if c.ReportKeep < 0 {
+		return fmt.Errorf("invalid report-keep: must be 0 or greater")
+	}
+	if c.ReportMaxAge < 0 {
+		return fmt.Errorf("invalid report-max-age: must be 0 or greater")
+	}
+
+	if c.FailOnRegression && c.Baseline == "" {
+		return fmt.Errorf("--fail-on-regression requires --baseline")
+	}
+
 	// Validate max redirects
 	if c.MaxRedirects < 0 {
 		return fmt.Errorf("invalid max-redirects: must be 0 or greater")
 	}
 
+	// Validate TCP samples
+	if c.TCPSamples < 1 {
+		return fmt.Errorf("invalid tcp-samples: must be 1 or greater")
+	}
+
+	// Validate address family forcing
+	if c.IPFamily != "" && c.HappyEyeballs {
+		return fmt.Errorf("--%s and --happy-eyeballs cannot be combined: the race needs both address families", c.IPFamily)
+	}
+
+	// Validate parallel ranged GET settings
+	if c.RangedGetSizeMB < 1 || c.RangedGetSizeMB > 1024 {
+		return fmt.Errorf("invalid ranged-get-size: must be between 1 and 1024 MiB")
+	}
+	if c.RangedGetConcurrency < 1 || c.RangedGetConcurrency > 64 {
+		return fmt.Errorf("invalid ranged-get-concurrency: must be between 1 and 64")
+	}
+
+	// Validate role assumption
+	if c.ExternalID != "" && c.RoleArn == "" {
+		return fmt.Errorf("external-id requires role-arn")
+	}
+	if c.STSEndpoint != "" && !strings.HasPrefix(c.STSEndpoint, "http://") && !strings.HasPrefix(c.STSEndpoint, "https://") {
+		c.STSEndpoint = "https://" + c.STSEndpoint
+	}
+
+	// Checking an existing object must not write anything next to it
+	if c.ObjectKey != "" {
+		c.ObjectKey = strings.TrimPrefix(c.ObjectKey, "/")
+		if c.ObjectKey == "" {
+			return fmt.Errorf("invalid object-key: must not be empty")
+		}
+		if !c.ReadOnly {
+			c.ReadOnly = true
+			c.addWarning(output.WarningSeverityInfo, "object-key", "--object-key implies --read-only: checks that write test objects are skipped")
+		}
+	}
+
+	// The CDN URL is the base the object keys are appended to
+	if c.CDN != "" {
+		if !strings.Contains(c.CDN, "://") {
+			c.CDN = "https://" + c.CDN
+		}
+		if u, err := url.Parse(c.CDN); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" {
+			return fmt.Errorf("invalid cdn: must be an http or https URL without a query")
+		}
+	}
+
+	// The website endpoint is requested as visitors would, usually over HTTP
+	if c.WebsiteEndpoint != "" {
+		if !strings.Contains(c.WebsiteEndpoint, "://") {
+			c.WebsiteEndpoint = "http://" + c.WebsiteEndpoint
+		}
+		if u, err := url.Parse(c.WebsiteEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" {
+			return fmt.Errorf("invalid website-endpoint: must be an http or https URL without a query")
+		}
+	}
+
+	// The notification webhook is served by this process, so it needs a port
+	// to listen on; providers deliver over plain HTTP to it
+	if c.VerifyNotifications != "" {
+		if u, err := url.Parse(c.VerifyNotifications); err != nil || u.Scheme != "http" || u.Port() == "" || u.RawQuery != "" {
+			return fmt.Errorf("invalid verify-notifications: must be an http URL with a port this host listens on, e.g. http://10.0.0.5:9099/s3tester")
+		}
+	}
+
+	// Resolve the key prefix for objects written by this run
+	if err := c.resolveTestPrefix(); err != nil {
+		return err
+	}
+
+	// Validate repeat count
+	if c.Repeat < 1 {
+		return fmt.Errorf("invalid repeat: must be 1 or greater")
+	}
+
+	// Validate watch mode
+	if c.Interval < 1 {
+		return fmt.Errorf("invalid interval: must be 1 or greater")
+	}
+	if c.Watch && c.Repeat > 1 {
+		return fmt.Errorf("watch and repeat cannot be combined")
+	}
+	if c.NotifyWebhook != "" {
+		if !c.WatchPolicy {
+			return fmt.Errorf("notify-webhook requires --watch-policy")
+		}
+		if u, err := url.Parse(c.NotifyWebhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid notify-webhook: must be an http or https URL")
+		}
+	}
+
+	// Validate capability requirements
+	if _, err := options.ParseRequirements(c.Require); err != nil {
+		return fmt.Errorf("invalid require: %w", err)
+	}
+
+	// Validate SLO thresholds
+	if err := validateSLO(c.SLO); err != nil {
+		return fmt.Errorf("invalid config file: %w", err)
+	}
+
 	// Generate provider-specific warnings
 	c.generateProviderWarnings()
 
 	return nil
 }
 
+// validateTransport validates the TLS and proxy options shared by the test run
+// and the cert-watch mode. The client key defaults to the certificate file for
+// combined PEM files.
+func validateTransport(caCert, proxyURL, sni, clientCert string, clientKey *string) error {
+	if caCert != "" {
+		if _, err := options.LoadCAPool(caCert); err != nil {
+			return fmt.Errorf("invalid ca-cert: %w", err)
+		}
+	}
+
+	if proxyURL != "" {
+		if _, err := proxy.Parse(proxyURL); err != nil {
+			return fmt.Errorf("invalid proxy: %w", err)
+		}
+	}
+
+	if *clientKey != "" && clientCert == "" {
+		return fmt.Errorf("--client-key requires --client-cert")
+	}
+	if clientCert != "" {
+		if *clientKey == "" {
+			*clientKey = clientCert
+		}
+		if _, err := options.LoadClientCertificate(clientCert, *clientKey); err != nil {
+			return fmt.Errorf("invalid client-cert: %w", err)
+		}
+	}
+
+	// The SNI override is a DNS name the certificate is verified against
+	if sni != "" {
+		if net.ParseIP(sni) != nil {
+			return fmt.Errorf("invalid sni: %q is an IP address; SNI carries only host names", sni)
+		}
+		if strings.ContainsAny(sni, ":/ ") {
+			return fmt.Errorf("invalid sni: %q is not a host name", sni)
+		}
+	}
+
+	return nil
+}
+
+// resolveTestPrefix assigns the run ID and validates --test-prefix, or
+// derives the default per-run prefix s3tester-<runid>/
+func (c *Config) resolveTestPrefix() error {
+	if c.RunID == "" {
+		c.RunID = newRunID()
+	}
+
+	if c.TestPrefix == "" {
+		c.TestPrefix = options.DefaultTestPrefix(c.RunID)
+		return nil
+	}
+
+	c.customTestPrefix = true
+	if strings.HasPrefix(c.TestPrefix, "/") {
+		return fmt.Errorf("invalid test-prefix: must not start with '/'")
+	}
+	for _, part := range strings.Split(strings.TrimSuffix(c.TestPrefix, "/"), "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid test-prefix %q: empty, '.' and '..' path segments are not allowed", c.TestPrefix)
+		}
+	}
+	if !strings.HasSuffix(c.TestPrefix, "/") {
+		c.TestPrefix += "/"
+	}
+
+	return nil
+}
+
+// newRunID returns a sortable identifier for this run
+func newRunID() string {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(suffix)
+}
+
 // generateProviderWarnings generates warnings based on provider capabilities
 func (c *Config) generateProviderWarnings() {
 	if c.ProviderCapabilities == nil {
@@ -334,21 +945,15 @@ func (c *Config) generateProviderWarnings() {
 
 	// Path-style warning
 	if c.PathStyle && !c.ProviderCapabilities.PathStyleSupport {
-		if c.Warning != "" {
-			c.Warning += "\n"
-		}
 		if c.DetectedProvider == "custom" {
-			c.Warning += "Warning: --path-style addressing may not be supported by this provider. Try removing --path-style flag."
+			c.addWarning(output.WarningSeverityInfo, "path-style", "--path-style addressing may not be supported by this provider. Try removing --path-style flag.")
 		} else {
-			c.Warning += fmt.Sprintf("Warning: --path-style addressing is not supported by %s. Try removing --path-style flag.", c.ProviderCapabilities.Name)
+			c.addWarning(output.WarningSeverityWarning, "path-style", fmt.Sprintf("--path-style addressing is not supported by %s. Try removing --path-style flag.", c.ProviderCapabilities.Name))
 		}
 	} else if c.PathStyle && c.DetectedProvider != "custom" {
 		// For providers that support path-style but may have limitations
 		if c.ProviderCapabilities.Notes != "" && strings.Contains(c.ProviderCapabilities.Notes, "deprecated") {
-			if c.Warning != "" {
-				c.Warning += "\n"
-			}
-			c.Warning += fmt.Sprintf("Warning: --path-style addressing is deprecated for %s. %s", c.ProviderCapabilities.Name, c.ProviderCapabilities.Notes)
+			c.addWarning(output.WarningSeverityInfo, "path-style", fmt.Sprintf("--path-style addressing is deprecated for %s. %s", c.ProviderCapabilities.Name, c.ProviderCapabilities.Notes))
 		}
 	}
 
@@ -356,24 +961,49 @@ func (c *Config) generateProviderWarnings() {
 	if c.CheckPolicy {
 		if c.DetectedProvider == "custom" {
 			// Custom endpoints get the generic warning
-			if c.Warning != "" {
-				c.Warning += "\n"
-			}
-			c.Warning += "Warning: --check-policy is not supported on all S3-Compatible providers. This feature may not work with your provider."
+			c.addWarning(output.WarningSeverityInfo, "check-policy", "--check-policy is not supported on all S3-Compatible providers. This feature may not work with your provider.")
 		} else if c.ProviderCapabilities.PolicySupport == "None" {
-			if c.Warning != "" {
-				c.Warning += "\n"
-			}
-			c.Warning += fmt.Sprintf("Warning: --check-policy is not supported by %s. Policy support: %s", c.ProviderCapabilities.Name, c.ProviderCapabilities.PolicySupport)
+			c.addWarning(output.WarningSeverityWarning, "check-policy", fmt.Sprintf("--check-policy is not supported by %s. Policy support: %s", c.ProviderCapabilities.Name, c.ProviderCapabilities.PolicySupport))
 		} else if c.ProviderCapabilities.PolicySupport != "Full" {
-			if c.Warning != "" {
-				c.Warning += "\n"
-			}
-			c.Warning += fmt.Sprintf("Warning: --check-policy has limited support for %s. Policy support: %s. %s", c.ProviderCapabilities.Name, c.ProviderCapabilities.PolicySupport, c.ProviderCapabilities.Notes)
+			c.addWarning(output.WarningSeverityInfo, "check-policy", fmt.Sprintf("--check-policy has limited support for %s. Policy support: %s. %s", c.ProviderCapabilities.Name, c.ProviderCapabilities.PolicySupport, c.ProviderCapabilities.Notes))
 		}
 	}
 }
 
+// credentialExpiryWarning is how close to expiry temporary credentials must be
+// at the start of a run to warn that the run may be cut short
+const credentialExpiryWarning = 15 * time.Minute
+
+// CheckCredentialExpiry fails if temporary credentials have already expired,
+// and warns if they expire soon enough to cut the run short
+func (c *Config) CheckCredentialExpiry(now time.Time) error {
+	if c.CredentialExpiration.IsZero() {
+		return nil
+	}
+
+	remaining := c.CredentialExpiration.Sub(now)
+	if remaining <= 0 {
+		return fmt.Errorf("temporary credentials from %s expired at %s; refresh them and run again",
+			c.CredentialSource, c.CredentialExpiration.Local().Format(time.RFC3339))
+	}
+	if remaining < credentialExpiryWarning {
+		c.addWarning(output.WarningSeverityWarning, "credentials", fmt.Sprintf(
+			"Temporary credentials expire in %v (at %s); later checks fail if they expire mid-run. Refresh them before long runs.",
+			remaining.Round(time.Second), c.CredentialExpiration.Local().Format(time.RFC3339)))
+	}
+
+	return nil
+}
+
+// addWarning records a configuration warning
+func (c *Config) addWarning(severity output.WarningSeverity, source, message string) {
+	c.Warnings = append(c.Warnings, output.Warning{
+		Severity: severity,
+		Source:   source,
+		Message:  message,
+	})
+}
+
 // ResolveProviderEndpoint resolves the endpoint from provider template
 func (c *Config) ResolveProviderEndpoint() error {
 	provider, ok := Providers[c.Provider]
@@ -386,6 +1016,13 @@ func (c *Config) ResolveProviderEndpoint() error {
 	endpoint = strings.ReplaceAll(endpoint, "<bucket>", c.Bucket)
 	endpoint = strings.ReplaceAll(endpoint, "<region>", c.Region)
 
+	// Other placeholders, such as the tenancy namespace of OCI, have no flag
+	if start := strings.Index(endpoint, "<"); start >= 0 {
+		placeholder := endpoint[start : start+strings.Index(endpoint[start:], ">")+1]
+		return fmt.Errorf("provider %s needs %s in the endpoint: pass the endpoint URL instead of the shortcut, e.g. --endpoint https://%s",
+			c.Provider, placeholder, endpoint)
+	}
+
 	// Add protocol if not present
 	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
 		if c.Insecure {
@@ -496,23 +1133,148 @@ func ParsePort(endpoint string) int {
 	return 443 // Default to HTTPS
 }
 
+// ReportToStdout reports whether the JSON or YAML report replaces the console
+// report on stdout, which is the case when no --output-file is given
+func (c *Config) ReportToStdout() bool {
+	return c.OutputFile == "" && (c.OutputFormat == "json" || c.OutputFormat == "yaml")
+}
+
+// ResolveSTSEndpoint returns the STS endpoint used for role assumption: the
+// explicit --sts-endpoint, the regional AWS endpoint for AWS, and the S3
+// endpoint itself for other providers (MinIO and Ceph serve STS there)
+func (c *Config) ResolveSTSEndpoint() string {
+	if c.STSEndpoint != "" {
+		return c.STSEndpoint
+	}
+	if c.DetectedProvider == "aws" {
+		return sts.DefaultEndpoint(c.Region)
+	}
+
+	endpointURL, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return c.Endpoint
+	}
+	return endpointURL.Scheme + "://" + endpointURL.Host
+}
+
 // ToOutputConfig converts config to output config
 func (c *Config) ToOutputConfig() output.Config {
-	return output.Config{
-		Endpoint:       c.Endpoint,
-		Bucket:         c.Bucket,
-		Region:         c.Region,
-		AccessKey:      c.AccessKey,
-		SecretKey:      c.SecretKey,
-		AuthType:       c.AuthType,
-		Port:           c.Port,
-		Insecure:       c.Insecure,
-		Timeout:        c.Timeout,
-		OutputFormat:   c.OutputFormat,
-		OutputFile:     c.OutputFile,
-		FollowRedirect: c.FollowRedirect,
-		MaxRedirects:   c.MaxRedirects,
-		Verbose:        c.Verbose,
-		PathStyle:      c.PathStyle,
+	outputConfig := output.Config{
+		Endpoint:             c.Endpoint,
+		Bucket:               c.Bucket,
+		Region:               c.Region,
+		AccessKey:            c.AccessKey,
+		SecretKey:            c.SecretKey,
+		SessionToken:         c.SessionToken,
+		Profile:              c.Profile,
+		RoleArn:              c.RoleArn,
+		CredentialSource:     c.CredentialSource,
+		AuthType:             c.AuthType,
+		Port:                 c.Port,
+		Insecure:             c.Insecure,
+		CACert:               c.CACert,
+		ClientCert:           c.ClientCert,
+		ClientKey:            c.ClientKey,
+		SNI:                  c.SNI,
+		Proxy:                c.Proxy,
+		Timeout:              c.Timeout,
+		DNSTimeout:           c.DNSTimeout,
+		TCPTimeout:           c.TCPTimeout,
+		HTTPTimeout:          c.HTTPTimeout,
+		OutputFormat:         c.OutputFormat,
+		OutputFile:           c.OutputFile,
+		FollowRedirect:       c.FollowRedirect,
+		MaxRedirects:         c.MaxRedirects,
+		Verbose:              c.Verbose,
+		PathStyle:            c.PathStyle,
+		TCPSamples:           c.TCPSamples,
+		HappyEyeballs:        c.HappyEyeballs,
+		IPFamily:             c.IPFamily,
+		SupportBundle:        c.SupportBundle,
+		TLSResumption:        c.TLSResumption,
+		SNIProbe:             c.SNIProbe,
+		SkipOCSP:             c.SkipOCSP,
+		CertWarnDays:         c.CertWarnDays,
+		CertCritDays:         c.CertCritDays,
+		FailOnCertExpiry:     c.FailOnCertExpiry,
+		Baseline:             c.Baseline,
+		History:              c.History,
+		FailOnRegression:     c.FailOnRegression,
+		CheckExpect:          c.CheckExpect,
+		CheckObject:          c.CheckObject,
+		ProbeTransfer:        c.ProbeTransfer,
+		CheckEncoding:        c.CheckEncoding,
+		CheckCache:           c.CheckCache,
+		CheckVersionedDelete: c.CheckVersionedDelete,
+		CheckVersioning:      c.CheckVersioning,
+		CheckSSEC:            c.CheckSSEC,
+		CheckChecksums:       c.CheckChecksums,
+		CheckConditional:     c.CheckConditional,
+		CheckCopy:            c.CheckCopy,
+		CheckBatchDelete:     c.CheckBatchDelete,
+		CheckPresigned:       c.CheckPresigned,
+		CheckPostPolicy:      c.CheckPostPolicy,
+		CheckLifecycle:       c.CheckLifecycle,
+		CheckLogging:         c.CheckLogging,
+		CheckTagging:         c.CheckTagging,
+		CheckWebsite:         c.CheckWebsite || c.WebsiteEndpoint != "",
+		CheckNotifications:   c.CheckNotifications || c.VerifyNotifications != "",
+		VerifyNotifications:  c.VerifyNotifications,
+		WebsiteEndpoint:      c.WebsiteEndpoint,
+		CheckReplication:     c.CheckReplication || c.TestReplication,
+		TestReplication:      c.TestReplication,
+		CheckOwnership:       c.CheckOwnership,
+		CheckObjectLock:      c.CheckObjectLock || c.TestRetention,
+		TestRetention:        c.TestRetention,
+		CheckPolicy:          c.CheckPolicy,
+		CheckPublicAccess:    c.CheckPublicAccess,
+		CheckLocation:        c.CheckLocation,
+		TestBothStyles:       c.TestBothStyles,
+		Provider:             c.DetectedProvider,
+		CheckRangedGet:       c.CheckRangedGet,
+		SDKParity:            c.SDKParity,
+		ProbeCapabilities:    c.ProbeCapabilities,
+		CDN:                  c.CDN,
+		RangedGetSizeMB:      c.RangedGetSizeMB,
+		RangedGetConcurrency: c.RangedGetConcurrency,
+		BenchSize:            c.BenchSize,
+		BenchCount:           c.BenchCount,
+		BenchConcurrency:     c.BenchConcurrency,
+		CheckArtifacts:       c.CheckArtifacts || c.PurgeArtifacts,
+		CheckPermissions:     c.CheckPermissions,
+		ReadOnly:             c.ReadOnly,
+		IncludeCredentials:   c.IncludeCredentials,
+		ObjectKey:            c.ObjectKey,
+		PurgeArtifacts:       c.PurgeArtifacts,
+		SkipAnonymous:        c.SkipAnonymous,
+		RunID:                c.RunID,
+		TestPrefix:           c.TestPrefix,
+		CustomTestPrefix:     c.customTestPrefix,
+		Repeat:               c.Repeat,
+		Watch:                c.Watch,
+		WatchPolicy:          c.WatchPolicy,
+		Warmup:               c.Warmup,
+		SlowThresholdMs:      c.SlowThresholdMs,
+		Retries:              c.Retries,
+		RetryDelayMs:         c.RetryDelayMs,
+		BudgetMs:             int(c.Budget / time.Millisecond),
+		Interval:             c.Interval,
+		Require:              c.Require,
+		ConfigFile:           c.ConfigFile,
+		SLO:                  c.SLO,
+		Warnings:             c.Warnings,
+	}
+
+	// Carry the provider's known support levels for capability requirements
+	if c.ProviderCapabilities != nil {
+		outputConfig.PolicySupport = c.ProviderCapabilities.PolicySupport
+		outputConfig.ACLSupport = c.ProviderCapabilities.ACLSupport
 	}
+
+	if !c.CredentialExpiration.IsZero() {
+		expiration := c.CredentialExpiration
+		outputConfig.CredentialExpiry = &expiration
+	}
+
+	return outputConfig
 }
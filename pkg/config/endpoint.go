@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// splitBucketEndpoint accepts an --endpoint URL that already names the bucket,
+// either in the path (https://s3.example.com/mybucket) or as the first host
+// label (https://mybucket.s3.example.com), and replaces it with the plain
+// service endpoint, the bucket and the matching addressing style. Explicit
+// --bucket, --path-style and --virtual-hosted flags take precedence.
+//
+// With --bucket, the rest of the path is a prefix the S3 API is served
+// under behind a reverse proxy, as in https://gateway.example.com/s3. It
+// stays part of the endpoint and precedes the bucket or the object keys.
+func (c *Config) splitBucketEndpoint() error {
+	if c.Endpoint == "" {
+		return nil
+	}
+
+	hasScheme := strings.Contains(c.Endpoint, "://")
+	raw := c.Endpoint
+	if !hasScheme {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		// Reported by Validate
+		return nil
+	}
+
+	bucket, style, prefix := "", "", ""
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		segments := strings.Split(path, "/")
+		last := len(segments) - 1
+		switch {
+		case c.Bucket != "" && segments[last] == c.Bucket:
+			// Path-style: the bucket, after an optional prefix
+			bucket, style = c.Bucket, "path-style"
+			prefix = strings.Join(segments[:last], "/")
+		case c.Bucket != "":
+			prefix = path
+		case last > 0:
+			return fmt.Errorf("invalid endpoint URL %q: only the bucket may follow the host, not an object path; pass --bucket to use the path as a prefix the S3 API is served under", c.Endpoint)
+		default:
+			// Path-style: the path is the bucket
+			bucket, style = path, "path-style"
+		}
+		u.Path = ""
+		if prefix != "" {
+			u.Path = "/" + prefix
+		}
+	}
+	if bucket == "" {
+		if host, ok := c.virtualHostedBucket(u.Hostname()); ok {
+			// Virtual-hosted: the first host label is the bucket
+			bucket, style = host, "virtual-hosted"
+			u.Host = strings.TrimPrefix(u.Host, bucket+".")
+		}
+	}
+	if bucket == "" && prefix == "" {
+		return nil
+	}
+
+	// With --bucket, a path that does not end in it is a prefix and a host
+	// label must match it, so the bucket cannot differ
+	if bucket != "" {
+		c.Bucket = bucket
+	}
+
+	// Virtual-hosted is the default; --path-style still overrides it
+	if style == "path-style" && !c.VirtualHosted {
+		c.PathStyle = true
+	}
+
+	u.RawQuery, u.Fragment = "", ""
+	endpoint := u.String()
+	if !hasScheme {
+		endpoint = strings.TrimPrefix(endpoint, "https://")
+	}
+	c.Endpoint = endpoint
+
+	if prefix != "" {
+		c.addWarning(output.WarningSeverityInfo, "endpoint", fmt.Sprintf(
+			"The endpoint path /%s is used as the prefix the S3 API is served under; requests go to %s/...", prefix, strings.TrimSuffix(endpoint, "/")))
+	}
+	if bucket != "" {
+		c.addWarning(output.WarningSeverityInfo, "endpoint", fmt.Sprintf(
+			"Bucket %q and %s addressing were taken from the endpoint URL; testing endpoint %s", bucket, style, endpoint))
+	}
+
+	return nil
+}
+
+// virtualHostedBucket returns the bucket named by the first label of a
+// virtual-hosted host name. With --bucket the label must match it; without,
+// the host must follow a known virtual-hosted pattern, such as
+// <bucket>.s3.<region>.amazonaws.com or <bucket>.s3.example.com.
+func (c *Config) virtualHostedBucket(host string) (string, bool) {
+	if net.ParseIP(host) != nil {
+		return "", false
+	}
+
+	labels := strings.Split(strings.ToLower(host), ".")
+	if len(labels) < 3 {
+		return "", false
+	}
+
+	if c.Bucket != "" {
+		return c.Bucket, strings.HasPrefix(strings.ToLower(host), strings.ToLower(c.Bucket)+".")
+	}
+
+	// <bucket>.s3.<...>, <bucket>.s3-<region>.<...>, <bucket>.s3-accelerate.<...>
+	if labels[1] == "s3" || strings.HasPrefix(labels[1], "s3-") {
+		return labels[0], true
+	}
+
+	// <bucket>.<region>.digitaloceanspaces.com
+	if len(labels) == 4 && strings.HasSuffix(host, ".digitaloceanspaces.com") {
+		return labels[0], true
+	}
+
+	return "", false
+}
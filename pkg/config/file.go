@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// FileConfig holds the settings read from a --config file
+type FileConfig struct {
+	SLO *output.SLOThresholds `json:"slo,omitempty"`
+}
+
+// LoadConfigFile reads a JSON configuration file. Unknown keys are rejected
+// so a misspelled threshold is not silently ignored.
+func LoadConfigFile(path string) (*FileConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	var fileConfig FileConfig
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fileConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return &fileConfig, nil
+}
+
+// validateSLO checks that the thresholds are consistent
+func validateSLO(slo *output.SLOThresholds) error {
+	if slo == nil {
+		return nil
+	}
+
+	limits := []struct {
+		name    string
+		limit   *output.SLOLimit
+		minimum bool
+	}{
+		{"maxDnsMs", slo.MaxDNSMs, false},
+		{"maxTtfbMs", slo.MaxTTFBMs, false},
+		{"minCertDays", slo.MinCertDays, true},
+	}
+	for _, l := range limits {
+		if l.limit == nil {
+			continue
+		}
+		if l.limit.Warn < 0 || l.limit.Fail < 0 {
+			return fmt.Errorf("slo.%s: thresholds must be 0 or greater", l.name)
+		}
+		if l.limit.Warn == 0 || l.limit.Fail == 0 {
+			continue
+		}
+		if !l.minimum && l.limit.Warn > l.limit.Fail {
+			return fmt.Errorf("slo.%s: warn must not be greater than fail", l.name)
+		}
+		if l.minimum && l.limit.Warn < l.limit.Fail {
+			return fmt.Errorf("slo.%s: warn must not be less than fail", l.name)
+		}
+	}
+
+	if v := slo.MinTLSVersion; v != nil {
+		for _, version := range []string{v.Warn, v.Fail} {
+			if version != "" && version != "1.0" && version != "1.1" && version != "1.2" && version != "1.3" {
+				return fmt.Errorf("slo.minTlsVersion: invalid version %q (use 1.0, 1.1, 1.2 or 1.3)", version)
+			}
+		}
+	}
+
+	return nil
+}
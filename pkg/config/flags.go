@@ -4,182 +4,286 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
-
-	"github.com/s3-bucket-tester/s3tester/pkg/checker"
+	"time"
 )
 
-// ParseFlags parses command-line flags and returns the configuration
+// Version is the build version, set by main from its ldflags value
+var Version = "dev"
+
+// ParseFlags parses the flags of the check command and returns the
+// configuration
 func ParseFlags(args []string) (*Config, error) {
 	config := GetDefaultConfig()
+	flags := newFlagSet("check")
+	addCheckFlags(flags, config)
 
-	// Parse flags
-	for i := 0; i < len(args); i++ {
-		arg := args[i]
-
-		switch {
-		case arg == "--help" || arg == "-h":
-			printHelp()
-			os.Exit(0)
-		case arg == "--version":
-			fmt.Println("s3-bucket-tester version 1.0.0")
-			os.Exit(0)
-		case arg == "--endpoint":
-			if i+1 >= len(args) {
-				return nil, fmt.Errorf("--endpoint requires a value")
-			}
-			value := args[i+1]
-			// Check if value is a built-in provider name
-			if _, ok := Providers[value]; ok {
-				config.Provider = value
-			} else {
-				config.Endpoint = value
-			}
-			i++
-		case arg == "--bucket":
-			if i+1 >= len(args) {
-				return nil, fmt.Errorf("--bucket requires a value")
-			}
-			config.Bucket = args[i+1]
-			i++
-		case arg == "--access-key":
-			if i+1 >= len(args) {
-				return nil, fmt.Errorf("--access-key requires a value")
-			}
-			config.AccessKey = args[i+1]
-			i++
-		case arg == "--secret-key":
-			if i+1 >= len(args) {
-				return nil, fmt.Errorf("--secret-key requires a value")
-			}
-			config.SecretKey = args[i+1]
-			i++
-		case arg == "--region":
-			if i+1 >= len(args) {
-				return nil, fmt.Errorf("--region requires a value")
-			}
-			config.Region = args[i+1]
-			i++
-		case arg == "--auth-type":
-			if i+1 >= len(args) {
-				return nil, fmt.Errorf("--auth-type requires a value")
-			}
-			config.AuthType = args[i+1]
-			i++
-		case arg == "--insecure":
-			config.Insecure = true
-		case arg == "--timeout":
-			if i+1 >= len(args) {
-				return nil, fmt.Errorf("--timeout requires a value")
-			}
-			var timeout int
-			fmt.Sscanf(args[i+1], "%d", &timeout)
-			config.Timeout = timeout
-			i++
-		case arg == "--output-file":
-			if i+1 >= len(args) {
-				return nil, fmt.Errorf("--output-file requires a value")
+	positional, err := flags.parse(args)
+	if err != nil {
+		return nil, exitOnHelp(err, printHelp)
+	}
+	if len(positional) > 0 {
+		return nil, fmt.Errorf("unexpected argument %q: the check command takes only flags", positional[0])
+	}
+
+	if err := config.resolve(flags.isSet("region")); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// resolve completes a parsed configuration: the SLO thresholds of the config
+// file, credentials from a profile, the provider chain or secret references,
+// and the port of the endpoint. A provider shortcut given as --endpoint is
+// recognized here, after the providers file is loaded.
+func (c *Config) resolve(regionSet bool) error {
+	// Providers from the providers file are shortcuts like the built-in ones
+	if err := loadProviders(c.ProvidersFile); err != nil {
+		return err
+	}
+	if c.listProviders {
+		ListProviders()
+		os.Exit(0)
+	}
+	if _, ok := Providers[c.Endpoint]; ok {
+		c.Provider = c.Endpoint
+		c.Endpoint = ""
+	}
+
+	// Load SLO thresholds from the config file
+	if c.ConfigFile != "" {
+		fileConfig, err := LoadConfigFile(c.ConfigFile)
+		if err != nil {
+			return err
+		}
+		c.SLO = fileConfig.SLO
+	}
+
+	if !c.policyOnly {
+		// Resolve credentials and region from the AWS shared files
+		if c.Profile != "" {
+			if err := c.applyProfile(regionSet); err != nil {
+				return err
 			}
-			config.OutputFile = args[i+1]
-			i++
-		case arg == "--follow-redirects":
-			config.FollowRedirect = true
-		case arg == "--no-redirects":
-			config.FollowRedirect = false
-		case arg == "--max-redirects":
-			if i+1 >= len(args) {
-				return nil, fmt.Errorf("--max-redirects requires a value")
+		}
+
+		// Fall back to the credential provider chain when no keys were given
+		if c.AccessKey == "" && c.SecretKey == "" && c.Profile == "" {
+			if err := c.resolveCredentialChain(regionSet); err != nil {
+				return err
 			}
-			var maxRedirects int
-			fmt.Sscanf(args[i+1], "%d", &maxRedirects)
-			config.MaxRedirects = maxRedirects
-			i++
-		case arg == "--verbose":
-			config.Verbose = true
-		case arg == "--virtual-hosted":
-			config.VirtualHosted = true
-		case arg == "--path-style":
-			config.PathStyle = true
-		case strings.HasPrefix(arg, "--"):
-			return nil, fmt.Errorf("unknown flag: %s", arg)
+		}
+
+		// Replace vault:// and awssm:// references with the secrets they name
+		if err := c.resolveSecrets(); err != nil {
+			return err
 		}
 	}
 
 	// Auto-detect port from endpoint
-	if config.Endpoint != "" {
-		config.Port = checker.ParsePort(config.Endpoint)
+	if c.Endpoint != "" {
+		c.Port = ParsePort(c.Endpoint)
 	}
 
-	return config, nil
+	return nil
+}
+
+// addCheckFlags defines the flags of a check run on the flag set; bench and
+// policy accept the same flags
+func addCheckFlags(f *flagSet, config *Config) {
+	f.section("TARGET FLAGS")
+	f.stringVar(&config.Endpoint, "endpoint", "e", "url", "S3 endpoint URL or provider shortcut (see s3tester providers)")
+	f.stringVar(&config.Bucket, "bucket", "b", "name", "Bucket name to test (optional when --endpoint includes it)")
+	f.stringVar(&config.Region, "region", "r", "region", "AWS region (default: us-east-1, or the profile's region)")
+	f.boolVar(&config.VirtualHosted, "virtual-hosted", "", "Use virtual-hosted addressing, https://<bucket>.<endpoint> (default)")
+	f.boolVar(&config.PathStyle, "path-style", "", "Use path-style addressing, https://<endpoint>/<bucket>")
+	f.stringVar(&config.ProvidersFile, "providers-file", "", "file", "YAML file of additional provider shortcuts and capabilities (default: s3tester/providers.yaml in the user config directory, when it exists)")
+	f.note(`Custom endpoints: https://s3.example.com, http://localhost:9000,
+s3.example.com:9000 or 192.168.1.10:8080. Endpoints that include the
+bucket set --bucket and the addressing style:
+    https://s3.example.com/mybucket      (path-style)
+    https://mybucket.s3.example.com      (virtual-hosted)`)
+
+	f.section("CREDENTIAL FLAGS")
+	f.stringVar(&config.AccessKey, "access-key", "", "key", "Access key ID")
+	f.stringVar(&config.SecretKey, "secret-key", "", "key", "Secret access key")
+	f.stringVar(&config.SessionToken, "session-token", "", "token", "Session token for temporary (STS) credentials")
+	f.stringVar(&config.Profile, "profile", "p", "name", "Read credentials and region from the named profile in ~/.aws/credentials and ~/.aws/config")
+	f.stringVar(&config.RoleArn, "role-arn", "", "arn", "Assume this role via STS and run all checks with the temporary credentials")
+	f.stringVar(&config.ExternalID, "external-id", "", "id", "External ID to pass when assuming the role")
+	f.stringVar(&config.STSEndpoint, "sts-endpoint", "", "url", "STS endpoint for --role-arn (default: regional AWS STS for AWS, the S3 endpoint for other providers)")
+	f.stringVar(&config.AuthType, "auth-type", "", "type", "Authentication type: sigv4 or sigv2 (default: sigv4)")
+	f.note(`Without keys or --profile, credentials are resolved from the environment,
+the AWS_PROFILE/default profile, web identity (IRSA), ECS task credentials
+or EC2 instance metadata. Keys and tokens may be secret references:
+    vault://<mount>/<path>#<key>     HashiCorp Vault KV (VAULT_ADDR, VAULT_TOKEN)
+    awssm://<name or ARN>#<key>      AWS Secrets Manager`)
+
+	f.section("CONNECTION FLAGS")
+	f.stringVar(&config.Proxy, "proxy", "", "url", "Send all connections through an http:// or socks5:// proxy (default: HTTP_PROXY/HTTPS_PROXY/NO_PROXY)")
+	f.intVar(&config.Timeout, "timeout", "", "seconds", "Request timeout in seconds (default: 30)")
+	f.intVar(&config.DNSTimeout, "dns-timeout", "", "seconds", "Timeout of DNS lookups (default: --timeout)")
+	f.intVar(&config.TCPTimeout, "tcp-timeout", "", "seconds", "Timeout of TCP connects and TLS handshakes (default: --timeout)")
+	f.intVar(&config.HTTPTimeout, "http-timeout", "", "seconds", "Timeout of each S3 request, including reading the response (default: --timeout)")
+	f.boolVar(&config.FollowRedirect, "follow-redirects", "", "Follow HTTP redirects (default: true)")
+	f.boolFunc("no-redirects", "", "Do not follow HTTP redirects", func() error {
+		config.FollowRedirect = false
+		return nil
+	})
+	f.intVar(&config.MaxRedirects, "max-redirects", "", "n", "Maximum redirects to follow (default: 10)")
+	f.intVar(&config.TCPSamples, "tcp-samples", "", "n", "Number of TCP handshakes to sample for latency statistics (default: 1)")
+	f.boolVar(&config.HappyEyeballs, "happy-eyeballs", "", "Race IPv6 against IPv4 (RFC 8305) and report which address family wins")
+	addIPFamilyFlags(f, &config.IPFamily, "in every check")
+	f.boolVar(&config.Warmup, "warmup", "", "Run a throwaway DNS, TCP, TLS and HEAD cycle before the checks so their latencies exclude first-connection overhead; the cold and warm numbers are reported")
+	f.intVar(&config.Retries, "retries", "", "n", "Re-run a check up to n times when it fails with a transient error such as SlowDown, 503 or a reset connection (default: 0, max: 10)")
+	f.intVar(&config.RetryDelayMs, "retry-delay", "", "ms", "Wait before the first retry, doubled for each further retry up to one minute (default: 1000)")
+
+	f.section("TLS FLAGS")
+	f.boolVar(&config.Insecure, "insecure", "k", "Skip TLS certificate verification (not recommended)")
+	f.stringVar(&config.CACert, "ca-cert", "", "path", "Trust the PEM CA certificates in this file or directory in addition to the system roots (internal CAs)")
+	f.stringVar(&config.ClientCert, "client-cert", "", "file", "PEM client certificate for mutual TLS")
+	f.stringVar(&config.ClientKey, "client-key", "", "file", "PEM private key of --client-cert (default: read from the --client-cert file)")
+	f.stringVar(&config.SNI, "sni", "", "name", "TLS server name to send and verify instead of the endpoint host (testing by IP or behind SNI routing)")
+	f.boolVar(&config.TLSResumption, "tls-resumption", "", "Test TLS session ticket resumption")
+	f.boolVar(&config.SNIProbe, "sni-probe", "", "Report which certificate is served without SNI and when connecting by raw IP")
+	f.boolVar(&config.SkipOCSP, "skip-ocsp", "", "Do not query the OCSP responder of the certificate; a stapled OCSP response is still checked")
+	f.intVar(&config.CertWarnDays, "cert-warn-days", "", "days", "Warn when the certificate expires within this many days (default: 30)")
+	f.intVar(&config.CertCritDays, "cert-crit-days", "", "days", "Critical expiry threshold in days (default: 7)")
+	f.boolVar(&config.FailOnCertExpiry, "fail-on-cert-expiry", "", "Fail the TLS check, and exit with code 1, when the certificate expires within --cert-warn-days")
+
+	f.section("CHECK FLAGS")
+	f.boolVar(&config.CheckObject, "check-object", "", "PUT, GET, compare and DELETE a test object to verify read/write access (writes to the bucket)")
+	f.boolVar(&config.CheckExpect, "check-expect-continue", "", "Upload a test object with Expect: 100-continue and verify the interim response (writes to the bucket)")
+	f.boolVar(&config.ProbeTransfer, "probe-transfer-encoding", "", "Probe chunked uploads without Content-Length and zero-length PUTs (writes to the bucket)")
+	f.boolVar(&config.CheckEncoding, "check-content-encoding", "", "Upload a gzip-encoded object and verify it is returned byte-identically with Content-Encoding intact (writes to the bucket)")
+	f.boolVar(&config.CheckCache, "check-cache-headers", "", "Verify Cache-Control and Expires are returned unchanged and report CDN cache headers (writes to the bucket)")
+	f.boolVar(&config.CheckVersionedDelete, "check-versioned-delete", "", "On a versioned bucket, delete a test object by key and by versionId and verify delete markers and permanent removal match AWS (writes to the bucket)")
+	f.boolVar(&config.CheckVersioning, "check-versioning", "", "Read the bucket's versioning status and, when it is enabled, write two versions of a test object, list them and read the first back by versionId (writes to the bucket unless --read-only)")
+	f.boolVar(&config.CheckPresigned, "check-presigned", "", "Upload and download a test object through presigned URLs without credentials and verify that tampered and expired URLs are refused (writes to the bucket)")
+	f.boolVar(&config.CheckPostPolicy, "check-post-policy", "", "Upload a test object with a signed POST policy form, as browsers do, and verify uploads that violate the policy are refused (writes to the bucket)")
+	f.boolVar(&config.CheckCopy, "check-copy", "", "Copy a test object with CopyObject and UploadPartCopy and report quirks in metadata handling and copy preconditions (writes to the bucket)")
+	f.boolVar(&config.CheckBatchDelete, "check-batch-delete", "", "Delete test objects with a multi-object DeleteObjects request and verify the response and that the objects are gone (writes to the bucket)")
+	f.boolVar(&config.CheckConditional, "check-conditional", "", "Upload a test object and verify that Range, If-Match, If-None-Match, If-Modified-Since and If-Unmodified-Since GETs behave as on S3 (writes to the bucket)")
+	f.boolVar(&config.CheckChecksums, "check-checksums", "", "Upload a test object with correct and wrong Content-MD5, SHA-256 and CRC32C digests and report which integrity mechanisms the provider honors on upload and download (writes to the bucket)")
+	f.boolVar(&config.CheckSSEC, "check-sse-c", "", "Upload and download a test object encrypted with a customer-provided key (SSE-C) and verify it cannot be read without the key (writes to the bucket; HTTPS endpoints only)")
+	f.boolVar(&config.CheckRangedGet, "check-ranged-get", "", "Download a test object with concurrent ranged GETs like SDK transfer managers and verify the reassembled content (writes to the bucket)")
+	f.intVar(&config.RangedGetSizeMB, "ranged-get-size", "", "mb", "Size of the ranged GET test object in MiB (default: 64)")
+	f.intVar(&config.RangedGetConcurrency, "ranged-get-concurrency", "", "n", "Concurrent ranged GETs (default: 10)")
+	f.boolVar(&config.SDKParity, "sdk-parity", "", "Send HeadBucket, ListObjectsV2 and an object round trip with both s3tester's signer and the AWS SDK for Go and compare the outcomes (writes to the bucket)")
+	f.boolVar(&config.ProbeCapabilities, "probe-capabilities", "", "Test the endpoint for virtual-hosted and path-style addressing, the policy, ACL and versioning APIs and multipart uploads, and print the capabilities found as a providers file entry (starts and aborts a multipart upload unless --read-only)")
+	f.stringVar(&config.CDN, "cdn", "", "url", "Validate delivery through the CDN fronting the bucket: DNS and TLS of the CDN host, and an object fetched through it compared with the origin (writes to the bucket unless --object-key is set)")
+	f.boolVar(&config.CheckPolicy, "check-policy", "", "Retrieve and analyze the bucket policy and ACL; on AWS also read the bucket and account Block Public Access settings")
+	f.boolVar(&config.CheckPublicAccess, "check-public-access-block", "", "Read the bucket's Block Public Access settings (on AWS also the account's) and fail when none blocks public access, warn when some are off")
+	f.boolVar(&config.TestBothStyles, "test-both-styles", "", "Run the authentication check with virtual-hosted and with path-style addressing and report which styles work for the bucket")
+	f.boolVar(&config.CheckOwnership, "check-ownership", "", "Read the bucket's Object Ownership setting and report whether ACLs are disabled; ACL probes are skipped on buckets with ACLs disabled")
+	f.boolVar(&config.CheckObjectLock, "check-object-lock", "", "Read the bucket's Object Lock configuration and default retention and warn when Object Lock is not enabled")
+	f.boolVar(&config.TestRetention, "test-retention", "", "Like --check-object-lock, and verify that a test object under a one-minute governance retention cannot be deleted (writes to the bucket)")
+	f.boolVar(&config.CheckLifecycle, "check-lifecycle", "", "Read and validate the bucket's lifecycle rules and warn when none expires objects or aborts incomplete multipart uploads")
+	f.boolVar(&config.CheckLogging, "check-logging", "", "Read the bucket's server access logging configuration and warn when logging is disabled")
+	f.boolVar(&config.CheckNotifications, "check-notifications", "", "Read the bucket's event notification targets (SNS, SQS, Lambda, EventBridge, webhooks) and warn when none is configured")
+	f.stringVar(&config.VerifyNotifications, "verify-notifications", "", "url", "Like --check-notifications, and listen on this http URL, upload a canary object and wait up to a minute for its event to be delivered there (writes to the bucket)")
+	f.boolVar(&config.CheckWebsite, "check-website", "", "Read the bucket's static website configuration and request the index document from the website endpoint")
+	f.stringVar(&config.WebsiteEndpoint, "website-endpoint", "", "url", "Website endpoint of the bucket for --check-website, on providers other than AWS; implies --check-website")
+	f.boolVar(&config.CheckTagging, "check-tagging", "", "Read the bucket's tags and verify object tagging with a round trip on a test object (writes to the bucket unless --read-only)")
+	f.boolVar(&config.CheckReplication, "check-replication", "", "Read the bucket's replication rules and destinations and warn when none is enabled")
+	f.boolVar(&config.TestReplication, "test-replication", "", "Like --check-replication, and write a canary object and poll its replication status for up to two minutes (writes to the bucket)")
+	f.boolVar(&config.CheckLocation, "check-location", "", "Read the bucket's region with GetBucketLocation and fail when it is not --region")
+	f.boolVar(&config.CheckPermissions, "check-permissions", "", "Attempt a matrix of S3 operations and report which are allowed or denied (writes a test object and writes the current bucket ACL back unchanged)")
+	f.boolVar(&config.CheckArtifacts, "check-artifacts", "", "List objects under the test prefix (default: all s3tester-* prefixes) and report stale artifacts left by interrupted runs")
+	f.boolVar(&config.PurgeArtifacts, "purge-artifacts", "", "Like --check-artifacts, and delete the stale artifacts")
+	f.boolVar(&config.SkipAnonymous, "skip-anonymous-scan", "", "Do not probe the bucket without credentials for public read or list access")
+	f.stringVar(&config.TestPrefix, "test-prefix", "", "pfx", "Key prefix for all objects written by the tool (default: s3tester-<runid>/); writes outside it are refused")
+	f.stringVar(&config.ObjectKey, "object-key", "", "key", "Check HEAD, GET, ACL, tagging and presigned GET access to an existing object; implies --read-only")
+	f.boolVar(&config.ReadOnly, "read-only", "", "Disable every check that writes to the bucket and refuse any write request; the report records that the run was side-effect free")
+	f.funcVar("require", "", "caps", "Fail unless the endpoint provides the listed capabilities, e.g. versioning,encryption,policy=full (versioning, encryption, object-lock, policy, acl); repeatable", func(value string) error {
+		config.Require = append(config.Require, value)
+		return nil
+	})
+	f.stringVar(&config.ConfigFile, "config", "", "file", "JSON config file with SLO thresholds (max DNS ms, max TTFB ms, min TLS version, min certificate days) that turn passing checks into WARN or FAIL")
+	f.funcVar("budget", "", "duration", "Finish the checks within this time, e.g. 30s or 2m: connectivity checks run first, optional checks share what is left and are skipped when it runs out", func(value string) error {
+		budget, err := parseBudget(value)
+		if err != nil {
+			return err
+		}
+		config.Budget = budget
+		return nil
+	})
+	f.intVar(&config.SlowThresholdMs, "slow-threshold", "", "ms", "Trace every S3 request, log those slower than <ms> with their DNS, connect, TLS, first byte and transfer times, and report the slowest operations")
+
+	f.section("REPEATED RUN FLAGS")
+	f.intVar(&config.Repeat, "repeat", "", "n", "Run the whole suite n times and report per-check success rate and duration distribution (default: 1)")
+	f.boolVar(&config.Watch, "watch", "", "Re-run the suite until interrupted, printing one status line per run; Ctrl+C prints the aggregated report")
+	f.intVar(&config.Interval, "interval", "", "seconds", "Pause between --watch runs (default: 60)")
+	f.boolVar(&config.WatchPolicy, "watch-policy", "", "Fingerprint the bucket policy, ACL grants and Block Public Access settings every run and warn when they change")
+	f.stringVar(&config.NotifyWebhook, "notify-webhook", "", "url", "POST a JSON notification when --watch-policy detects a change")
+
+	f.section("OUTPUT FLAGS")
+	f.stringVar(&config.OutputFile, "output-file", "o", "file", "Save the report to file (JSON unless --output-format)")
+	f.funcVar("output-format", "", "fmt", "Report format: console, json, yaml or junit. Without --output-file, json and yaml replace the console report on stdout; with it, the format of the file (default: json)", func(value string) error {
+		config.OutputFormat = strings.ToLower(value)
+		return nil
+	})
+	f.funcVar("format", "", "fmt", "Console output: console, or oneline for one tab-separated line of target, status, duration and failed checks (default: console)", func(value string) error {
+		config.Format = strings.ToLower(value)
+		return nil
+	})
+	f.boolVar(&config.Nagios, "nagios", "", "Nagios/Icinga plugin output: one status line with perfdata and exit codes 0 OK, 1 WARNING, 2 CRITICAL, 3 UNKNOWN")
+	f.stringVar(&config.EmitPolicy, "emit-policy", "", "file", "Write the least-privilege IAM policy for the selected checks to file (- for stdout)")
+	f.stringVar(&config.Lang, "lang", "", "code", "Language of the console output and remediation suggestions: en or fi (default: en)")
+	f.stringVar(&config.Messages, "messages", "", "file", `JSON catalog of "English text": "translation" pairs layered over --lang, to add or adjust a translation`)
+	f.stringVar(&config.RemediationFile, "remediation-file", "", "file", "YAML remediation rules tried before the built-in ones, to add site-specific guidance")
+	f.boolVar(&config.Anonymize, "anonymize", "", "Replace bucket names, hostnames, IP addresses and account IDs with consistent hashed tokens in the console output and reports, for sharing publicly")
+	f.stringVar(&config.SupportBundle, "support-bundle", "", "zip", "Write a zip for the storage provider's support with the report, sanitized HTTP transcripts, certificates (PEM), DNS answers and environment information")
+	f.stringVar(&config.ReportDir, "report-dir", "", "dir", "Archive each run's JSON report with a timestamped name")
+	f.intVar(&config.ReportKeep, "report-keep", "", "n", "Reports kept per bucket in --report-dir (default: 30, 0 = unlimited)")
+	f.intVar(&config.ReportMaxAge, "report-max-age", "", "days", "Remove archived reports older than this many days (default: 0 = no age limit)")
+	f.stringVar(&config.Baseline, "baseline", "", "file", "Compare the run with an earlier JSON report and list regressions and improvements: new failures, latency changes, TLS and certificate changes")
+	f.boolVar(&config.FailOnRegression, "fail-on-regression", "", "With --baseline, exit with code 1 when there are regressions, and only then")
+	f.stringVar(&config.History, "history", "", "file", "Record the run in this SQLite database, e.g. ~/.s3tester/history.db, for s3tester history to show trends")
+	f.boolVar(&config.IncludeCredentials, "include-credentials", "", "Write the secret key and session token to JSON reports unmasked (default: masked)")
+
+	f.section("GENERAL FLAGS")
+	f.boolVar(&config.Verbose, "verbose", "v", "Enable verbose output")
+	f.boolVar(&config.ASCII, "ascii", "", "Plain ASCII output without icons or color, for screen readers and ticketing systems")
+	f.boolFunc("version", "", "Show version information", func() error {
+		fmt.Printf("s3-bucket-tester version %s\n", Version)
+		os.Exit(0)
+		return nil
+	})
+	f.boolVar(&config.listProviders, "list-providers", "", "List the built-in providers and their capabilities, like s3tester providers")
+	f.help()
 }
 
-// printHelp prints the help message
+// printHelp prints the help message of the check command
 func printHelp() {
-	fmt.Println(`S3 Bucket Tester - Test S3-compatible storage providers
+	fmt.Print(`S3 Bucket Tester - Test S3-compatible storage providers
 
 USAGE:
-    s3tester [FLAGS]
-
-REQUIRED FLAGS:
-    --bucket <name>        Bucket name to test
-    --access-key <key>     Access key ID
-    --secret-key <key>     Secret access key
-
-ENDPOINT FLAGS (required):
-    --endpoint <url>       S3 endpoint URL or built-in provider shortcut
-
-    Built-in providers (use with --endpoint):
-        aws                    <bucket>.s3.<region>.amazonaws.com
-        aws-legacy             s3.<region>.amazonaws.com/<bucket>
-        wasabi                 <bucket>.s3.<region>.wasabisys.com
-        wasabi-legacy          s3.<region>.wasabisys.com/<bucket>
-        b2                     <bucket>.s3.<region>.backblazeb2.com
-        b2-legacy              s3.<region>.backblazeb2.com/<bucket>
-        ibm                    <bucket>.<region>.objectstorage.cloud.ibm.com
-        do                     <bucket>.<region>.digitaloceanspaces.com
-
-    Custom endpoint examples:
-        https://s3.example.com
-        http://localhost:9000
-        s3.example.com
-        s3.example.com:9000
-        192.168.1.10:8080
-
-ADDRESSING STYLE FLAGS:
-    --virtual-hosted        Use virtual-hosted addressing (default)
-                            URL format: https://<bucket>.<endpoint>
-    --path-style            Use path-style addressing
-                            URL format: https://<endpoint>/<bucket>
-
-OPTIONAL FLAGS:
-    --region <region>      AWS region (default: us-east-1)
-    --auth-type <type>     Authentication type: sigv4 or sigv2 (default: sigv4)
-    --insecure             Skip TLS certificate verification (not recommended)
-    --timeout <seconds>    Request timeout in seconds (default: 30)
-    --output-file <file>   Save JSON output to file
-    --follow-redirects     Follow HTTP redirects (default: true)
-    --no-redirects         Do not follow HTTP redirects
-    --max-redirects <n>    Maximum redirects to follow (default: 10)
-    --verbose              Enable verbose output
-    --help, -h             Show this help message
-    --version              Show version information
-
-EXAMPLES:
+    s3tester [check] [FLAGS]
+
+Runs the connectivity, TLS and S3 checks against a bucket. The endpoint,
+the bucket and credentials are required. Other commands: bench, compare,
+cert-watch, policy, history, providers, version (see s3tester help).
+
+`)
+	flags := newFlagSet("check")
+	addCheckFlags(flags, GetDefaultConfig())
+	flags.printFlags(os.Stdout)
+
+	fmt.Println(`EXAMPLES:
     # Using built-in provider (AWS)
     s3tester --endpoint aws --region us-east-1 \
              --bucket my-bucket \
              --access-key KEY \
              --secret-key SECRET
 
-    # Using custom insecure endpoint
-    s3tester --endpoint 192.168.0.10:9000 \
-             --bucket my-bucket \
-             --access-key KEY \
-             --secret-key SECRET \
-             --insecure
+    # Using custom insecure endpoint, with short flags
+    s3tester -e 192.168.0.10:9000 -b my-bucket -k \
+             --access-key=KEY --secret-key=SECRET
 
     # Using path-style addressing
-    s3tester --endpoint s3.example.com \
+    s3tester check --endpoint s3.example.com \
              --bucket my-bucket \
              --access-key KEY \
              --secret-key SECRET \
@@ -200,9 +304,11 @@ EXAMPLES:
              --auth-type sigv2`)
 }
 
-// ListProviders prints all available built-in providers
+// ListProviders prints the provider shortcuts, built-in and from the
+// providers file, and the capability matrix of the providers s3tester
+// detects from the endpoint
 func ListProviders() {
-	fmt.Println("Built-in providers:")
+	fmt.Println("Providers (use with --endpoint):")
 	fmt.Println()
 
 	// Sort provider names
@@ -212,9 +318,77 @@ func ListProviders() {
 	}
 	sort.Strings(names)
 
+	width := 0
+	for _, name := range names {
+		width = max(width, len(Providers[name].Template))
+	}
+
 	// Print providers
 	for _, name := range names {
 		provider := Providers[name]
-		fmt.Printf("  %-15s  %s\n", name, provider.Description)
+		fmt.Printf("  %-15s  %-*s  %s\n", name, width, provider.Template, provider.Description)
+	}
+
+	fmt.Println()
+	fmt.Println("Provider capabilities (detected from the endpoint host):")
+	fmt.Println()
+
+	// Detected providers in name order, with the fallback for unknown
+	// endpoints last
+	names = names[:0]
+	for name := range ProviderCapabilitiesMap {
+		if name != "custom" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append(names, "custom")
+
+	fmt.Printf("  %-11s %-29s %-9s %-15s %-15s %-11s %s\n", "PROVIDER", "NAME", "POLICY", "ACL", "VIRTUAL-HOSTED", "PATH-STYLE", "NOTES")
+	for _, name := range names {
+		caps := ProviderCapabilitiesMap[name]
+		virtualHosted, pathStyle := supportText(caps.VirtualHostSupport), supportText(caps.PathStyleSupport)
+		// Nothing is known about the addressing of an unknown provider
+		if name == "custom" {
+			virtualHosted, pathStyle = "Unknown", "Unknown"
+		}
+		fmt.Printf("  %-11s %-29s %-9s %-15s %-15s %-11s %s\n",
+			name, caps.Name, caps.PolicySupport, caps.ACLSupport, virtualHosted, pathStyle, caps.Notes)
+	}
+}
+
+// supportText formats an addressing capability for the provider list
+func supportText(supported bool) string {
+	if supported {
+		return "Yes"
+	}
+	return "No"
+}
+
+// parseBudget parses the value of --budget: a duration such as 30s or 1m30s,
+// or a bare number of seconds like the other timeouts take
+func parseBudget(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	budget, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --budget %q: use a duration such as 30s or 2m", value)
+	}
+	return budget, nil
+}
+
+// addIPFamilyFlags defines --ipv4 and --ipv6, which force the address family
+// of every connection, described by scope, into ipFamily
+func addIPFamilyFlags(f *flagSet, ipFamily *string, scope string) {
+	for _, family := range []string{"ipv4", "ipv6"} {
+		family := family
+		f.boolFunc(family, "", "Resolve and connect over "+strings.ToUpper(family[:2])+family[2:]+" only, "+scope, func() error {
+			if *ipFamily != "" && *ipFamily != family {
+				return fmt.Errorf("--ipv4 and --ipv6 cannot be combined")
+			}
+			*ipFamily = family
+			return nil
+		})
 	}
 }
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBudget(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30", 30 * time.Second, false},
+		{"0", 0, false},
+		{"30s", 30 * time.Second, false},
+		{"1m30s", 90 * time.Second, false},
+		{"2h", 2 * time.Hour, false},
+		{"500ms", 500 * time.Millisecond, false},
+		{"", 0, true},
+		{"soon", 0, true},
+		{"30 s", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseBudget(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBudget(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseBudget(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPFamilyFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"neither", nil, "", false},
+		{"ipv4", []string{"--ipv4"}, "ipv4", false},
+		{"ipv6", []string{"--ipv6"}, "ipv6", false},
+		{"repeated", []string{"--ipv6", "--ipv6"}, "ipv6", false},
+		{"explicitly false", []string{"--ipv4=false"}, "", false},
+		{"other family false", []string{"--ipv4", "--ipv6=false"}, "ipv4", false},
+		{"both", []string{"--ipv4", "--ipv6"}, "", true},
+		{"invalid value", []string{"--ipv4=maybe"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var family string
+			flags := newFlagSet("test")
+			flags.section("FLAGS")
+			addIPFamilyFlags(flags, &family, "")
+
+			_, err := flags.parse(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parse(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if err == nil && family != tt.want {
+				t.Errorf("parse(%q) family = %q, want %q", tt.args, family, tt.want)
+			}
+		})
+	}
+}
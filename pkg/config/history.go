@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryConfig holds the configuration for the history command
+type HistoryConfig struct {
+	Database   string
+	Target     string
+	Since      time.Duration
+	OutputFile string
+	ASCII      bool
+}
+
+// ParseHistoryFlags parses the arguments of the history command
+func ParseHistoryFlags(args []string) (*HistoryConfig, error) {
+	config := &HistoryConfig{Since: 30 * 24 * time.Hour}
+
+	flags := newFlagSet("history")
+	addHistoryFlags(flags, config)
+
+	positional, err := flags.parse(args)
+	if err != nil {
+		return nil, exitOnHelp(err, printHistoryHelp)
+	}
+	if len(positional) > 0 {
+		return nil, fmt.Errorf("unexpected argument %q: history takes only flags", positional[0])
+	}
+	return config, nil
+}
+
+// addHistoryFlags defines the flags of the history command on the flag set
+func addHistoryFlags(f *flagSet, config *HistoryConfig) {
+	f.section("FLAGS")
+	f.stringVar(&config.Database, "history", "", "file", "SQLite database the runs were recorded in with --history (default: ~/.s3tester/history.db)")
+	f.stringVar(&config.Target, "target", "", "text", "Show only the endpoints and buckets whose URL contains the text, e.g. a bucket name")
+	f.funcVar("since", "", "duration", "Show the runs of this period, e.g. 7d or 12h (default: 30d)", func(value string) error {
+		since, err := parseSince(value)
+		if err != nil {
+			return err
+		}
+		config.Since = since
+		return nil
+	})
+	f.stringVar(&config.OutputFile, "output-file", "o", "file", "Save the trends as JSON to file")
+	f.boolVar(&config.ASCII, "ascii", "", "Plain ASCII output without icons or color")
+	f.help()
+}
+
+// parseSince parses a period in days, such as 7d, or as a Go duration
+func parseSince(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if since, err := time.ParseDuration(value); err == nil && since > 0 {
+		return since, nil
+	}
+	return 0, fmt.Errorf("use a period such as 7d or 12h")
+}
+
+// printHistoryHelp prints the help message for the history command
+func printHistoryHelp() {
+	fmt.Print(`S3 Bucket Tester - History
+
+USAGE:
+    s3tester history [FLAGS]
+
+Shows the trends of the runs recorded with --history: for each endpoint and
+bucket the current status of every check and when it changed, the daily
+median duration of every check, and when the certificate was replaced.
+
+`)
+	flags := newFlagSet("history")
+	addHistoryFlags(flags, &HistoryConfig{})
+	flags.printFlags(os.Stdout)
+
+	fmt.Println(`EXAMPLES:
+    s3tester --endpoint https://s3.example.com --bucket my-bucket \
+             --history ~/.s3tester/history.db
+
+    s3tester history --target my-bucket --since 7d`)
+}
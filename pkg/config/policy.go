@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// ParsePolicyFlags parses the arguments of the policy command. It takes the
+// flags of a check run, which select the checks the policy must allow, but
+// needs no credentials since nothing is sent to the endpoint.
+func ParsePolicyFlags(args []string) (*Config, error) {
+	config := GetDefaultConfig()
+	config.policyOnly = true
+
+	flags := newFlagSet("policy")
+	addCheckFlags(flags, config)
+
+	positional, err := flags.parse(args)
+	if err != nil {
+		return nil, exitOnHelp(err, printPolicyHelp)
+	}
+	if len(positional) > 0 {
+		return nil, fmt.Errorf("unexpected argument %q: policy takes only flags", positional[0])
+	}
+
+	if err := config.resolve(flags.isSet("region")); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// printPolicyHelp prints the help message for the policy command
+func printPolicyHelp() {
+	fmt.Print(`S3 Bucket Tester - Least-privilege IAM policy
+
+USAGE:
+    s3tester policy [FLAGS]
+
+Prints the IAM policy that allows exactly the requests of a check run with
+the same flags, without running the checks. The endpoint and the bucket are
+required; credentials are not. The policy is written to stdout, or to the
+file named by --emit-policy.
+
+`)
+	flags := newFlagSet("policy")
+	addCheckFlags(flags, GetDefaultConfig())
+	flags.printFlags(os.Stdout)
+
+	fmt.Println(`EXAMPLES:
+    s3tester policy --endpoint aws --bucket my-bucket --check-object --check-policy
+
+    s3tester policy -e aws -b my-bucket --object-key reports/latest.csv \
+                    --emit-policy s3tester-policy.json`)
+}
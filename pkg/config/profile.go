@@ -0,0 +1,159 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Profile holds the settings resolved from the AWS shared configuration files
+type Profile struct {
+	Name         string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Region       string
+}
+
+// sharedFilePath returns the path of an AWS shared file, honoring the
+// environment variable that overrides its location
+func sharedFilePath(envVar, name string) string {
+	if path := os.Getenv(envVar); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".aws", name)
+}
+
+// LoadProfile resolves a named profile from ~/.aws/credentials and ~/.aws/config.
+// Keys in the credentials file take precedence over the same keys in the
+// config file, matching the AWS CLI and SDKs.
+func LoadProfile(name string) (*Profile, error) {
+	credentialsPath := sharedFilePath("AWS_SHARED_CREDENTIALS_FILE", "credentials")
+	configPath := sharedFilePath("AWS_CONFIG_FILE", "config")
+
+	credentials, err := parseINIFile(credentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", credentialsPath, err)
+	}
+	configSections, err := parseINIFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	// The config file prefixes every section except default with "profile "
+	configSection := "profile " + name
+	if name == "default" {
+		configSection = "default"
+	}
+
+	fromCredentials, inCredentials := credentials[name]
+	fromConfig, inConfig := configSections[configSection]
+	if !inConfig && name == "default" {
+		fromConfig, inConfig = configSections["profile default"]
+	}
+	if !inCredentials && !inConfig {
+		return nil, fmt.Errorf("profile %q not found in %s or %s", name, credentialsPath, configPath)
+	}
+
+	lookup := func(key string) string {
+		if value := fromCredentials[key]; value != "" {
+			return value
+		}
+		return fromConfig[key]
+	}
+
+	return &Profile{
+		Name:         name,
+		AccessKey:    lookup("aws_access_key_id"),
+		SecretKey:    lookup("aws_secret_access_key"),
+		SessionToken: lookup("aws_session_token"),
+		Region:       fromConfig["region"],
+	}, nil
+}
+
+// parseINIFile parses an AWS shared file into sections of lowercase keys.
+// A missing file yields no sections.
+func parseINIFile(path string) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+	if path == "" {
+		return sections, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sections, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var current map[string]string
+	nested := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		// Indented lines below a key without value (e.g. "s3 =") belong to a
+		// nested sub-section, which is not used
+		if nested && raw != strings.TrimLeft(raw, " \t") {
+			continue
+		}
+		nested = false
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+			if sections[name] == nil {
+				sections[name] = make(map[string]string)
+			}
+			current = sections[name]
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || current == nil {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			nested = true
+			continue
+		}
+		current[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+
+	return sections, scanner.Err()
+}
+
+// applyProfile fills credentials and region from a named profile. Values given
+// explicitly on the command line are kept.
+func (c *Config) applyProfile(regionSet bool) error {
+	profile, err := LoadProfile(c.Profile)
+	if err != nil {
+		return err
+	}
+
+	if c.AccessKey == "" && c.SecretKey == "" {
+		c.AccessKey = profile.AccessKey
+		c.SecretKey = profile.SecretKey
+		c.CredentialSource = "profile " + profile.Name
+		if c.SessionToken == "" {
+			c.SessionToken = profile.SessionToken
+		}
+	}
+	if !regionSet && profile.Region != "" {
+		c.Region = profile.Region
+	}
+
+	return nil
+}
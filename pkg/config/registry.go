@@ -0,0 +1,213 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProviderDefinition is a provider in a providers file: an endpoint template
+// usable with --endpoint and, optionally, the capabilities s3tester assumes
+// when it detects the provider from an endpoint host
+type ProviderDefinition struct {
+	Template     string                  `yaml:"template"`
+	Description  string                  `yaml:"description"`
+	Match        []string                `yaml:"match"`
+	Capabilities *ProviderCapabilityFile `yaml:"capabilities"`
+}
+
+// ProviderCapabilityFile is the capability entry of a providers file
+type ProviderCapabilityFile struct {
+	Name          string `yaml:"name"`
+	Policy        string `yaml:"policy"`
+	ACL           string `yaml:"acl"`
+	VirtualHosted bool   `yaml:"virtualHosted"`
+	PathStyle     bool   `yaml:"pathStyle"`
+	Notes         string `yaml:"notes"`
+}
+
+// providerMatches holds the host patterns of the providers loaded from a
+// file, tried by DetectProvider before the built-in patterns
+var providerMatches []providerMatch
+
+// providersLoaded is set once loadProviders has run, so resolving several
+// configurations in one process, as compare does, loads the file once
+var providersLoaded bool
+
+type providerMatch struct {
+	name     string
+	patterns []string
+}
+
+// DefaultProvidersFile returns the providers file loaded when
+// --providers-file is not given, or "" when there is no user config directory
+func DefaultProvidersFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "s3tester", "providers.yaml")
+}
+
+// loadProviders loads the providers file named by --providers-file, or the
+// default one when it exists
+func loadProviders(path string) error {
+	if providersLoaded {
+		return nil
+	}
+	providersLoaded = true
+	if path == "" {
+		path = DefaultProvidersFile()
+		if path == "" {
+			return nil
+		}
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+	return LoadProvidersFile(path)
+}
+
+// ParseProvidersFlags parses the arguments of the providers command and
+// loads the providers file, so the listing includes its providers
+func ParseProvidersFlags(args []string) error {
+	var path string
+	flags := newFlagSet("providers")
+	flags.section("FLAGS")
+	flags.stringVar(&path, "providers-file", "", "file", "YAML file of additional provider shortcuts and capabilities (default: s3tester/providers.yaml in the user config directory, when it exists)")
+	flags.help()
+
+	positional, err := flags.parse(args)
+	if err != nil {
+		return exitOnHelp(err, func() {
+			fmt.Print("S3 Bucket Tester - Providers\n\nUSAGE:\n    s3tester providers [FLAGS]\n\n")
+			flags.printFlags(os.Stdout)
+		})
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument %q: providers takes only flags", positional[0])
+	}
+	return loadProviders(path)
+}
+
+// LoadProvidersFile reads provider definitions from a YAML file and merges
+// them into Providers and ProviderCapabilitiesMap; a definition with the name
+// of a built-in provider replaces it
+func LoadProvidersFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read providers file: %w", err)
+	}
+
+	definitions, err := parseProviders(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse providers file %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		definition := definitions[name]
+		description := definition.Description
+		if description == "" {
+			description = name
+		}
+		Providers[name] = ProviderEndpoint{
+			Template:    definition.Template,
+			Description: description,
+		}
+		if definition.Capabilities == nil {
+			continue
+		}
+
+		caps := definition.Capabilities
+		if caps.Name == "" {
+			caps.Name = description
+		}
+		ProviderCapabilitiesMap[name] = &ProviderCapabilities{
+			Name:               caps.Name,
+			PolicySupport:      caps.Policy,
+			ACLSupport:         caps.ACL,
+			VirtualHostSupport: caps.VirtualHosted,
+			PathStyleSupport:   caps.PathStyle,
+			Notes:              caps.Notes,
+		}
+		patterns := definition.Match
+		if len(patterns) == 0 {
+			if domain := templateDomain(definition.Template); domain != "" {
+				patterns = []string{domain}
+			}
+		}
+		providerMatches = append(providerMatches, providerMatch{name: name, patterns: patterns})
+	}
+
+	return nil
+}
+
+// parseProviders decodes a YAML map of provider definitions, rejecting
+// unknown fields so a misspelled key is not silently ignored
+func parseProviders(data []byte) (map[string]ProviderDefinition, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var definitions map[string]ProviderDefinition
+	if err := decoder.Decode(&definitions); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	for name, definition := range definitions {
+		if name == "" || strings.ContainsAny(name, " /:") {
+			return nil, fmt.Errorf("invalid provider name %q: use a shortcut such as mycorp", name)
+		}
+		if name == "custom" {
+			return nil, fmt.Errorf("provider name %q is reserved for unknown endpoints", name)
+		}
+		if definition.Template == "" {
+			return nil, fmt.Errorf("provider %s has no template", name)
+		}
+		for _, pattern := range definition.Match {
+			if pattern == "" {
+				return nil, fmt.Errorf("provider %s has an empty match pattern", name)
+			}
+		}
+	}
+	return definitions, nil
+}
+
+// templateDomain returns the fixed part of a template's host after its last
+// placeholder, such as ceph.mycorp.internal for
+// <bucket>.s3.<region>.ceph.mycorp.internal, to detect the provider by
+func templateDomain(template string) string {
+	host := template
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	host, _, _ = strings.Cut(host, "/")
+	if i := strings.LastIndex(host, ">"); i >= 0 {
+		host = host[i+1:]
+	}
+	return strings.ToLower(strings.Trim(host, "."))
+}
+
+// detectUserProvider returns the provider from a providers file whose host
+// pattern the endpoint contains, or ""
+func detectUserProvider(endpoint string) string {
+	for _, match := range providerMatches {
+		for _, pattern := range match.patterns {
+			if strings.Contains(endpoint, strings.ToLower(pattern)) {
+				return match.name
+			}
+		}
+	}
+	return ""
+}
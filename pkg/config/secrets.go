@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/credentials"
+	"github.com/s3-bucket-tester/s3tester/pkg/proxy"
+	"github.com/s3-bucket-tester/s3tester/pkg/secrets"
+)
+
+// resolveSecrets replaces secret references in the credentials, such as
+// --secret-key vault://kv/s3/prod#secret, with the values they name. The
+// references may come from the command line, a profile or the environment,
+// so the keys themselves need not be stored anywhere s3tester reads.
+// Secrets Manager is called with the AWS credentials of the default chain,
+// not with the ones being resolved.
+func (c *Config) resolveSecrets() error {
+	timeout := time.Duration(c.Timeout) * time.Second
+	awsCredentials := credentials.ProviderFunc{
+		SourceName: "credential chain",
+		Fn:         c.credentialChain(true).Retrieve,
+	}
+	resolver := secrets.NewResolver(
+		secrets.NewVaultBackend(proxy.Func(c.Proxy), timeout),
+		secrets.NewSecretsManagerBackend(awsCredentials, c.Region, proxy.Func(c.Proxy), timeout),
+	)
+
+	fields := []struct {
+		flag  string
+		value *string
+	}{
+		{"--access-key", &c.AccessKey},
+		{"--secret-key", &c.SecretKey},
+		{"--session-token", &c.SessionToken},
+	}
+
+	var sources []string
+	for _, f := range fields {
+		if !resolver.IsReference(*f.value) {
+			continue
+		}
+		ref, err := secrets.ParseReference(*f.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.flag, err)
+		}
+		value, err := resolver.Resolve(*f.value)
+		if err != nil {
+			return fmt.Errorf("%s: failed to resolve secret: %w", f.flag, err)
+		}
+		*f.value = value
+
+		source := ref.Scheme + "://" + ref.Path
+		if len(sources) == 0 || sources[len(sources)-1] != source {
+			sources = append(sources, source)
+		}
+	}
+
+	if len(sources) > 0 {
+		if c.CredentialSource == "" {
+			c.CredentialSource = strings.Join(sources, ", ")
+		} else {
+			c.CredentialSource += " (" + strings.Join(sources, ", ") + ")"
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNoCredentials is returned by a provider that has nothing to offer in the
+// current environment, so the chain moves on to the next provider
+var ErrNoCredentials = errors.New("no credentials available")
+
+// Value holds resolved credentials and the provider they came from
+type Value struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Expiration   time.Time
+	Source       string
+}
+
+// Provider defines the interface for credential sources
+type Provider interface {
+	// Name returns the name of the source, recorded in the report
+	Name() string
+
+	// Retrieve returns credentials, or ErrNoCredentials if the source is not
+	// configured in this environment
+	Retrieve() (*Value, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface
+type ProviderFunc struct {
+	SourceName string
+	Fn         func() (*Value, error)
+}
+
+// Name returns the name of the source
+func (p ProviderFunc) Name() string {
+	return p.SourceName
+}
+
+// Retrieve calls the wrapped function
+func (p ProviderFunc) Retrieve() (*Value, error) {
+	return p.Fn()
+}
+
+// Chain tries each provider in order and returns the first credentials found
+type Chain []Provider
+
+// Retrieve walks the chain. A provider that is configured but fails stops the
+// chain, like the AWS SDKs, so a broken setup is not silently skipped.
+func (c Chain) Retrieve() (*Value, error) {
+	var tried []string
+	for _, provider := range c {
+		value, err := provider.Retrieve()
+		if errors.Is(err, ErrNoCredentials) {
+			tried = append(tried, provider.Name())
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", provider.Name(), err)
+		}
+		if value.Source == "" {
+			value.Source = provider.Name()
+		}
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("%w (tried: %s)", ErrNoCredentials, strings.Join(tried, ", "))
+}
+
+// DefaultChain returns the environment, web identity (IRSA), ECS task and EC2
+// instance metadata providers in AWS SDK order. Shared-file profiles are
+// resolved by the caller and can be inserted after the environment provider.
+func DefaultChain(region string, timeout time.Duration) Chain {
+	return Chain{
+		&EnvProvider{},
+		&WebIdentityProvider{Region: region, Timeout: timeout},
+		&ECSProvider{Timeout: timeout},
+		&IMDSProvider{Timeout: imdsTimeout},
+	}
+}
@@ -0,0 +1,100 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ecsHost is the link-local address of the ECS task credentials endpoint
+const ecsHost = "http://169.254.170.2"
+
+// ECSProvider reads task role credentials from the ECS (or EKS Pod Identity)
+// container credentials endpoint
+type ECSProvider struct {
+	Timeout time.Duration
+}
+
+// Name returns the name of the source
+func (p *ECSProvider) Name() string {
+	return "ECS container credentials"
+}
+
+// Retrieve fetches credentials from AWS_CONTAINER_CREDENTIALS_RELATIVE_URI or
+// AWS_CONTAINER_CREDENTIALS_FULL_URI
+func (p *ECSProvider) Retrieve() (*Value, error) {
+	endpoint := ""
+	if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		endpoint = ecsHost + relative
+	} else if full := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"); full != "" {
+		endpoint = full
+	}
+	if endpoint == "" {
+		return nil, ErrNoCredentials
+	}
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read authorization token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	client := &http.Client{Timeout: p.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("credentials endpoint returned HTTP %d", resp.StatusCode)
+	}
+
+	return parseCredentialsJSON(body)
+}
+
+// credentialsDocument is the JSON document served by the ECS and EC2
+// instance metadata credential endpoints
+type credentialsDocument struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+// parseCredentialsJSON parses a credentials document
+func parseCredentialsJSON(body []byte) (*Value, error) {
+	var doc credentialsDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("invalid credentials document: %w", err)
+	}
+	if doc.AccessKeyID == "" || doc.SecretAccessKey == "" {
+		return nil, fmt.Errorf("credentials document contains no keys")
+	}
+
+	return &Value{
+		AccessKey:    doc.AccessKeyID,
+		SecretKey:    doc.SecretAccessKey,
+		SessionToken: doc.Token,
+		Expiration:   doc.Expiration,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// EnvProvider reads credentials from the standard AWS environment variables
+type EnvProvider struct{}
+
+// Name returns the name of the source
+func (p *EnvProvider) Name() string {
+	return "environment"
+}
+
+// Retrieve reads AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN,
+// and the expiry of session credentials from AWS_CREDENTIAL_EXPIRATION
+func (p *EnvProvider) Retrieve() (*Value, error) {
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	if accessKey == "" {
+		accessKey = os.Getenv("AWS_ACCESS_KEY")
+	}
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if secretKey == "" {
+		secretKey = os.Getenv("AWS_SECRET_KEY")
+	}
+
+	if accessKey == "" && secretKey == "" {
+		return nil, ErrNoCredentials
+	}
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must both be set")
+	}
+
+	var expiration time.Time
+	if value := os.Getenv("AWS_CREDENTIAL_EXPIRATION"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AWS_CREDENTIAL_EXPIRATION %q: expected RFC 3339 time", value)
+		}
+		expiration = parsed
+	}
+
+	return &Value{
+		AccessKey:    accessKey,
+		SecretKey:    secretKey,
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		Expiration:   expiration,
+	}, nil
+}
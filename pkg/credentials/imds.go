@@ -0,0 +1,126 @@
+package credentials
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// imdsEndpoint is the EC2 instance metadata service
+	imdsEndpoint = "http://169.254.169.254"
+
+	// imdsTimeout keeps the chain fast on machines that are not EC2 instances
+	imdsTimeout = 1 * time.Second
+
+	// imdsTokenTTL is the lifetime requested for the IMDSv2 session token
+	imdsTokenTTL = "21600"
+)
+
+// IMDSProvider reads instance profile credentials from the EC2 instance
+// metadata service, using IMDSv2 and falling back to IMDSv1
+type IMDSProvider struct {
+	Endpoint string
+	Timeout  time.Duration
+}
+
+// Name returns the name of the source
+func (p *IMDSProvider) Name() string {
+	return "EC2 instance metadata"
+}
+
+// Retrieve fetches the credentials of the instance profile role
+func (p *IMDSProvider) Retrieve() (*Value, error) {
+	if strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return nil, ErrNoCredentials
+	}
+
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = imdsEndpoint
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	client := &http.Client{Timeout: p.Timeout}
+
+	// IMDSv2 session token; an unreachable service means this is not EC2
+	token, err := p.sessionToken(client, endpoint)
+	if err != nil {
+		return nil, ErrNoCredentials
+	}
+
+	roles, err := p.get(client, endpoint+"/latest/meta-data/iam/security-credentials/", token)
+	if err != nil {
+		return nil, fmt.Errorf("no instance profile role: %w", err)
+	}
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	if role == "" {
+		return nil, fmt.Errorf("no instance profile role attached")
+	}
+
+	body, err := p.get(client, endpoint+"/latest/meta-data/iam/security-credentials/"+role, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCredentialsJSON(body)
+}
+
+// sessionToken requests an IMDSv2 token. An empty token with no error means
+// the service only speaks IMDSv1.
+func (p *IMDSProvider) sessionToken(client *http.Client, endpoint string) (string, error) {
+	req, err := http.NewRequest("PUT", endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", imdsTokenTTL)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil
+	}
+
+	token, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(token), nil
+}
+
+// get performs a metadata GET with the optional IMDSv2 token
+func (p *IMDSProvider) get(client *http.Client, url, token string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("X-aws-ec2-metadata-token", token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,61 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/sts"
+)
+
+// WebIdentityProvider exchanges a projected service account token for role
+// credentials (EKS IRSA and other OIDC federations)
+type WebIdentityProvider struct {
+	Region  string
+	Timeout time.Duration
+}
+
+// Name returns the name of the source
+func (p *WebIdentityProvider) Name() string {
+	return "web identity"
+}
+
+// Retrieve calls sts:AssumeRoleWithWebIdentity using AWS_WEB_IDENTITY_TOKEN_FILE
+// and AWS_ROLE_ARN
+func (p *WebIdentityProvider) Retrieve() (*Value, error) {
+	tokenFile := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	roleArn := os.Getenv("AWS_ROLE_ARN")
+	if tokenFile == "" || roleArn == "" {
+		return nil, ErrNoCredentials
+	}
+
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = fmt.Sprintf("s3tester-%d", time.Now().Unix())
+	}
+
+	// IRSA injects AWS_REGION; fall back to the configured region
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = p.Region
+	}
+
+	client := sts.NewClient(sts.DefaultEndpoint(region), region, "", "", "", false, p.Timeout)
+	role, err := client.AssumeRoleWithWebIdentity(roleArn, sessionName, strings.TrimSpace(string(token)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Value{
+		AccessKey:    role.Credentials.AccessKey,
+		SecretKey:    role.Credentials.SecretKey,
+		SessionToken: role.Credentials.SessionToken,
+		Expiration:   role.Credentials.Expiration,
+	}, nil
+}
@@ -0,0 +1,7 @@
+//go:build cgo
+
+package history
+
+// The SQLite driver is a cgo package; builds without cgo, such as the
+// cross-compiled release binaries, leave history unavailable
+import _ "github.com/mattn/go-sqlite3"
@@ -0,0 +1,298 @@
+// Package history records check runs in a SQLite database and derives
+// trends from them: latency over time, status changes and certificate
+// replacements.
+package history
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/output"
+)
+
+// driverName is the database/sql driver registered by driver.go, which is
+// only built with cgo
+const driverName = "sqlite3"
+
+// schema creates the tables on first use; runs are keyed by target, the
+// endpoint and bucket, so one database can hold many buckets
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          INTEGER PRIMARY KEY,
+	started_at  TEXT NOT NULL,
+	target      TEXT NOT NULL,
+	endpoint    TEXT NOT NULL,
+	bucket      TEXT NOT NULL,
+	duration_ms REAL NOT NULL,
+	passed      INTEGER NOT NULL,
+	failed      INTEGER NOT NULL,
+	warnings    INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS runs_target ON runs (target, started_at);
+CREATE TABLE IF NOT EXISTS results (
+	run_id      INTEGER NOT NULL REFERENCES runs (id),
+	check_name  TEXT NOT NULL,
+	status      TEXT NOT NULL,
+	duration_ms REAL NOT NULL,
+	error       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS results_run ON results (run_id);
+CREATE TABLE IF NOT EXISTS certificates (
+	run_id      INTEGER NOT NULL REFERENCES runs (id),
+	serial      TEXT NOT NULL,
+	subject     TEXT NOT NULL,
+	issuer      TEXT NOT NULL,
+	not_after   TEXT NOT NULL,
+	tls_version TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS certificates_run ON certificates (run_id);
+`
+
+// Store is a history database
+type Store struct {
+	db   *sql.DB
+	path string
+}
+
+// DefaultPath returns the database used by the history command when
+// --history is not given, ~/.s3tester/history.db
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".s3tester", "history.db")
+	}
+	return filepath.Join(home, ".s3tester", "history.db")
+}
+
+// Open opens the database at path, creating it and its directory when
+// needed
+func Open(path string) (*Store, error) {
+	if !driverAvailable() {
+		return nil, errors.New("history needs SQLite, which this build of s3tester does not include: build it with CGO_ENABLED=1")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history %s: %w", path, err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open history %s: %w", path, err)
+	}
+	return &Store{db: db, path: path}, nil
+}
+
+// driverAvailable reports whether the SQLite driver is compiled in
+func driverAvailable() bool {
+	for _, name := range sql.Drivers() {
+		if name == driverName {
+			return true
+		}
+	}
+	return false
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record stores one run of the checks against the target of config, with
+// the certificate the TLS check saw
+func (s *Store) Record(config output.Config, start, end time.Time, results []output.TestResult) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	summary := output.NewTestSummary(results)
+	run, err := tx.Exec(`INSERT INTO runs (started_at, target, endpoint, bucket, duration_ms, passed, failed, warnings)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		start.UTC().Format(time.RFC3339Nano), config.Target(), config.Endpoint, config.Bucket,
+		milliseconds(end.Sub(start)), summary.Passed, summary.Failed, summary.Warnings)
+	if err != nil {
+		return err
+	}
+	runID, err := run.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if _, err := tx.Exec(`INSERT INTO results (run_id, check_name, status, duration_ms, error) VALUES (?, ?, ?, ?, ?)`,
+			runID, result.TestName, string(result.Status), milliseconds(result.Duration), result.Error); err != nil {
+			return err
+		}
+		details, ok := result.Details.(output.TLSResult)
+		if !ok || details.Certificate.SerialNumber == "" {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO certificates (run_id, serial, subject, issuer, not_after, tls_version) VALUES (?, ?, ?, ?, ?, ?)`,
+			runID, details.Certificate.SerialNumber, details.Certificate.Subject, details.Certificate.Issuer,
+			details.Certificate.NotAfter.UTC().Format(time.RFC3339), details.TLSVersion); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Report builds the trends of the runs since the given time, for the
+// targets that contain filter, or all targets when it is empty
+func (s *Store) Report(filter string, since time.Time) (*output.HistoryReport, error) {
+	report := &output.HistoryReport{Database: s.path, Since: since}
+
+	rows, err := s.db.Query(`SELECT id, started_at, target FROM runs
+		WHERE started_at >= ? AND instr(target, ?) > 0 ORDER BY target, started_at`,
+		since.UTC().Format(time.RFC3339Nano), filter)
+	if err != nil {
+		return nil, err
+	}
+	type run struct {
+		id      int64
+		started time.Time
+	}
+	var targets []string
+	runs := make(map[string][]run)
+	for rows.Next() {
+		var r run
+		var started, target string
+		if err := rows.Scan(&r.id, &started, &target); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if r.started, err = time.Parse(time.RFC3339Nano, started); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if _, ok := runs[target]; !ok {
+			targets = append(targets, target)
+		}
+		runs[target] = append(runs[target], r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, target := range targets {
+		targetRuns := runs[target]
+		trend := output.HistoryTarget{
+			Target:   target,
+			Runs:     len(targetRuns),
+			FirstRun: targetRuns[0].started,
+			LastRun:  targetRuns[len(targetRuns)-1].started,
+		}
+
+		var checks []string
+		samples := make(map[string][]sample)
+		for _, r := range targetRuns {
+			results, err := s.db.Query(`SELECT check_name, status, duration_ms FROM results WHERE run_id = ?`, r.id)
+			if err != nil {
+				return nil, err
+			}
+			for results.Next() {
+				var name, status string
+				var durationMs float64
+				if err := results.Scan(&name, &status, &durationMs); err != nil {
+					results.Close()
+					return nil, err
+				}
+				if _, ok := samples[name]; !ok {
+					checks = append(checks, name)
+				}
+				samples[name] = append(samples[name], sample{at: r.started, status: output.Status(status), durationMs: durationMs})
+			}
+			results.Close()
+
+			var cert output.CertificateChange
+			var notAfter string
+			err = s.db.QueryRow(`SELECT serial, subject, issuer, not_after, tls_version FROM certificates WHERE run_id = ?`, r.id).
+				Scan(&cert.Serial, &cert.Subject, &cert.Issuer, &notAfter, &cert.TLSVersion)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			n := len(trend.Certificates)
+			if n > 0 && trend.Certificates[n-1].Serial == cert.Serial {
+				continue
+			}
+			cert.FirstSeen = r.started
+			cert.NotAfter, _ = time.Parse(time.RFC3339, notAfter)
+			trend.Certificates = append(trend.Certificates, cert)
+		}
+
+		for _, name := range checks {
+			trend.Checks = append(trend.Checks, checkTrend(name, samples[name]))
+		}
+		report.Targets = append(report.Targets, trend)
+	}
+
+	return report, nil
+}
+
+// sample is the outcome of a check in one run
+type sample struct {
+	at         time.Time
+	status     output.Status
+	durationMs float64
+}
+
+// checkTrend derives the status changes and the daily median duration of a
+// check from its samples, oldest first. Skipped runs do not change the
+// status and failed ones do not count toward the latency.
+func checkTrend(name string, samples []sample) output.CheckTrend {
+	trend := output.CheckTrend{Name: name, Runs: len(samples)}
+
+	var days []string
+	durations := make(map[string][]float64)
+	for _, s := range samples {
+		if s.status != output.StatusSkip {
+			if trend.Status != "" && s.status != trend.Status {
+				trend.Transitions = append(trend.Transitions, output.StatusTransition{At: s.at, From: trend.Status, To: s.status})
+			}
+			if s.status != trend.Status {
+				trend.Status = s.status
+				trend.StatusSince = s.at
+			}
+			if s.status == output.StatusPass {
+				at := s.at
+				trend.LastPass = &at
+			}
+		}
+		if s.status == output.StatusFail || s.status == output.StatusSkip {
+			continue
+		}
+		day := s.at.Local().Format("2006-01-02")
+		if _, ok := durations[day]; !ok {
+			days = append(days, day)
+		}
+		durations[day] = append(durations[day], s.durationMs)
+	}
+
+	for _, day := range days {
+		values := durations[day]
+		sort.Float64s(values)
+		trend.Daily = append(trend.Daily, output.DailyLatency{
+			Date:     day,
+			MedianMs: values[len(values)/2],
+			Runs:     len(values),
+		})
+	}
+	return trend
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
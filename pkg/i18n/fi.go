@@ -0,0 +1,124 @@
+package i18n
+
+// finnish is the built-in Finnish catalog. It covers the console layout,
+// the check names and the most common remediation causes; anything missing
+// is printed in English.
+var finnish = Catalog{
+	// Console layout
+	"S3 Bucket Tester":         "S3-ämpärin testaus",
+	"Configuration:":           "Asetukset:",
+	"Endpoint":                 "Päätepiste",
+	"Bucket":                   "Ämpäri",
+	"Region":                   "Alue",
+	"Auth Type":                "Tunnistustapa",
+	"Port":                     "Portti",
+	"Timeout":                  "Aikakatkaisu",
+	"Addressing Style":         "Osoitetyyli",
+	"Path-style":               "Polkutyyli",
+	"Virtual-hosted (default)": "Virtuaalipalvelin (oletus)",
+	"Test Prefix":              "Testietuliite",
+	"Credentials":              "Tunnukset",
+	"Assumed Role":             "Otettu rooli",
+	"Credentials Expire":       "Tunnukset vanhenevat",
+	"(expired)":                "(vanhentunut)",
+	"%s (in %v)":               "%s (%v kuluttua)",
+	"TLS Verify":               "TLS-tarkistus",
+	"Disabled":                 "Pois käytöstä",
+	"CA Certificates":          "CA-varmenteet",
+	"Client Certificate":       "Asiakasvarmenne",
+	"TLS Server Name":          "TLS-palvelinnimi",
+	"Mode":                     "Tila",
+	"Read-only":                "Vain luku",
+	"Address Family":           "Osoiteperhe",
+	"IPv4 only":                "Vain IPv4",
+	"IPv6 only":                "Vain IPv6",
+	"Runner":                   "Ajoympäristö",
+	"Warnings:":                "Varoitukset:",
+	"Warm-up:":                 "Lämmittely:",
+	"Cold":                     "Kylmä",
+	"Slow operation":           "Hidas pyyntö",
+	"Attempts":                 "Yrityksiä",
+	"#%d failed:":              "#%d epäonnistui:",
+	"Slowest Operations":       "Hitaimmat pyynnöt",
+	"Threshold: %dms":          "Raja: %dms",
+	"Warm":                     "Lämmin",
+	"Running Tests...":         "Ajetaan testejä...",
+	"PASS":                     "OK",
+	"FAIL":                     "VIRHE",
+	"WARN":                     "VAROITUS",
+	"SKIP":                     "OHITETTU",
+	"Error":                    "Virhe",
+	"Reason":                   "Syy",
+	"Test Summary":             "Yhteenveto",
+	"  Total: %s | Passed: %s | Failed: %s | Warnings: %s": "  Yhteensä: %s | Onnistui: %s | Epäonnistui: %s | Varoituksia: %s",
+	"All tests passed successfully!":                       "Kaikki testit onnistuivat!",
+	"Tests completed with warnings.":                       "Testit valmistuivat varoituksin.",
+	"Some tests failed. Please review the errors above.":   "Osa testeistä epäonnistui. Katso virheet yllä.",
+	"Read-only run: no write requests were sent":           "Vain luku -ajo: kirjoituspyyntöjä ei lähetetty",
+	"Classification":                                                              "Luokitus",
+	"Endpoint maintenance, not an S3 error":                                       "Päätepisteen huoltokatko, ei S3-virhe",
+	"Non-S3 response, likely a proxy or load balancer":                            "Vastaus ei ole S3:n, todennäköisesti välityspalvelin tai kuormantasaaja",
+	"%d failures were caused by endpoint maintenance; run again once it is over.": "%d virhettä johtui päätepisteen huoltokatkosta; aja uudelleen katkon jälkeen.",
+	"%d checks were skipped because a check they depend on failed.":               "%d tarkistusta ohitettiin, koska tarkistus, josta ne riippuvat, epäonnistui.",
+	"Throttled: rate limit or quota exhausted":                                    "Rajoitettu: pyyntöraja tai kiintiö on käytetty",
+	"Rate limit": "Pyyntöraja",
+	"%d failures were caused by throttling or an exhausted quota; run again once the limit resets.": "%d virhettä johtui pyyntörajoituksesta tai loppuneesta kiintiöstä; aja uudelleen, kun raja nollautuu.",
+
+	// Check names
+	"DNS Resolution Check":                          "DNS-nimenselvitys",
+	"TCP Connectivity Check":                        "TCP-yhteys",
+	"SSL/TLS Certificate Check":                     "SSL/TLS-varmenne",
+	"Bucket Authentication Check":                   "Ämpärin tunnistautuminen",
+	"Object Read/Write Check":                       "Objektin luku ja kirjoitus",
+	"Permission Matrix Check":                       "Käyttöoikeusmatriisi",
+	"Object Access Check":                           "Objektin käyttöoikeudet",
+	"Versioned Delete Check":                        "Versioitu poisto",
+	"Public Access Block Check":                     "Julkisen käytön esto",
+	"Anonymous Access Check":                        "Anonyymi pääsy",
+	"Test Artifact Inventory":                       "Testiobjektien inventaario",
+	"Capability Requirements Check":                 "Ominaisuusvaatimukset",
+	"Parallel Ranged GET Check":                     "Rinnakkaiset osalataukset",
+	"Credential Expiry Check":                       "Tunnusten voimassaolo",
+	"Remediation Suggestions":                       "Korjausehdotukset",
+	"Cause":                                         "Syy",
+	"Suggestion":                                    "Ehdotus",
+	"Commands to try:":                              "Kokeiltavat komennot:",
+	"Unknown error":                                 "Tuntematon virhe",
+	"Please check the error details and try again.": "Tarkista virheen tiedot ja yritä uudelleen.",
+
+	// Common remediation causes and suggestions
+	"The hostname does not exist or DNS resolution failed":                   "Palvelinnimeä ei ole tai DNS-nimenselvitys epäonnistui",
+	"Verify the hostname is correct and DNS servers are properly configured": "Tarkista palvelinnimi ja DNS-palvelimien asetukset",
+	"DNS query timed out": "DNS-kysely aikakatkaistiin",
+	"Check your network connection and DNS server settings":           "Tarkista verkkoyhteys ja DNS-palvelimen asetukset",
+	"The target port is closed or no service is listening":            "Kohdeportti on suljettu tai mikään palvelu ei kuuntele sitä",
+	"Verify the service is running and the correct port is specified": "Tarkista, että palvelu on käynnissä ja portti on oikea",
+	"Connection timed out": "Yhteys aikakatkaistiin",
+	"Check firewall rules, network connectivity, and endpoint availability":                                                                 "Tarkista palomuurisäännöt, verkkoyhteys ja päätepisteen saatavuus",
+	"The SSL/TLS certificate has expired":                                                                                                   "SSL/TLS-varmenne on vanhentunut",
+	"Renew the certificate on the server":                                                                                                   "Uusi palvelimen varmenne",
+	"The certificate is signed by an unknown or untrusted CA":                                                                               "Varmenteen on allekirjoittanut tuntematon tai ei-luotettu CA",
+	"The server sends its certificate without the intermediate certificates that link it to a trusted root":                                 "Palvelin lähettää varmenteensa ilman välivarmenteita, jotka liittävät sen luotettuun juureen",
+	"The bucket name contains dots, so its virtual-hosted host is not covered by the endpoint's wildcard certificate":                       "Säiliön nimessä on pisteitä, joten päätepisteen jokerimerkkivarmenne ei kata sen virtual-hosted-palvelinnimeä",
+	"Use path-style addressing for buckets with dots in their name":                                                                         "Käytä path-style-osoitusta säiliöille, joiden nimessä on pisteitä",
+	"The certificate does not cover the virtual-hosted bucket host":                                                                         "Varmenne ei kata säiliön virtual-hosted-palvelinnimeä",
+	"The SSL/TLS certificate expires within the configured threshold":                                                                       "SSL/TLS-varmenne vanhenee määritetyn rajan sisällä",
+	"Renew the certificate and install it on the endpoint before it expires":                                                                "Uusi varmenne ja asenna se päätepisteeseen ennen kuin se vanhenee",
+	"Certificate name does not match the hostname":                                                                                          "Varmenteen nimi ei vastaa palvelinnimeä",
+	"The access key ID is invalid or does not exist":                                                                                        "Access key ID on virheellinen tai sitä ei ole",
+	"Verify the access key ID is correct and the user exists in the S3 provider":                                                            "Tarkista access key ID ja että käyttäjä on olemassa S3-palvelussa",
+	"Signature calculation failed - credentials or region mismatch":                                                                         "Allekirjoitus ei täsmää - tunnukset tai alue ovat väärin",
+	"Check secret key, region, and endpoint configuration":                                                                                  "Tarkista salainen avain, alue ja päätepiste",
+	"Access denied - insufficient permissions":                                                                                              "Pääsy estetty - riittämättömät käyttöoikeudet",
+	"Grant required IAM permissions to the user/role for this bucket":                                                                       "Anna käyttäjälle tai roolille tarvittavat IAM-oikeudet tähän ämpäriin",
+	"The specified bucket does not exist":                                                                                                   "Ämpäriä ei ole olemassa",
+	"Verify the bucket name and region are correct":                                                                                         "Tarkista ämpärin nimi ja alue",
+	"The bucket is in another region than the one the request was signed for":                                                               "Ämpäri on eri alueella kuin se, jolle pyyntö allekirjoitettiin",
+	"Set --region to the bucket's region, and on AWS use the endpoint of that region":                                                       "Aseta --region ämpärin alueeksi ja käytä AWS:ssä sen alueen päätepistettä",
+	"The local clock differs from the server's by more than the server allows; the error gives the measured offset":                         "Paikallinen kello poikkeaa palvelimen kellosta enemmän kuin palvelin sallii; virheilmoitus kertoo mitatun eron",
+	"Synchronize system time with NTP server":                                                                                               "Synkronoi järjestelmän kello NTP-palvelimen kanssa",
+	"The endpoint is in maintenance and served a maintenance page instead of an S3 response":                                                "Päätepisteellä on huoltokatko ja se palautti huoltosivun S3-vastauksen sijaan",
+	"Wait for the maintenance window to end and run again; credentials and bucket settings are not the cause":                               "Odota huoltokatkon päättymistä ja aja uudelleen; tunnukset ja ämpärin asetukset eivät ole syy",
+	"The endpoint throttled the request: the rate limit or quota of the account or client was exhausted":                                    "Päätepiste rajoitti pyyntöä: tilin tai asiakkaan pyyntöraja tai kiintiö on käytetty",
+	"Wait until the limit resets, reduce the request rate of other clients sharing the credentials, or ask the provider to raise the quota": "Odota rajan nollautumista, vähennä samoja tunnuksia käyttävien asiakkaiden pyyntöjä tai pyydä palveluntarjoajaa nostamaan kiintiötä",
+}
@@ -0,0 +1,121 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Catalog maps English source text to its translation. The English text is
+// the key, so untranslated messages fall back to the original and a catalog
+// can be written by copying strings from the console output.
+type Catalog map[string]string
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[string]Catalog{
+		"en": {},
+		"fi": finnish,
+	}
+	active Catalog
+)
+
+// Register adds or extends the catalog of a language, for example from a
+// build that ships additional translations
+func Register(lang string, catalog Catalog) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	lang = normalize(lang)
+	merged := Catalog{}
+	for k, v := range catalogs[lang] {
+		merged[k] = v
+	}
+	for k, v := range catalog {
+		merged[k] = v
+	}
+	catalogs[lang] = merged
+}
+
+// Languages returns the languages with a built-in or registered catalog
+func Languages() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// SetLanguage selects the catalog used by T. Locale names such as fi_FI.UTF-8
+// select the fi catalog.
+func SetLanguage(lang string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	catalog, ok := catalogs[normalize(lang)]
+	if !ok {
+		return fmt.Errorf("unsupported language %q", lang)
+	}
+	active = catalog
+	return nil
+}
+
+// LoadFile reads a JSON object of "English text": "translation" pairs and
+// layers it over the selected language, so a file can add a new language or
+// override individual built-in translations
+func LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read message catalog: %w", err)
+	}
+
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return fmt.Errorf("failed to parse message catalog %s: %w", path, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	merged := Catalog{}
+	for k, v := range active {
+		merged[k] = v
+	}
+	for k, v := range catalog {
+		merged[k] = v
+	}
+	active = merged
+	return nil
+}
+
+// T returns the translation of msg, or msg itself when it has none
+func T(msg string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if translated, ok := active[msg]; ok && translated != "" {
+		return translated
+	}
+	return msg
+}
+
+// Tf translates format and then formats it with args
+func Tf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// normalize reduces a locale such as fi_FI.UTF-8 to its language code
+func normalize(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
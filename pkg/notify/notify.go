@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Severity represents the urgency of a notification
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Notification is a message delivered to notification backends
+type Notification struct {
+	Event     string      `json:"event"`
+	Severity  Severity    `json:"severity"`
+	Title     string      `json:"title"`
+	Message   string      `json:"message"`
+	Timestamp time.Time   `json:"timestamp"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// Notifier defines the interface for notification backends
+type Notifier interface {
+	// Notify delivers the notification
+	Notify(n Notification) error
+}
+
+// WebhookNotifier posts notifications as JSON to an HTTP endpoint
+type WebhookNotifier struct {
+	URL    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a new webhook notifier
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Notify posts the notification to the webhook URL
+func (w *WebhookNotifier) Notify(n Notification) error {
+	if n.Timestamp.IsZero() {
+		n.Timestamp = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.client.Post(w.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Multi delivers a notification to several notifiers, returning the first error
+type Multi []Notifier
+
+// Notify delivers the notification to every notifier
+func (m Multi) Notify(n Notification) error {
+	var firstErr error
+	for _, notifier := range m {
+		if err := notifier.Notify(n); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,21 @@
+// Package options holds the parsing and validation of command-line options
+// that both the configuration and the checks depend on, so the checks do
+// not have to import the configuration.
+package options
+
+import "time"
+
+// MinBudgetShare is the least time worth starting a check with under
+// --budget; a check left with less is skipped instead, and a shorter
+// --budget is rejected
+const MinBudgetShare = time.Second
+
+// ReservedPrefix starts the default key prefix of every run, so the
+// artifacts of all runs can be found with one listing
+const ReservedPrefix = "s3tester"
+
+// DefaultTestPrefix returns the key prefix used for a run when --test-prefix
+// is not given
+func DefaultTestPrefix(runID string) string {
+	return ReservedPrefix + "-" + runID + "/"
+}
@@ -0,0 +1,59 @@
+package options
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capability names accepted by --require
+var capabilityNames = []string{"versioning", "encryption", "object-lock", "policy", "acl"}
+
+// Requirement is a capability the endpoint must provide, optionally at a
+// specific level (e.g. policy=full)
+type Requirement struct {
+	Capability string
+	Value      string
+}
+
+// String returns the requirement as written on the command line
+func (r Requirement) String() string {
+	if r.Value == "" {
+		return r.Capability
+	}
+	return r.Capability + "=" + r.Value
+}
+
+// ParseRequirements parses comma-separated capability expressions such as
+// "versioning,encryption,policy=full"
+func ParseRequirements(specs []string) ([]Requirement, error) {
+	var requirements []Requirement
+	for _, spec := range specs {
+		for _, expr := range strings.Split(spec, ",") {
+			expr = strings.TrimSpace(expr)
+			if expr == "" {
+				continue
+			}
+
+			name, value, _ := strings.Cut(expr, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			value = strings.ToLower(strings.TrimSpace(value))
+
+			known := false
+			for _, capability := range capabilityNames {
+				if name == capability {
+					known = true
+					break
+				}
+			}
+			if !known {
+				return nil, fmt.Errorf("unknown capability %q (supported: %s)", name, strings.Join(capabilityNames, ", "))
+			}
+			if strings.Contains(expr, "=") && value == "" {
+				return nil, fmt.Errorf("capability %q requires a value after '='", name)
+			}
+
+			requirements = append(requirements, Requirement{Capability: name, Value: value})
+		}
+	}
+	return requirements, nil
+}
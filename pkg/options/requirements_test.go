@@ -0,0 +1,64 @@
+package options
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRequirements(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		want    []Requirement
+		wantErr bool
+	}{
+		{"none", nil, nil, false},
+		{"single", []string{"versioning"}, []Requirement{{Capability: "versioning"}}, false},
+		{
+			name:  "comma-separated with a level",
+			specs: []string{"versioning,encryption,policy=full"},
+			want:  []Requirement{{Capability: "versioning"}, {Capability: "encryption"}, {Capability: "policy", Value: "full"}},
+		},
+		{
+			name:  "repeated flag",
+			specs: []string{"acl", "object-lock"},
+			want:  []Requirement{{Capability: "acl"}, {Capability: "object-lock"}},
+		},
+		{
+			name:  "case and spaces ignored",
+			specs: []string{" Versioning , POLICY = Full "},
+			want:  []Requirement{{Capability: "versioning"}, {Capability: "policy", Value: "full"}},
+		},
+		{"empty expressions skipped", []string{",acl,,"}, []Requirement{{Capability: "acl"}}, false},
+		{"unknown capability", []string{"versioning,replication"}, nil, true},
+		{"missing level", []string{"policy="}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRequirements(tt.specs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRequirements(%q) error = %v, wantErr %v", tt.specs, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRequirements(%q) = %v, want %v", tt.specs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirementString(t *testing.T) {
+	tests := []struct {
+		requirement Requirement
+		want        string
+	}{
+		{Requirement{Capability: "versioning"}, "versioning"},
+		{Requirement{Capability: "policy", Value: "full"}, "policy=full"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.requirement.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.requirement, got, tt.want)
+		}
+	}
+}
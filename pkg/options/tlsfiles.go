@@ -0,0 +1,92 @@
+package options
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// caPools caches the pool built for each --ca-cert path
+var caPools sync.Map
+
+// LoadCAPool builds a certificate pool from the system roots plus the PEM
+// certificates in path, which is a file or a directory of files. Files in a
+// directory that contain no certificates are skipped; the pool must end up
+// with at least one certificate from path.
+func LoadCAPool(path string) (*x509.CertPool, error) {
+	if pool, ok := caPools.Load(path); ok {
+		return pool.(*x509.CertPool), nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA directory: %w", err)
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	added := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate %s: %w", file, err)
+		}
+		if pool.AppendCertsFromPEM(data) {
+			added++
+		} else if !info.IsDir() {
+			return nil, fmt.Errorf("no PEM certificates found in %s", file)
+		}
+	}
+	if added == 0 {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+
+	caPools.Store(path, pool)
+	return pool, nil
+}
+
+// clientCerts caches the key pair loaded for each --client-cert/--client-key
+var clientCerts sync.Map
+
+// LoadClientCertificate loads a PEM client certificate and private key for
+// mutual TLS. The key may be in the certificate file, in which case keyFile
+// is the same path.
+func LoadClientCertificate(certFile, keyFile string) (*tls.Certificate, error) {
+	cacheKey := certFile + "\x00" + keyFile
+	if cert, ok := clientCerts.Load(cacheKey); ok {
+		return cert.(*tls.Certificate), nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	if cert.Leaf == nil {
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+			cert.Leaf = leaf
+		}
+	}
+
+	clientCerts.Store(cacheKey, &cert)
+	return &cert, nil
+}
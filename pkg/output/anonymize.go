@@ -0,0 +1,188 @@
+package output
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// ipCandidate matches runs of characters an IPv4 or IPv6 address is made
+	// of; each match is confirmed with net.ParseIP
+	ipCandidate = regexp.MustCompile(`[0-9A-Fa-f:.]*[.:][0-9A-Fa-f:.]*`)
+
+	// accountID matches 12-digit AWS account IDs, standalone or in ARNs
+	accountID = regexp.MustCompile(`\b\d{12}\b`)
+)
+
+// anonymizer replaces infrastructure identifiers with tokens derived from a
+// keyed hash, so the same identifier gets the same token throughout a report
+// while the tokens cannot be reversed by guessing names
+type anonymizer struct {
+	key []byte
+
+	// names maps known bucket names, hostnames and keys to their tokens;
+	// order lists them longest first, so a bucket host is replaced before
+	// the endpoint host it contains
+	names map[string]string
+	order []string
+}
+
+// Anonymize returns a copy of the report with bucket names, hostnames, IP
+// addresses, AWS account IDs and the access key replaced by tokens such as
+// bucket-1f3a9c2e, consistently across the whole report. The tokens are
+// keyed with a random per-run secret.
+func Anonymize(report *TestReport) *TestReport {
+	a := &anonymizer{key: make([]byte, 32), names: make(map[string]string)}
+	rand.Read(a.key)
+
+	a.addName("bucket", report.Config.Bucket)
+	a.addName("key", report.Config.AccessKey)
+	if u, err := url.Parse(report.Config.Endpoint); err == nil {
+		a.addName("host", u.Hostname())
+	}
+	if report.Metadata != nil {
+		a.addName("host", report.Metadata.Hostname)
+	}
+	for _, result := range report.Results {
+		if details, ok := result.Details.(DNSResult); ok {
+			a.addName("host", details.Hostname)
+			a.addName("host", strings.TrimSuffix(details.ReverseDNS, "."))
+		}
+	}
+	sort.Slice(a.order, func(i, j int) bool { return len(a.order[i]) > len(a.order[j]) })
+
+	anonymized := &TestReport{}
+	a.copy(report, anonymized)
+	anonymized.Config.Proxy = ""
+	anonymized.Config.IncludeCredentials = false
+	for i, result := range report.Results {
+		if result.Details != nil {
+			details := reflect.New(reflect.TypeOf(result.Details))
+			a.copy(result.Details, details.Interface())
+			anonymized.Results[i].Details = details.Elem().Interface()
+		}
+	}
+	return anonymized
+}
+
+// addName registers an identifier to replace
+func (a *anonymizer) addName(kind, name string) {
+	if name == "" || net.ParseIP(name) != nil {
+		return
+	}
+	if _, ok := a.names[name]; ok {
+		return
+	}
+	a.names[name] = a.token(kind, name)
+	a.order = append(a.order, name)
+}
+
+// token returns the replacement of an identifier
+func (a *anonymizer) token(kind, value string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(value))
+	return kind + "-" + hex.EncodeToString(mac.Sum(nil))[:8]
+}
+
+// copy stores the anonymized src in dst through their JSON encoding. Fields
+// not encoded in JSON are left empty.
+func (a *anonymizer) copy(src, dst interface{}) {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return
+	}
+	if data, err = json.Marshal(a.walk(tree)); err == nil {
+		json.Unmarshal(data, dst)
+	}
+}
+
+// walk anonymizes every string of a decoded JSON value
+func (a *anonymizer) walk(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return a.replace(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = a.walk(v[i])
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = a.walk(v[k])
+		}
+	}
+	return v
+}
+
+// replace anonymizes the IP addresses, account IDs and known names in s
+func (a *anonymizer) replace(s string) string {
+	s = ipCandidate.ReplaceAllStringFunc(s, func(candidate string) string {
+		return a.replaceIP(candidate)
+	})
+	s = accountID.ReplaceAllStringFunc(s, func(id string) string {
+		return a.token("account", id)
+	})
+	for _, name := range a.order {
+		s = replaceName(s, name, a.names[name])
+	}
+	return s
+}
+
+// replaceIP anonymizes an IP address, a CIDR or an address with a port,
+// keeping the prefix length and port; anything else is returned unchanged
+func (a *anonymizer) replaceIP(candidate string) string {
+	trimmed := strings.Trim(candidate, ".:")
+	host, suffix := trimmed, ""
+	if i := strings.LastIndex(trimmed, ":"); i > 0 && net.ParseIP(trimmed) == nil {
+		if net.ParseIP(trimmed[:i]) != nil {
+			host, suffix = trimmed[:i], trimmed[i:]
+		}
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return candidate
+	}
+	kind := "ipv4"
+	if ip.To4() == nil {
+		kind = "ipv6"
+	}
+	return strings.Replace(candidate, trimmed, a.token(kind, ip.String())+suffix, 1)
+}
+
+// replaceName replaces the occurrences of name in s that are not part of a
+// longer word, so a bucket named "logs" leaves "catalogs" alone
+func replaceName(s, name, token string) string {
+	var sb strings.Builder
+	for {
+		i := strings.Index(s, name)
+		if i < 0 {
+			sb.WriteString(s)
+			return sb.String()
+		}
+		end := i + len(name)
+		if (i == 0 || !isNameChar(s[i-1])) && (end == len(s) || !isNameChar(s[end])) {
+			sb.WriteString(s[:i])
+			sb.WriteString(token)
+		} else {
+			sb.WriteString(s[:end])
+		}
+		s = s[end:]
+	}
+}
+
+// isNameChar reports whether c can be part of a bucket name or host label
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
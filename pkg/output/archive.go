@@ -0,0 +1,105 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveTimeFormat is the sortable timestamp used in archived report names
+const archiveTimeFormat = "20060102T150405.000Z"
+
+// unsafeNameChars matches characters not allowed in archive file names
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// RetentionPolicy controls which archived reports are kept
+type RetentionPolicy struct {
+	// Keep is the number of most recent reports kept per bucket (0 = unlimited)
+	Keep int
+
+	// MaxAge removes reports older than this (0 = no age limit)
+	MaxAge time.Duration
+}
+
+// ArchiveReport writes the report as JSON with a timestamped file name into
+// dir and prunes older reports of the same bucket according to the policy.
+// It returns the path of the new report and the removed files.
+func ArchiveReport(report *TestReport, dir string, policy RetentionPolicy) (string, []string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", nil, fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	prefix := archivePrefix(report.Config.Bucket)
+	name := prefix + report.StartTime.UTC().Format(archiveTimeFormat) + ".json"
+	path := filepath.Join(dir, name)
+
+	if err := PrintJSON(report, path); err != nil {
+		return "", nil, fmt.Errorf("failed to write report: %w", err)
+	}
+
+	pruned, err := pruneReports(dir, prefix, policy, time.Now())
+	if err != nil {
+		return path, pruned, fmt.Errorf("failed to prune old reports: %w", err)
+	}
+
+	return path, pruned, nil
+}
+
+// archivePrefix returns the file name prefix shared by all reports of a bucket
+func archivePrefix(bucket string) string {
+	return "s3tester-" + unsafeNameChars.ReplaceAllString(bucket, "_") + "-"
+}
+
+// pruneReports removes archived reports beyond the retention policy. Only
+// files written by ArchiveReport for the same bucket are considered.
+func pruneReports(dir, prefix string, policy RetentionPolicy, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type archived struct {
+		name    string
+		created time.Time
+	}
+
+	var reports []archived
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".json")
+		created, err := time.Parse(archiveTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		reports = append(reports, archived{name: name, created: created})
+	}
+
+	// Newest first
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].created.After(reports[j].created)
+	})
+
+	var pruned []string
+	for i, report := range reports {
+		tooMany := policy.Keep > 0 && i >= policy.Keep
+		tooOld := policy.MaxAge > 0 && now.Sub(report.created) > policy.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+
+		path := filepath.Join(dir, report.name)
+		if err := os.Remove(path); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, path)
+	}
+
+	return pruned, nil
+}
@@ -0,0 +1,362 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// The duration of a check that did not fail changes by more than both of
+// these before the change is reported; smaller differences are network noise
+const (
+	baselineLatencyRatio = 0.5
+	baselineLatencyMinMs = 100
+)
+
+// Kinds of BaselineChange
+const (
+	BaselineStatus      = "status"
+	BaselineLatency     = "latency"
+	BaselineTLS         = "tls"
+	BaselineCertificate = "certificate"
+)
+
+// Directions of BaselineResult.Change
+const (
+	BaselineRegression  = "regression"
+	BaselineImprovement = "improvement"
+)
+
+// Baseline is an earlier report loaded with --baseline
+type Baseline struct {
+	File      string
+	StartTime time.Time
+	Results   []BaselineCheck
+}
+
+// BaselineCheck is a result of the baseline report; TLS holds the details
+// of the TLS check
+type BaselineCheck struct {
+	TestName string
+	Status   Status
+	Duration time.Duration
+	TLS      *TLSResult
+}
+
+// BaselineComparison lists what changed between the baseline run and this
+// one. Regressions are the changes for the worse that --fail-on-regression
+// fails on; Changes are neither better nor worse, such as a replaced
+// certificate.
+type BaselineComparison struct {
+	File         string           `json:"file"`
+	StartTime    time.Time        `json:"startTime"`
+	Regressions  []BaselineChange `json:"regressions"`
+	Improvements []BaselineChange `json:"improvements"`
+	Changes      []BaselineChange `json:"changes,omitempty"`
+}
+
+// BaselineChange is one difference between the baseline run and this one
+type BaselineChange struct {
+	Check   string `json:"check"`
+	Kind    string `json:"kind"`
+	Before  string `json:"before"`
+	After   string `json:"after"`
+	Message string `json:"message"`
+}
+
+// BaselineResult annotates a result with the same check of the baseline run
+type BaselineResult struct {
+	Status     Status  `json:"status"`
+	DurationMs float64 `json:"durationMs"`
+	DeltaMs    float64 `json:"deltaMs"`
+	Change     string  `json:"change,omitempty"`
+}
+
+// LoadBaseline reads a JSON report written by an earlier run with
+// --output-file or --report-dir
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var report struct {
+		StartTime time.Time `json:"startTime"`
+		Results   []struct {
+			TestName string          `json:"testName"`
+			Status   Status          `json:"status"`
+			Duration json.RawMessage `json:"duration"`
+			Details  json.RawMessage `json:"details"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if len(report.Results) == 0 {
+		return nil, fmt.Errorf("baseline %s has no results: pass a JSON report of an earlier run", path)
+	}
+
+	baseline := &Baseline{File: path, StartTime: report.StartTime}
+	for _, result := range report.Results {
+		check := BaselineCheck{TestName: result.TestName, Status: result.Status}
+		check.Duration, err = parseReportDuration(result.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse baseline %s: %s: %w", path, result.TestName, err)
+		}
+		if result.TestName == "SSL/TLS Certificate Check" && len(result.Details) > 0 {
+			var details TLSResult
+			if json.Unmarshal(result.Details, &details) == nil && details.TLSVersion != "" {
+				check.TLS = &details
+			}
+		}
+		baseline.Results = append(baseline.Results, check)
+	}
+	return baseline, nil
+}
+
+// parseReportDuration reads a duration as written to JSON reports: in
+// nanoseconds, or as a string such as "1.5s" in reports with remediation
+func parseReportDuration(raw json.RawMessage) (time.Duration, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+	if raw[0] == '"' {
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return 0, err
+		}
+		return time.ParseDuration(text)
+	}
+	n, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %s", raw)
+	}
+	return time.Duration(n), nil
+}
+
+// CompareBaseline compares the results of the report with the baseline and
+// annotates each result that ran in both with its baseline status and
+// duration
+func CompareBaseline(baseline *Baseline, report *TestReport) *BaselineComparison {
+	comparison := &BaselineComparison{
+		File:         baseline.File,
+		StartTime:    baseline.StartTime,
+		Regressions:  []BaselineChange{},
+		Improvements: []BaselineChange{},
+	}
+
+	previous := make(map[string]BaselineCheck)
+	for _, check := range baseline.Results {
+		previous[check.TestName] = check
+	}
+
+	for i := range report.Results {
+		result := &report.Results[i]
+		before, ok := previous[result.TestName]
+		if !ok {
+			if result.Status == StatusFail {
+				comparison.add(BaselineRegression, BaselineChange{
+					Check:   result.TestName,
+					Kind:    BaselineStatus,
+					Before:  "-",
+					After:   string(result.Status),
+					Message: "fails and was not part of the baseline run",
+				})
+			}
+			continue
+		}
+
+		beforeMs := float64(before.Duration.Microseconds()) / 1000
+		afterMs := float64(result.Duration.Microseconds()) / 1000
+		annotation := &BaselineResult{
+			Status:     before.Status,
+			DurationMs: beforeMs,
+			DeltaMs:    afterMs - beforeMs,
+		}
+		result.Baseline = annotation
+
+		rankBefore, rankAfter := statusRank(before.Status), statusRank(result.Status)
+		if rankBefore < 0 || rankAfter < 0 {
+			continue
+		}
+		if rankBefore != rankAfter {
+			annotation.Change = BaselineRegression
+			if rankAfter < rankBefore {
+				annotation.Change = BaselineImprovement
+			}
+			comparison.add(annotation.Change, BaselineChange{
+				Check:   result.TestName,
+				Kind:    BaselineStatus,
+				Before:  string(before.Status),
+				After:   string(result.Status),
+				Message: fmt.Sprintf("was %s, now %s", before.Status, result.Status),
+			})
+		} else if delta := afterMs - beforeMs; result.Status != StatusFail && abs(delta) > max(beforeMs*baselineLatencyRatio, baselineLatencyMinMs) {
+			annotation.Change = BaselineRegression
+			direction := "slower"
+			if delta < 0 {
+				annotation.Change = BaselineImprovement
+				direction = "faster"
+			}
+			comparison.add(annotation.Change, BaselineChange{
+				Check:  result.TestName,
+				Kind:   BaselineLatency,
+				Before: fmt.Sprintf("%.0fms", beforeMs),
+				After:  fmt.Sprintf("%.0fms", afterMs),
+				Message: fmt.Sprintf("took %.0fms, %.0fms %s than the baseline's %.0fms",
+					afterMs, abs(delta), direction, beforeMs),
+			})
+		}
+
+		if details, ok := result.Details.(TLSResult); ok && before.TLS != nil {
+			comparison.compareTLS(result.TestName, *before.TLS, details)
+		}
+	}
+
+	return comparison
+}
+
+// compareTLS reports a changed protocol version, chain verification, expiry
+// class or certificate
+func (c *BaselineComparison) compareTLS(check string, before, after TLSResult) {
+	if before.TLSVersion != after.TLSVersion {
+		// "TLS 1.2" < "TLS 1.3" holds for the names as strings
+		direction := BaselineImprovement
+		if after.TLSVersion < before.TLSVersion {
+			direction = BaselineRegression
+		}
+		c.add(direction, BaselineChange{
+			Check:   check,
+			Kind:    BaselineTLS,
+			Before:  before.TLSVersion,
+			After:   after.TLSVersion,
+			Message: fmt.Sprintf("negotiates %s, was %s", after.TLSVersion, before.TLSVersion),
+		})
+	}
+	if before.Verified != after.Verified {
+		direction, message := BaselineImprovement, "certificate chain verifies, it did not in the baseline"
+		if !after.Verified {
+			direction, message = BaselineRegression, "certificate chain no longer verifies"
+		}
+		c.add(direction, BaselineChange{
+			Check:   check,
+			Kind:    BaselineCertificate,
+			Before:  yesNo(before.Verified),
+			After:   yesNo(after.Verified),
+			Message: message,
+		})
+	}
+	if rankBefore, rankAfter := expiryRank(before.Expiry), expiryRank(after.Expiry); rankBefore >= 0 && rankAfter >= 0 && rankBefore != rankAfter {
+		direction := BaselineImprovement
+		if rankAfter > rankBefore {
+			direction = BaselineRegression
+		}
+		c.add(direction, BaselineChange{
+			Check:   check,
+			Kind:    BaselineCertificate,
+			Before:  before.Expiry,
+			After:   after.Expiry,
+			Message: fmt.Sprintf("certificate expiry is %s (%d days left), was %s", after.Expiry, after.Certificate.DaysUntilExpiry, before.Expiry),
+		})
+	}
+	if before.Certificate.SerialNumber != after.Certificate.SerialNumber {
+		message := fmt.Sprintf("certificate replaced: expires %s, was %s",
+			after.Certificate.NotAfter.Format("2006-01-02"), before.Certificate.NotAfter.Format("2006-01-02"))
+		if before.Certificate.Issuer != after.Certificate.Issuer {
+			message += fmt.Sprintf("; issued by %s, was %s", after.Certificate.Issuer, before.Certificate.Issuer)
+		}
+		c.Changes = append(c.Changes, BaselineChange{
+			Check:   check,
+			Kind:    BaselineCertificate,
+			Before:  before.Certificate.SerialNumber,
+			After:   after.Certificate.SerialNumber,
+			Message: message,
+		})
+	}
+}
+
+// add records a change as a regression or an improvement
+func (c *BaselineComparison) add(direction string, change BaselineChange) {
+	if direction == BaselineRegression {
+		c.Regressions = append(c.Regressions, change)
+	} else {
+		c.Improvements = append(c.Improvements, change)
+	}
+}
+
+// statusRank orders the statuses from best to worst; a skipped check has no
+// rank and is not compared
+func statusRank(status Status) int {
+	switch status {
+	case StatusPass:
+		return 0
+	case StatusWarn:
+		return 1
+	case StatusFail:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// WorseStatus returns the worse of two statuses, so merging findings never
+// turns a failure back into a warning
+func WorseStatus(a, b Status) Status {
+	if statusRank(b) > statusRank(a) {
+		return b
+	}
+	return a
+}
+
+// expiryRank orders the expiry classes from the longest validity
+func expiryRank(expiry string) int {
+	for i, class := range []string{ExpiryValid, ExpiryWarning, ExpiryCritical, ExpiryExpired} {
+		if expiry == class {
+			return i
+		}
+	}
+	return -1
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// printBaseline prints the regressions, improvements and other changes
+// against the --baseline report
+func printBaseline(comparison *BaselineComparison) {
+	title := fmt.Sprintf("Baseline Comparison (%s", comparison.File)
+	if !comparison.StartTime.IsZero() {
+		title += ", " + comparison.StartTime.Local().Format("2006-01-02 15:04")
+	}
+	fmt.Println(bold(title + ")"))
+
+	for _, group := range []struct {
+		title   string
+		icon    string
+		changes []BaselineChange
+	}{
+		{"Regressions", failIcon, comparison.Regressions},
+		{"Improvements", passIcon, comparison.Improvements},
+		{"Changes", infoIcon, comparison.Changes},
+	} {
+		if len(group.changes) == 0 {
+			continue
+		}
+		fmt.Printf("  %s %s:\n", group.icon, cyan(group.title))
+		for _, change := range group.changes {
+			fmt.Printf("    %s: %s\n", white(change.Check), change.Message)
+		}
+	}
+	if len(comparison.Regressions) == 0 {
+		fmt.Printf("  %s %s\n", passIcon, green("No regressions against the baseline"))
+	}
+	fmt.Println()
+}
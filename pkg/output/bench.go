@@ -0,0 +1,144 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BenchReport contains the results of a bench run
+type BenchReport struct {
+	Endpoint    string       `json:"endpoint"`
+	Bucket      string       `json:"bucket"`
+	ObjectSize  int64        `json:"objectSize"`
+	Objects     int          `json:"objects"`
+	Concurrency int          `json:"concurrency"`
+	StartTime   time.Time    `json:"startTime"`
+	DurationMs  float64      `json:"durationMs"`
+	Upload      BenchPhase   `json:"upload"`
+	Download    BenchPhase   `json:"download"`
+	Cleanup     BenchCleanup `json:"cleanup"`
+	Metadata    *RunMetadata `json:"metadata,omitempty"`
+}
+
+// BenchPhase contains the throughput and latency distribution of the
+// uploads or downloads of a bench run
+type BenchPhase struct {
+	Requests       int     `json:"requests"`
+	Errors         int     `json:"errors"`
+	Bytes          int64   `json:"bytes"`
+	DurationMs     float64 `json:"durationMs"`
+	ThroughputMBps float64 `json:"throughputMBps"`
+	RequestsPerSec float64 `json:"requestsPerSec"`
+	MinMs          float64 `json:"minMs"`
+	MeanMs         float64 `json:"meanMs"`
+	P50Ms          float64 `json:"p50Ms"`
+	P95Ms          float64 `json:"p95Ms"`
+	P99Ms          float64 `json:"p99Ms"`
+	MaxMs          float64 `json:"maxMs"`
+	FirstError     string  `json:"firstError,omitempty"`
+}
+
+// BenchCleanup records the deletion of the benchmark objects
+type BenchCleanup struct {
+	Deleted    int    `json:"deleted"`
+	Errors     int    `json:"errors"`
+	FirstError string `json:"firstError,omitempty"`
+}
+
+// Failed reports whether any benchmark request failed
+func (r *BenchReport) Failed() bool {
+	return r.Upload.Errors > 0 || r.Download.Errors > 0 || r.Cleanup.Errors > 0
+}
+
+// NewBenchPhase computes the phase statistics from the latencies of the
+// successful requests. Throughput counts only successfully transferred bytes;
+// MB/s are decimal megabytes as quoted by storage providers.
+func NewBenchPhase(latenciesMs []float64, errors int, bytes int64, elapsed time.Duration, firstError string) BenchPhase {
+	phase := BenchPhase{
+		Requests:   len(latenciesMs) + errors,
+		Errors:     errors,
+		Bytes:      bytes,
+		DurationMs: float64(elapsed.Microseconds()) / 1000,
+		FirstError: firstError,
+	}
+	if elapsed > 0 {
+		phase.ThroughputMBps = float64(bytes) / 1e6 / elapsed.Seconds()
+		phase.RequestsPerSec = float64(phase.Requests) / elapsed.Seconds()
+	}
+	if len(latenciesMs) == 0 {
+		return phase
+	}
+
+	sorted := append([]float64(nil), latenciesMs...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	phase.MinMs = sorted[0]
+	phase.MaxMs = sorted[len(sorted)-1]
+	phase.MeanMs = sum / float64(len(sorted))
+	phase.P50Ms = percentile(sorted, 50)
+	phase.P95Ms = percentile(sorted, 95)
+	phase.P99Ms = percentile(sorted, 99)
+
+	return phase
+}
+
+// PrintBench prints the bench report to console
+func PrintBench(report *BenchReport) {
+	fmt.Println(strings.Repeat("=", 50))
+	fmt.Println(bold("S3 Bucket Tester - Benchmark"))
+	fmt.Println(strings.Repeat("=", 50))
+	fmt.Println()
+	fmt.Printf("  %s: %s\n", cyan("Endpoint"), white(report.Endpoint))
+	fmt.Printf("  %s: %s\n", cyan("Bucket"), white(report.Bucket))
+	fmt.Printf("  %s: %d x %s, %d concurrent\n", cyan("Workload"), report.Objects, formatBytes(report.ObjectSize), report.Concurrency)
+	fmt.Println()
+
+	printBenchPhase("Upload (PUT)", report.Upload)
+	printBenchPhase("Download (GET)", report.Download)
+
+	if report.Cleanup.Errors > 0 {
+		fmt.Printf("%s %s: %d of %d objects not deleted: %s\n", warnIcon, yellow("Cleanup"),
+			report.Cleanup.Errors, report.Cleanup.Deleted+report.Cleanup.Errors, report.Cleanup.FirstError)
+	} else {
+		fmt.Printf("%s %s: %d objects deleted\n", passIcon, cyan("Cleanup"), report.Cleanup.Deleted)
+	}
+	fmt.Println()
+}
+
+// printBenchPhase prints the statistics of one benchmark phase
+func printBenchPhase(name string, phase BenchPhase) {
+	icon := passIcon
+	if phase.Errors > 0 {
+		icon = failIcon
+	}
+	fmt.Printf("%s %s\n", icon, bold(name))
+	fmt.Printf("  %s: %.2f MB/s, %.1f req/s (%s in %.2fs)\n", cyan("Throughput"),
+		phase.ThroughputMBps, phase.RequestsPerSec, formatBytes(phase.Bytes), phase.DurationMs/1000)
+	if phase.Errors < phase.Requests {
+		fmt.Printf("  %s: min %.2fms, mean %.2fms, p50 %.2fms, p95 %.2fms, p99 %.2fms, max %.2fms\n", cyan("Latency"),
+			phase.MinMs, phase.MeanMs, phase.P50Ms, phase.P95Ms, phase.P99Ms, phase.MaxMs)
+	}
+	if phase.Errors > 0 {
+		fmt.Printf("  %s: %d of %d requests failed: %s\n", red("Errors"), phase.Errors, phase.Requests, phase.FirstError)
+	}
+	fmt.Println()
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1024*1024*1024:
+		return fmt.Sprintf("%.1f GiB", float64(n)/(1024*1024*1024))
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KiB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
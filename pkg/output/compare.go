@@ -0,0 +1,353 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CompareReport contains the results of a compare run: the report of every
+// endpoint and the rows of their side-by-side comparison
+type CompareReport struct {
+	Bucket       string         `json:"bucket"`
+	StartTime    time.Time      `json:"startTime"`
+	Endpoints    []string       `json:"endpoints"`
+	Checks       []CompareCheck `json:"checks"`
+	Latency      []CompareRow   `json:"latency"`
+	TLS          []CompareRow   `json:"tls"`
+	Capabilities []CompareRow   `json:"capabilities"`
+	Differences  int            `json:"differences"`
+	Reports      []*TestReport  `json:"reports"`
+	Metadata     *RunMetadata   `json:"metadata,omitempty"`
+}
+
+// CompareCheck is the outcome of one check on each endpoint
+type CompareCheck struct {
+	Name    string        `json:"name"`
+	Results []CompareCell `json:"results"`
+	Differs bool          `json:"differs"`
+}
+
+// CompareCell is the outcome of a check on one endpoint; Status is empty
+// when the check was not part of that endpoint's run
+type CompareCell struct {
+	Status     Status  `json:"status,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// CompareRow is a property compared across the endpoints. Differs is only
+// set for properties expected to match, not for measurements such as
+// latencies.
+type CompareRow struct {
+	Name    string   `json:"name"`
+	Values  []string `json:"values"`
+	Differs bool     `json:"differs"`
+}
+
+// Failed reports whether a check failed on any endpoint
+func (r *CompareReport) Failed() bool {
+	for _, report := range r.Reports {
+		if report.Summary.Failed > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCompareReport builds the comparison of the reports of the same checks
+// run against several endpoints
+func NewCompareReport(reports []*TestReport) *CompareReport {
+	compare := &CompareReport{Reports: reports}
+	for _, report := range reports {
+		compare.Endpoints = append(compare.Endpoints, report.Config.Endpoint)
+	}
+	if len(reports) > 0 {
+		compare.Bucket = reports[0].Config.Bucket
+		compare.StartTime = reports[0].StartTime
+	}
+
+	// Checks in the order they first ran on any endpoint
+	var names []string
+	seen := make(map[string]bool)
+	for _, report := range reports {
+		for _, result := range report.Results {
+			if !seen[result.TestName] {
+				seen[result.TestName] = true
+				names = append(names, result.TestName)
+			}
+		}
+	}
+	for _, name := range names {
+		check := CompareCheck{Name: name}
+		for _, report := range reports {
+			var cell CompareCell
+			for _, result := range report.Results {
+				if result.TestName == name {
+					cell = CompareCell{
+						Status:     result.Status,
+						DurationMs: float64(result.Duration.Microseconds()) / 1000,
+						Error:      result.Error,
+					}
+				}
+			}
+			if len(check.Results) > 0 && cell.Status != check.Results[0].Status {
+				check.Differs = true
+			}
+			check.Results = append(check.Results, cell)
+		}
+		if check.Differs {
+			compare.Differences++
+		}
+		compare.Checks = append(compare.Checks, check)
+	}
+
+	compare.Latency = compare.rows(false, []compareProperty{
+		{"DNS lookup", authLatency(func(a AuthResult) float64 { return a.DNSMs })},
+		{"TCP connect", authLatency(func(a AuthResult) float64 { return a.ConnectMs })},
+		{"TLS handshake", authLatency(func(a AuthResult) float64 { return a.TLSHandshakeMs })},
+		{"First byte", authLatency(func(a AuthResult) float64 { return a.TTFBMs })},
+		{"Total request", authLatency(func(a AuthResult) float64 { return a.TotalMs })},
+	})
+
+	compare.TLS = compare.rows(true, []compareProperty{
+		{"Protocol", tlsProperty(func(t TLSResult) string { return t.TLSVersion })},
+		{"Cipher suite", tlsProperty(func(t TLSResult) string { return t.CipherSuite })},
+		{"Chain verified", tlsProperty(func(t TLSResult) string { return yesNo(t.Verified) })},
+		{"Certificate expiry", tlsProperty(func(t TLSResult) string { return t.Expiry })},
+		{"Covers bucket host", tlsProperty(func(t TLSResult) string {
+			if t.BucketHost == nil {
+				return "-"
+			}
+			return yesNo(t.BucketHost.Covered)
+		})},
+	})
+	compare.TLS = append(compare.TLS, compare.rows(false, []compareProperty{
+		{"Days until expiry", tlsProperty(func(t TLSResult) string { return fmt.Sprintf("%d", t.Certificate.DaysUntilExpiry) })},
+		{"Issuer", tlsProperty(func(t TLSResult) string { return t.Certificate.Issuer })},
+	})...)
+
+	compare.Capabilities = compare.rows(false, []compareProperty{
+		{"Provider", func(r *TestReport) string { return orDash(r.Provider()) }},
+	})
+	compare.Capabilities = append(compare.Capabilities, compare.rows(true, []compareProperty{
+		{"Policy support", func(r *TestReport) string { return orDash(r.Config.PolicySupport) }},
+		{"ACL support", func(r *TestReport) string { return orDash(r.Config.ACLSupport) }},
+	})...)
+
+	// The measured capabilities, when the runs included the Capability Probe
+	var probes []string
+	for _, report := range reports {
+		for _, result := range report.Results {
+			if details, ok := result.Details.(CapabilityProbeResult); ok && len(probes) == 0 {
+				for _, probe := range details.Probes {
+					probes = append(probes, probe.Name)
+				}
+			}
+		}
+	}
+	var probeRows []compareProperty
+	for _, name := range probes {
+		name := name
+		probeRows = append(probeRows, compareProperty{"Probe: " + name, func(r *TestReport) string {
+			for _, result := range r.Results {
+				if details, ok := result.Details.(CapabilityProbeResult); ok {
+					for _, probe := range details.Probes {
+						if probe.Name == name {
+							return probe.Outcome
+						}
+					}
+				}
+			}
+			return "-"
+		}})
+	}
+	compare.Capabilities = append(compare.Capabilities, compare.rows(true, probeRows)...)
+
+	return compare
+}
+
+// compareProperty is a row of the comparison and how to read it from a report
+type compareProperty struct {
+	name  string
+	value func(*TestReport) string
+}
+
+// rows reads the properties from every report; compared rows whose values
+// differ count as differences
+func (r *CompareReport) rows(compared bool, properties []compareProperty) []CompareRow {
+	rows := make([]CompareRow, 0, len(properties))
+	for _, property := range properties {
+		row := CompareRow{Name: property.name}
+		for _, report := range r.Reports {
+			value := property.value(report)
+			if compared && len(row.Values) > 0 && value != row.Values[0] {
+				row.Differs = true
+			}
+			row.Values = append(row.Values, value)
+		}
+		if row.Differs {
+			r.Differences++
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// tlsProperty reads a property of the TLS check details, or "-" for an
+// endpoint without them (plain HTTP, or no certificate retrieved)
+func tlsProperty(value func(TLSResult) string) func(*TestReport) string {
+	return func(r *TestReport) string {
+		for _, result := range r.Results {
+			if details, ok := result.Details.(TLSResult); ok {
+				return orDash(value(details))
+			}
+		}
+		return "-"
+	}
+}
+
+// authLatency reads a phase of the Bucket Authentication request, the one
+// request every run sends to the bucket
+func authLatency(value func(AuthResult) float64) func(*TestReport) string {
+	return func(r *TestReport) string {
+		for _, result := range r.Results {
+			if details, ok := result.Details.(AuthResult); ok {
+				return fmt.Sprintf("%.1fms", value(details))
+			}
+		}
+		return "-"
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// PrintCompare prints the comparison with one column per endpoint and the
+// rows that differ marked
+func PrintCompare(report *CompareReport) {
+	fmt.Println(strings.Repeat("=", 50))
+	fmt.Println(bold("S3 Bucket Tester - Endpoint Comparison"))
+	fmt.Println(strings.Repeat("=", 50))
+	fmt.Println()
+	fmt.Printf("  %s: %s\n", cyan("Bucket"), white(report.Bucket))
+	for i, endpoint := range report.Endpoints {
+		fmt.Printf("  %s: %s\n", cyan(fmt.Sprintf("Endpoint %c", 'A'+i)), white(endpoint))
+	}
+	fmt.Println()
+
+	// Column widths from the longest name and value
+	nameWidth := 0
+	for _, check := range report.Checks {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(check.Name))
+	}
+	cells := make([][]string, len(report.Checks))
+	valueWidth := 12
+	for i, check := range report.Checks {
+		for _, cell := range check.Results {
+			text := "-"
+			if cell.Status != "" {
+				text = fmt.Sprintf("%s %s", cell.Status, FormatDuration(time.Duration(cell.DurationMs*float64(time.Millisecond))))
+			}
+			cells[i] = append(cells[i], text)
+			valueWidth = max(valueWidth, len(text))
+		}
+	}
+	for _, rows := range [][]CompareRow{report.Latency, report.TLS, report.Capabilities} {
+		for _, row := range rows {
+			nameWidth = max(nameWidth, utf8.RuneCountInString(row.Name))
+			for _, value := range row.Values {
+				valueWidth = max(valueWidth, utf8.RuneCountInString(value))
+			}
+		}
+	}
+	nameWidth += 2
+	valueWidth += 2
+
+	diffMarker := yellow("≠")
+	if asciiOutput {
+		diffMarker = "!="
+	}
+	heading := func(title string) {
+		fmt.Printf("%s", bold(padText(title, nameWidth)))
+		for i := range report.Endpoints {
+			fmt.Printf("%s", bold(padText(fmt.Sprintf("%c", 'A'+i), valueWidth)))
+		}
+		fmt.Println()
+	}
+
+	heading("CHECK")
+	for i, check := range report.Checks {
+		fmt.Print(padText(check.Name, nameWidth))
+		for j, cell := range check.Results {
+			fmt.Print(statusColor(cell.Status)(padText(cells[i][j], valueWidth)))
+		}
+		if check.Differs {
+			fmt.Print(diffMarker)
+		}
+		fmt.Println()
+	}
+
+	for _, section := range []struct {
+		title string
+		rows  []CompareRow
+	}{
+		{"LATENCY", report.Latency},
+		{"TLS", report.TLS},
+		{"CAPABILITIES", report.Capabilities},
+	} {
+		fmt.Println()
+		heading(section.title)
+		for _, row := range section.rows {
+			fmt.Print(padText(row.Name, nameWidth))
+			for _, value := range row.Values {
+				fmt.Print(white(padText(value, valueWidth)))
+			}
+			if row.Differs {
+				fmt.Print(diffMarker)
+			}
+			fmt.Println()
+		}
+	}
+	fmt.Println()
+
+	// The errors behind the cells that did not pass
+	printed := false
+	for _, check := range report.Checks {
+		for i, cell := range check.Results {
+			if cell.Error == "" || cell.Status == StatusPass {
+				continue
+			}
+			fmt.Printf("  %s %s: %s\n", bold(fmt.Sprintf("%c", 'A'+i)), check.Name, gray(cell.Error))
+			printed = true
+		}
+	}
+	if printed {
+		fmt.Println()
+	}
+
+	if report.Differences == 0 {
+		fmt.Printf("%s %s\n", passIcon, green("The endpoints behave the same"))
+	} else {
+		fmt.Printf("%s %s\n", warnIcon, yellow(fmt.Sprintf("%d difference(s) between the endpoints", report.Differences)))
+	}
+	fmt.Println()
+}
+
+// padText pads s with spaces to width characters; colors are applied after
+// padding so escape codes do not count
+func padText(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 1))
+}
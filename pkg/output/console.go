@@ -2,38 +2,67 @@ package output
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
+	"github.com/s3-bucket-tester/s3tester/pkg/i18n"
 )
 
 var (
 	// Color definitions
-	bold      = color.New(color.Bold).SprintFunc()
-	green     = color.New(color.FgGreen).SprintFunc()
-	red       = color.New(color.FgRed).SprintFunc()
-	yellow    = color.New(color.FgYellow).SprintFunc()
-	cyan      = color.New(color.FgCyan).SprintFunc()
-	white     = color.New(color.FgWhite).SprintFunc()
-	gray      = color.New(color.FgHiBlack).SprintFunc()
-	passIcon  = green("✓")
-	failIcon  = red("✗")
-	warnIcon  = yellow("⚠")
-	skipIcon  = gray("-")
+	bold     = color.New(color.Bold).SprintFunc()
+	green    = color.New(color.FgGreen).SprintFunc()
+	red      = color.New(color.FgRed).SprintFunc()
+	yellow   = color.New(color.FgYellow).SprintFunc()
+	cyan     = color.New(color.FgCyan).SprintFunc()
+	white    = color.New(color.FgWhite).SprintFunc()
+	gray     = color.New(color.FgHiBlack).SprintFunc()
+	passIcon = green("✓")
+	failIcon = red("✗")
+	warnIcon = yellow("⚠")
+	skipIcon = gray("-")
+	infoIcon = cyan("ℹ")
+
+	// asciiOutput restricts the console output to ASCII, see SetASCII
+	asciiOutput = false
 )
 
+// SetASCII switches the console output to plain ASCII without color, for
+// screen readers, limited terminals and ticketing systems that mangle
+// unicode or ANSI escapes
+func SetASCII() {
+	color.NoColor = true
+	asciiOutput = true
+	passIcon = "[OK]"
+	failIcon = "[FAIL]"
+	warnIcon = "[WARN]"
+	skipIcon = "[-]"
+	infoIcon = "[i]"
+}
+
+// Bold returns s in bold unless color is disabled
+func Bold(s string) string {
+	return bold(s)
+}
+
 // PrintConsole prints the test report to console
 func PrintConsole(report *TestReport) {
 	// Print header
 	printHeader()
 
 	// Print configuration
-	printConfig(report.Config)
+	printConfig(report.Config, report.Metadata)
+
+	if report.Warmup != nil {
+		printWarmup(report.Warmup)
+	}
 
 	// Print separator
 	fmt.Println(strings.Repeat("=", 50))
-	fmt.Println(bold("Running Tests..."))
+	fmt.Println(bold(i18n.T("Running Tests...")))
 	fmt.Println(strings.Repeat("=", 50))
 
 	// Print results
@@ -44,9 +73,26 @@ func PrintConsole(report *TestReport) {
 	// Print separator
 	fmt.Println(strings.Repeat("=", 50))
 
+	if len(report.SlowestOperations) > 0 {
+		printSlowestOperations(report.SlowestOperations, report.Config.SlowThresholdMs)
+	}
+
+	// Print statistics across repeated runs
+	if report.Repeat != nil {
+		printRepeatReport(report.Repeat)
+	}
+
+	if report.Baseline != nil {
+		printBaseline(report.Baseline)
+	}
+
 	// Print summary
 	printSummary(report.Summary)
 
+	if report.SideEffectFree {
+		fmt.Printf("%s %s\n", passIcon, green(i18n.T("Read-only run: no write requests were sent")))
+	}
+
 	// Print footer
 	fmt.Println()
 }
@@ -54,30 +100,134 @@ func PrintConsole(report *TestReport) {
 // printHeader prints the tool header
 func printHeader() {
 	fmt.Println(strings.Repeat("=", 50))
-	fmt.Println(bold("S3 Bucket Tester"))
+	fmt.Println(bold(i18n.T("S3 Bucket Tester")))
 	fmt.Println(strings.Repeat("=", 50))
 	fmt.Println()
 }
 
+// printWarmup prints the cold and warm timing of the --warmup cycles
+func printWarmup(warmup *WarmupResult) {
+	fmt.Println(bold(i18n.T("Warm-up:")))
+	for _, cycle := range []struct {
+		name  string
+		cycle WarmupCycle
+	}{{"Cold", warmup.Cold}, {"Warm", warmup.Warm}} {
+		if cycle.cycle.Error != "" {
+			fmt.Printf("  %s: %s\n", cyan(i18n.T(cycle.name)), yellow(cycle.cycle.Error))
+			continue
+		}
+		fmt.Printf("  %s: DNS %.2fms | connect %.2fms | TLS %.2fms | first byte %.2fms | total %.2fms\n",
+			cyan(i18n.T(cycle.name)), cycle.cycle.DNSMs, cycle.cycle.ConnectMs, cycle.cycle.TLSHandshakeMs, cycle.cycle.TTFBMs, cycle.cycle.TotalMs)
+	}
+	fmt.Println()
+}
+
 // printConfig prints the test configuration
-func printConfig(config Config) {
-	fmt.Println(bold("Configuration:"))
-	fmt.Printf("  %s: %s\n", cyan("Endpoint"), white(config.Endpoint))
-	fmt.Printf("  %s: %s\n", cyan("Bucket"), white(config.Bucket))
-	fmt.Printf("  %s: %s\n", cyan("Region"), white(config.Region))
-	fmt.Printf("  %s: %s\n", cyan("Auth Type"), white(strings.ToUpper(config.AuthType)))
-	fmt.Printf("  %s: %d\n", cyan("Port"), config.Port)
-	fmt.Printf("  %s: %ds\n", cyan("Timeout"), config.Timeout)
-	
+func printConfig(config Config, metadata *RunMetadata) {
+	fmt.Println(bold(i18n.T("Configuration:")))
+	fmt.Printf("  %s: %s\n", cyan(i18n.T("Endpoint")), white(config.Endpoint))
+	fmt.Printf("  %s: %s\n", cyan(i18n.T("Bucket")), white(config.Bucket))
+	fmt.Printf("  %s: %s\n", cyan(i18n.T("Region")), white(config.Region))
+	fmt.Printf("  %s: %s\n", cyan(i18n.T("Auth Type")), white(strings.ToUpper(config.AuthType)))
+	fmt.Printf("  %s: %d\n", cyan(i18n.T("Port")), config.Port)
+	if config.DNSTimeout == config.Timeout && config.TCPTimeout == config.Timeout && config.HTTPTimeout == config.Timeout {
+		fmt.Printf("  %s: %ds\n", cyan(i18n.T("Timeout")), config.Timeout)
+	} else {
+		fmt.Printf("  %s: DNS %ds | TCP/TLS %ds | HTTP %ds\n", cyan(i18n.T("Timeout")), config.DNSTimeout, config.TCPTimeout, config.HTTPTimeout)
+	}
+
 	// Show addressing style
 	if config.PathStyle {
-		fmt.Printf("  %s: %s\n", cyan("Addressing Style"), white("Path-style"))
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Addressing Style")), white(i18n.T("Path-style")))
 	} else {
-		fmt.Printf("  %s: %s\n", cyan("Addressing Style"), white("Virtual-hosted (default)"))
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Addressing Style")), white(i18n.T("Virtual-hosted (default)")))
+	}
+
+	if config.TestPrefix != "" {
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Test Prefix")), white(config.TestPrefix))
+	}
+
+	if config.CredentialSource != "" {
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Credentials")), white(config.CredentialSource))
 	}
-	
+
+	if config.AssumedRole != nil {
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Assumed Role")), white(config.AssumedRole.Arn))
+	}
+
+	if config.CredentialExpiry != nil {
+		remaining := time.Until(*config.CredentialExpiry)
+		expiry := config.CredentialExpiry.Local().Format(time.RFC3339)
+		if remaining <= 0 {
+			fmt.Printf("  %s: %s\n", cyan(i18n.T("Credentials Expire")), red(expiry+" "+i18n.T("(expired)")))
+		} else {
+			fmt.Printf("  %s: %s\n", cyan(i18n.T("Credentials Expire")), white(i18n.Tf("%s (in %v)", expiry, remaining.Round(time.Second))))
+		}
+	}
+
 	if config.Insecure {
-		fmt.Printf("  %s: %s\n", cyan("TLS Verify"), red("Disabled"))
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("TLS Verify")), red(i18n.T("Disabled")))
+	} else if config.CACert != "" {
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("CA Certificates")), white(config.CACert))
+	}
+
+	if config.ClientCert != "" {
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Client Certificate")), white(config.ClientCert))
+	}
+
+	if config.SNI != "" {
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("TLS Server Name")), white(config.SNI))
+	}
+
+	if config.ReadOnly {
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Mode")), green(i18n.T("Read-only")))
+	}
+
+	switch config.IPFamily {
+	case IPv4Only:
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Address Family")), white(i18n.T("IPv4 only")))
+	case IPv6Only:
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Address Family")), white(i18n.T("IPv6 only")))
+	}
+
+	if metadata != nil {
+		runner := fmt.Sprintf("%s (%s/%s), s3tester %s", metadata.Hostname, metadata.OS, metadata.Arch, metadata.ToolVersion)
+		if metadata.SourceIP != "" {
+			runner += ", source IP " + metadata.SourceIP
+		}
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Runner")), white(runner))
+	}
+	fmt.Println()
+
+	printWarnings(config.Warnings)
+}
+
+// printWarnings prints configuration warnings grouped by the flag or
+// setting that raised them
+func printWarnings(warnings []Warning) {
+	if len(warnings) == 0 {
+		return
+	}
+
+	var sources []string
+	bySource := make(map[string][]Warning)
+	for _, w := range warnings {
+		if _, ok := bySource[w.Source]; !ok {
+			sources = append(sources, w.Source)
+		}
+		bySource[w.Source] = append(bySource[w.Source], w)
+	}
+
+	fmt.Println(bold(i18n.T("Warnings:")))
+	for _, source := range sources {
+		fmt.Printf("  %s:\n", cyan(source))
+		for _, w := range bySource[source] {
+			if w.Severity == WarningSeverityWarning {
+				fmt.Printf("    %s %s\n", warnIcon, yellow(w.Message))
+			} else {
+				fmt.Printf("    %s %s\n", infoIcon, w.Message)
+			}
+		}
 	}
 	fmt.Println()
 }
@@ -101,13 +251,38 @@ func printResult(index, total int, result TestResult) {
 	}
 
 	// Print test line
-	fmt.Printf("%s %s", gray(progress), white(result.TestName))
-	fmt.Printf(" %s\n", strings.Repeat(".", 45-len(result.TestName)-len(progress)))
-	fmt.Printf("  %s %s\n", statusIcon, statusColor(result.Status)(result.Status))
+	name := i18n.T(result.TestName)
+	fmt.Printf("%s %s", gray(progress), white(name))
+	fmt.Printf(" %s\n", strings.Repeat(".", max(45-utf8.RuneCountInString(name)-len(progress), 3)))
+	fmt.Printf("  %s %s\n", statusIcon, statusColor(result.Status)(i18n.T(string(result.Status))))
 
 	// Print details based on test type
-	if result.Error != "" {
-		fmt.Printf("  %s: %s\n", red("Error"), result.Error)
+	switch {
+	case result.Error != "" && result.Status == StatusSkip:
+		fmt.Printf("  %s: %s\n", gray(i18n.T("Reason")), result.Error)
+	case result.Error != "":
+		fmt.Printf("  %s: %s\n", red(i18n.T("Error")), result.Error)
+	}
+	switch result.FailureKind {
+	case FailureMaintenance:
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Classification")), yellow(i18n.T("Endpoint maintenance, not an S3 error")))
+	case FailureNonS3Response:
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Classification")), yellow(i18n.T("Non-S3 response, likely a proxy or load balancer")))
+	case FailureRateLimited:
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Classification")), yellow(i18n.T("Throttled: rate limit or quota exhausted")))
+	}
+	if r := result.RateLimit; r != nil && !strings.Contains(result.Error, r.Summary()) {
+		color := gray
+		if r.Exhausted {
+			color = yellow
+		}
+		fmt.Printf("  %s: %s\n", cyan(i18n.T("Rate limit")), color(r.Summary()))
+	}
+	if result.Attempts > 1 {
+		fmt.Printf("  %s: %d\n", cyan(i18n.T("Attempts")), result.Attempts)
+		for i, err := range result.AttemptErrors {
+			fmt.Printf("    %s %s\n", gray(i18n.Tf("#%d failed:", i+1)), err)
+		}
 	}
 
 	switch result.TestName {
@@ -119,11 +294,108 @@ func printResult(index, total int, result TestResult) {
 		printTLSResult(result)
 	case "Bucket Authentication Check":
 		printAuthResult(result)
+	case "Object Read/Write Check":
+		printObjectResult(result)
+	case "Expect: 100-continue Check":
+		printExpectContinueResult(result)
+	case "Transfer Encoding Probe":
+		printTransferEncodingResult(result)
+	case "Content-Encoding Passthrough Check":
+		printContentEncodingResult(result)
+	case "Cache Header Check":
+		printCacheHeaderResult(result)
+	case "Parallel Ranged GET Check":
+		printRangedGetResult(result)
+	case "Permission Matrix Check":
+		printPermissionsResult(result)
+	case "Object Access Check":
+		printObjectAccessResult(result)
+	case "Bucket Location Check":
+		printBucketLocationResult(result)
+	case "Addressing Style Check":
+		printAddressingStyleResult(result)
+	case "Public Access Block Check":
+		printPublicAccessBlockResult(result)
+	case "Versioned Delete Check":
+		printVersionedDeleteResult(result)
+	case "Versioning Check":
+		printVersioningResult(result)
+	case "SSE-C Check":
+		printSSECResult(result)
+	case "Presigned URL Check":
+		printPresignedResult(result)
+	case "POST Policy Upload Check":
+		printPostPolicyResult(result)
+	case "Copy Object Check":
+		printCopyResult(result)
+	case "Batch Delete Check":
+		printBatchDeleteResult(result)
+	case "Range and Conditional GET Check":
+		printConditionalResult(result)
+	case "Checksum Check":
+		printChecksumResult(result)
+	case "Object Lock Check":
+		printObjectLockResult(result)
+	case "Lifecycle Configuration Check":
+		printLifecycleResult(result)
+	case "Bucket Logging Check":
+		printLoggingResult(result)
+	case "Event Notification Check":
+		printNotificationResult(result)
+	case "Static Website Check":
+		printWebsiteResult(result)
+	case "Bucket Tagging Check":
+		printBucketTaggingResult(result)
+	case "Object Tagging Check":
+		printObjectTaggingResult(result)
+	case "Replication Check":
+		printReplicationResult(result)
+	case "Object Ownership Check":
+		printOwnershipResult(result)
+	case "Anonymous Access Check":
+		printAnonymousAccessResult(result)
+	case "Test Artifact Inventory":
+		printArtifactInventoryResult(result)
+	case "Capability Requirements Check":
+		printCapabilityResult(result)
+	case "SDK Parity Check":
+		printSDKParityResult(result)
+	case "CDN Delivery Check":
+		printCDNResult(result)
+	case "Capability Probe":
+		printCapabilityProbeResult(result)
+	case "Policy Fingerprint Check":
+		printPolicyFingerprintResult(result)
+	}
+
+	for _, op := range result.SlowOperations {
+		fmt.Printf("  %s %s: %s %s %d, %.2fms\n", warnIcon, yellow(i18n.T("Slow operation")), op.Method, op.Path, op.StatusCode, op.TotalMs)
+		fmt.Printf("      %s\n", gray(op.Breakdown()))
 	}
 
 	fmt.Println()
 }
 
+// printSlowestOperations prints the slowest traced HTTP operations of the run
+func printSlowestOperations(ops []HTTPOperation, thresholdMs int) {
+	fmt.Println(bold(i18n.T("Slowest Operations")))
+	fmt.Println(strings.Repeat("=", 50))
+	for i, op := range ops {
+		icon := passIcon
+		if op.Slow {
+			icon = warnIcon
+		}
+		check := op.Check
+		if check == "" {
+			check = "-"
+		}
+		fmt.Printf("%2d. %s %.2fms %s %s %d (%s)\n", i+1, icon, op.TotalMs, op.Method, op.Path, op.StatusCode, i18n.T(check))
+		fmt.Printf("      %s\n", gray(op.Breakdown()))
+	}
+	fmt.Printf("  %s\n", gray(i18n.Tf("Threshold: %dms", thresholdMs)))
+	fmt.Println(strings.Repeat("=", 50))
+}
+
 // printDNSResult prints DNS check result details
 func printDNSResult(result TestResult) {
 	if details, ok := result.Details.(DNSResult); ok {
@@ -146,7 +418,62 @@ func printTCPResult(result TestResult) {
 			fmt.Printf("  %s: %s\n", cyan("Local address"), white(details.LocalAddr))
 			fmt.Printf("  %s: %s\n", cyan("Remote address"), white(details.RemoteAddr))
 		}
+		if details.Proxy != "" {
+			fmt.Printf("  %s: %s\n", cyan("Proxy"), white(details.Proxy))
+		}
 		fmt.Printf("  %s: %dms\n", cyan("Connection time"), details.ConnectionTime)
+
+		// Handshake sampling statistics
+		if s := details.Samples; s != nil {
+			fmt.Printf("  %s: %d/%d successful\n", cyan("Handshake samples"), s.Successful, s.Count)
+			if s.Successful > 0 {
+				fmt.Printf("  %s: %.2f / %.2f / %.2f ms\n", cyan("Min / Avg / Max"), s.MinMs, s.AvgMs, s.MaxMs)
+				fmt.Printf("  %s: %.2fms\n", cyan("Jitter"), s.JitterMs)
+			}
+			lossText := fmt.Sprintf("%.1f%%", s.PacketLossPercent)
+			if s.PacketLossPercent > 0 {
+				fmt.Printf("  %s: %s (%d failed, %d suspected retransmits)\n", cyan("Estimated loss"), yellow(lossText), s.Failed, s.SuspectedRetransmits)
+			} else {
+				fmt.Printf("  %s: %s\n", cyan("Estimated loss"), green(lossText))
+			}
+		}
+
+		// Dual-stack race outcome
+		if he := details.HappyEyeballs; he != nil {
+			printHappyEyeballs(he)
+		}
+	}
+}
+
+// printHappyEyeballs prints the Happy Eyeballs race outcome
+func printHappyEyeballs(he *HappyEyeballsResult) {
+	if he.Error != "" {
+		fmt.Printf("  %s: %s\n", cyan("Happy Eyeballs"), red(he.Error))
+		return
+	}
+
+	winner := fmt.Sprintf("%s (%s)", he.WinningFamily, he.WinningAddress)
+	if he.IPv6 != nil && he.IPv4 != nil && he.IPv6.Connected && he.IPv4.Connected {
+		winner += fmt.Sprintf(" by %.2fms", he.MarginMs)
+	}
+	fmt.Printf("  %s: %s\n", cyan("Happy Eyeballs winner"), white(winner))
+
+	for _, family := range []struct {
+		name    string
+		outcome *AddressFamilyOutcome
+	}{{"IPv6", he.IPv6}, {"IPv4", he.IPv4}} {
+		switch {
+		case family.outcome == nil:
+			fmt.Printf("    %s: %s\n", family.name, gray("no addresses"))
+		case family.outcome.Connected:
+			fmt.Printf("    %s: %s in %.2fms (started +%.0fms)\n", family.name, green("connected"), family.outcome.ConnectMs, family.outcome.StartOffsetMs)
+		default:
+			fmt.Printf("    %s: %s (%s)\n", family.name, red("failed"), family.outcome.Error)
+		}
+	}
+
+	if he.Warning != "" {
+		fmt.Printf("  %s %s\n", warnIcon, yellow(he.Warning))
 	}
 }
 
@@ -159,6 +486,11 @@ func printTLSResult(result TestResult) {
 		fmt.Printf("  %s: %s to %s\n", cyan("Valid from"), white(cert.NotBefore.Format("2006-01-02")), white(cert.NotAfter.Format("2006-01-02")))
 		fmt.Printf("  %s: %s\n", cyan("TLS Version"), white(details.TLSVersion))
 		fmt.Printf("  %s: %s\n", cyan("Cipher Suite"), white(details.CipherSuite))
+		if details.ClientCertificate != "" {
+			fmt.Printf("  %s: %s\n", cyan("Client Certificate"), white(details.ClientCertificate))
+		} else if details.ClientCertRequested {
+			fmt.Printf("  %s: %s\n", cyan("Client Certificate"), yellow("requested by server, none sent"))
+		}
 
 		// SANs
 		if len(cert.SANs) > 0 {
@@ -179,7 +511,9 @@ func printTLSResult(result TestResult) {
 		days := cert.DaysUntilExpiry
 		if days < 0 {
 			fmt.Printf("  %s: %s\n", red("Certificate Status"), red("EXPIRED"))
-		} else if days < 30 {
+		} else if details.Expiry == ExpiryCritical {
+			fmt.Printf("  %s: %s (%d days remaining)\n", red("Certificate Status"), red("Expiring, renew immediately"), days)
+		} else if details.Expiry == ExpiryWarning {
 			fmt.Printf("  %s: %s (%d days remaining)\n", yellow("Certificate Status"), yellow("Expiring Soon"), days)
 		} else {
 			fmt.Printf("  %s: %s (%d days remaining)\n", green("Certificate Status"), green("Valid"), days)
@@ -192,6 +526,28 @@ func printTLSResult(result TestResult) {
 			fmt.Printf("  %s: %s\n", cyan("Verification"), red("Not Verified"))
 		}
 
+		// Completeness of the served chain
+		if a := details.ChainAnalysis; a != nil {
+			printChainAnalysis(a)
+		}
+
+		// Coverage of the virtual-hosted bucket host
+		if b := details.BucketHost; b != nil {
+			switch b.Match {
+			case BucketHostExact, BucketHostWildcard:
+				fmt.Printf("  %s: %s %s\n", cyan("Bucket Host"), white(b.Host), green("covered by "+b.SAN))
+			case BucketHostDottedBucket:
+				fmt.Printf("  %s: %s %s\n", cyan("Bucket Host"), white(b.Host), red("not covered, "+b.SAN+" matches one label only"))
+			default:
+				fmt.Printf("  %s: %s %s\n", cyan("Bucket Host"), white(b.Host), red("not covered"))
+			}
+		}
+
+		// OCSP revocation status
+		if r := cert.Revocation; r != nil {
+			printRevocation(r)
+		}
+
 		// Certificate chain
 		if len(cert.Chain) > 0 {
 			fmt.Printf("  %s: %d certificate(s)\n", cyan("Certificate Chain"), len(cert.Chain))
@@ -199,15 +555,114 @@ func printTLSResult(result TestResult) {
 				fmt.Printf("    %d. %s\n", i+1, white(chainCert.Issuer))
 			}
 		}
+
+		// Session resumption
+		if r := details.Resumption; r != nil {
+			printTLSResumption(r)
+		}
+
+		// Certificate selection without SNI
+		if len(details.SNIProbes) > 0 {
+			printSNIProbes(details.SNIProbes)
+		}
 	}
 }
 
+// printSNIProbes prints the certificates served without SNI
+func printSNIProbes(probes []SNIProbeResult) {
+	fmt.Printf("  %s:\n", cyan("Certificate without SNI"))
+	for _, p := range probes {
+		if p.Error != "" {
+			fmt.Printf("    %s %s: %s\n", p.Variant, white(p.Address), red(p.Error))
+			continue
+		}
+
+		sameCert := green("same certificate")
+		if !p.MatchesSNICert {
+			sameCert = yellow("different certificate")
+		}
+		fmt.Printf("    %s %s: %s (%s)\n", p.Variant, white(p.Address), white(p.Subject), sameCert)
+
+		if !p.CoversHostname {
+			fmt.Printf("      %s\n", yellow("Certificate does not cover the hostname"))
+		}
+		if p.Variant == "Raw IP" && !p.CoversIP {
+			fmt.Printf("      %s\n", yellow("Certificate has no matching IP SAN"))
+		}
+	}
+}
+
+// printChainAnalysis prints whether the server sends a complete chain and the
+// issuer certificates fetched through AIA
+func printChainAnalysis(a *ChainAnalysis) {
+	switch a.Status {
+	case ChainComplete:
+		fmt.Printf("  %s: %s (%d sent)\n", cyan("Chain"), green("Complete"), a.Sent)
+	case ChainIncomplete:
+		fmt.Printf("  %s: %s (%d sent)\n", cyan("Chain"), yellow("Incomplete, server does not send the intermediates"), a.Sent)
+		for _, subject := range a.Missing {
+			fmt.Printf("    %s %s\n", yellow("missing:"), white(subject))
+		}
+	case ChainSelfSigned:
+		fmt.Printf("  %s: %s\n", cyan("Chain"), red("Self-signed certificate"))
+	default:
+		fmt.Printf("  %s: %s (%d sent)\n", cyan("Chain"), red("Untrusted CA"), a.Sent)
+	}
+	for _, f := range a.AIAFetches {
+		if f.Error != "" {
+			fmt.Printf("    %s %s: %s\n", gray("AIA"), white(f.URL), red(f.Error))
+			continue
+		}
+		fmt.Printf("    %s %s: %s\n", gray("AIA"), white(f.URL), white(f.Subject))
+	}
+}
+
+// printRevocation prints the OCSP revocation status of the certificate
+func printRevocation(r *RevocationStatus) {
+	source := "OCSP " + r.Source
+	switch r.Status {
+	case RevocationGood:
+		fmt.Printf("  %s: %s (%s)\n", cyan("Revocation"), green("Good"), source)
+	case RevocationRevoked:
+		fmt.Printf("  %s: %s on %s, %s (%s)\n", cyan("Revocation"), red("REVOKED"), r.RevokedAt.Format("2006-01-02"), r.Reason, source)
+	case RevocationNotChecked:
+		fmt.Printf("  %s: %s\n", cyan("Revocation"), gray("Not checked, "+r.Error))
+	default:
+		fmt.Printf("  %s: %s\n", cyan("Revocation"), yellow("Unavailable, "+r.Error))
+	}
+}
+
+// printTLSResumption prints TLS session resumption details
+func printTLSResumption(r *TLSResumptionResult) {
+	if r.Error != "" {
+		fmt.Printf("  %s: %s\n", cyan("Session Resumption"), red(r.Error))
+		return
+	}
+
+	switch {
+	case !r.TicketIssued:
+		fmt.Printf("  %s: %s\n", cyan("Session Resumption"), yellow("No session ticket issued"))
+	case r.Resumed:
+		fmt.Printf("  %s: %s (full %.2fms, resumed %.2fms)\n", cyan("Session Resumption"), green("Supported"), r.FullHandshakeMs, r.ResumedHandshakeMs)
+	default:
+		fmt.Printf("  %s: %s\n", cyan("Session Resumption"), red("Ticket issued but resumption rejected"))
+	}
+	fmt.Printf("  %s: %s\n", cyan("0-RTT Early Data"), gray(r.EarlyData))
+}
+
 // printAuthResult prints auth check result details
 func printAuthResult(result TestResult) {
 	if details, ok := result.Details.(AuthResult); ok {
 		fmt.Printf("  %s: %s\n", cyan("Auth Type"), white(details.AuthType))
 		fmt.Printf("  %s: %s\n", cyan("Provider"), white(details.Provider))
 		fmt.Printf("  %s: %s\n", cyan("Endpoint"), white(details.Endpoint))
+		if details.DetectedRegion != "" {
+			fmt.Printf("  %s: %s\n", cyan("Detected Region"), yellow(details.DetectedRegion))
+		}
+		if details.HeadBucketStatus != 0 {
+			fmt.Printf("  %s: %s\n", cyan("Verified With"),
+				yellow(fmt.Sprintf("%s (HEAD Bucket returned HTTP %d)", details.Operation, details.HeadBucketStatus)))
+		}
 
 		if details.BucketExists {
 			fmt.Printf("  %s: %s\n", cyan("Bucket Exists"), green("Yes"))
@@ -222,14 +677,932 @@ func printAuthResult(result TestResult) {
 		}
 
 		fmt.Printf("  %s: %d\n", cyan("Status Code"), details.StatusCode)
+		if details.ServerDate != "" {
+			fmt.Printf("  %s: %s\n", cyan("Clock Skew"), clockSkewText(details.ClockSkewSeconds))
+		}
 		fmt.Printf("  %s: %dms\n", cyan("Response time"), details.ResponseTime)
+		fmt.Printf("  %s: %.2fms\n", cyan("Time to first byte"), details.TTFBMs)
+
+		// Server time is what remains of time-to-first-byte after the
+		// connection was set up
+		server := details.TTFBMs - details.DNSMs - details.ConnectMs - details.TLSHandshakeMs
+		if server < 0 {
+			server = 0
+		}
+		fmt.Printf("  %s: DNS %.2fms | connect %.2fms | TLS %.2fms | server %.2fms | total %.2fms\n",
+			cyan("Latency breakdown"), details.DNSMs, details.ConnectMs, details.TLSHandshakeMs, server, details.TotalMs)
+		if details.ConnectionReused {
+			fmt.Printf("  %s: %s\n", cyan("Connection"), gray("reused, no DNS, connect or TLS time"))
+		}
+	}
+}
+
+// clockSkewText describes the skew of the local clock against the server's,
+// highlighting skews that come close to the 15 minutes S3 allows
+func clockSkewText(seconds int64) string {
+	skew := time.Duration(seconds) * time.Second
+	var text string
+	switch {
+	case seconds > 1:
+		text = fmt.Sprintf("local clock %v ahead of the server", skew)
+	case seconds < -1:
+		text = fmt.Sprintf("local clock %v behind the server", -skew)
+	default:
+		return green("in sync with the server")
+	}
+	if skew > 5*time.Minute || skew < -5*time.Minute {
+		return red(text)
+	}
+	return yellow(text)
+}
+
+// printObjectResult prints object round-trip check details
+func printObjectResult(result TestResult) {
+	if details, ok := result.Details.(ObjectResult); ok {
+		fmt.Printf("  %s: %s (%d bytes)\n", cyan("Test object"), white(details.Key), details.Size)
+		for _, phase := range details.Phases {
+			if phase.Success {
+				fmt.Printf("    %s %-6s %d in %dms\n", passIcon, phase.Operation, phase.StatusCode, phase.LatencyMs)
+			} else {
+				fmt.Printf("    %s %-6s %s\n", failIcon, phase.Operation, red(phase.Error))
+			}
+		}
+		if details.ReadAccess {
+			if details.ContentMatch {
+				fmt.Printf("  %s: %s\n", cyan("Content Match"), green("Yes"))
+			} else {
+				fmt.Printf("  %s: %s\n", cyan("Content Match"), red("No"))
+			}
+		}
+	}
+}
+
+// printExpectContinueResult prints Expect: 100-continue check details
+func printExpectContinueResult(result TestResult) {
+	if details, ok := result.Details.(ExpectContinueResult); ok {
+		fmt.Printf("  %s: %s\n", cyan("Test object"), white(details.Key))
+		fmt.Printf("  %s: %d\n", cyan("Status Code"), details.StatusCode)
+		if details.Got100 {
+			fmt.Printf("  %s: %s (after %.2fms)\n", cyan("100 Continue"), green("Received"), details.InterimMs)
+		} else {
+			fmt.Printf("  %s: %s\n", cyan("100 Continue"), yellow("Not received"))
+		}
+		fmt.Printf("  %s: %s\n", cyan("Behavior"), white(details.Behavior))
+		fmt.Printf("  %s: %.2fms\n", cyan("Final response"), details.FinalResponseMs)
+		if details.StatusCode < 300 && !details.CleanedUp {
+			fmt.Printf("  %s: %s\n", cyan("Cleanup"), yellow("Test object could not be deleted"))
+		}
+	}
+}
+
+// printTransferEncodingResult prints transfer encoding probe details
+func printTransferEncodingResult(result TestResult) {
+	if details, ok := result.Details.(TransferEncodingResult); ok {
+		for _, tc := range details.Cases {
+			switch {
+			case tc.TransportError:
+				fmt.Printf("  %s %s: %s\n", failIcon, white(tc.Name), red(tc.Error))
+			case !tc.Accepted:
+				fmt.Printf("  %s %s: %s\n", warnIcon, white(tc.Name), yellow(fmt.Sprintf("rejected (%s)", tc.Error)))
+			case tc.Verified:
+				fmt.Printf("  %s %s: %s (HTTP %d, %d bytes stored)\n", passIcon, white(tc.Name), green("accepted"), tc.StatusCode, tc.StoredSize)
+			default:
+				fmt.Printf("  %s %s: %s (sent %d bytes, stored %d bytes)\n", warnIcon, white(tc.Name), yellow("accepted but altered"), tc.Sent, tc.StoredSize)
+			}
+		}
+	}
+}
+
+// printContentEncodingResult prints Content-Encoding passthrough details
+func printContentEncodingResult(result TestResult) {
+	if details, ok := result.Details.(ContentEncodingResult); ok {
+		fmt.Printf("  %s: %d bytes (gzip)\n", cyan("Uploaded"), details.UploadedBytes)
+		for _, d := range details.Downloads {
+			label := white("Accept-Encoding: " + d.AcceptEncoding)
+			switch {
+			case d.Error != "":
+				fmt.Printf("  %s %s: %s\n", failIcon, label, red(d.Error))
+			case !d.ByteIdentical:
+				fmt.Printf("  %s %s: %s (%d bytes, Content-Encoding %q)\n", failIcon, label, red("body altered"), d.ReceivedBytes, d.ContentEncoding)
+			case !d.HeaderPreserved:
+				fmt.Printf("  %s %s: %s (Content-Encoding %q)\n", warnIcon, label, yellow("header not preserved"), d.ContentEncoding)
+			default:
+				fmt.Printf("  %s %s: %s\n", passIcon, label, green("byte-identical, Content-Encoding: gzip"))
+			}
+		}
+	}
+}
+
+// printRangedGetResult prints parallel ranged GET check details
+func printRangedGetResult(result TestResult) {
+	if details, ok := result.Details.(RangedGetResult); ok {
+		fmt.Printf("  %s: %s (%d bytes)\n", cyan("Test object"), white(details.Key), details.Size)
+		fmt.Printf("  %s: %d x %d bytes, %d concurrent\n", cyan("Parts"), len(details.Parts), details.PartSize, details.Concurrency)
+		for _, part := range details.Parts {
+			if part.Error != "" {
+				fmt.Printf("    %s %s %s\n", failIcon, part.Range, red(part.Error))
+			}
+		}
+		if details.FailedParts == 0 && len(details.Parts) > 0 {
+			fmt.Printf("  %s: %dms (%.2f MiB/s aggregate)\n", cyan("Download"), details.DownloadMs, details.ThroughputMiBps)
+			if details.IntegrityOK {
+				fmt.Printf("  %s: %s\n", cyan("SHA-256 Match"), green("Yes"))
+			} else {
+				fmt.Printf("  %s: %s\n", cyan("SHA-256 Match"), red("No"))
+			}
+		}
+	}
+}
+
+// printCacheHeaderResult prints cache header check details
+func printCacheHeaderResult(result TestResult) {
+	if details, ok := result.Details.(CacheHeaderResult); ok {
+		for _, h := range details.Headers {
+			if h.Unchanged {
+				fmt.Printf("  %s %s: %s\n", passIcon, cyan(h.Name), white(h.Received))
+			} else {
+				fmt.Printf("  %s %s: sent %s, received %s\n", warnIcon, cyan(h.Name), white(h.Sent), yellow(fmt.Sprintf("%q", h.Received)))
+			}
+		}
+
+		if !details.CacheDetected {
+			fmt.Printf("  %s: %s\n", cyan("Cache in path"), green("None detected"))
+			return
+		}
+
+		fmt.Printf("  %s: %s\n", cyan("Cache in path"), yellow("Detected"))
+		names := make([]string, 0, len(details.CDNHeaders))
+		for name := range details.CDNHeaders {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("    %s: %s\n", white(name), details.CDNHeaders[name])
+		}
+	}
+}
+
+// printRepeatReport prints per-check statistics across repeated runs
+func printRepeatReport(repeat *RepeatReport) {
+	fmt.Println(bold(fmt.Sprintf("Reliability Sample (%d runs)", repeat.Runs)))
+	for _, stats := range repeat.Checks {
+		rate := fmt.Sprintf("%.1f%%", stats.SuccessRate)
+		switch {
+		case stats.Failed == 0:
+			rate = green(rate)
+		case stats.Failed == stats.Runs:
+			rate = red(rate)
+		default:
+			rate = yellow(rate)
+		}
+
+		fmt.Printf("  %s\n", white(stats.TestName))
+		fmt.Printf("    %s: %s (%d passed, %d warnings, %d failed)\n", cyan("Success"), rate, stats.Passed, stats.Warnings, stats.Failed)
+		fmt.Printf("    %s: min %.2fms, mean %.2fms, p50 %.2fms, p95 %.2fms, max %.2fms\n",
+			cyan("Duration"), stats.MinMs, stats.MeanMs, stats.P50Ms, stats.P95Ms, stats.MaxMs)
+	}
+	fmt.Println()
+}
+
+// printPermissionsResult prints the permission matrix as a table
+func printPermissionsResult(result TestResult) {
+	if details, ok := result.Details.(PermissionsResult); ok {
+		fmt.Printf("  %s: %d allowed, %d denied of %d\n", cyan("Operations"), details.Allowed, details.Denied, len(details.Operations))
+		for _, p := range details.Operations {
+			action := fmt.Sprintf("%-34s", p.Action)
+			switch p.Outcome {
+			case "allowed":
+				fmt.Printf("  %s %s %s\n", passIcon, white(action), green(p.Outcome))
+			case "denied":
+				fmt.Printf("  %s %s %s\n", failIcon, white(action), red(p.Outcome))
+			default:
+				fmt.Printf("  %s %s %s %s\n", warnIcon, white(action), yellow(p.Outcome), gray("("+p.Detail+")"))
+			}
+		}
+	}
+}
+
+// printVersionedDeleteResult prints versioned delete check details
+func printVersionedDeleteResult(result TestResult) {
+	if details, ok := result.Details.(VersionedDeleteResult); ok {
+		fmt.Printf("  %s: %s\n", cyan("Versioning"), white(details.Versioning))
+		if len(details.Steps) == 0 {
+			return
+		}
+		fmt.Printf("  %s: %s\n", cyan("Test object"), white(details.Key))
+		printSteps(details.Steps, 22)
+		if details.MatchesAWS {
+			fmt.Printf("  %s: %s\n", cyan("Matches AWS"), green("Yes"))
+		} else {
+			fmt.Printf("  %s: %s\n", cyan("Matches AWS"), red("No"))
+		}
+	}
+}
+
+// printVersioningResult prints versioning check details
+func printVersioningResult(result TestResult) {
+	if details, ok := result.Details.(VersioningResult); ok {
+		fmt.Printf("  %s: %s\n", cyan("Versioning"), white(details.Status))
+		if details.MFADelete != "" {
+			fmt.Printf("  %s: %s\n", cyan("MFA Delete"), white(details.MFADelete))
+		}
+		if len(details.Steps) == 0 {
+			fmt.Printf("  %s: %s\n", cyan("Round Trip"), gray(details.RoundTrip))
+			return
+		}
+		fmt.Printf("  %s: %s\n", cyan("Test object"), white(details.Key))
+		printSteps(details.Steps, 28)
+		if details.Works {
+			fmt.Printf("  %s: %s\n", cyan("Versioning Works"), green("Yes"))
+		} else {
+			fmt.Printf("  %s: %s\n", cyan("Versioning Works"), red("No"))
+		}
+	}
+}
+
+// printObjectLockResult prints the Object Lock configuration and the steps of
+// the retention test
+func printObjectLockResult(result TestResult) {
+	if details, ok := result.Details.(ObjectLockResult); ok {
+		if !details.Enabled {
+			fmt.Printf("  %s: %s\n", cyan("Object Lock"), yellow("not enabled"))
+			return
+		}
+		fmt.Printf("  %s: %s\n", cyan("Object Lock"), green("enabled"))
+		switch {
+		case details.DefaultDays > 0:
+			fmt.Printf("  %s: %s\n", cyan("Default Retention"), white(fmt.Sprintf("%s, %d days", details.DefaultMode, details.DefaultDays)))
+		case details.DefaultYears > 0:
+			fmt.Printf("  %s: %s\n", cyan("Default Retention"), white(fmt.Sprintf("%s, %d years", details.DefaultMode, details.DefaultYears)))
+		default:
+			fmt.Printf("  %s: %s\n", cyan("Default Retention"), gray("none"))
+		}
+		if len(details.Steps) == 0 {
+			fmt.Printf("  %s: %s\n", cyan("Retention Test"), gray(details.RetentionTest))
+			return
+		}
+		fmt.Printf("  %s: %s %s\n", cyan("Test object"), white(details.Key), gray("(retained until "+details.RetainUntil+")"))
+		printSteps(details.Steps, 24)
+		if details.Works {
+			fmt.Printf("  %s: %s\n", cyan("Retention Works"), green("Yes"))
+		} else {
+			fmt.Printf("  %s: %s\n", cyan("Retention Works"), red("No"))
+		}
+	}
+}
+
+// printPresignedResult prints the presigned URL steps and the outcome
+func printPresignedResult(result TestResult) {
+	if details, ok := result.Details.(PresignedResult); ok {
+		fmt.Printf("  %s: %s (URLs valid for %s)\n", cyan("Test object"), white(details.Key), details.Expires)
+		printSteps(details.Steps, 22)
+		outcome := red(details.Outcome)
+		if details.Outcome == "supported" {
+			outcome = green(details.Outcome)
+		}
+		fmt.Printf("  %s: %s\n", cyan("Presigned URLs"), outcome)
+	}
+}
+
+// printPostPolicyResult prints the policy limits, the form upload steps and
+// the outcome
+func printPostPolicyResult(result TestResult) {
+	if details, ok := result.Details.(PostPolicyResult); ok {
+		fmt.Printf("  %s: keys starting with %s, up to %d bytes\n", cyan("Policy"), white(details.KeyPrefix), details.MaxSize)
+		printSteps(details.Steps, 24)
+		outcome := red(details.Outcome)
+		if details.Outcome == "supported" {
+			outcome = green(details.Outcome)
+		}
+		fmt.Printf("  %s: %s\n", cyan("POST uploads"), outcome)
+	}
+}
+
+// printCopyResult prints the copy steps, the outcome per operation and the
+// quirks
+func printCopyResult(result TestResult) {
+	if details, ok := result.Details.(CopyResult); ok {
+		fmt.Printf("  %s: %s\n", cyan("Source object"), white(details.SourceKey))
+		printSteps(details.Steps, 32)
+		fmt.Printf("  %s: %s\n", cyan("CopyObject"), operationOutcome(details.CopyObject))
+		fmt.Printf("  %s: %s\n", cyan("UploadPartCopy"), operationOutcome(details.UploadPartCopy))
+		for _, quirk := range details.Quirks {
+			fmt.Printf("  %s %s\n", warnIcon, yellow(quirk))
+		}
+	}
+}
+
+// printBatchDeleteResult prints the DeleteObjects steps, its outcome and the
+// quirks
+func printBatchDeleteResult(result TestResult) {
+	if details, ok := result.Details.(BatchDeleteResult); ok {
+		fmt.Printf("  %s: %d\n", cyan("Test objects"), len(details.Keys))
+		printSteps(details.Steps, 32)
+		fmt.Printf("  %s: %s\n", cyan("DeleteObjects"), operationOutcome(details.DeleteObjects))
+		for _, quirk := range details.Quirks {
+			fmt.Printf("  %s %s\n", warnIcon, yellow(quirk))
+		}
+	}
+}
+
+// printSteps prints the steps of a check, with the step names padded to
+// width and the expectation of each step that did not match
+func printSteps(steps []Step, width int) {
+	for _, step := range steps {
+		if step.Match {
+			fmt.Printf("    %s %-*s %s\n", passIcon, width, step.Step, gray(step.Observed))
+		} else {
+			fmt.Printf("    %s %-*s %s %s\n", failIcon, width, step.Step, red(step.Observed), gray("(expected "+step.Expected+")"))
+		}
+	}
+}
+
+// operationOutcome colors the outcome of an operation
+func operationOutcome(outcome string) string {
+	switch outcome {
+	case "supported":
+		return green(outcome)
+	case "denied":
+		return yellow(outcome)
+	}
+	return red(outcome)
+}
+
+// printConditionalResult prints the validators of the test object and the
+// outcome of each GET
+func printConditionalResult(result TestResult) {
+	if details, ok := result.Details.(ConditionalResult); ok {
+		fmt.Printf("  %s: %s (%d bytes)\n", cyan("Test object"), white(details.Key), details.Size)
+		if details.ETag != "" {
+			fmt.Printf("  %s: ETag %s, Last-Modified %s\n", cyan("Validators"), details.ETag, details.LastModified)
+		}
+		printSteps(details.Steps, 28)
+	}
+}
+
+// printChecksumResult prints the checksum steps and the outcome per
+// integrity mechanism
+func printChecksumResult(result TestResult) {
+	if details, ok := result.Details.(ChecksumResult); ok {
+		fmt.Printf("  %s: %s\n", cyan("Test object"), white(details.Key))
+		printSteps(details.Steps, 28)
+		for _, mechanism := range details.Mechanisms {
+			outcome := yellow(mechanism.Outcome)
+			switch mechanism.Outcome {
+			case "end to end", "MD5 of the content":
+				outcome = green(mechanism.Outcome)
+			case "verified":
+				if mechanism.Name == "Content-MD5" {
+					outcome = green(mechanism.Outcome)
+				}
+			case "ignored", "broken":
+				outcome = red(mechanism.Outcome)
+			}
+			fmt.Printf("  %s: %s\n", cyan(mechanism.Name), outcome)
+		}
+	}
+}
+
+// printSSECResult prints SSE-C check details
+func printSSECResult(result TestResult) {
+	if details, ok := result.Details.(SSECResult); ok {
+		fmt.Printf("  %s: %s\n", cyan("Test object"), white(details.Key))
+		printSteps(details.Steps, 22)
+		outcome := red(details.Outcome)
+		if details.Outcome == "supported" {
+			outcome = green(details.Outcome)
+		}
+		fmt.Printf("  %s: %s\n", cyan("SSE-C"), outcome)
+	}
+}
+
+// printLifecycleResult prints the lifecycle rules and whether they clean up
+// expired objects and incomplete multipart uploads
+func printLifecycleResult(result TestResult) {
+	if details, ok := result.Details.(LifecycleResult); ok {
+		if !details.Configured {
+			fmt.Printf("  %s: %s\n", cyan("Lifecycle"), yellow("not configured"))
+			return
+		}
+		fmt.Printf("  %s: %d\n", cyan("Rules"), len(details.Rules))
+		for _, rule := range details.Rules {
+			scope := "whole bucket"
+			if rule.Prefix != "" {
+				scope = "prefix " + rule.Prefix
+			}
+			switch {
+			case rule.Problem != "":
+				fmt.Printf("    %s %s (%s): %s\n", failIcon, rule.ID, scope, red(rule.Problem))
+			case rule.Status != "Enabled":
+				fmt.Printf("    %s %s (%s): %s\n", skipIcon, rule.ID, scope, gray("disabled, "+strings.Join(rule.Actions, ", ")))
+			default:
+				fmt.Printf("    %s %s (%s): %s\n", passIcon, rule.ID, scope, strings.Join(rule.Actions, ", "))
+			}
+		}
+		for _, cleanup := range []struct {
+			name string
+			set  bool
+		}{{"Expires Objects", details.Expires}, {"Aborts Multipart Uploads", details.AbortsMultipart}} {
+			if cleanup.set {
+				fmt.Printf("  %s: %s\n", cyan(cleanup.name), green("Yes"))
+			} else {
+				fmt.Printf("  %s: %s\n", cyan(cleanup.name), yellow("No"))
+			}
+		}
+	}
+}
+
+// printNotificationResult prints the notification targets and the outcome of
+// the delivery verification
+func printNotificationResult(result TestResult) {
+	if details, ok := result.Details.(NotificationResult); ok {
+		if len(details.Targets) == 0 {
+			fmt.Printf("  %s: %s\n", cyan("Targets"), yellow("none"))
+			return
+		}
+		fmt.Printf("  %s: %d\n", cyan("Targets"), len(details.Targets))
+		for _, target := range details.Targets {
+			name := target.ARN
+			if name == "" {
+				name = "default event bus"
+			}
+			fmt.Printf("    %s %s %s\n", white(fmt.Sprintf("%-11s", target.Type)), name, gray(strings.Join(target.Events, ", ")))
+		}
+		switch details.Verification {
+		case "delivered":
+			fmt.Printf("  %s: %s\n", cyan("Delivery"), green(fmt.Sprintf("delivered to %s after %dms", details.WebhookURL, details.DeliveryMs)))
+		case "not delivered":
+			fmt.Printf("  %s: %s\n", cyan("Delivery"), red("not delivered to "+details.WebhookURL))
+		default:
+			fmt.Printf("  %s: %s\n", cyan("Delivery"), gray(details.Verification))
+		}
+	}
+}
+
+// printWebsiteResult prints the website configuration and the index document
+// as the website endpoint and the S3 API returned it
+func printWebsiteResult(result TestResult) {
+	if details, ok := result.Details.(WebsiteResult); ok {
+		if !details.Configured {
+			fmt.Printf("  %s: %s\n", cyan("Website"), gray("not configured"))
+			return
+		}
+		if details.RedirectTo != "" {
+			fmt.Printf("  %s: %s\n", cyan("Redirects To"), white(details.RedirectTo))
+		} else {
+			fmt.Printf("  %s: %s\n", cyan("Index Document"), white(details.IndexDocument))
+		}
+		if details.ErrorDocument != "" {
+			fmt.Printf("  %s: %s\n", cyan("Error Document"), white(details.ErrorDocument))
+		}
+		if details.RoutingRules > 0 {
+			fmt.Printf("  %s: %d\n", cyan("Routing Rules"), details.RoutingRules)
+		}
+		if details.WebsiteURL == "" {
+			return
+		}
+		fmt.Printf("  %s: %s\n", cyan("Website URL"), white(details.WebsiteURL))
+		for _, fetch := range []struct {
+			name  string
+			fetch WebsiteFetch
+		}{{"Website Endpoint", details.Website}, {"S3 API", details.API}} {
+			switch {
+			case fetch.fetch.Error != "" && fetch.fetch.StatusCode == 0:
+				fmt.Printf("  %s: %s\n", cyan(fetch.name), red(fetch.fetch.Error))
+			case fetch.fetch.Error != "":
+				fmt.Printf("  %s: HTTP %d %s\n", cyan(fetch.name), fetch.fetch.StatusCode, red(fetch.fetch.Error))
+			case fetch.fetch.StatusCode == 0:
+			case fetch.fetch.Location != "":
+				fmt.Printf("  %s: HTTP %d to %s\n", cyan(fetch.name), fetch.fetch.StatusCode, fetch.fetch.Location)
+			default:
+				fmt.Printf("  %s: HTTP %d, %d bytes %s\n", cyan(fetch.name), fetch.fetch.StatusCode, fetch.fetch.Size, gray(fetch.fetch.ContentType))
+			}
+		}
+	}
+}
+
+// printBucketTaggingResult prints the bucket's tags
+func printBucketTaggingResult(result TestResult) {
+	if details, ok := result.Details.(BucketTaggingResult); ok {
+		if len(details.Tags) == 0 {
+			fmt.Printf("  %s: %s\n", cyan("Tags"), yellow("none"))
+			return
+		}
+		fmt.Printf("  %s: %d\n", cyan("Tags"), len(details.Tags))
+		for _, tag := range details.Tags {
+			fmt.Printf("    %s = %s\n", white(tag.Key), tag.Value)
+		}
+	}
+}
+
+// printObjectTaggingResult prints the steps of the object tagging round trip
+func printObjectTaggingResult(result TestResult) {
+	if details, ok := result.Details.(ObjectTaggingResult); ok {
+		fmt.Printf("  %s: %s\n", cyan("Test object"), white(details.Key))
+		printSteps(details.Steps, 24)
+		if details.Works {
+			fmt.Printf("  %s: %s\n", cyan("Object Tagging Works"), green("Yes"))
+		} else {
+			fmt.Printf("  %s: %s\n", cyan("Object Tagging Works"), red("No"))
+		}
+	}
+}
+
+// printLoggingResult prints whether access logging is enabled and where the
+// logs are delivered
+func printLoggingResult(result TestResult) {
+	if details, ok := result.Details.(LoggingResult); ok {
+		if !details.Enabled {
+			fmt.Printf("  %s: %s\n", cyan("Access Logging"), yellow("disabled"))
+			return
+		}
+		fmt.Printf("  %s: %s\n", cyan("Access Logging"), green("enabled"))
+		fmt.Printf("  %s: %s\n", cyan("Delivered To"), white("s3://"+details.TargetBucket+"/"+details.TargetPrefix))
+	}
+}
+
+// printReplicationResult prints the replication rules and the canary outcome
+func printReplicationResult(result TestResult) {
+	if details, ok := result.Details.(ReplicationResult); ok {
+		if !details.Configured {
+			fmt.Printf("  %s: %s\n", cyan("Replication"), yellow("not configured"))
+			return
+		}
+		fmt.Printf("  %s: %d\n", cyan("Rules"), len(details.Rules))
+		for _, rule := range details.Rules {
+			scope := "whole bucket"
+			if rule.Prefix != "" {
+				scope = "prefix " + rule.Prefix
+			}
+			if rule.Status == "Enabled" {
+				fmt.Printf("    %s %s (%s): to %s\n", passIcon, rule.ID, scope, rule.Destination)
+			} else {
+				fmt.Printf("    %s %s (%s): %s\n", skipIcon, rule.ID, scope, gray("disabled, to "+rule.Destination))
+			}
+		}
+		canary := details.Canary
+		switch canary {
+		case "completed":
+			canary = green(canary)
+		case "failed":
+			canary = red(canary)
+		case "pending", "not replicated":
+			canary = yellow(canary)
+		default:
+			canary = gray(canary)
+		}
+		if details.Key != "" {
+			canary += gray(fmt.Sprintf(" (%s after %dms)", details.Key, details.WaitMs))
+		}
+		fmt.Printf("  %s: %s\n", cyan("Canary"), canary)
+	}
+}
+
+// printOwnershipResult prints the Object Ownership setting and whether ACLs
+// are in effect
+func printOwnershipResult(result TestResult) {
+	if details, ok := result.Details.(OwnershipResult); ok {
+		ownership := white(details.ObjectOwnership)
+		if !details.Configured {
+			ownership += gray(" (default, no ownership controls)")
+		}
+		fmt.Printf("  %s: %s\n", cyan("Object Ownership"), ownership)
+		if details.ACLsDisabled {
+			fmt.Printf("  %s: %s\n", cyan("ACLs"), green("disabled"))
+		} else {
+			fmt.Printf("  %s: %s\n", cyan("ACLs"), yellow("enabled"))
+		}
+	}
+}
+
+// printPublicAccessBlockResult prints the bucket, account and effective Block
+// Public Access settings
+func printPublicAccessBlockResult(result TestResult) {
+	if details, ok := result.Details.(PublicAccessBlockResult); ok {
+		if details.AccountID != "" {
+			fmt.Printf("  %s: %s\n", cyan("Account"), white(details.AccountID))
+		}
+		for _, level := range []struct {
+			name   string
+			lookup PublicAccessBlockLookup
+		}{{"Bucket setting", details.Bucket}, {"Account setting", details.Account}} {
+			status := level.lookup.Status
+			switch status {
+			case "configured":
+				status = green(status)
+			case "not configured", "not applicable":
+				status = white(status)
+			default:
+				status = yellow(status)
+			}
+			if level.lookup.DeniedBy != "" {
+				status += gray(" (by " + level.lookup.DeniedBy + ")")
+			} else if level.lookup.Error != "" {
+				status += gray(" (" + level.lookup.Error + ")")
+			}
+			fmt.Printf("  %s: %s\n", cyan(level.name), status)
+		}
+
+		onOff := func(settings *PublicAccessBlockSettings, on func(PublicAccessBlockSettings) bool) string {
+			switch {
+			case settings == nil:
+				return gray(fmt.Sprintf("%-8s", "-"))
+			case on(*settings):
+				return green(fmt.Sprintf("%-8s", "on"))
+			default:
+				return white(fmt.Sprintf("%-8s", "off"))
+			}
+		}
+		fmt.Printf("  %s\n", gray(fmt.Sprintf("%-22s %-8s %-8s %s", "", "Bucket", "Account", "Effective")))
+		for _, setting := range []struct {
+			name string
+			on   func(PublicAccessBlockSettings) bool
+		}{
+			{"BlockPublicAcls", func(s PublicAccessBlockSettings) bool { return s.BlockPublicAcls }},
+			{"IgnorePublicAcls", func(s PublicAccessBlockSettings) bool { return s.IgnorePublicAcls }},
+			{"BlockPublicPolicy", func(s PublicAccessBlockSettings) bool { return s.BlockPublicPolicy }},
+			{"RestrictPublicBuckets", func(s PublicAccessBlockSettings) bool { return s.RestrictPublicBuckets }},
+		} {
+			effective := details.Effective
+			fmt.Printf("  %s %s %s %s\n", white(fmt.Sprintf("%-22s", setting.name)),
+				onOff(details.Bucket.Settings, setting.on), onOff(details.Account.Settings, setting.on), onOff(&effective, setting.on))
+		}
+	}
+}
+
+// printAddressingStyleResult prints addressing style check details
+func printAddressingStyleResult(result TestResult) {
+	if details, ok := result.Details.(AddressingStyleResult); ok {
+		fmt.Printf("  %s: %s\n", cyan("Configured Style"), white(details.Configured))
+		for _, style := range details.Styles {
+			label := fmt.Sprintf("%-15s", style.Style)
+			switch {
+			case style.Works:
+				fmt.Printf("  %s %s %s %s\n", passIcon, white(label), green("works"), gray(fmt.Sprintf("(HTTP %d in %dms, %s)", style.StatusCode, style.ResponseTimeMs, style.URL)))
+			case style.StatusCode == 0 && style.URL == "":
+				fmt.Printf("  %s %s %s\n", skipIcon, white(label), gray(style.Error))
+			default:
+				fmt.Printf("  %s %s %s %s\n", failIcon, white(label), red("fails"), gray("("+style.Error+")"))
+			}
+		}
+	}
+}
+
+// printBucketLocationResult prints bucket location check details
+func printBucketLocationResult(result TestResult) {
+	if details, ok := result.Details.(BucketLocationResult); ok {
+		switch {
+		case details.Region == "" && details.StatusCode == 200:
+			fmt.Printf("  %s: %s\n", cyan("Bucket Region"), gray("not reported by the endpoint"))
+		case details.Match:
+			fmt.Printf("  %s: %s\n", cyan("Bucket Region"), green(details.Region))
+		case details.Region != "":
+			fmt.Printf("  %s: %s\n", cyan("Bucket Region"), red(details.Region))
+		}
+		if details.LocationConstraint != details.Region {
+			fmt.Printf("  %s: %q\n", cyan("Location Constraint"), details.LocationConstraint)
+		}
+		fmt.Printf("  %s: %s\n", cyan("Configured Region"), white(details.ConfiguredRegion))
+	}
+}
+
+// printObjectAccessResult prints object access check details
+func printObjectAccessResult(result TestResult) {
+	if details, ok := result.Details.(ObjectAccessResult); ok {
+		fmt.Printf("  %s: %s\n", cyan("Object"), white(details.Key))
+		if details.Size > 0 || details.ContentType != "" {
+			fmt.Printf("  %s: %d bytes, %s\n", cyan("Size"), details.Size, white(details.ContentType))
+		}
+		if details.ETag != "" {
+			fmt.Printf("  %s: %s\n", cyan("ETag"), white(details.ETag))
+		}
+		if details.LastModified != "" {
+			fmt.Printf("  %s: %s\n", cyan("Last modified"), white(details.LastModified))
+		}
+		if details.StorageClass != "" {
+			fmt.Printf("  %s: %s\n", cyan("Storage class"), white(details.StorageClass))
+		}
+		if details.VersionID != "" {
+			fmt.Printf("  %s: %s\n", cyan("Version ID"), white(details.VersionID))
+		}
+		for _, p := range details.Operations {
+			operation := fmt.Sprintf("%-20s", p.Operation)
+			switch p.Outcome {
+			case "allowed":
+				fmt.Printf("  %s %s %s %s\n", passIcon, white(operation), green(p.Outcome), gray("("+p.Detail+")"))
+			case "denied":
+				fmt.Printf("  %s %s %s %s\n", failIcon, white(operation), red(p.Outcome), gray("("+p.Detail+")"))
+			default:
+				fmt.Printf("  %s %s %s %s\n", warnIcon, white(operation), yellow(p.Outcome), gray("("+p.Detail+")"))
+			}
+		}
+	}
+}
+
+// printAnonymousAccessResult prints anonymous access check details
+func printAnonymousAccessResult(result TestResult) {
+	if details, ok := result.Details.(AnonymousAccessResult); ok {
+		severity := string(details.Severity)
+		switch details.Severity {
+		case ExposureNone:
+			severity = green("none")
+		case ExposureLow, ExposureMedium:
+			severity = yellow(severity)
+		default:
+			severity = red(strings.ToUpper(severity))
+		}
+		fmt.Printf("  %s: %s\n", cyan("Exposure"), severity)
+
+		for _, p := range details.Probes {
+			label := fmt.Sprintf("%s %s", p.Method, p.Name)
+			if p.Key != "" {
+				label += " " + p.Key
+			}
+			switch {
+			case p.Allowed && ExposureRank(p.Severity) >= ExposureRank(ExposureHigh):
+				fmt.Printf("  %s %s: %s\n", failIcon, white(label), red(fmt.Sprintf("[%s] %s", p.Severity, p.Detail)))
+			case p.Allowed:
+				fmt.Printf("  %s %s: %s\n", warnIcon, white(label), yellow(fmt.Sprintf("[%s] %s", p.Severity, p.Detail)))
+			case p.StatusCode == 0:
+				fmt.Printf("  %s %s: %s\n", skipIcon, white(label), gray(p.Detail))
+			default:
+				fmt.Printf("  %s %s: %s\n", passIcon, white(label), green("denied ("+p.Detail+")"))
+			}
+		}
+	}
+}
+
+// printArtifactInventoryResult prints test artifact inventory details
+func printArtifactInventoryResult(result TestResult) {
+	if details, ok := result.Details.(ArtifactInventoryResult); ok {
+		fmt.Printf("  %s: %s\n", cyan("Prefix"), white(details.Prefix))
+		fmt.Printf("  %s: %d (%d older than %s)\n", cyan("Objects"), details.Total, len(details.Stale), details.StaleAfter)
+		for _, a := range details.Stale {
+			fmt.Printf("    %s %s (%d bytes, %s old)\n", warnIcon, white(a.Key), a.Size, a.Age)
+		}
+		if details.Purged > 0 {
+			fmt.Printf("  %s: %s\n", cyan("Purged"), green(fmt.Sprintf("%d", details.Purged)))
+		}
+		for _, e := range details.PurgeErrors {
+			fmt.Printf("    %s %s\n", failIcon, red(e))
+		}
+	}
+}
+
+// printCapabilityResult prints capability requirements check details
+func printCapabilityResult(result TestResult) {
+	if details, ok := result.Details.(CapabilityResult); ok {
+		for _, r := range details.Requirements {
+			if r.Met {
+				fmt.Printf("  %s %s: %s\n", passIcon, white(r.Requirement), green(r.Detected))
+			} else {
+				fmt.Printf("  %s %s: %s\n", failIcon, white(r.Requirement), red(r.Reason))
+			}
+		}
+	}
+}
+
+// printSDKParityResult prints SDK parity check details
+func printSDKParityResult(result TestResult) {
+	if details, ok := result.Details.(SDKParityResult); ok {
+		for _, op := range details.Operations {
+			icon, color := passIcon, white
+			if !op.Match {
+				icon, color = warnIcon, yellow
+			}
+			fmt.Printf("  %s %s: s3tester %s, AWS SDK %s\n", icon, white(op.Operation), color(op.S3Tester.String()), color(op.SDK.String()))
+		}
+		switch details.Verdict {
+		case ParityConsistent:
+			fmt.Printf("  %s: %s\n", cyan("Verdict"), green("both clients agree; failures are on the endpoint's side"))
+		case ParityS3TesterArtifact:
+			fmt.Printf("  %s: %s\n", cyan("Verdict"), red("fails only with s3tester's signer"))
+		case ParitySDKOnly:
+			fmt.Printf("  %s: %s\n", cyan("Verdict"), yellow("fails only with the AWS SDK"))
+		case ParityDifferent:
+			fmt.Printf("  %s: %s\n", cyan("Verdict"), yellow("both clients fail, differently"))
+		}
+	}
+}
+
+// printCapabilityProbeResult prints capability probe details and the
+// capabilities found, as an entry for a providers file
+func printCapabilityProbeResult(result TestResult) {
+	details, ok := result.Details.(CapabilityProbeResult)
+	if !ok {
+		return
+	}
+	for _, probe := range details.Probes {
+		icon, color := passIcon, green
+		switch probe.Outcome {
+		case ProbeNotSupported, ProbeSkipped:
+			icon, color = infoIcon, white
+		case ProbeDenied, ProbeInconclusive:
+			icon, color = warnIcon, yellow
+		}
+		line := fmt.Sprintf("  %s %s: %s", icon, white(probe.Name), color(probe.Outcome))
+		if probe.Detail != "" {
+			line += " " + gray("("+probe.Detail+")")
+		}
+		fmt.Println(line)
+	}
+
+	g := details.Generated
+	fmt.Printf("  %s:\n", cyan("Providers file entry"))
+	fmt.Printf("    %s\n", gray("capabilities:"))
+	fmt.Printf("    %s\n", gray("  policy: "+g.PolicySupport))
+	fmt.Printf("    %s\n", gray("  acl: "+g.ACLSupport))
+	fmt.Printf("    %s\n", gray(fmt.Sprintf("  virtualHosted: %v", g.VirtualHostSupport)))
+	fmt.Printf("    %s\n", gray(fmt.Sprintf("  pathStyle: %v", g.PathStyleSupport)))
+}
+
+// printCDNResult prints CDN delivery check details
+func printCDNResult(result TestResult) {
+	if details, ok := result.Details.(CDNResult); ok {
+		host := details.Host
+		if details.CNAME != "" {
+			host += " -> " + details.CNAME
+		}
+		if len(details.Addresses) > 0 {
+			host += " (" + strings.Join(details.Addresses, ", ") + ")"
+		}
+		fmt.Printf("  %s: %s\n", cyan("CDN Host"), white(host))
+		if details.Provider != "" {
+			fmt.Printf("  %s: %s\n", cyan("CDN"), white(details.Provider))
+		}
+		if t := details.TLS; t != nil && t.Error == "" {
+			verified := green("verified")
+			if !t.Verified {
+				verified = yellow("not verified")
+			}
+			expiry := ""
+			if t.Certificate != nil {
+				expiry = ", expires " + t.Certificate.NotAfter.Format("2006-01-02")
+			}
+			fmt.Printf("  %s: %s %s%s\n", cyan("CDN TLS"), white(t.Version), verified, expiry)
+		}
+		if details.ObjectURL != "" {
+			fmt.Printf("  %s: %s\n", cyan("Object URL"), white(details.ObjectURL))
+		}
+		for i, fetch := range details.Fetches {
+			status := fmt.Sprintf("HTTP %d", fetch.StatusCode)
+			if fetch.Error != "" {
+				status = fetch.Error
+			}
+			if fetch.CacheStatus != "" {
+				status += ", cache " + fetch.CacheStatus
+			}
+			if fetch.Age != "" {
+				status += ", age " + fetch.Age
+			}
+			fmt.Printf("  %s %d: %s (%v)\n", cyan("CDN request"), i+1, white(status), fetch.Duration.Round(time.Millisecond))
+		}
+		if details.BodyMatch {
+			compared := fmt.Sprintf("%d bytes identical", details.Origin.Size)
+			if details.Range != "" {
+				compared += " (" + details.Range + ")"
+			}
+			fmt.Printf("  %s %s: %s\n", passIcon, white("Body"), gray(compared))
+		}
+		for _, h := range details.Headers {
+			if h.Match {
+				fmt.Printf("  %s %s: %s\n", passIcon, white(h.Name), gray(h.CDN))
+			} else {
+				fmt.Printf("  %s %s: origin %s, CDN %s\n", warnIcon, white(h.Name), white(fmt.Sprintf("%q", h.Origin)), yellow(fmt.Sprintf("%q", h.CDN)))
+			}
+		}
+	}
+}
+
+// printPolicyFingerprintResult prints policy fingerprint check details
+func printPolicyFingerprintResult(result TestResult) {
+	if details, ok := result.Details.(PolicyFingerprintResult); ok {
+		for _, c := range details.Components {
+			changed := false
+			for _, change := range details.Changes {
+				changed = changed || change.Component == c.Name
+			}
+			switch {
+			case changed:
+				fmt.Printf("  %s %s: %s\n", warnIcon, white(c.Name), yellow(c.Describe()+" (changed)"))
+			case c.Error != "":
+				fmt.Printf("  %s %s: %s\n", warnIcon, white(c.Name), yellow("not read, "+c.Error))
+			default:
+				fmt.Printf("  %s %s: %s\n", passIcon, white(c.Name), gray(c.Describe()))
+			}
+		}
+		fingerprint := details.Fingerprint[:16]
+		if details.Baseline {
+			fingerprint += " (baseline)"
+		}
+		fmt.Printf("  %s: %s\n", cyan("Fingerprint"), white(fingerprint))
 	}
 }
 
 // printSummary prints the test summary
 func printSummary(summary TestSummary) {
-	fmt.Println(bold("Test Summary"))
-	fmt.Printf("  Total: %s | Passed: %s | Failed: %s | Warnings: %s\n",
+	fmt.Println(bold(i18n.T("Test Summary")))
+	fmt.Printf(i18n.T("  Total: %s | Passed: %s | Failed: %s | Warnings: %s")+"\n",
 		white(fmt.Sprintf("%d", summary.Total)),
 		green(fmt.Sprintf("%d", summary.Passed)),
 		red(fmt.Sprintf("%d", summary.Failed)),
@@ -238,12 +1611,99 @@ func printSummary(summary TestSummary) {
 	fmt.Println()
 
 	if summary.Failed == 0 && summary.Warnings == 0 {
-		fmt.Println(green("All tests passed successfully!"))
+		fmt.Println(green(i18n.T("All tests passed successfully!")))
 	} else if summary.Failed == 0 {
-		fmt.Println(yellow("Tests completed with warnings."))
+		fmt.Println(yellow(i18n.T("Tests completed with warnings.")))
 	} else {
-		fmt.Println(red("Some tests failed. Please review the errors above."))
+		fmt.Println(red(i18n.T("Some tests failed. Please review the errors above.")))
+	}
+	if summary.Maintenance > 0 {
+		fmt.Println(yellow(i18n.Tf("%d failures were caused by endpoint maintenance; run again once it is over.", summary.Maintenance)))
+	}
+	if summary.RateLimited > 0 {
+		fmt.Println(yellow(i18n.Tf("%d failures were caused by throttling or an exhausted quota; run again once the limit resets.", summary.RateLimited)))
+	}
+	if summary.Dependent > 0 {
+		fmt.Println(gray(i18n.Tf("%d checks were skipped because a check they depend on failed.", summary.Dependent)))
+	}
+}
+
+// PrintOneLine prints the report as a single tab-separated line for cron
+// digests and shell pipelines: target, overall status, total duration and
+// the comma-separated names of the failed checks ("-" when none)
+func PrintOneLine(report *TestReport) {
+	status := StatusPass
+	var failed []string
+	for _, result := range report.Results {
+		switch result.Status {
+		case StatusFail:
+			status = StatusFail
+			failed = append(failed, result.TestName)
+		case StatusWarn:
+			if status == StatusPass {
+				status = StatusWarn
+			}
+		}
+	}
+
+	failedChecks := "-"
+	if len(failed) > 0 {
+		failedChecks = strings.Join(failed, ",")
+	}
+
+	fmt.Printf("%s\t%s\t%s\t%s\n", report.Config.Target(), status, report.Duration.Round(time.Millisecond), failedChecks)
+}
+
+// PrintCertWatch prints the cert-watch report to console
+func PrintCertWatch(report *CertWatchReport) {
+	fmt.Println(strings.Repeat("=", 50))
+	fmt.Println(bold("S3 Bucket Tester - Certificate Watch"))
+	fmt.Println(strings.Repeat("=", 50))
+	fmt.Println()
+	fmt.Printf("  %s: %d days\n", cyan("Warning threshold"), report.WarnDays)
+	fmt.Println()
+
+	for _, r := range report.Results {
+		var statusIcon string
+		switch r.Status {
+		case StatusPass:
+			statusIcon = passIcon
+		case StatusWarn:
+			statusIcon = warnIcon
+		default:
+			statusIcon = failIcon
+		}
+
+		fmt.Printf("%s %s:%d\n", statusIcon, white(r.Host), r.Port)
+		if r.Error != "" {
+			fmt.Printf("  %s: %s\n", red("Error"), r.Error)
+			fmt.Println()
+			continue
+		}
+
+		fmt.Printf("  %s: %s\n", cyan("Subject"), white(r.Subject))
+		fmt.Printf("  %s: %s\n", cyan("Expires"), white(r.NotAfter.Format("2006-01-02")))
+		switch {
+		case r.DaysUntilExpiry < 0:
+			fmt.Printf("  %s: %s\n", cyan("Status"), red("EXPIRED"))
+		case r.Status == StatusWarn:
+			fmt.Printf("  %s: %s\n", cyan("Status"), yellow(fmt.Sprintf("%d days remaining", r.DaysUntilExpiry)))
+		default:
+			fmt.Printf("  %s: %s\n", cyan("Status"), green(fmt.Sprintf("%d days remaining", r.DaysUntilExpiry)))
+		}
+		if !r.Verified {
+			fmt.Printf("  %s: %s\n", cyan("Verification"), red("Not Verified"))
+		}
+		fmt.Println()
 	}
+
+	fmt.Println(strings.Repeat("=", 50))
+	fmt.Printf("  Checked: %s | OK: %s | Expiring: %s | Failed: %s\n",
+		white(fmt.Sprintf("%d", report.Summary.Total)),
+		green(fmt.Sprintf("%d", report.Summary.Passed)),
+		yellow(fmt.Sprintf("%d", report.Summary.Warnings)),
+		red(fmt.Sprintf("%d", report.Summary.Failed)))
+	fmt.Println()
 }
 
 // statusColor returns the color function for a given status
@@ -265,6 +1725,9 @@ func statusColor(status Status) func(a ...interface{}) string {
 // FormatDuration formats a duration for display
 func FormatDuration(d time.Duration) string {
 	if d < time.Millisecond {
+		if asciiOutput {
+			return fmt.Sprintf("%dus", d.Microseconds())
+		}
 		return fmt.Sprintf("%dµs", d.Microseconds())
 	} else if d < time.Second {
 		return fmt.Sprintf("%dms", d.Milliseconds())
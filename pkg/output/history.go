@@ -0,0 +1,201 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HistoryReport contains the trends of the runs recorded with --history
+type HistoryReport struct {
+	Database string          `json:"database"`
+	Since    time.Time       `json:"since"`
+	Targets  []HistoryTarget `json:"targets"`
+}
+
+// HistoryTarget contains the trends of one endpoint and bucket
+type HistoryTarget struct {
+	Target       string              `json:"target"`
+	Runs         int                 `json:"runs"`
+	FirstRun     time.Time           `json:"firstRun"`
+	LastRun      time.Time           `json:"lastRun"`
+	Checks       []CheckTrend        `json:"checks"`
+	Certificates []CertificateChange `json:"certificates"`
+}
+
+// CheckTrend is the history of one check: its current status and since when
+// it has had it, the runs where it changed, and its daily median duration
+type CheckTrend struct {
+	Name        string             `json:"name"`
+	Runs        int                `json:"runs"`
+	Status      Status             `json:"status"`
+	StatusSince time.Time          `json:"statusSince"`
+	LastPass    *time.Time         `json:"lastPass,omitempty"`
+	Transitions []StatusTransition `json:"transitions,omitempty"`
+	Daily       []DailyLatency     `json:"daily"`
+}
+
+// StatusTransition is a run where a check's status changed
+type StatusTransition struct {
+	At   time.Time `json:"at"`
+	From Status    `json:"from"`
+	To   Status    `json:"to"`
+}
+
+// DailyLatency is the median duration of a check's runs on one day that did
+// not fail
+type DailyLatency struct {
+	Date     string  `json:"date"`
+	MedianMs float64 `json:"medianMs"`
+	Runs     int     `json:"runs"`
+}
+
+// CertificateChange is a certificate the TLS check saw for the first time
+type CertificateChange struct {
+	FirstSeen  time.Time `json:"firstSeen"`
+	Serial     string    `json:"serial"`
+	Subject    string    `json:"subject"`
+	Issuer     string    `json:"issuer"`
+	NotAfter   time.Time `json:"notAfter"`
+	TLSVersion string    `json:"tlsVersion"`
+}
+
+// historyTimeFormat is how run times are shown in the history
+const historyTimeFormat = "2006-01-02 15:04"
+
+// PrintHistory prints the trends of each target
+func PrintHistory(report *HistoryReport) {
+	fmt.Println(strings.Repeat("=", 50))
+	fmt.Println(bold("S3 Bucket Tester - History"))
+	fmt.Println(strings.Repeat("=", 50))
+	fmt.Println()
+	fmt.Printf("  %s: %s\n", cyan("Database"), white(report.Database))
+	fmt.Printf("  %s: %s\n", cyan("Since"), white(report.Since.Local().Format(historyTimeFormat)))
+	fmt.Println()
+
+	if len(report.Targets) == 0 {
+		fmt.Printf("%s No runs recorded in this period; record runs with --history\n\n", infoIcon)
+		return
+	}
+
+	for _, target := range report.Targets {
+		fmt.Printf("%s\n", bold(target.Target))
+		fmt.Printf("  %s: %d, %s to %s\n", cyan("Runs"), target.Runs,
+			target.FirstRun.Local().Format(historyTimeFormat), target.LastRun.Local().Format(historyTimeFormat))
+		fmt.Println()
+
+		printCheckStatus(target.Checks)
+		printLatencyTrend(target.Checks)
+		printCertificateChanges(target.Certificates)
+	}
+}
+
+// printCheckStatus prints the current status of each check, since when it
+// has had it, and the recent status changes
+func printCheckStatus(checks []CheckTrend) {
+	fmt.Println(bold("  Status"))
+	for _, check := range checks {
+		if check.Status == "" {
+			fmt.Printf("    %s %s: %s\n", skipIcon, white(check.Name), gray("skipped in every run"))
+			continue
+		}
+		icon := passIcon
+		switch check.Status {
+		case StatusFail:
+			icon = failIcon
+		case StatusWarn:
+			icon = warnIcon
+		}
+		line := fmt.Sprintf("%s since %s", check.Status, check.StatusSince.Local().Format(historyTimeFormat))
+		if check.Status != StatusPass {
+			if check.LastPass != nil {
+				line += fmt.Sprintf(", last passed %s", check.LastPass.Local().Format(historyTimeFormat))
+			} else {
+				line += ", never passed in this period"
+			}
+		}
+		fmt.Printf("    %s %s: %s\n", icon, white(check.Name), statusColor(check.Status)(line))
+
+		// The last few changes are what explains the current status
+		transitions := check.Transitions
+		if len(transitions) > 5 {
+			fmt.Printf("      %s\n", gray(fmt.Sprintf("... %d earlier changes", len(transitions)-5)))
+			transitions = transitions[len(transitions)-5:]
+		}
+		for _, t := range transitions {
+			fmt.Printf("      %s %s %s %s\n", gray(t.At.Local().Format(historyTimeFormat)),
+				statusColor(t.From)(string(t.From)), arrow(), statusColor(t.To)(string(t.To)))
+		}
+	}
+	fmt.Println()
+}
+
+// printLatencyTrend prints a sparkline of each check's daily median
+// duration with the first and last day's values
+func printLatencyTrend(checks []CheckTrend) {
+	width := 0
+	for _, check := range checks {
+		width = max(width, len(check.Name))
+	}
+	fmt.Printf("  %s\n", bold("Latency (daily median)"))
+	for _, check := range checks {
+		if len(check.Daily) == 0 {
+			continue
+		}
+		first, last := check.Daily[0], check.Daily[len(check.Daily)-1]
+		fmt.Printf("    %-*s  %s  %s %s %s\n", width, check.Name, cyan(sparkline(check.Daily)),
+			formatMs(first.MedianMs), arrow(), formatMs(last.MedianMs))
+	}
+	fmt.Println()
+}
+
+// printCertificateChanges prints each certificate in the order it was first
+// served
+func printCertificateChanges(certificates []CertificateChange) {
+	if len(certificates) == 0 {
+		return
+	}
+	fmt.Printf("  %s\n", bold("Certificates"))
+	for i, cert := range certificates {
+		label := "first seen"
+		if i > 0 {
+			label = "replaced"
+		}
+		fmt.Printf("    %s %s: %s, issued by %s, expires %s (%s)\n", gray(cert.FirstSeen.Local().Format(historyTimeFormat)),
+			label, white(cert.Subject), cert.Issuer, cert.NotAfter.Local().Format("2006-01-02"), cert.TLSVersion)
+	}
+	fmt.Println()
+}
+
+// sparkline draws the daily medians as bars scaled between the lowest and
+// the highest
+func sparkline(daily []DailyLatency) string {
+	bars := []rune("▁▂▃▄▅▆▇█")
+	if asciiOutput {
+		bars = []rune("_.-=+*#@")
+	}
+	low, high := daily[0].MedianMs, daily[0].MedianMs
+	for _, day := range daily {
+		low, high = min(low, day.MedianMs), max(high, day.MedianMs)
+	}
+	var b strings.Builder
+	for _, day := range daily {
+		i := 0
+		if high > low {
+			i = int((day.MedianMs - low) / (high - low) * float64(len(bars)-1))
+		}
+		b.WriteRune(bars[i])
+	}
+	return b.String()
+}
+
+func arrow() string {
+	if asciiOutput {
+		return "->"
+	}
+	return "→"
+}
+
+func formatMs(ms float64) string {
+	return FormatDuration(time.Duration(ms * float64(time.Millisecond)))
+}
@@ -0,0 +1,40 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// IAMPolicyVersion is the policy language version of generated policies
+const IAMPolicyVersion = "2012-10-17"
+
+// IAMPolicy is an IAM identity policy document
+type IAMPolicy struct {
+	Version   string         `json:"Version"`
+	Statement []IAMStatement `json:"Statement"`
+}
+
+// IAMStatement is one statement of an IAM policy
+type IAMStatement struct {
+	Sid      string   `json:"Sid"`
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource []string `json:"Resource"`
+}
+
+// WriteIAMPolicy writes the policy as JSON to a file, or to stdout if the
+// file is "-"
+func WriteIAMPolicy(policy *IAMPolicy, outputFile string) error {
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if outputFile == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	return os.WriteFile(outputFile, data, 0644)
+}
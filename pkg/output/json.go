@@ -6,10 +6,12 @@ import (
 	"time"
 )
 
-// PrintJSON prints the test report as JSON to a file
+// PrintJSON prints the test report as JSON to a file, or to stdout when no
+// file is given, with credentials masked unless --include-credentials was
+// given
 func PrintJSON(report *TestReport, outputFile string) error {
 	// Marshal to JSON with indentation
-	data, err := json.MarshalIndent(report, "", "  ")
+	data, err := json.MarshalIndent(sanitize(report), "", "  ")
 	if err != nil {
 		return err
 	}
@@ -20,52 +22,110 @@ func PrintJSON(report *TestReport, outputFile string) error {
 	}
 
 	// Otherwise print to stdout
-	_, err = os.Stdout.Write(data)
+	_, err = os.Stdout.Write(append(data, '\n'))
 	return err
 }
 
+// PrintCertWatchJSON writes the cert-watch report as JSON to a file
+func PrintCertWatchJSON(report *CertWatchReport, outputFile string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outputFile, data, 0644)
+}
+
+// PrintBenchJSON writes the bench report as JSON to a file
+func PrintBenchJSON(report *BenchReport, outputFile string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outputFile, data, 0644)
+}
+
+// PrintCompareJSON writes the compare report as JSON to a file
+func PrintCompareJSON(report *CompareReport, outputFile string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outputFile, data, 0644)
+}
+
+// PrintHistoryJSON writes the history report as JSON to a file
+func PrintHistoryJSON(report *HistoryReport, outputFile string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outputFile, data, 0644)
+}
+
 // PrintJSONWithRemediation prints the test report as JSON with remediation suggestions
 func PrintJSONWithRemediation(report *TestReport, outputFile string) error {
 	// Create extended report with remediations
 	type ExtendedTestResult struct {
-		TestName      string        `json:"testName"`
-		Status        Status        `json:"status"`
-		Duration      string        `json:"duration"`
-		Error         string        `json:"error,omitempty"`
-		Details       interface{}   `json:"details,omitempty"`
-		Remediation   interface{}   `json:"remediation,omitempty"`
-		Warnings      []string      `json:"warnings,omitempty"`
+		TestName       string         `json:"testName"`
+		Status         Status         `json:"status"`
+		Duration       string         `json:"duration"`
+		Error          string         `json:"error,omitempty"`
+		Details        interface{}    `json:"details,omitempty"`
+		Remediation    interface{}    `json:"remediation,omitempty"`
+		Warnings       []string       `json:"warnings,omitempty"`
+		StartTime      string         `json:"startTime"`
+		EndTime        string         `json:"endTime"`
+		Sequence       uint64         `json:"sequence"`
+		Target         string         `json:"target"`
+		SLO            []string       `json:"sloViolations,omitempty"`
+		FailureKind    string         `json:"failureKind,omitempty"`
+		SkippedBecause string         `json:"skippedBecause,omitempty"`
+		RateLimit      *RateLimitInfo `json:"rateLimit,omitempty"`
 	}
 
 	type ExtendedTestReport struct {
-		Config     Config                `json:"config"`
-		StartTime  string                `json:"startTime"`
-		EndTime    string                `json:"endTime"`
-		Duration   string                `json:"duration"`
-		Results    []ExtendedTestResult  `json:"results"`
-		Summary    TestSummary           `json:"summary"`
+		Config    Config               `json:"config"`
+		StartTime string               `json:"startTime"`
+		EndTime   string               `json:"endTime"`
+		Duration  string               `json:"duration"`
+		Results   []ExtendedTestResult `json:"results"`
+		Summary   TestSummary          `json:"summary"`
+		Metadata  *RunMetadata         `json:"metadata,omitempty"`
 	}
 
 	// Convert results
 	extendedResults := make([]ExtendedTestResult, len(report.Results))
 	for i, result := range report.Results {
 		extendedResults[i] = ExtendedTestResult{
-			TestName: result.TestName,
-			Status:   result.Status,
-			Duration: result.Duration.String(),
-			Error:    result.Error,
-			Details:  result.Details,
+			TestName:       result.TestName,
+			Status:         result.Status,
+			Duration:       result.Duration.String(),
+			Error:          result.Error,
+			Details:        result.Details,
+			StartTime:      result.StartTime.Format(time.RFC3339Nano),
+			EndTime:        result.EndTime.Format(time.RFC3339Nano),
+			Sequence:       result.Sequence,
+			Target:         result.Target,
+			SLO:            result.SLOViolations,
+			FailureKind:    result.FailureKind,
+			SkippedBecause: result.SkippedBecause,
+			RateLimit:      result.RateLimit,
 		}
 	}
 
 	// Create extended report
 	extendedReport := ExtendedTestReport{
-		Config:     report.Config,
-		StartTime:  report.StartTime.Format(time.RFC3339),
-		EndTime:    report.EndTime.Format(time.RFC3339),
-		Duration:   report.Duration.String(),
-		Results:    extendedResults,
-		Summary:    report.Summary,
+		Config:    sanitize(report).Config,
+		StartTime: report.StartTime.Format(time.RFC3339),
+		EndTime:   report.EndTime.Format(time.RFC3339),
+		Duration:  report.Duration.String(),
+		Results:   extendedResults,
+		Summary:   report.Summary,
+		Metadata:  report.Metadata,
 	}
 
 	// Marshal to JSON with indentation
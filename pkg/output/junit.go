@@ -0,0 +1,132 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/remediation"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of one run
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+// junitProperty is a name/value pair describing the run
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// junitTestCase is the result of one checker
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitMessage is a failure or skip message with optional body text
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// PrintJUnit writes the test report as JUnit XML, one test case per checker.
+// Failures carry the error and remediation; warnings pass with the warning
+// in system-out so CI does not block on them.
+func PrintJUnit(report *TestReport, outputFile string) error {
+	suite := junitTestSuite{
+		Name:      "s3tester: " + report.Config.Target(),
+		Tests:     report.Summary.Total,
+		Failures:  report.Summary.Failed,
+		Skipped:   report.Summary.Skipped,
+		Time:      junitSeconds(report.Duration.Seconds()),
+		Timestamp: report.StartTime.UTC().Format("2006-01-02T15:04:05"),
+		Properties: []junitProperty{
+			{Name: "endpoint", Value: report.Config.Endpoint},
+			{Name: "bucket", Value: report.Config.Bucket},
+			{Name: "region", Value: report.Config.Region},
+			{Name: "authType", Value: report.Config.AuthType},
+		},
+	}
+	if m := report.Metadata; m != nil {
+		suite.Properties = append(suite.Properties,
+			junitProperty{Name: "toolVersion", Value: m.ToolVersion},
+			junitProperty{Name: "hostname", Value: m.Hostname},
+			junitProperty{Name: "os", Value: m.OS + "/" + m.Arch},
+			junitProperty{Name: "sourceIp", Value: m.SourceIP},
+		)
+	}
+
+	classname := "s3tester." + report.Config.Bucket
+	for _, result := range report.Results {
+		tc := junitTestCase{
+			Name:      result.TestName,
+			Classname: classname,
+			Time:      junitSeconds(result.Duration.Seconds()),
+		}
+
+		switch result.Status {
+		case StatusFail:
+			text := result.Error
+			if rem := remediation.GetRemediation(result.TestName, report.Provider(), fmt.Errorf("%s", result.Error)); rem != nil && result.Error != "" {
+				text = strings.TrimSpace(remediation.FormatRemediation(rem))
+			}
+			tc.Failure = &junitMessage{Message: result.Error, Type: string(StatusFail), Text: text}
+		case StatusSkip:
+			tc.Skipped = &junitMessage{Message: result.Error}
+		case StatusWarn:
+			tc.SystemOut = fmt.Sprintf("WARN: %s", result.Error)
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	suites := junitTestSuites{
+		Name:     "s3tester",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	return os.WriteFile(outputFile, data, 0644)
+}
+
+// junitSeconds formats a duration in seconds as JUnit expects
+func junitSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
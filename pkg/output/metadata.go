@@ -0,0 +1,57 @@
+package output
+
+import (
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+)
+
+// RunMetadata describes the tool build and the machine a report was
+// produced on, so archived reports can be compared across runners
+type RunMetadata struct {
+	ToolVersion    string   `json:"toolVersion"`
+	GoVersion      string   `json:"goVersion"`
+	Hostname       string   `json:"hostname"`
+	OS             string   `json:"os"`
+	Arch           string   `json:"arch"`
+	SourceIP       string   `json:"sourceIp,omitempty"`
+	LocalAddresses []string `json:"localAddresses,omitempty"`
+}
+
+// NewRunMetadata collects metadata about the current runner. SourceIP is the
+// local address the OS would use to reach host:port over the forced address
+// family, if any; it is determined with a UDP socket, so nothing is sent.
+func NewRunMetadata(toolVersion, host string, port int, family string) *RunMetadata {
+	metadata := &RunMetadata{
+		ToolVersion: toolVersion,
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		metadata.Hostname = hostname
+	}
+
+	if host != "" && port > 0 {
+		if conn, err := net.Dial(FamilyNetwork("udp", family), net.JoinHostPort(host, strconv.Itoa(port))); err == nil {
+			if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+				metadata.SourceIP = addr.IP.String()
+			}
+			conn.Close()
+		}
+	}
+
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			metadata.LocalAddresses = append(metadata.LocalAddresses, ipNet.String())
+		}
+	}
+
+	return metadata
+}
@@ -0,0 +1,118 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Nagios plugin exit codes
+const (
+	NagiosOK       = 0
+	NagiosWarning  = 1
+	NagiosCritical = 2
+	NagiosUnknown  = 3
+)
+
+// nagiosStates names the plugin exit codes in the status line
+var nagiosStates = map[int]string{
+	NagiosOK:       "OK",
+	NagiosWarning:  "WARNING",
+	NagiosCritical: "CRITICAL",
+	NagiosUnknown:  "UNKNOWN",
+}
+
+// PrintNagios prints the report as a Nagios/Icinga plugin status line with
+// perfdata and returns the plugin exit code: CRITICAL when a check failed,
+// WARNING when one warned, UNKNOWN when the run was interrupted or ran no
+// checks, OK otherwise
+func PrintNagios(report *TestReport, interrupted bool) int {
+	state := NagiosOK
+	var problems []string
+	for _, result := range report.Results {
+		switch result.Status {
+		case StatusFail:
+			state = NagiosCritical
+			problems = append(problems, nagiosProblem(result))
+		case StatusWarn:
+			if state == NagiosOK {
+				state = NagiosWarning
+			}
+			problems = append(problems, nagiosProblem(result))
+		}
+	}
+
+	var text string
+	switch {
+	case interrupted:
+		state = NagiosUnknown
+		text = "run interrupted before all checks completed"
+	case len(report.Results) == 0:
+		state = NagiosUnknown
+		text = "no checks were run"
+	case len(problems) > 0:
+		text = strings.Join(problems, "; ")
+		if report.Summary.Dependent > 0 {
+			text += fmt.Sprintf("; %d dependent checks skipped", report.Summary.Dependent)
+		}
+	default:
+		text = fmt.Sprintf("%d checks passed", report.Summary.Passed)
+	}
+
+	fmt.Printf("S3 %s - %s: %s | %s\n", nagiosStates[state], report.Config.Target(), nagiosText(text), strings.Join(nagiosPerfdata(report), " "))
+	return state
+}
+
+// nagiosProblem describes a failed or warning check for the status line
+func nagiosProblem(result TestResult) string {
+	if result.Error == "" {
+		return fmt.Sprintf("%s %s", result.TestName, result.Status)
+	}
+	return fmt.Sprintf("%s: %s", result.TestName, result.Error)
+}
+
+// nagiosText makes text safe for the status line, where a pipe starts the
+// perfdata and a newline the long output
+func nagiosText(text string) string {
+	text = strings.ReplaceAll(text, "|", "/")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// nagiosPerfdata returns the perfdata of the report: the total duration,
+// the duration of every check that ran, the time to first byte of the
+// authentication request and the days until the certificate expires, with
+// the thresholds the TLS check warns at
+func nagiosPerfdata(report *TestReport) []string {
+	perfdata := []string{fmt.Sprintf("time=%.3fs;;;0", report.Duration.Seconds())}
+	for _, result := range report.Results {
+		if result.Status == StatusSkip {
+			continue
+		}
+		perfdata = append(perfdata, fmt.Sprintf("%s=%.2fms;;;0", nagiosLabel(result.TestName), float64(result.Duration.Microseconds())/1000))
+
+		switch details := result.Details.(type) {
+		case AuthResult:
+			perfdata = append(perfdata, fmt.Sprintf("auth_ttfb=%.2fms;;;0", details.TTFBMs))
+		case TLSResult:
+			if details.Certificate.NotAfter.IsZero() {
+				continue
+			}
+			perfdata = append(perfdata, fmt.Sprintf("cert_days=%d;%d:;%d:", details.Certificate.DaysUntilExpiry, report.Config.CertWarnDays, report.Config.CertCritDays))
+		}
+	}
+	return perfdata
+}
+
+// nagiosLabel turns a check name into a perfdata label, for example
+// "Bucket Authentication Check" into "bucket_authentication"
+func nagiosLabel(testName string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSuffix(testName, " Check")) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		case sb.Len() > 0 && !strings.HasSuffix(sb.String(), "_"):
+			sb.WriteByte('_')
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "_")
+}
@@ -0,0 +1,37 @@
+package output
+
+import "strings"
+
+// redacted replaces a masked credential in written reports
+const redacted = "REDACTED"
+
+// Redacted returns a copy of the config with its credentials masked. The
+// access key keeps its last four characters, so reports can still tell keys
+// apart; the secret key and session token are replaced entirely.
+func (c Config) Redacted() Config {
+	if c.AccessKey != "" {
+		if len(c.AccessKey) > 8 {
+			c.AccessKey = strings.Repeat("*", len(c.AccessKey)-4) + c.AccessKey[len(c.AccessKey)-4:]
+		} else {
+			c.AccessKey = redacted
+		}
+	}
+	if c.SecretKey != "" {
+		c.SecretKey = redacted
+	}
+	if c.SessionToken != "" {
+		c.SessionToken = redacted
+	}
+	return c
+}
+
+// sanitize returns the report as it is written to disk: with its credentials
+// masked, unless --include-credentials was given
+func sanitize(report *TestReport) *TestReport {
+	if report.Config.IncludeCredentials {
+		return report
+	}
+	sanitized := *report
+	sanitized.Config = report.Config.Redacted()
+	return &sanitized
+}
@@ -2,6 +2,11 @@ package output
 
 import (
 	"crypto/x509"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,13 +20,136 @@ const (
 	StatusSkip Status = "SKIP"
 )
 
+// Failure kinds of results that failed for a reason other than the S3 API
+// response, set in TestResult.FailureKind
+const (
+	// FailureMaintenance marks a result failed by a maintenance page or a 503
+	// asking the client to retry later
+	FailureMaintenance = "maintenance"
+
+	// FailureNonS3Response marks a result failed by an HTML page served by
+	// something other than the S3 API, such as a proxy or load balancer
+	FailureNonS3Response = "non-s3-response"
+
+	// FailureRateLimited marks a result failed because the endpoint throttled
+	// the requests or the quota ran out, as its rate-limit headers show
+	FailureRateLimited = "rate-limited"
+)
+
 // TestResult represents a single test result
 type TestResult struct {
-	TestName string        `json:"testName"`
-	Status   Status        `json:"status"`
-	Duration time.Duration `json:"duration"`
-	Error    string        `json:"error,omitempty"`
-	Details  interface{}   `json:"details,omitempty"`
+	TestName  string        `json:"testName"`
+	Status    Status        `json:"status"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+	Details   interface{}   `json:"details,omitempty"`
+	StartTime time.Time     `json:"startTime"`
+	EndTime   time.Time     `json:"endTime"`
+	Sequence  uint64        `json:"sequence"`
+	Target    string        `json:"target"`
+
+	// SLOViolations lists the SLO thresholds this result did not meet
+	SLOViolations []string `json:"sloViolations,omitempty"`
+
+	// Attempts is the number of times the check ran with --retries, and
+	// AttemptErrors the errors of the attempts before the last one
+	Attempts      int      `json:"attempts,omitempty"`
+	AttemptErrors []string `json:"attemptErrors,omitempty"`
+
+	// SlowOperations lists the HTTP requests of this check that exceeded
+	// --slow-threshold
+	SlowOperations []HTTPOperation `json:"slowOperations,omitempty"`
+
+	// FailureKind classifies a failure caused by the endpoint not answering
+	// as S3, so it is not mistaken for a credential or protocol problem
+	FailureKind string `json:"failureKind,omitempty"`
+
+	// SkippedBecause names the failed check that made this one pointless to
+	// run; Error then gives the reason
+	SkippedBecause string `json:"skippedBecause,omitempty"`
+
+	// PolicyFailure marks a failure that does not keep the checks depending
+	// on this one from running, such as a revoked certificate the TLS
+	// handshake still accepts
+	PolicyFailure bool `json:"-"`
+
+	// RateLimit holds the rate-limit headers of this check's responses: of
+	// the first throttled one, or else of the last one that had any
+	RateLimit *RateLimitInfo `json:"rateLimit,omitempty"`
+
+	// Baseline is the same check in the --baseline report
+	Baseline *BaselineResult `json:"baseline,omitempty"`
+}
+
+// RateLimitInfo is the rate-limit and quota information of a response, from
+// its x-ratelimit-*, RateLimit-*, x-amz-* and other quota headers and its
+// Retry-After header. Window names the limit window, such as "minute", when
+// the endpoint reports several and Remaining is that of the tightest one.
+type RateLimitInfo struct {
+	StatusCode        int               `json:"statusCode"`
+	Limit             *int64            `json:"limit,omitempty"`
+	Remaining         *int64            `json:"remaining,omitempty"`
+	Window            string            `json:"window,omitempty"`
+	ResetAt           *time.Time        `json:"resetAt,omitempty"`
+	RetryAfterSeconds int               `json:"retryAfterSeconds,omitempty"`
+	Headers           map[string]string `json:"headers"`
+
+	// Exhausted is set when the response was throttled: HTTP 429, no
+	// requests remaining, or a 503 carrying rate-limit headers
+	Exhausted bool `json:"exhausted"`
+}
+
+// Summary formats the limit, what remains of it and when it resets
+func (r *RateLimitInfo) Summary() string {
+	var parts []string
+	switch {
+	case r.Remaining != nil && r.Limit != nil:
+		parts = append(parts, fmt.Sprintf("%d of %d requests left", *r.Remaining, *r.Limit))
+	case r.Remaining != nil:
+		parts = append(parts, fmt.Sprintf("%d requests left", *r.Remaining))
+	case r.Limit != nil:
+		parts = append(parts, fmt.Sprintf("limit %d requests", *r.Limit))
+	}
+	if r.Window != "" && len(parts) > 0 {
+		parts[0] += " per " + r.Window
+	}
+	if r.ResetAt != nil {
+		parts = append(parts, "resets "+r.ResetAt.Local().Format("15:04:05"))
+	}
+	if r.RetryAfterSeconds > 0 {
+		parts = append(parts, fmt.Sprintf("Retry-After %ds", r.RetryAfterSeconds))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("HTTP %d", r.StatusCode)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// HTTPOperation is one HTTP request traced with --slow-threshold. TTFBMs is
+// measured from the start of the request, so it includes DNS, connect and
+// TLS; TransferMs is the time spent reading the response body.
+type HTTPOperation struct {
+	Check            string    `json:"check,omitempty"`
+	Method           string    `json:"method"`
+	Host             string    `json:"host"`
+	Path             string    `json:"path"`
+	StatusCode       int       `json:"statusCode,omitempty"`
+	StartTime        time.Time `json:"startTime"`
+	DNSMs            float64   `json:"dnsMs"`
+	ConnectMs        float64   `json:"connectMs"`
+	TLSHandshakeMs   float64   `json:"tlsHandshakeMs"`
+	TTFBMs           float64   `json:"ttfbMs"`
+	TransferMs       float64   `json:"transferMs"`
+	TotalMs          float64   `json:"totalMs"`
+	ConnectionReused bool      `json:"connectionReused"`
+	Slow             bool      `json:"slow"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// Breakdown formats the per-phase timing of the operation
+func (o HTTPOperation) Breakdown() string {
+	return fmt.Sprintf("DNS %.2fms | connect %.2fms | TLS %.2fms | first byte %.2fms | transfer %.2fms | total %.2fms",
+		o.DNSMs, o.ConnectMs, o.TLSHandshakeMs, o.TTFBMs, o.TransferMs, o.TotalMs)
 }
 
 // DNSResult contains DNS resolution details
@@ -34,54 +162,995 @@ type DNSResult struct {
 
 // TCPResult contains TCP connectivity details
 type TCPResult struct {
-	Host           string `json:"host"`
-	Port           int    `json:"port"`
-	Connected      bool   `json:"connected"`
-	ConnectionTime int64  `json:"connectionTimeMs"`
-	LocalAddr      string `json:"localAddr,omitempty"`
-	RemoteAddr     string `json:"remoteAddr,omitempty"`
+	Host           string               `json:"host"`
+	Port           int                  `json:"port"`
+	Connected      bool                 `json:"connected"`
+	ConnectionTime int64                `json:"connectionTimeMs"`
+	LocalAddr      string               `json:"localAddr,omitempty"`
+	RemoteAddr     string               `json:"remoteAddr,omitempty"`
+	Proxy          string               `json:"proxy,omitempty"`
+	Samples        *TCPSampleStats      `json:"samples,omitempty"`
+	HappyEyeballs  *HappyEyeballsResult `json:"happyEyeballs,omitempty"`
+}
+
+// TCPSampleStats contains latency statistics over repeated TCP handshakes
+type TCPSampleStats struct {
+	Count                int     `json:"count"`
+	Successful           int     `json:"successful"`
+	Failed               int     `json:"failed"`
+	MinMs                float64 `json:"minMs"`
+	AvgMs                float64 `json:"avgMs"`
+	MaxMs                float64 `json:"maxMs"`
+	JitterMs             float64 `json:"jitterMs"`
+	SuspectedRetransmits int     `json:"suspectedRetransmits"`
+	PacketLossPercent    float64 `json:"packetLossPercent"`
+}
+
+// HappyEyeballsResult contains the outcome of an RFC 8305 dual-stack connection race
+type HappyEyeballsResult struct {
+	WinningFamily  string                `json:"winningFamily,omitempty"`
+	WinningAddress string                `json:"winningAddress,omitempty"`
+	MarginMs       float64               `json:"marginMs"`
+	IPv6           *AddressFamilyOutcome `json:"ipv6,omitempty"`
+	IPv4           *AddressFamilyOutcome `json:"ipv4,omitempty"`
+	Warning        string                `json:"warning,omitempty"`
+	Error          string                `json:"error,omitempty"`
+}
+
+// AddressFamilyOutcome contains the connection outcome for one address family
+type AddressFamilyOutcome struct {
+	Addresses     int     `json:"addresses"`
+	Connected     bool    `json:"connected"`
+	Address       string  `json:"address,omitempty"`
+	StartOffsetMs float64 `json:"startOffsetMs"`
+	ConnectMs     float64 `json:"connectMs,omitempty"`
+	Error         string  `json:"error,omitempty"`
 }
 
 // CertificateInfo contains SSL/TLS certificate details
 type CertificateInfo struct {
-	Subject            string    `json:"subject"`
-	Issuer             string    `json:"issuer"`
-	NotBefore          time.Time `json:"notBefore"`
-	NotAfter           time.Time `json:"notAfter"`
-	SANs               []string  `json:"sans"`
-	SerialNumber       string    `json:"serialNumber"`
-	SignatureAlgorithm string    `json:"signatureAlgorithm"`
-	DNSNames           []string  `json:"dnsNames"`
-	EmailAddresses     []string  `json:"emailAddresses"`
-	IPAddresses        []string  `json:"ipAddresses"`
-	URIs               []string  `json:"uris"`
-	IsExpired          bool      `json:"isExpired"`
-	DaysUntilExpiry    int       `json:"daysUntilExpiry"`
+	Subject            string            `json:"subject"`
+	Issuer             string            `json:"issuer"`
+	NotBefore          time.Time         `json:"notBefore"`
+	NotAfter           time.Time         `json:"notAfter"`
+	SANs               []string          `json:"sans"`
+	SerialNumber       string            `json:"serialNumber"`
+	SignatureAlgorithm string            `json:"signatureAlgorithm"`
+	DNSNames           []string          `json:"dnsNames"`
+	EmailAddresses     []string          `json:"emailAddresses"`
+	IPAddresses        []string          `json:"ipAddresses"`
+	URIs               []string          `json:"uris"`
+	IsExpired          bool              `json:"isExpired"`
+	DaysUntilExpiry    int               `json:"daysUntilExpiry"`
 	Chain              []CertificateInfo `json:"chain,omitempty"`
+
+	// Revocation is the OCSP status of the leaf certificate
+	Revocation *RevocationStatus `json:"revocation,omitempty"`
+}
+
+// OCSP revocation states of a certificate
+const (
+	RevocationGood        = "good"
+	RevocationRevoked     = "revoked"
+	RevocationUnknown     = "unknown"
+	RevocationUnavailable = "unavailable"
+	RevocationNotChecked  = "not-checked"
+)
+
+// RevocationStatus is the outcome of an OCSP revocation check. Source is
+// "stapled" when the server sent the response in the handshake and
+// "responder" when it was fetched from the OCSP URL of the certificate.
+type RevocationStatus struct {
+	Status     string     `json:"status"`
+	Source     string     `json:"source,omitempty"`
+	Responder  string     `json:"responder,omitempty"`
+	ProducedAt *time.Time `json:"producedAt,omitempty"`
+	NextUpdate *time.Time `json:"nextUpdate,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	Error      string     `json:"error,omitempty"`
 }
 
 // TLSResult contains TLS certificate check details
 type TLSResult struct {
-	Host          string            `json:"host"`
-	Port          int               `json:"port"`
-	Certificate   CertificateInfo   `json:"certificate"`
-	Verified      bool              `json:"verified"`
-	VerifyError   string            `json:"verifyError,omitempty"`
-	TLSVersion    string            `json:"tlsVersion"`
-	CipherSuite   string            `json:"cipherSuite"`
-	PeerCerts     []CertificateInfo `json:"peerCerts"`
+	Host        string               `json:"host"`
+	Port        int                  `json:"port"`
+	Certificate CertificateInfo      `json:"certificate"`
+	Verified    bool                 `json:"verified"`
+	VerifyError string               `json:"verifyError,omitempty"`
+	TLSVersion  string               `json:"tlsVersion"`
+	CipherSuite string               `json:"cipherSuite"`
+	PeerCerts   []CertificateInfo    `json:"peerCerts"`
+	Resumption  *TLSResumptionResult `json:"resumption,omitempty"`
+	SNIProbes   []SNIProbeResult     `json:"sniProbes,omitempty"`
+
+	// ClientCertRequested is set when the server asked for a client
+	// certificate; ClientCertificate is the subject of the one presented
+	ClientCertRequested bool   `json:"clientCertRequested"`
+	ClientCertificate   string `json:"clientCertificate,omitempty"`
+
+	// ChainAnalysis tells whether the certificates the server sent form a
+	// chain to a trusted root, when verification failed or was skipped
+	ChainAnalysis *ChainAnalysis `json:"chainAnalysis,omitempty"`
+
+	// Expiry classifies the certificate's remaining validity by the
+	// --cert-warn-days and --cert-crit-days thresholds
+	Expiry string `json:"expiry,omitempty"`
+
+	// BucketHost tells whether the certificate covers the host of
+	// virtual-hosted requests, bucket.endpoint
+	BucketHost *BucketHostCoverage `json:"bucketHost,omitempty"`
+}
+
+// Expiry classes of TLSResult
+const (
+	ExpiryValid    = "valid"
+	ExpiryWarning  = "expiring"
+	ExpiryCritical = "critical"
+	ExpiryExpired  = "expired"
+)
+
+// How a certificate matches the virtual-hosted bucket host
+const (
+	// BucketHostExact means a SAN names the bucket host
+	BucketHostExact = "exact"
+
+	// BucketHostWildcard means a one-label wildcard SAN covers the bucket host
+	BucketHostWildcard = "wildcard"
+
+	// BucketHostDottedBucket means the endpoint's wildcard SAN would cover
+	// the bucket host if the bucket name had no dots
+	BucketHostDottedBucket = "dotted-bucket"
+
+	// BucketHostNotCovered means no SAN covers the bucket host
+	BucketHostNotCovered = "not-covered"
+)
+
+// BucketHostCoverage contains whether the certificate served for the
+// virtual-hosted bucket host covers it, and the SAN that matched or, for a
+// dotted bucket name, the wildcard that falls short
+type BucketHostCoverage struct {
+	Host    string `json:"host"`
+	Covered bool   `json:"covered"`
+	Match   string `json:"match"`
+	SAN     string `json:"san,omitempty"`
+	Subject string `json:"subject,omitempty"`
+}
+
+// Chain statuses of ChainAnalysis
+const (
+	// ChainComplete means the served certificates verify on their own
+	ChainComplete = "complete"
+
+	// ChainIncomplete means the server leaves out intermediates which, once
+	// fetched through AIA, complete the chain to a trusted root
+	ChainIncomplete = "incomplete"
+
+	// ChainSelfSigned means the server certificate is its own issuer
+	ChainSelfSigned = "self-signed"
+
+	// ChainUntrusted means the chain does not lead to a trusted root, even
+	// with any intermediates fetched through AIA
+	ChainUntrusted = "untrusted"
+)
+
+// ChainAnalysis contains the completeness of the served certificate chain.
+// Missing lists the subjects of the intermediates the server did not send
+// that were fetched from the Authority Information Access URLs.
+type ChainAnalysis struct {
+	Status     string     `json:"status"`
+	Sent       int        `json:"sentCertificates"`
+	Missing    []string   `json:"missing,omitempty"`
+	AIAFetches []AIAFetch `json:"aiaFetches,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// AIAFetch is an issuer certificate requested from a caIssuers URL
+type AIAFetch struct {
+	URL     string `json:"url"`
+	Subject string `json:"subject,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// SNIProbeResult contains the certificate served for a handshake without SNI
+type SNIProbeResult struct {
+	Variant        string `json:"variant"`
+	Address        string `json:"address"`
+	Subject        string `json:"subject,omitempty"`
+	Fingerprint    string `json:"fingerprintSha256,omitempty"`
+	MatchesSNICert bool   `json:"matchesSniCertificate"`
+	CoversHostname bool   `json:"coversHostname"`
+	CoversIP       bool   `json:"coversIp"`
+	Error          string `json:"error,omitempty"`
+}
+
+// TLSResumptionResult contains TLS session resumption test details
+type TLSResumptionResult struct {
+	TLSVersion         string  `json:"tlsVersion,omitempty"`
+	TicketIssued       bool    `json:"ticketIssued"`
+	Resumed            bool    `json:"resumed"`
+	FullHandshakeMs    float64 `json:"fullHandshakeMs"`
+	ResumedHandshakeMs float64 `json:"resumedHandshakeMs,omitempty"`
+	EarlyData          string  `json:"earlyData"`
+	Error              string  `json:"error,omitempty"`
 }
 
 // AuthResult contains authentication check details
 type AuthResult struct {
-	Success       bool   `json:"success"`
-	AuthType      string `json:"authType"`
-	BucketExists  bool   `json:"bucketExists"`
-	AccessGranted bool   `json:"accessGranted"`
+	Success       bool    `json:"success"`
+	AuthType      string  `json:"authType"`
+	BucketExists  bool    `json:"bucketExists"`
+	AccessGranted bool    `json:"accessGranted"`
+	StatusCode    int     `json:"statusCode"`
+	ResponseTime  int64   `json:"responseTimeMs"`
+	TTFBMs        float64 `json:"ttfbMs"`
+	Provider      string  `json:"provider,omitempty"`
+	Endpoint      string  `json:"endpoint"`
+
+	// Latency breakdown of the request; DNS, connect and TLS are zero when
+	// the connection was reused or the phase did not apply
+	DNSMs            float64 `json:"dnsMs"`
+	ConnectMs        float64 `json:"connectMs"`
+	TLSHandshakeMs   float64 `json:"tlsHandshakeMs"`
+	TotalMs          float64 `json:"totalMs"`
+	ConnectionReused bool    `json:"connectionReused"`
+
+	// RateLimit holds the rate-limit headers of the response, if it had any
+	RateLimit *RateLimitInfo `json:"rateLimit,omitempty"`
+
+	// ServerDate is the Date header of the response and ClockSkewSeconds
+	// how far the local clock was ahead of it, negative when behind; both
+	// are empty when the server sent no date
+	ServerDate       string `json:"serverDate,omitempty"`
+	ClockSkewSeconds int64  `json:"clockSkewSeconds,omitempty"`
+
+	// DetectedRegion is the region the bucket answered from when it was
+	// not --region and the request was retried signed for it
+	DetectedRegion string `json:"detectedRegion,omitempty"`
+
+	// Operation is the request that decided the result: HeadBucket, or
+	// ListObjectsV2 when HEAD Bucket was denied with HeadBucketStatus
+	Operation        string `json:"operation"`
+	HeadBucketStatus int    `json:"headBucketStatus,omitempty"`
+}
+
+// ExpectContinueResult contains Expect: 100-continue check details
+type ExpectContinueResult struct {
+	Key             string  `json:"key"`
+	StatusCode      int     `json:"statusCode"`
+	Got100          bool    `json:"got100Continue"`
+	InterimMs       float64 `json:"interimResponseMs,omitempty"`
+	BodyDelayMs     float64 `json:"bodyDelayMs"`
+	FinalResponseMs float64 `json:"finalResponseMs"`
+	WaitTimeoutMs   int64   `json:"waitTimeoutMs"`
+	Behavior        string  `json:"behavior"`
+	CleanedUp       bool    `json:"cleanedUp"`
+}
+
+// ObjectResult contains object round-trip check details
+type ObjectResult struct {
+	Key          string              `json:"key"`
+	Size         int                 `json:"size"`
+	Phases       []ObjectPhaseResult `json:"phases"`
+	WriteAccess  bool                `json:"writeAccess"`
+	ReadAccess   bool                `json:"readAccess"`
+	DeleteAccess bool                `json:"deleteAccess"`
+	ContentMatch bool                `json:"contentMatch"`
+}
+
+// ObjectPhaseResult contains the outcome of a single object operation
+type ObjectPhaseResult struct {
+	Operation  string `json:"operation"`
+	Success    bool   `json:"success"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	LatencyMs  int64  `json:"latencyMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// RangedGetResult contains parallel ranged GET check details
+type RangedGetResult struct {
+	Key             string          `json:"key"`
+	Size            int64           `json:"size"`
+	PartSize        int64           `json:"partSize"`
+	Concurrency     int             `json:"concurrency"`
+	SHA256          string          `json:"sha256"`
+	UploadMs        int64           `json:"uploadMs"`
+	DownloadMs      int64           `json:"downloadMs"`
+	ThroughputMiBps float64         `json:"throughputMiBps"`
+	IntegrityOK     bool            `json:"integrityOk"`
+	FailedParts     int             `json:"failedParts"`
+	Parts           []RangedGetPart `json:"parts"`
+	DeleteError     string          `json:"deleteError,omitempty"`
+}
+
+// RangedGetPart contains the outcome of one ranged GET
+type RangedGetPart struct {
+	Index      int    `json:"index"`
+	Range      string `json:"range"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	LatencyMs  int64  `json:"latencyMs"`
+	EndMs      int64  `json:"endMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// TransferEncodingResult contains transfer encoding probe details
+type TransferEncodingResult struct {
+	Cases []TransferCaseResult `json:"cases"`
+}
+
+// TransferCaseResult contains the provider behavior for one upload framing
+type TransferCaseResult struct {
+	Name           string `json:"name"`
+	Chunked        bool   `json:"chunked"`
+	Sent           int    `json:"sentBytes"`
+	StatusCode     int    `json:"statusCode,omitempty"`
+	Accepted       bool   `json:"accepted"`
+	StoredSize     int    `json:"storedBytes"`
+	Verified       bool   `json:"verified"`
+	TransportError bool   `json:"transportError"`
+	LatencyMs      int64  `json:"latencyMs"`
+	Error          string `json:"error,omitempty"`
+}
+
+// ContentEncodingResult contains Content-Encoding passthrough check details
+type ContentEncodingResult struct {
+	Key           string                    `json:"key"`
+	UploadedBytes int                       `json:"uploadedBytes"`
+	Downloads     []ContentEncodingDownload `json:"downloads"`
+}
+
+// ContentEncodingDownload contains the outcome of one download of the gzip object
+type ContentEncodingDownload struct {
+	AcceptEncoding  string `json:"acceptEncoding"`
+	StatusCode      int    `json:"statusCode,omitempty"`
+	ContentEncoding string `json:"contentEncoding"`
+	ReceivedBytes   int    `json:"receivedBytes"`
+	ByteIdentical   bool   `json:"byteIdentical"`
+	HeaderPreserved bool   `json:"headerPreserved"`
+	Error           string `json:"error,omitempty"`
+}
+
+// CacheHeaderResult contains cache header check details
+type CacheHeaderResult struct {
+	Key           string                  `json:"key"`
+	Headers       []CacheHeaderComparison `json:"headers"`
+	CacheDetected bool                    `json:"cacheDetected"`
+	CDNHeaders    map[string]string       `json:"cdnHeaders,omitempty"`
+}
+
+// Verdicts of the SDK parity check
+const (
+	// ParityConsistent means both clients got the same outcome for every
+	// operation, so any failure is on the endpoint's side
+	ParityConsistent = "consistent"
+
+	// ParityS3TesterArtifact means an operation failed only with s3tester's
+	// own signer, so the failure is an artifact of this tool
+	ParityS3TesterArtifact = "s3tester-artifact"
+
+	// ParitySDKOnly means an operation failed only with the AWS SDK, so
+	// SDK-based applications may fail where s3tester passes
+	ParitySDKOnly = "sdk-only"
+
+	// ParityDifferent means both clients failed an operation, differently
+	ParityDifferent = "different"
+)
+
+// PolicyFingerprintResult contains the fingerprint of the bucket's access
+// settings. Baseline is set on the first watch cycle; later cycles list the
+// settings that changed since the previous one.
+type PolicyFingerprintResult struct {
+	Fingerprint         string                 `json:"fingerprint"`
+	Components          []FingerprintComponent `json:"components"`
+	Baseline            bool                   `json:"baseline,omitempty"`
+	PreviousFingerprint string                 `json:"previousFingerprint,omitempty"`
+	Changes             []FingerprintChange    `json:"changes,omitempty"`
+}
+
+// Component returns the named component, or nil
+func (r *PolicyFingerprintResult) Component(name string) *FingerprintComponent {
+	for i := range r.Components {
+		if r.Components[i].Name == name {
+			return &r.Components[i]
+		}
+	}
+	return nil
+}
+
+// FingerprintComponent is one access setting: the bucket policy, the ACL or
+// the Block Public Access configuration. Hash is the SHA-256 of its canonical
+// form when present. Error is set when it could not be read this cycle, in
+// which case State and Hash are those of the previous cycle, if any.
+type FingerprintComponent struct {
+	Name  string `json:"name"`
+	State string `json:"state,omitempty"`
+	Hash  string `json:"hash,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Describe returns the state with the start of the hash, if any
+func (c FingerprintComponent) Describe() string {
+	if len(c.Hash) >= 12 {
+		return c.State + " " + c.Hash[:12]
+	}
+	return c.State
+}
+
+// FingerprintChange is a setting whose fingerprint changed between cycles
+type FingerprintChange struct {
+	Component string `json:"component"`
+	Before    string `json:"before"`
+	After     string `json:"after"`
+}
+
+// CDNResult contains CDN delivery check details: the CDN host's DNS and TLS,
+// and the object as the origin and the CDN returned it
+type CDNResult struct {
+	URL       string                `json:"url"`
+	Host      string                `json:"host"`
+	Provider  string                `json:"provider,omitempty"`
+	Addresses []string              `json:"addresses,omitempty"`
+	CNAME     string                `json:"cname,omitempty"`
+	TLS       *CDNTLSResult         `json:"tls,omitempty"`
+	Key       string                `json:"key,omitempty"`
+	ObjectURL string                `json:"objectUrl,omitempty"`
+	Range     string                `json:"range,omitempty"`
+	Origin    CDNFetch              `json:"origin"`
+	Fetches   []CDNFetch            `json:"fetches,omitempty"`
+	BodyMatch bool                  `json:"bodyMatch"`
+	Headers   []CDNHeaderComparison `json:"headers,omitempty"`
+	Cached    bool                  `json:"cached"`
+}
+
+// CDNTLSResult is the TLS handshake with the CDN host
+type CDNTLSResult struct {
+	Version     string           `json:"version,omitempty"`
+	CipherSuite string           `json:"cipherSuite,omitempty"`
+	Verified    bool             `json:"verified"`
+	Certificate *CertificateInfo `json:"certificate,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// CDNFetch is one GET of the object from the origin or through the CDN.
+// CacheStatus is HIT, MISS or the CDN's own term; Headers holds the compared
+// and the CDN headers of the response.
+type CDNFetch struct {
+	StatusCode  int               `json:"statusCode,omitempty"`
+	Size        int64             `json:"size"`
+	SHA256      string            `json:"sha256,omitempty"`
+	CacheStatus string            `json:"cacheStatus,omitempty"`
+	Age         string            `json:"age,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Duration    time.Duration     `json:"duration,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// CDNHeaderComparison compares a response header of the origin with the one
+// the CDN returned
+type CDNHeaderComparison struct {
+	Name   string `json:"name"`
+	Origin string `json:"origin"`
+	CDN    string `json:"cdn"`
+	Match  bool   `json:"match"`
+}
+
+// SDKParityResult contains SDK parity check details
+type SDKParityResult struct {
+	Operations []ParityOperation `json:"operations"`
+	Verdict    string            `json:"verdict"`
+}
+
+// ParityOperation compares the outcome of an operation sent with s3tester's
+// hand-rolled signer with that of the official AWS SDK
+type ParityOperation struct {
+	Operation string        `json:"operation"`
+	S3Tester  ParityOutcome `json:"s3tester"`
+	SDK       ParityOutcome `json:"sdk"`
+	Match     bool          `json:"match"`
+}
+
+// ParityOutcome is the result of one operation with one client: the HTTP
+// status and S3 error code, or the error when no response arrived
+type ParityOutcome struct {
+	StatusCode int    `json:"statusCode,omitempty"`
+	ErrorCode  string `json:"errorCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Succeeded reports whether the operation succeeded
+func (o ParityOutcome) Succeeded() bool {
+	return o.Error == "" && o.StatusCode >= 200 && o.StatusCode < 300
+}
+
+// String formats the outcome as its status and error code
+func (o ParityOutcome) String() string {
+	switch {
+	case o.StatusCode == 0:
+		return "error: " + o.Error
+	case o.ErrorCode != "":
+		return fmt.Sprintf("%d %s", o.StatusCode, o.ErrorCode)
+	default:
+		return fmt.Sprintf("%d", o.StatusCode)
+	}
+}
+
+// CacheHeaderComparison compares a caching header sent on upload with the one returned
+type CacheHeaderComparison struct {
+	Name      string `json:"name"`
+	Sent      string `json:"sent"`
+	Received  string `json:"received"`
+	Unchanged bool   `json:"unchanged"`
+}
+
+// PermissionsResult contains permission matrix check details
+type PermissionsResult struct {
+	Key        string            `json:"key"`
+	Allowed    int               `json:"allowed"`
+	Denied     int               `json:"denied"`
+	Operations []PermissionProbe `json:"operations"`
+}
+
+// VersionedDeleteResult contains the DeleteObject semantics observed on a
+// versioned bucket, step by step against the AWS behavior
+type VersionedDeleteResult struct {
+	Key            string `json:"key"`
+	Versioning     string `json:"versioning"`
+	VersionID      string `json:"versionId,omitempty"`
+	DeleteMarkerID string `json:"deleteMarkerId,omitempty"`
+	Steps          []Step `json:"steps,omitempty"`
+	MatchesAWS     bool   `json:"matchesAws"`
+}
+
+// Step is one request of a check with the response S3 returns and the one
+// observed
+type Step struct {
+	Step     string `json:"step"`
+	Expected string `json:"expected"`
+	Observed string `json:"observed"`
+	Match    bool   `json:"match"`
+}
+
+// VersioningResult contains the bucket's versioning status and the outcome of
+// the version round trip run when versioning is enabled
+type VersioningResult struct {
+	Status     string   `json:"status"`
+	MFADelete  string   `json:"mfaDelete,omitempty"`
+	RoundTrip  string   `json:"roundTrip"`
+	Key        string   `json:"key,omitempty"`
+	VersionIDs []string `json:"versionIds,omitempty"`
+	Steps      []Step   `json:"steps,omitempty"`
+	Works      bool     `json:"works"`
+}
+
+// ObjectLockResult contains the bucket's Object Lock configuration and the
+// outcome of the retention test: "passed", "failed" or why it was not run
+type ObjectLockResult struct {
 	StatusCode    int    `json:"statusCode"`
-	ResponseTime  int64  `json:"responseTimeMs"`
-	Provider      string `json:"provider,omitempty"`
-	Endpoint      string `json:"endpoint"`
+	Enabled       bool   `json:"enabled"`
+	DefaultMode   string `json:"defaultMode,omitempty"`
+	DefaultDays   int    `json:"defaultDays,omitempty"`
+	DefaultYears  int    `json:"defaultYears,omitempty"`
+	RetentionTest string `json:"retentionTest"`
+	Key           string `json:"key,omitempty"`
+	VersionID     string `json:"versionId,omitempty"`
+	RetainUntil   string `json:"retainUntil,omitempty"`
+	Steps         []Step `json:"steps,omitempty"`
+	Works         bool   `json:"works"`
+
+	// Removed is set when the locked test version was deleted again by
+	// bypassing its governance retention
+	Removed bool `json:"removed"`
+}
+
+// SSECResult contains the outcome of the SSE-C round trip: "supported",
+// "ignored" when the object was served without its key, "rejected" when the
+// upload failed, or "broken"
+type SSECResult struct {
+	Key       string `json:"key"`
+	Algorithm string `json:"algorithm"`
+	Outcome   string `json:"outcome"`
+	Steps     []Step `json:"steps"`
+}
+
+// PresignedResult contains the outcome of the presigned URL check:
+// "supported", "not supported" when presigned requests fail, "not verified"
+// when a forged URL was accepted, or "broken"
+type PresignedResult struct {
+	Key     string `json:"key"`
+	Expires string `json:"expires"`
+	Outcome string `json:"outcome"`
+	Steps   []Step `json:"steps"`
+}
+
+// PostPolicyResult contains the policy limits of the POST policy check and
+// its outcome, named as for presigned URLs: "supported", "not supported",
+// "not verified" when an upload violating the policy was accepted, or
+// "broken"
+type PostPolicyResult struct {
+	KeyPrefix string `json:"keyPrefix"`
+	MaxSize   int    `json:"maxSize"`
+	Outcome   string `json:"outcome"`
+	Steps     []Step `json:"steps"`
+}
+
+// CopyResult contains the outcome of CopyObject and UploadPartCopy:
+// "supported", "not supported", "denied" or "broken", and the quirks seen
+// along the way
+type CopyResult struct {
+	SourceKey      string   `json:"sourceKey"`
+	CopyObject     string   `json:"copyObject"`
+	UploadPartCopy string   `json:"uploadPartCopy"`
+	Quirks         []string `json:"quirks,omitempty"`
+	Steps          []Step   `json:"steps"`
+}
+
+// BatchDeleteResult contains the outcome of DeleteObjects: "supported",
+// "not supported", "denied" or "broken", and the quirks seen along the way
+type BatchDeleteResult struct {
+	Keys          []string `json:"keys"`
+	DeleteObjects string   `json:"deleteObjects"`
+	Quirks        []string `json:"quirks,omitempty"`
+	Steps         []Step   `json:"steps"`
+}
+
+// ConditionalResult contains the validators of the test object of the range
+// and conditional GET check and the outcome of each GET
+type ConditionalResult struct {
+	Key          string `json:"key"`
+	Size         int    `json:"size"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Steps        []Step `json:"steps,omitempty"`
+}
+
+// ChecksumResult contains the steps of the checksum check and the outcome
+// per integrity mechanism
+type ChecksumResult struct {
+	Key        string              `json:"key"`
+	Mechanisms []ChecksumMechanism `json:"mechanisms"`
+	Steps      []Step              `json:"steps"`
+}
+
+// ChecksumMechanism is the outcome for one integrity mechanism: "end to end",
+// "verified" on upload, "ignored" when a wrong digest was accepted,
+// "rejected" when a correct one was refused, or "broken". For the ETag it is
+// "MD5 of the content" or "opaque".
+type ChecksumMechanism struct {
+	Name    string `json:"name"`
+	Outcome string `json:"outcome"`
+}
+
+// LifecycleResult contains the bucket's lifecycle rules and whether the
+// enabled ones expire objects and abort incomplete multipart uploads
+type LifecycleResult struct {
+	StatusCode      int             `json:"statusCode"`
+	Configured      bool            `json:"configured"`
+	Rules           []LifecycleRule `json:"rules,omitempty"`
+	Expires         bool            `json:"expires"`
+	AbortsMultipart bool            `json:"abortsMultipart"`
+}
+
+// LifecycleRule is one lifecycle rule with its actions, and the reason it is
+// invalid if it is
+type LifecycleRule struct {
+	ID      string   `json:"id"`
+	Status  string   `json:"status"`
+	Prefix  string   `json:"prefix,omitempty"`
+	Actions []string `json:"actions,omitempty"`
+	Problem string   `json:"problem,omitempty"`
+}
+
+// NotificationResult contains the bucket's event notification targets and
+// the outcome of --verify-notifications: "delivered", "not delivered",
+// "interrupted" or why it was not run
+type NotificationResult struct {
+	StatusCode    int                  `json:"statusCode"`
+	Targets       []NotificationTarget `json:"targets,omitempty"`
+	Verification  string               `json:"verification"`
+	WebhookURL    string               `json:"webhookUrl,omitempty"`
+	Key           string               `json:"key,omitempty"`
+	DeliveryMs    int64                `json:"deliveryMs,omitempty"`
+	DeliveredFrom string               `json:"deliveredFrom,omitempty"`
+}
+
+// NotificationTarget is one notification target: "sns", "sqs", "lambda",
+// "eventbridge" or "webhook"
+type NotificationTarget struct {
+	ID     string   `json:"id,omitempty"`
+	Type   string   `json:"type"`
+	ARN    string   `json:"arn,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// WebsiteResult contains the bucket's static website configuration and the
+// index document as the website endpoint and the S3 API returned it
+type WebsiteResult struct {
+	StatusCode    int          `json:"statusCode"`
+	Configured    bool         `json:"configured"`
+	IndexDocument string       `json:"indexDocument,omitempty"`
+	ErrorDocument string       `json:"errorDocument,omitempty"`
+	RedirectTo    string       `json:"redirectTo,omitempty"`
+	RoutingRules  int          `json:"routingRules,omitempty"`
+	WebsiteURL    string       `json:"websiteUrl,omitempty"`
+	Website       WebsiteFetch `json:"website"`
+	API           WebsiteFetch `json:"api"`
+	BodyMatch     bool         `json:"bodyMatch"`
+}
+
+// WebsiteFetch is one request for the index document
+type WebsiteFetch struct {
+	StatusCode  int    `json:"statusCode,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Location    string `json:"location,omitempty"`
+	SHA256      string `json:"sha256,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BucketTaggingResult contains the bucket's tags
+type BucketTaggingResult struct {
+	StatusCode int   `json:"statusCode"`
+	Tags       []Tag `json:"tags"`
+}
+
+// Tag is one bucket or object tag
+type Tag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ObjectTaggingResult contains the steps of the object tagging round trip
+// and the tags the test object was left with
+type ObjectTaggingResult struct {
+	Key   string `json:"key"`
+	Tags  []Tag  `json:"tags,omitempty"`
+	Steps []Step `json:"steps"`
+	Works bool   `json:"works"`
+}
+
+// LoggingResult contains the bucket's server access logging configuration
+type LoggingResult struct {
+	StatusCode   int    `json:"statusCode"`
+	Enabled      bool   `json:"enabled"`
+	TargetBucket string `json:"targetBucket,omitempty"`
+	TargetPrefix string `json:"targetPrefix,omitempty"`
+}
+
+// ReplicationResult contains the bucket's replication rules and the outcome
+// of the canary: "completed", "failed", "pending", "not replicated" or why it
+// was not run
+type ReplicationResult struct {
+	StatusCode        int               `json:"statusCode"`
+	Configured        bool              `json:"configured"`
+	Role              string            `json:"role,omitempty"`
+	Rules             []ReplicationRule `json:"rules,omitempty"`
+	Canary            string            `json:"canary"`
+	Key               string            `json:"key,omitempty"`
+	ReplicationStatus string            `json:"replicationStatus,omitempty"`
+	WaitMs            int64             `json:"waitMs,omitempty"`
+}
+
+// ReplicationRule is one replication rule with its destination bucket
+type ReplicationRule struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	Priority      int    `json:"priority,omitempty"`
+	Prefix        string `json:"prefix,omitempty"`
+	Destination   string `json:"destination"`
+	StorageClass  string `json:"storageClass,omitempty"`
+	DeleteMarkers string `json:"deleteMarkerReplication,omitempty"`
+}
+
+// OwnershipResult contains the bucket's Object Ownership setting; a bucket
+// without ownership controls reports the ObjectWriter default
+type OwnershipResult struct {
+	StatusCode      int    `json:"statusCode"`
+	Configured      bool   `json:"configured"`
+	ObjectOwnership string `json:"objectOwnership"`
+	ACLsDisabled    bool   `json:"aclsDisabled"`
+}
+
+// PublicAccessBlockResult contains the Block Public Access settings of the
+// bucket and of the AWS account that owns the credentials
+type PublicAccessBlockResult struct {
+	AccountID string                    `json:"accountId,omitempty"`
+	Bucket    PublicAccessBlockLookup   `json:"bucket"`
+	Account   PublicAccessBlockLookup   `json:"account"`
+	Effective PublicAccessBlockSettings `json:"effective"`
+
+	// Missing lists the settings neither level enables
+	Missing []string `json:"missing,omitempty"`
+}
+
+// PublicAccessBlockLookup is the outcome of reading one Block Public Access
+// configuration: "configured", "not configured", "denied" or "error"
+type PublicAccessBlockLookup struct {
+	Status     string                     `json:"status"`
+	StatusCode int                        `json:"statusCode,omitempty"`
+	Settings   *PublicAccessBlockSettings `json:"settings,omitempty"`
+	DeniedBy   string                     `json:"deniedBy,omitempty"`
+	Error      string                     `json:"error,omitempty"`
+}
+
+// PublicAccessBlockSettings are the four Block Public Access switches
+type PublicAccessBlockSettings struct {
+	BlockPublicAcls       bool `json:"blockPublicAcls" xml:"BlockPublicAcls"`
+	IgnorePublicAcls      bool `json:"ignorePublicAcls" xml:"IgnorePublicAcls"`
+	BlockPublicPolicy     bool `json:"blockPublicPolicy" xml:"BlockPublicPolicy"`
+	RestrictPublicBuckets bool `json:"restrictPublicBuckets" xml:"RestrictPublicBuckets"`
+}
+
+// AddressingStyleResult contains the outcome of the authentication check
+// with each addressing style
+type AddressingStyleResult struct {
+	Configured string                   `json:"configured"`
+	Working    []string                 `json:"working"`
+	Styles     []AddressingStyleOutcome `json:"styles"`
+}
+
+// AddressingStyleOutcome is the authentication check with one addressing
+// style; Error is empty when it passed
+type AddressingStyleOutcome struct {
+	Style          string `json:"style"`
+	URL            string `json:"url,omitempty"`
+	Works          bool   `json:"works"`
+	StatusCode     int    `json:"statusCode,omitempty"`
+	ResponseTimeMs int64  `json:"responseTimeMs,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// BucketLocationResult contains the region GetBucketLocation reported for
+// the bucket. Region is empty when the endpoint reports none.
+type BucketLocationResult struct {
+	LocationConstraint string `json:"locationConstraint"`
+	Region             string `json:"region"`
+	ConfiguredRegion   string `json:"configuredRegion"`
+	Match              bool   `json:"match"`
+	StatusCode         int    `json:"statusCode"`
+}
+
+// ObjectAccessResult contains the read access checks of an existing object
+// given with --object-key
+type ObjectAccessResult struct {
+	Key          string            `json:"key"`
+	Size         int64             `json:"size,omitempty"`
+	ContentType  string            `json:"contentType,omitempty"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified string            `json:"lastModified,omitempty"`
+	StorageClass string            `json:"storageClass,omitempty"`
+	VersionID    string            `json:"versionId,omitempty"`
+	Allowed      int               `json:"allowed"`
+	Denied       int               `json:"denied"`
+	Operations   []PermissionProbe `json:"operations"`
+}
+
+// PermissionProbe is the outcome of one operation of the permission matrix
+type PermissionProbe struct {
+	Operation  string `json:"operation"`
+	Action     string `json:"action"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Outcome    string `json:"outcome"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+// ArtifactInventoryResult contains test artifact inventory details
+type ArtifactInventoryResult struct {
+	Prefix      string     `json:"prefix"`
+	Total       int        `json:"total"`
+	StaleAfter  string     `json:"staleAfter"`
+	Stale       []Artifact `json:"stale,omitempty"`
+	Purged      int        `json:"purged"`
+	PurgeErrors []string   `json:"purgeErrors,omitempty"`
+}
+
+// Artifact is an object left under the reserved test prefix
+type Artifact struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	Age          string    `json:"age"`
+}
+
+// ExposureSeverity rates how serious a public access finding is
+type ExposureSeverity string
+
+const (
+	ExposureNone     ExposureSeverity = "none"
+	ExposureLow      ExposureSeverity = "low"
+	ExposureMedium   ExposureSeverity = "medium"
+	ExposureHigh     ExposureSeverity = "high"
+	ExposureCritical ExposureSeverity = "critical"
+)
+
+// ExposureRank orders severities from none (0) to critical (4)
+func ExposureRank(severity ExposureSeverity) int {
+	switch severity {
+	case ExposureLow:
+		return 1
+	case ExposureMedium:
+		return 2
+	case ExposureHigh:
+		return 3
+	case ExposureCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// AnonymousAccessResult contains anonymous access check details
+type AnonymousAccessResult struct {
+	Exposed  bool             `json:"exposed"`
+	Severity ExposureSeverity `json:"severity"`
+	Probes   []AnonymousProbe `json:"probes"`
+}
+
+// AnonymousProbe is the outcome of one unauthenticated request
+type AnonymousProbe struct {
+	Name       string           `json:"name"`
+	Method     string           `json:"method"`
+	Key        string           `json:"key,omitempty"`
+	StatusCode int              `json:"statusCode"`
+	Allowed    bool             `json:"allowed"`
+	Severity   ExposureSeverity `json:"severity,omitempty"`
+	Detail     string           `json:"detail"`
+}
+
+// CapabilityResult contains capability requirements check details
+type CapabilityResult struct {
+	Capabilities []CapabilityStatus  `json:"capabilities"`
+	Requirements []RequirementResult `json:"requirements"`
+}
+
+// CapabilityStatus describes how the endpoint provides one capability
+type CapabilityStatus struct {
+	Name      string `json:"name"`
+	Supported bool   `json:"supported"`
+	Value     string `json:"value"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// RequirementResult contains the outcome of one --require expression
+type RequirementResult struct {
+	Requirement string `json:"requirement"`
+	Met         bool   `json:"met"`
+	Detected    string `json:"detected"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// CapabilityProbeResult contains capability probe details: the outcome of
+// each probe and the capabilities derived from them
+type CapabilityProbeResult struct {
+	Probes    []CapabilityProbe     `json:"probes"`
+	Generated GeneratedCapabilities `json:"generated"`
+}
+
+// Outcomes of a capability probe
+const (
+	ProbeSupported    = "supported"
+	ProbeNotSupported = "not-supported"
+	ProbeDenied       = "denied"
+	ProbeInconclusive = "inconclusive"
+	ProbeSkipped      = "skipped"
+)
+
+// CapabilityProbe is the outcome of probing one capability
+type CapabilityProbe struct {
+	Name       string `json:"name"`
+	Outcome    string `json:"outcome"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+// GeneratedCapabilities are the provider capabilities derived from the
+// probes, in the terms of the built-in capability matrix
+type GeneratedCapabilities struct {
+	PolicySupport      string `json:"policySupport"`
+	ACLSupport         string `json:"aclSupport"`
+	VirtualHostSupport bool   `json:"virtualHostSupport"`
+	PathStyleSupport   bool   `json:"pathStyleSupport"`
+	Versioning         string `json:"versioning"`
+	Multipart          string `json:"multipart"`
 }
 
 // TestSummary contains the overall test summary
@@ -91,35 +1160,306 @@ type TestSummary struct {
 	Failed   int `json:"failed"`
 	Warnings int `json:"warnings"`
 	Skipped  int `json:"skipped"`
+
+	// Maintenance counts the failures caused by maintenance pages
+	Maintenance int `json:"maintenance,omitempty"`
+
+	// RateLimited counts the failures caused by throttling or an exhausted
+	// quota
+	RateLimited int `json:"rateLimited,omitempty"`
+
+	// Dependent counts the checks skipped because a check they depend on
+	// failed
+	Dependent int `json:"dependent,omitempty"`
 }
 
 // TestReport contains the complete test report
 type TestReport struct {
-	Config     Config      `json:"config"`
-	StartTime  time.Time   `json:"startTime"`
-	EndTime    time.Time   `json:"endTime"`
-	Duration   time.Duration `json:"duration"`
-	Results    []TestResult `json:"results"`
-	Summary    TestSummary  `json:"summary"`
+	Config    Config        `json:"config"`
+	StartTime time.Time     `json:"startTime"`
+	EndTime   time.Time     `json:"endTime"`
+	Duration  time.Duration `json:"duration"`
+	Results   []TestResult  `json:"results"`
+	Summary   TestSummary   `json:"summary"`
+	Repeat    *RepeatReport `json:"repeat,omitempty"`
+	Warmup    *WarmupResult `json:"warmup,omitempty"`
+
+	// SlowestOperations lists the slowest HTTP requests of the run, slowest
+	// first, when --slow-threshold is set
+	SlowestOperations []HTTPOperation `json:"slowestOperations,omitempty"`
+	Metadata          *RunMetadata    `json:"metadata,omitempty"`
+
+	// SideEffectFree is set for --read-only runs that sent no write requests
+	SideEffectFree bool `json:"sideEffectFree,omitempty"`
+
+	// Baseline lists the changes against the --baseline report
+	Baseline *BaselineComparison `json:"baseline,omitempty"`
+}
+
+// WarmupResult contains the throwaway connection cycles run with --warmup
+// before the checks. Cold is the first DNS, TCP, TLS and HEAD cycle; Warm
+// repeats it on a new connection, so the difference is the first-connection
+// overhead the checks no longer include.
+type WarmupResult struct {
+	Cold WarmupCycle `json:"cold"`
+	Warm WarmupCycle `json:"warm"`
+}
+
+// WarmupCycle contains the per-phase timing of one warm-up request
+type WarmupCycle struct {
+	StatusCode     int     `json:"statusCode,omitempty"`
+	DNSMs          float64 `json:"dnsMs"`
+	ConnectMs      float64 `json:"connectMs"`
+	TLSHandshakeMs float64 `json:"tlsHandshakeMs"`
+	TTFBMs         float64 `json:"ttfbMs"`
+	TotalMs        float64 `json:"totalMs"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// resultSequence numbers results in the order they are recorded, across
+// repeated runs and targets
+var resultSequence uint64
+
+// AddResult stamps a result with its timestamps, sequence number and target
+// and appends it to the report
+func (r *TestReport) AddResult(result TestResult) {
+	result.EndTime = time.Now().UTC()
+	result.StartTime = result.EndTime.Add(-result.Duration)
+	result.Sequence = atomic.AddUint64(&resultSequence, 1)
+	result.Target = r.Config.Target()
+	r.Results = append(r.Results, result)
+}
+
+// Provider returns the provider detected from the endpoint, or for a custom
+// endpoint the one the authentication check recognized by its Server header
+func (r *TestReport) Provider() string {
+	if r.Config.Provider != "" && r.Config.Provider != "custom" {
+		return r.Config.Provider
+	}
+	for _, result := range r.Results {
+		if details, ok := result.Details.(AuthResult); ok {
+			switch details.Provider {
+			case "MinIO":
+				return "minio"
+			case "NetApp StorageGRID":
+				return "netapp"
+			}
+		}
+	}
+	return r.Config.Provider
+}
+
+// Target returns the identifier of the tested bucket (endpoint/bucket)
+func (c Config) Target() string {
+	return strings.TrimSuffix(c.Endpoint, "/") + "/" + c.Bucket
+}
+
+// Address families forced with --ipv4 and --ipv6
+const (
+	IPv4Only = "ipv4"
+	IPv6Only = "ipv6"
+)
+
+// FamilyNetwork returns the Go network name restricted to the address
+// family, e.g. "tcp4" for "tcp" and IPv4Only, or network itself when no
+// family is forced
+func FamilyNetwork(network, family string) string {
+	switch family {
+	case IPv4Only:
+		return network + "4"
+	case IPv6Only:
+		return network + "6"
+	}
+	return network
+}
+
+// CredentialsExpired reports whether the temporary credentials of the run
+// have expired at the given time
+func (c Config) CredentialsExpired(now time.Time) bool {
+	return c.CredentialExpiry != nil && !now.Before(*c.CredentialExpiry)
+}
+
+// RepeatReport contains per-check statistics across repeated suite runs
+type RepeatReport struct {
+	Runs   int          `json:"runs"`
+	Checks []CheckStats `json:"checks"`
+}
+
+// CheckStats contains the success rate and latency distribution of one check
+type CheckStats struct {
+	TestName    string  `json:"testName"`
+	Runs        int     `json:"runs"`
+	Passed      int     `json:"passed"`
+	Warnings    int     `json:"warnings"`
+	Failed      int     `json:"failed"`
+	SuccessRate float64 `json:"successRate"`
+	MinMs       float64 `json:"minMs"`
+	MeanMs      float64 `json:"meanMs"`
+	P50Ms       float64 `json:"p50Ms"`
+	P95Ms       float64 `json:"p95Ms"`
+	MaxMs       float64 `json:"maxMs"`
+}
+
+// CertWatchResult contains the certificate expiry status of one endpoint
+type CertWatchResult struct {
+	Endpoint        string    `json:"endpoint"`
+	Host            string    `json:"host"`
+	Port            int       `json:"port"`
+	Status          Status    `json:"status"`
+	Subject         string    `json:"subject,omitempty"`
+	Issuer          string    `json:"issuer,omitempty"`
+	NotAfter        time.Time `json:"notAfter,omitempty"`
+	DaysUntilExpiry int       `json:"daysUntilExpiry"`
+	Verified        bool      `json:"verified"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// CertWatchReport contains the results of a cert-watch run
+type CertWatchReport struct {
+	CheckedAt time.Time         `json:"checkedAt"`
+	WarnDays  int               `json:"warnDays"`
+	Results   []CertWatchResult `json:"results"`
+	Summary   TestSummary       `json:"summary"`
 }
 
 // Config contains the test configuration
 type Config struct {
-	Endpoint       string `json:"endpoint"`
-	Bucket         string `json:"bucket"`
-	Region         string `json:"region"`
-	AccessKey      string `json:"accessKey"`
-	SecretKey      string `json:"secretKey"`
-	AuthType       string `json:"authType"`
-	Port           int    `json:"port"`
-	Insecure       bool   `json:"insecure"`
-	Timeout        int    `json:"timeout"`
-	OutputFormat   string `json:"outputFormat"`
-	OutputFile     string `json:"outputFile"`
-	FollowRedirect bool   `json:"followRedirect"`
-	MaxRedirects   int    `json:"maxRedirects"`
-	Verbose        bool   `json:"verbose"`
-	PathStyle      bool   `json:"pathStyle"`
+	Endpoint             string           `json:"endpoint"`
+	Bucket               string           `json:"bucket"`
+	Region               string           `json:"region"`
+	ConfiguredRegion     string           `json:"configuredRegion,omitempty"`
+	ObjectOwnership      string           `json:"objectOwnership,omitempty"`
+	AccessKey            string           `json:"accessKey"`
+	SecretKey            string           `json:"secretKey"`
+	SessionToken         string           `json:"sessionToken,omitempty"`
+	IncludeCredentials   bool             `json:"includeCredentials,omitempty"`
+	Profile              string           `json:"profile,omitempty"`
+	RoleArn              string           `json:"roleArn,omitempty"`
+	CredentialSource     string           `json:"credentialSource,omitempty"`
+	AssumedRole          *AssumedRoleInfo `json:"assumedRole,omitempty"`
+	CredentialExpiry     *time.Time       `json:"credentialExpiration,omitempty"`
+	AuthType             string           `json:"authType"`
+	Port                 int              `json:"port"`
+	Insecure             bool             `json:"insecure"`
+	CACert               string           `json:"caCert,omitempty"`
+	ClientCert           string           `json:"clientCert,omitempty"`
+	ClientKey            string           `json:"clientKey,omitempty"`
+	SNI                  string           `json:"sni,omitempty"`
+	Proxy                string           `json:"-"`
+	Timeout              int              `json:"timeout"`
+	DNSTimeout           int              `json:"dnsTimeout"`
+	TCPTimeout           int              `json:"tcpTimeout"`
+	HTTPTimeout          int              `json:"httpTimeout"`
+	OutputFormat         string           `json:"outputFormat"`
+	OutputFile           string           `json:"outputFile"`
+	FollowRedirect       bool             `json:"followRedirect"`
+	MaxRedirects         int              `json:"maxRedirects"`
+	Verbose              bool             `json:"verbose"`
+	PathStyle            bool             `json:"pathStyle"`
+	TCPSamples           int              `json:"tcpSamples"`
+	HappyEyeballs        bool             `json:"happyEyeballs"`
+	IPFamily             string           `json:"ipFamily,omitempty"`
+	SupportBundle        string           `json:"supportBundle,omitempty"`
+	TLSResumption        bool             `json:"tlsResumption"`
+	SNIProbe             bool             `json:"sniProbe"`
+	SkipOCSP             bool             `json:"skipOcsp,omitempty"`
+	CertWarnDays         int              `json:"certWarnDays"`
+	CertCritDays         int              `json:"certCritDays"`
+	FailOnCertExpiry     bool             `json:"failOnCertExpiry,omitempty"`
+	Baseline             string           `json:"baseline,omitempty"`
+	History              string           `json:"history,omitempty"`
+	FailOnRegression     bool             `json:"failOnRegression,omitempty"`
+	CheckExpect          bool             `json:"checkExpectContinue"`
+	CheckObject          bool             `json:"checkObject"`
+	ProbeTransfer        bool             `json:"probeTransferEncoding"`
+	CheckEncoding        bool             `json:"checkContentEncoding"`
+	CheckCache           bool             `json:"checkCacheHeaders"`
+	CheckVersionedDelete bool             `json:"checkVersionedDelete,omitempty"`
+	CheckVersioning      bool             `json:"checkVersioning,omitempty"`
+	CheckSSEC            bool             `json:"checkSseC,omitempty"`
+	CheckChecksums       bool             `json:"checkChecksums,omitempty"`
+	CheckConditional     bool             `json:"checkConditional,omitempty"`
+	CheckCopy            bool             `json:"checkCopy,omitempty"`
+	CheckBatchDelete     bool             `json:"checkBatchDelete,omitempty"`
+	CheckPresigned       bool             `json:"checkPresigned,omitempty"`
+	CheckPostPolicy      bool             `json:"checkPostPolicy,omitempty"`
+	CheckLifecycle       bool             `json:"checkLifecycle,omitempty"`
+	CheckLogging         bool             `json:"checkLogging,omitempty"`
+	CheckTagging         bool             `json:"checkTagging,omitempty"`
+	CheckWebsite         bool             `json:"checkWebsite,omitempty"`
+	CheckNotifications   bool             `json:"checkNotifications,omitempty"`
+	VerifyNotifications  string           `json:"verifyNotifications,omitempty"`
+	WebsiteEndpoint      string           `json:"websiteEndpoint,omitempty"`
+	CheckReplication     bool             `json:"checkReplication,omitempty"`
+	TestReplication      bool             `json:"testReplication,omitempty"`
+	CheckOwnership       bool             `json:"checkOwnership,omitempty"`
+	CheckObjectLock      bool             `json:"checkObjectLock,omitempty"`
+	TestRetention        bool             `json:"testRetention,omitempty"`
+	CheckPolicy          bool             `json:"checkPolicy,omitempty"`
+	CheckPublicAccess    bool             `json:"checkPublicAccessBlock,omitempty"`
+	CheckLocation        bool             `json:"checkLocation,omitempty"`
+	TestBothStyles       bool             `json:"testBothStyles,omitempty"`
+	Provider             string           `json:"provider,omitempty"`
+	CheckRangedGet       bool             `json:"checkRangedGet"`
+	SDKParity            bool             `json:"sdkParity,omitempty"`
+	ProbeCapabilities    bool             `json:"probeCapabilities,omitempty"`
+	CDN                  string           `json:"cdn,omitempty"`
+	RangedGetSizeMB      int              `json:"rangedGetSizeMB,omitempty"`
+	RangedGetConcurrency int              `json:"rangedGetConcurrency,omitempty"`
+	BenchSize            int64            `json:"benchSize,omitempty"`
+	BenchCount           int              `json:"benchCount,omitempty"`
+	BenchConcurrency     int              `json:"benchConcurrency,omitempty"`
+	CheckArtifacts       bool             `json:"checkArtifacts"`
+	CheckPermissions     bool             `json:"checkPermissions"`
+	ReadOnly             bool             `json:"readOnly"`
+	SkipAnonymous        bool             `json:"skipAnonymousScan,omitempty"`
+	RunID                string           `json:"runId"`
+	TestPrefix           string           `json:"testPrefix"`
+	ObjectKey            string           `json:"objectKey,omitempty"`
+	CustomTestPrefix     bool             `json:"customTestPrefix,omitempty"`
+	PurgeArtifacts       bool             `json:"purgeArtifacts"`
+	Repeat               int              `json:"repeat"`
+	Watch                bool             `json:"watch,omitempty"`
+	WatchPolicy          bool             `json:"watchPolicy,omitempty"`
+	Warmup               bool             `json:"warmup,omitempty"`
+	SlowThresholdMs      int              `json:"slowThresholdMs,omitempty"`
+	Retries              int              `json:"retries,omitempty"`
+	RetryDelayMs         int              `json:"retryDelayMs,omitempty"`
+	BudgetMs             int              `json:"budgetMs,omitempty"`
+	Interval             int              `json:"intervalSeconds,omitempty"`
+	Require              []string         `json:"require,omitempty"`
+	PolicySupport        string           `json:"policySupport,omitempty"`
+	ACLSupport           string           `json:"aclSupport,omitempty"`
+	ConfigFile           string           `json:"configFile,omitempty"`
+	SLO                  *SLOThresholds   `json:"slo,omitempty"`
+	Warnings             []Warning        `json:"warnings,omitempty"`
+}
+
+// WarningSeverity indicates how likely a configuration warning is to affect
+// the results
+type WarningSeverity string
+
+const (
+	// WarningSeverityInfo notes a limitation that may affect some checks
+	WarningSeverityInfo WarningSeverity = "info"
+	// WarningSeverityWarning notes a setting the provider does not support
+	WarningSeverityWarning WarningSeverity = "warning"
+)
+
+// Warning is a configuration warning raised before the tests run
+type Warning struct {
+	Severity WarningSeverity `json:"severity"`
+	Source   string          `json:"source"`
+	Message  string          `json:"message"`
+}
+
+// AssumedRoleInfo records the identity the tests ran as after sts:AssumeRole
+type AssumedRoleInfo struct {
+	Arn           string    `json:"arn"`
+	AssumedRoleID string    `json:"assumedRoleId"`
+	SessionName   string    `json:"sessionName"`
+	STSEndpoint   string    `json:"stsEndpoint"`
+	Expiration    time.Time `json:"expiration"`
 }
 
 // NewCertificateInfo creates a CertificateInfo from x509.Certificate
@@ -162,6 +1502,65 @@ func NewCertificateInfo(cert *x509.Certificate) CertificateInfo {
 	return info
 }
 
+// NewRepeatReport aggregates the results of repeated suite runs per check.
+// A run counts as successful unless the check failed.
+func NewRepeatReport(runs [][]TestResult) *RepeatReport {
+	report := &RepeatReport{Runs: len(runs)}
+
+	index := make(map[string]int)
+	durations := make(map[string][]float64)
+	for _, results := range runs {
+		for _, result := range results {
+			i, ok := index[result.TestName]
+			if !ok {
+				i = len(report.Checks)
+				index[result.TestName] = i
+				report.Checks = append(report.Checks, CheckStats{TestName: result.TestName})
+			}
+
+			stats := &report.Checks[i]
+			stats.Runs++
+			switch result.Status {
+			case StatusPass:
+				stats.Passed++
+			case StatusWarn:
+				stats.Warnings++
+			case StatusFail:
+				stats.Failed++
+			}
+			durations[result.TestName] = append(durations[result.TestName], float64(result.Duration.Microseconds())/1000)
+		}
+	}
+
+	for i := range report.Checks {
+		stats := &report.Checks[i]
+		stats.SuccessRate = float64(stats.Runs-stats.Failed) / float64(stats.Runs) * 100
+
+		values := durations[stats.TestName]
+		sort.Float64s(values)
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		stats.MinMs = values[0]
+		stats.MaxMs = values[len(values)-1]
+		stats.MeanMs = sum / float64(len(values))
+		stats.P50Ms = percentile(values, 50)
+		stats.P95Ms = percentile(values, 95)
+	}
+
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
 // NewTestSummary creates a test summary from results
 func NewTestSummary(results []TestResult) TestSummary {
 	summary := TestSummary{}
@@ -176,6 +1575,15 @@ func NewTestSummary(results []TestResult) TestSummary {
 			summary.Warnings++
 		case StatusSkip:
 			summary.Skipped++
+			if result.SkippedBecause != "" {
+				summary.Dependent++
+			}
+		}
+		switch result.FailureKind {
+		case FailureMaintenance:
+			summary.Maintenance++
+		case FailureRateLimited:
+			summary.RateLimited++
 		}
 	}
 	return summary
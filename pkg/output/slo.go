@@ -0,0 +1,120 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SLOLimit is a numeric threshold with separate warning and failure levels.
+// A zero level is not checked.
+type SLOLimit struct {
+	Warn float64 `json:"warn,omitempty"`
+	Fail float64 `json:"fail,omitempty"`
+}
+
+// SLOVersionLimit is a minimum TLS version ("1.2", "1.3") with separate
+// warning and failure levels. An empty level is not checked.
+type SLOVersionLimit struct {
+	Warn string `json:"warn,omitempty"`
+	Fail string `json:"fail,omitempty"`
+}
+
+// SLOThresholds defines operator service levels that turn passing checks
+// into warnings or failures when they are not met
+type SLOThresholds struct {
+	MaxDNSMs      *SLOLimit        `json:"maxDnsMs,omitempty"`
+	MaxTTFBMs     *SLOLimit        `json:"maxTtfbMs,omitempty"`
+	MinTLSVersion *SLOVersionLimit `json:"minTlsVersion,omitempty"`
+	MinCertDays   *SLOLimit        `json:"minCertDays,omitempty"`
+}
+
+// ApplySLO evaluates the thresholds against the results and downgrades
+// passing checks in place. Failed and skipped checks are left unchanged.
+func ApplySLO(results []TestResult, slo *SLOThresholds) {
+	if slo == nil {
+		return
+	}
+
+	for i := range results {
+		result := &results[i]
+		if result.Status != StatusPass && result.Status != StatusWarn {
+			continue
+		}
+
+		switch details := result.Details.(type) {
+		case DNSResult:
+			if slo.MaxDNSMs != nil {
+				checkMaximum(result, "DNS resolution", float64(details.ResolutionTime), "ms", *slo.MaxDNSMs)
+			}
+		case AuthResult:
+			if slo.MaxTTFBMs != nil {
+				checkMaximum(result, "time to first byte", details.TTFBMs, "ms", *slo.MaxTTFBMs)
+			}
+		case TLSResult:
+			if slo.MinTLSVersion != nil {
+				checkTLSVersion(result, details.TLSVersion, *slo.MinTLSVersion)
+			}
+			if slo.MinCertDays != nil {
+				checkMinimum(result, "certificate validity", float64(details.Certificate.DaysUntilExpiry), " days", *slo.MinCertDays)
+			}
+		}
+	}
+}
+
+// checkMaximum records a violation when value exceeds the limit
+func checkMaximum(result *TestResult, what string, value float64, unit string, limit SLOLimit) {
+	switch {
+	case limit.Fail > 0 && value > limit.Fail:
+		recordSLOViolation(result, StatusFail, fmt.Sprintf("%s %.0f%s exceeds %.0f%s", what, value, unit, limit.Fail, unit))
+	case limit.Warn > 0 && value > limit.Warn:
+		recordSLOViolation(result, StatusWarn, fmt.Sprintf("%s %.0f%s exceeds %.0f%s", what, value, unit, limit.Warn, unit))
+	}
+}
+
+// checkMinimum records a violation when value is below the limit
+func checkMinimum(result *TestResult, what string, value float64, unit string, limit SLOLimit) {
+	switch {
+	case limit.Fail > 0 && value < limit.Fail:
+		recordSLOViolation(result, StatusFail, fmt.Sprintf("%s %.0f%s is below %.0f%s", what, value, unit, limit.Fail, unit))
+	case limit.Warn > 0 && value < limit.Warn:
+		recordSLOViolation(result, StatusWarn, fmt.Sprintf("%s %.0f%s is below %.0f%s", what, value, unit, limit.Warn, unit))
+	}
+}
+
+// checkTLSVersion records a violation when the negotiated version is older
+// than the limit
+func checkTLSVersion(result *TestResult, negotiated string, limit SLOVersionLimit) {
+	version := strings.TrimPrefix(negotiated, "TLS ")
+	switch {
+	case limit.Fail != "" && CompareTLSVersions(version, limit.Fail) < 0:
+		recordSLOViolation(result, StatusFail, fmt.Sprintf("%s is older than TLS %s", negotiated, limit.Fail))
+	case limit.Warn != "" && CompareTLSVersions(version, limit.Warn) < 0:
+		recordSLOViolation(result, StatusWarn, fmt.Sprintf("%s is older than TLS %s", negotiated, limit.Warn))
+	}
+}
+
+// CompareTLSVersions compares dotted TLS versions such as "1.2" and "1.3"
+func CompareTLSVersions(a, b string) int {
+	var aMajor, aMinor, bMajor, bMinor int
+	fmt.Sscanf(a, "%d.%d", &aMajor, &aMinor)
+	fmt.Sscanf(b, "%d.%d", &bMajor, &bMinor)
+	if aMajor != bMajor {
+		return aMajor - bMajor
+	}
+	return aMinor - bMinor
+}
+
+// recordSLOViolation adds a violation to the result and raises its status
+func recordSLOViolation(result *TestResult, status Status, message string) {
+	result.SLOViolations = append(result.SLOViolations, message)
+	if status == StatusFail || result.Status == StatusPass {
+		result.Status = status
+	}
+
+	message = "SLO: " + message
+	if result.Error != "" {
+		result.Error += "; " + message
+	} else {
+		result.Error = message
+	}
+}
@@ -0,0 +1,227 @@
+package output
+
+import (
+	"archive/zip"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HTTPExchange is one HTTP request and its response recorded for a support
+// bundle, with signatures, tokens and keys removed
+type HTTPExchange struct {
+	Check           string
+	StartTime       time.Time
+	Method          string
+	URL             string
+	Host            string
+	RequestHeaders  http.Header
+	Proto           string
+	Status          string
+	ResponseHeaders http.Header
+	ResponseBody    string
+	Error           string
+}
+
+// DNSAnswers are the DNS records of a host recorded for a support bundle
+type DNSAnswers struct {
+	Host      string
+	CNAME     string
+	Addresses []string
+	Error     string
+}
+
+// SupportData is what a support bundle collects besides the report
+type SupportData struct {
+	Exchanges        []HTTPExchange
+	DroppedExchanges int
+	DNS              []DNSAnswers
+	Certificates     []*x509.Certificate
+	CertificateError string
+}
+
+// supportEnvVars are the environment variables that change how requests are
+// sent; proxy URLs are written with their passwords removed
+var supportEnvVars = []string{
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"AWS_REGION", "AWS_DEFAULT_REGION", "AWS_PROFILE", "AWS_CONFIG_FILE", "AWS_SHARED_CREDENTIALS_FILE",
+	"SSL_CERT_FILE", "SSL_CERT_DIR", "GODEBUG",
+}
+
+// WriteSupportBundle writes a zip archive for a storage provider's support:
+// the JSON report, the HTTP transcripts, the endpoint's certificates as PEM,
+// the DNS answers and the environment. Credentials are always masked,
+// whatever --include-credentials says.
+func WriteSupportBundle(report *TestReport, data *SupportData, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	for _, entry := range []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"report.json", func(w io.Writer) error { return writeBundleReport(w, report) }},
+		{"http-transcript.txt", func(w io.Writer) error { return writeTranscript(w, data) }},
+		{"certificates.pem", func(w io.Writer) error { return writeCertificates(w, data) }},
+		{"dns.txt", func(w io.Writer) error { return writeDNSAnswers(w, data) }},
+		{"environment.txt", func(w io.Writer) error { return writeEnvironment(w, report) }},
+	} {
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: entry.name, Method: zip.Deflate, Modified: report.EndTime})
+		if err != nil {
+			return err
+		}
+		if err := entry.write(w); err != nil {
+			return fmt.Errorf("%s: %w", entry.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// writeBundleReport writes the report as JSON with its credentials masked
+func writeBundleReport(w io.Writer, report *TestReport) error {
+	masked := *report
+	masked.Config = report.Config.Redacted()
+	data, err := json.MarshalIndent(&masked, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// writeTranscript writes the HTTP exchanges in the order they were sent
+func writeTranscript(w io.Writer, data *SupportData) error {
+	if len(data.Exchanges) == 0 {
+		_, err := fmt.Fprintln(w, "No HTTP requests were sent.")
+		return err
+	}
+	for i, ex := range data.Exchanges {
+		check := ex.Check
+		if check == "" {
+			check = "-"
+		}
+		fmt.Fprintf(w, "### %d [%s] %s\n", i+1, check, ex.StartTime.Format(time.RFC3339Nano))
+		fmt.Fprintf(w, "> %s %s\n", ex.Method, ex.URL)
+		if ex.RequestHeaders.Get("Host") == "" {
+			fmt.Fprintf(w, "> Host: %s\n", ex.Host)
+		}
+		writeHeaders(w, "> ", ex.RequestHeaders)
+		if ex.Error != "" {
+			fmt.Fprintf(w, "! %s\n\n", ex.Error)
+			continue
+		}
+		fmt.Fprintf(w, "< %s %s\n", ex.Proto, ex.Status)
+		writeHeaders(w, "< ", ex.ResponseHeaders)
+		if ex.ResponseBody != "" {
+			fmt.Fprintf(w, "\n%s\n", strings.TrimRight(ex.ResponseBody, "\n"))
+		}
+		fmt.Fprintln(w)
+	}
+	if data.DroppedExchanges > 0 {
+		fmt.Fprintf(w, "%d further requests were not recorded.\n", data.DroppedExchanges)
+	}
+	return nil
+}
+
+// writeHeaders writes headers sorted by name, one line per value
+func writeHeaders(w io.Writer, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+}
+
+// writeCertificates writes the endpoint's certificate chain as PEM, each
+// certificate preceded by its subject and issuer
+func writeCertificates(w io.Writer, data *SupportData) error {
+	switch {
+	case data.CertificateError != "":
+		fmt.Fprintf(w, "# %s\n", data.CertificateError)
+	case len(data.Certificates) == 0:
+		fmt.Fprintln(w, "# No certificates: the endpoint does not use TLS")
+	}
+	for _, cert := range data.Certificates {
+		fmt.Fprintf(w, "# Subject: %s\n# Issuer: %s\n# Not After: %s\n",
+			cert.Subject, cert.Issuer, cert.NotAfter.UTC().Format(time.RFC3339))
+		if err := pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDNSAnswers writes the DNS answers of each host
+func writeDNSAnswers(w io.Writer, data *SupportData) error {
+	for _, answers := range data.DNS {
+		fmt.Fprintf(w, "%s\n", answers.Host)
+		if answers.Error != "" {
+			fmt.Fprintf(w, "  error: %s\n", answers.Error)
+		}
+		if answers.CNAME != "" {
+			fmt.Fprintf(w, "  CNAME %s\n", answers.CNAME)
+		}
+		for _, addr := range answers.Addresses {
+			record := "A"
+			if strings.Contains(addr, ":") {
+				record = "AAAA"
+			}
+			fmt.Fprintf(w, "  %s %s\n", record, addr)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// writeEnvironment writes the runner metadata and the environment variables
+// that affect requests
+func writeEnvironment(w io.Writer, report *TestReport) error {
+	if m := report.Metadata; m != nil {
+		fmt.Fprintf(w, "s3tester: %s\n", m.ToolVersion)
+		fmt.Fprintf(w, "Go: %s\n", m.GoVersion)
+		fmt.Fprintf(w, "OS/Arch: %s/%s\n", m.OS, m.Arch)
+		fmt.Fprintf(w, "Hostname: %s\n", m.Hostname)
+		if m.SourceIP != "" {
+			fmt.Fprintf(w, "Source IP: %s\n", m.SourceIP)
+		}
+		if len(m.LocalAddresses) > 0 {
+			fmt.Fprintf(w, "Local addresses: %s\n", strings.Join(m.LocalAddresses, ", "))
+		}
+	}
+	fmt.Fprintf(w, "Target: %s\n", report.Config.Target())
+	fmt.Fprintf(w, "Run started: %s\n", report.StartTime.UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "Time zone: %s\n", time.Now().Format("MST -07:00"))
+
+	fmt.Fprintln(w, "\nEnvironment:")
+	for _, name := range supportEnvVars {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if u, err := url.Parse(value); err == nil && u.User != nil {
+			value = u.Redacted()
+		}
+		fmt.Fprintf(w, "  %s=%s\n", name, value)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PrintYAML prints the test report as YAML to a file, or to stdout when no
+// file is given, with credentials masked unless --include-credentials was
+// given. The document has the same fields, in the same order, as the JSON
+// report.
+func PrintYAML(report *TestReport, outputFile string) error {
+	data, err := json.Marshal(sanitize(report))
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := decodeYAMLNode(dec)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writeYAMLBlock(&buf, root, 0)
+
+	if outputFile != "" {
+		return os.WriteFile(outputFile, buf.Bytes(), 0644)
+	}
+
+	_, err = os.Stdout.Write(buf.Bytes())
+	return err
+}
+
+// yamlNode is a decoded JSON value that keeps the order of object keys
+type yamlNode struct {
+	// scalar is the YAML form of a string, number, bool or null
+	scalar string
+
+	// mapping tells objects from arrays; keys is nil for arrays
+	mapping bool
+	keys    []string
+	items   []*yamlNode
+}
+
+// isBlock reports whether the node is written as an indented block rather
+// than on the line of its key or dash
+func (n *yamlNode) isBlock() bool {
+	return len(n.items) > 0
+}
+
+// decodeYAMLNode reads the next JSON value from the decoder
+func decodeYAMLNode(dec *json.Decoder) (*yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		node := &yamlNode{mapping: tok == '{'}
+		for dec.More() {
+			if node.mapping {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, fmt.Sprint(key))
+			}
+			item, err := decodeYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.items = append(node.items, item)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		switch {
+		case len(node.items) > 0:
+		case node.mapping:
+			node.scalar = "{}"
+		default:
+			node.scalar = "[]"
+		}
+		return node, nil
+	case string:
+		return &yamlNode{scalar: yamlString(tok)}, nil
+	case json.Number:
+		return &yamlNode{scalar: tok.String()}, nil
+	case bool:
+		return &yamlNode{scalar: strconv.FormatBool(tok)}, nil
+	default:
+		return &yamlNode{scalar: "null"}, nil
+	}
+}
+
+// writeYAMLBlock writes a non-empty object or array at the given indent
+func writeYAMLBlock(w *bytes.Buffer, n *yamlNode, indent int) {
+	pad := strings.Repeat("  ", indent)
+	for i, item := range n.items {
+		if n.mapping {
+			w.WriteString(pad + yamlString(n.keys[i]) + ":")
+			writeYAMLChild(w, item, indent+1)
+			continue
+		}
+
+		w.WriteString(pad + "-")
+		if item.mapping && item.isBlock() {
+			// The first key of a mapping in a sequence follows the dash
+			var nested bytes.Buffer
+			writeYAMLBlock(&nested, item, indent+1)
+			w.WriteString(" ")
+			w.Write(bytes.TrimPrefix(nested.Bytes(), []byte(pad+"  ")))
+			continue
+		}
+		writeYAMLChild(w, item, indent+1)
+	}
+}
+
+// writeYAMLChild writes the value after a key or dash: scalars on the same
+// line, collections as a block on the following lines
+func writeYAMLChild(w *bytes.Buffer, n *yamlNode, indent int) {
+	if !n.isBlock() {
+		w.WriteString(" " + n.scalar + "\n")
+		return
+	}
+	w.WriteString("\n")
+	writeYAMLBlock(w, n, indent)
+}
+
+// yamlString returns s as a plain scalar when YAML reads it back as the same
+// string, and double-quoted otherwise
+func yamlString(s string) string {
+	if !plainSafe(s) {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// plainSafe reports whether s can be written without quotes: it starts with a
+// letter or slash, has no characters with a meaning in YAML and cannot be
+// mistaken for a key, a comment, a number or a timestamp
+func plainSafe(s string) bool {
+	if s == "" || strings.Contains(s, ": ") || strings.HasSuffix(s, ":") || strings.HasSuffix(s, " ") {
+		return false
+	}
+	if c := s[0]; !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '/') {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune(" _-./:+@()=", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
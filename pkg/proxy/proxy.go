@@ -0,0 +1,305 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Parse parses a --proxy value. Only http:// and socks5:// proxies are
+// supported; a missing port defaults to 8080 and 1080 respectively.
+func Parse(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q: use http:// or socks5://", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", raw)
+	}
+	if u.Port() == "" {
+		port := "8080"
+		if u.Scheme == "socks5" {
+			port = "1080"
+		}
+		u.Host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	return u, nil
+}
+
+// Func returns the proxy function for HTTP transports: the --proxy URL for
+// every request, or HTTP_PROXY/HTTPS_PROXY/NO_PROXY when raw is empty
+func Func(raw string) func(*http.Request) (*url.URL, error) {
+	if raw == "" {
+		return http.ProxyFromEnvironment
+	}
+	u, err := Parse(raw)
+	if err != nil {
+		return func(*http.Request) (*url.URL, error) { return nil, err }
+	}
+	return http.ProxyURL(u)
+}
+
+// ForTarget returns the proxy used to reach target, an http:// or https://
+// URL, or nil when the connection is direct
+func ForTarget(raw string, target *url.URL) (*url.URL, error) {
+	return Func(raw)(&http.Request{Method: "GET", URL: target, Header: http.Header{}})
+}
+
+// Redact returns the proxy URL without its password, for reports and logs
+func Redact(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+// Dialer opens TCP connections directly or through a proxy
+type Dialer struct {
+	// Proxy is the proxy to tunnel through, nil to connect directly
+	Proxy *url.URL
+
+	// Timeout bounds connecting and the proxy handshake
+	Timeout time.Duration
+}
+
+// DialContext connects to address, tunnelling through the proxy with HTTP
+// CONNECT or SOCKS5. The target host name is passed to the proxy unresolved.
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: d.Timeout}
+	if d.Proxy == nil {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+
+	// Connect to the proxy over the requested network, so a forced address
+	// family applies to it too
+	conn, err := dialer.DialContext(ctx, network, d.Proxy.Host)
+	if err != nil {
+		return nil, fmt.Errorf("proxy %s: %w", Redact(d.Proxy), err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	switch d.Proxy.Scheme {
+	case "socks5":
+		err = socks5Connect(conn, d.Proxy.User, address)
+	default:
+		conn, err = httpConnect(conn, d.Proxy.User, address)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %w", Redact(d.Proxy), err)
+	}
+
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// Dial connects to address without a context
+func (d *Dialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+// bufferedConn returns bytes buffered while reading the CONNECT response
+// before reading from the connection
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// httpConnect opens a tunnel with an HTTP CONNECT request
+func httpConnect(conn net.Conn, user *url.Userinfo, address string) (net.Conn, error) {
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONNECT response: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CONNECT %s: %s", address, resp.Status)
+	}
+
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// SOCKS5 protocol constants (RFC 1928, RFC 1929)
+const (
+	socksVersion       = 0x05
+	socksAuthNone      = 0x00
+	socksAuthPassword  = 0x02
+	socksAuthNoAccept  = 0xff
+	socksCmdConnect    = 0x01
+	socksAddrIPv4      = 0x01
+	socksAddrDomain    = 0x03
+	socksAddrIPv6      = 0x04
+	socksReplySuccess  = 0x00
+	socksPasswordAuthV = 0x01
+)
+
+// socksReplies describes the SOCKS5 reply codes
+var socksReplies = map[byte]string{
+	0x01: "general SOCKS server failure",
+	0x02: "connection not allowed by ruleset",
+	0x03: "network unreachable",
+	0x04: "host unreachable",
+	0x05: "connection refused",
+	0x06: "TTL expired",
+	0x07: "command not supported",
+	0x08: "address type not supported",
+}
+
+// socks5Connect performs the SOCKS5 handshake and CONNECT request
+func socks5Connect(conn net.Conn, user *url.Userinfo, address string) error {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return fmt.Errorf("invalid port %q", portStr)
+	}
+
+	methods := []byte{socksAuthNone}
+	if user != nil {
+		methods = append(methods, socksAuthPassword)
+	}
+	if _, err := conn.Write(append([]byte{socksVersion, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("SOCKS5 handshake failed: %w", err)
+	}
+	if reply[0] != socksVersion {
+		return errors.New("not a SOCKS5 proxy")
+	}
+
+	switch reply[1] {
+	case socksAuthNone:
+	case socksAuthPassword:
+		if user == nil {
+			return errors.New("SOCKS5 proxy requires a username and password")
+		}
+		password, _ := user.Password()
+		if len(user.Username()) > 255 || len(password) > 255 {
+			return errors.New("SOCKS5 username or password too long")
+		}
+		auth := []byte{socksPasswordAuthV, byte(len(user.Username()))}
+		auth = append(auth, user.Username()...)
+		auth = append(auth, byte(len(password)))
+		auth = append(auth, password...)
+		if _, err := conn.Write(auth); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return fmt.Errorf("SOCKS5 authentication failed: %w", err)
+		}
+		if reply[1] != 0x00 {
+			return errors.New("SOCKS5 authentication rejected")
+		}
+	case socksAuthNoAccept:
+		return errors.New("SOCKS5 proxy accepted none of the offered authentication methods")
+	default:
+		return fmt.Errorf("SOCKS5 proxy chose unsupported authentication method %d", reply[1])
+	}
+
+	req := []byte{socksVersion, socksCmdConnect, 0x00}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(req, socksAddrIPv4)
+			req = append(req, ip4...)
+		} else {
+			req = append(req, socksAddrIPv6)
+			req = append(req, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return fmt.Errorf("host name too long for SOCKS5: %s", host)
+		}
+		req = append(req, socksAddrDomain, byte(len(host)))
+		req = append(req, host...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	// Reply: version, status, reserved, bound address type, address, port
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("SOCKS5 CONNECT failed: %w", err)
+	}
+	if header[1] != socksReplySuccess {
+		reason, ok := socksReplies[header[1]]
+		if !ok {
+			reason = fmt.Sprintf("reply code %d", header[1])
+		}
+		return fmt.Errorf("SOCKS5 CONNECT %s: %s", address, reason)
+	}
+
+	var skip int
+	switch header[3] {
+	case socksAddrIPv4:
+		skip = net.IPv4len
+	case socksAddrIPv6:
+		skip = net.IPv6len
+	case socksAddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		skip = int(length[0])
+	default:
+		return fmt.Errorf("SOCKS5 reply has unknown address type %d", header[3])
+	}
+	if _, err := io.ReadFull(conn, make([]byte, skip+2)); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package remediation
+
+import "strings"
+
+// Denial sources reported by DenialSource
+const (
+	DeniedByOrganizationSCP = "organization service control policy"
+	DeniedByOrganizationRCP = "organization resource control policy"
+	DeniedByBucketPolicy    = "bucket policy"
+	DeniedByIdentityPolicy  = "IAM identity policy"
+	DeniedByBoundary        = "IAM permissions boundary"
+	DeniedBySessionPolicy   = "session policy"
+	DeniedByVPCEndpoint     = "VPC endpoint policy"
+	DeniedByPublicAccess    = "Block Public Access setting"
+)
+
+// denialContexts maps the phrases AWS appends to AccessDenied messages to
+// the policy type that caused the denial. Explicit denies are listed first,
+// so they win over a missing allow mentioned in the same message.
+var denialContexts = []struct {
+	phrase string
+	source string
+}{
+	{"explicit deny in a service control policy", DeniedByOrganizationSCP},
+	{"explicit deny in a resource control policy", DeniedByOrganizationRCP},
+	{"explicit deny in a resource-based policy", DeniedByBucketPolicy},
+	{"explicit deny in an identity-based policy", DeniedByIdentityPolicy},
+	{"explicit deny in a permissions boundary", DeniedByBoundary},
+	{"explicit deny in a session policy", DeniedBySessionPolicy},
+	{"explicit deny in a vpc endpoint policy", DeniedByVPCEndpoint},
+	{"block public access setting", DeniedByPublicAccess},
+	{"no service control policy allows", DeniedByOrganizationSCP},
+	{"no permissions boundary allows", DeniedByBoundary},
+	{"no session policy allows", DeniedBySessionPolicy},
+	{"no vpc endpoint policy allows", DeniedByVPCEndpoint},
+	{"no resource-based policy allows", DeniedByBucketPolicy},
+	{"no identity-based policy allows", DeniedByIdentityPolicy},
+}
+
+// DenialSource returns the policy type an AWS AccessDenied message blames,
+// or "" when the message carries no denial context. AWS adds the context
+// only for callers in the same account as the denying policy.
+func DenialSource(errMsg string) string {
+	lower := strings.ToLower(errMsg)
+	for _, c := range denialContexts {
+		if strings.Contains(lower, c.phrase) {
+			return c.source
+		}
+	}
+	return ""
+}
+
+// IsOrganizationDenial reports whether a denial source is a policy of the
+// AWS organization, which no bucket or IAM policy in the account can override
+func IsOrganizationDenial(source string) bool {
+	return source == DeniedByOrganizationSCP || source == DeniedByOrganizationRCP
+}
+
+// getDenialRemediation explains an AccessDenied error by the policy type
+// that denied it, whatever check it came from. It returns nil when the
+// error carries no denial context.
+func getDenialRemediation(errMsg string) *Remediation {
+	source := DenialSource(errMsg)
+	if source == "" {
+		return nil
+	}
+
+	r := &Remediation{Error: errMsg}
+
+	switch source {
+	case DeniedByOrganizationSCP:
+		r.Cause = "A service control policy of the AWS organization denies the request"
+		r.Suggestion = "Ask the organization administrators to exempt the principal or the bucket; bucket and IAM policies in the account cannot override an SCP"
+		r.Commands = []string{
+			"aws organizations list-policies-for-target --target-id <account-id> --filter SERVICE_CONTROL_POLICY",
+		}
+	case DeniedByOrganizationRCP:
+		r.Cause = "A resource control policy of the AWS organization denies the request"
+		r.Suggestion = "Ask the organization administrators to review the RCPs attached to the account; bucket policies cannot override an RCP"
+		r.Commands = []string{
+			"aws organizations list-policies-for-target --target-id <account-id> --filter RESOURCE_CONTROL_POLICY",
+		}
+	case DeniedByBucketPolicy:
+		r.Cause = "The bucket policy denies the request or, for another account, does not allow it"
+		r.Suggestion = "Review the Deny statements and conditions of the bucket policy, or add an Allow for the principal"
+		r.Commands = []string{
+			"aws s3api get-bucket-policy --bucket <bucket>",
+		}
+	case DeniedByIdentityPolicy:
+		r.Cause = "The IAM policies of the user or role do not allow the request"
+		r.Suggestion = "Attach a policy granting the action, for example the one written by --emit-policy"
+		r.Commands = []string{
+			"aws iam simulate-principal-policy --policy-source-arn <principal-arn> --action-names <action>",
+		}
+	case DeniedByBoundary:
+		r.Cause = "The permissions boundary of the user or role does not allow the request"
+		r.Suggestion = "Extend the permissions boundary to include the action; identity policies cannot grant more than the boundary"
+		r.Commands = []string{
+			"aws iam get-role --role-name <role> --query Role.PermissionsBoundary",
+		}
+	case DeniedBySessionPolicy:
+		r.Cause = "The session policy passed when the role was assumed does not allow the request"
+		r.Suggestion = "Include the action in the session policy, or assume the role without one"
+	case DeniedByVPCEndpoint:
+		r.Cause = "The policy of the VPC endpoint the request went through denies it"
+		r.Suggestion = "Allow the bucket and action in the VPC endpoint policy, or test from outside the VPC"
+		r.Commands = []string{
+			"aws ec2 describe-vpc-endpoints --filters Name=service-name,Values=com.amazonaws.<region>.s3",
+		}
+	case DeniedByPublicAccess:
+		r.Cause = "Block Public Access on the bucket or the AWS account rejects public policies or ACLs"
+		r.Suggestion = "Use --check-policy to see whether the bucket or the account setting applies; account-level settings override the bucket"
+		r.Commands = []string{
+			"aws s3api get-public-access-block --bucket <bucket>",
+			"aws s3control get-public-access-block --account-id <account-id>",
+		}
+	}
+
+	return r
+}
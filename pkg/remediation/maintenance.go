@@ -0,0 +1,40 @@
+package remediation
+
+import "strings"
+
+// getNonS3Remediation explains errors for responses that did not come from
+// the S3 API: maintenance pages and other HTML pages, reported by the
+// checkers with the MaintenancePage and HTMLPage codes, and responses the
+// endpoint throttled without an S3 error, reported as RateLimited. It returns
+// nil for any other error.
+func getNonS3Remediation(errMsg string) *Remediation {
+	r := &Remediation{Error: errMsg}
+
+	switch {
+	case strings.Contains(errMsg, "MaintenancePage:"):
+		r.Cause = "The endpoint is in maintenance and served a maintenance page instead of an S3 response"
+		r.Suggestion = "Wait for the maintenance window to end and run again; credentials and bucket settings are not the cause"
+		r.Commands = []string{
+			"Check the status page or announcements of the S3 provider",
+			"Retry automatically: --retries 5 --retry-delay 30000",
+		}
+	case strings.Contains(errMsg, "RateLimited:"):
+		r.Cause = "The endpoint throttled the request: the rate limit or quota of the account or client was exhausted"
+		r.Suggestion = "Wait until the limit resets, reduce the request rate of other clients sharing the credentials, or ask the provider to raise the quota"
+		r.Commands = []string{
+			"Compare the Rate limit line of the result with the provider's documented limits",
+			"Retry automatically after the limit resets: --retries 3 --retry-delay 30000",
+		}
+	case strings.Contains(errMsg, "HTMLPage:"):
+		r.Cause = "An HTML page was returned instead of an S3 response, typically by a proxy, load balancer or captive portal in front of the endpoint"
+		r.Suggestion = "Verify the endpoint URL points at the S3 API rather than a web console, and check any proxy between this host and the endpoint"
+		r.Commands = []string{
+			"curl -sv https://<endpoint>/ -o /dev/null",
+			"Check the proxy settings: --proxy, HTTP_PROXY, HTTPS_PROXY, NO_PROXY",
+		}
+	default:
+		return nil
+	}
+
+	return r
+}
@@ -0,0 +1,139 @@
+package remediation
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// builtinRules is the remediation knowledge base shipped with the tool
+//
+//go:embed rules.yaml
+var builtinRules []byte
+
+// Rule maps errors of a check to their likely cause and fix
+type Rule struct {
+	// Check is the name of the check the rule applies to; empty for any check
+	Check string `yaml:"check"`
+
+	// Match lists phrases of which the error must contain at least one, and
+	// All phrases it must contain every one of, compared case-insensitively
+	Match []string `yaml:"match"`
+	All   []string `yaml:"all"`
+
+	Cause      string   `yaml:"cause"`
+	Suggestion string   `yaml:"suggestion"`
+	Commands   []string `yaml:"commands"`
+
+	// ProviderCommands replaces Commands for the providers it lists, keyed
+	// by provider as detected from the endpoint (minio, ceph, b2, ...)
+	ProviderCommands map[string][]string `yaml:"providerCommands"`
+}
+
+var (
+	rulesMu sync.RWMutex
+
+	// rules are tried in order: rules loaded with LoadFile, then the built-in
+	// ones
+	rules     []Rule
+	userRules []Rule
+)
+
+func init() {
+	var err error
+	if rules, err = parseRules(builtinRules); err != nil {
+		panic("remediation: invalid built-in rules: " + err.Error())
+	}
+}
+
+// LoadFile reads remediation rules in the format of the built-in rules.yaml
+// and tries them before the built-in ones, so a file can add site-specific
+// guidance or replace the advice for an error
+func LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read remediation rules: %w", err)
+	}
+
+	loaded, err := parseRules(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse remediation rules %s: %w", path, err)
+	}
+
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
+	userRules = append(userRules, loaded...)
+	return nil
+}
+
+// parseRules decodes a YAML list of rules, rejecting unknown fields so a
+// misspelled key does not silently turn a rule into a catch-all
+func parseRules(data []byte) ([]Rule, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var parsed []Rule
+	if err := decoder.Decode(&parsed); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	for i, rule := range parsed {
+		if rule.Cause == "" && rule.Suggestion == "" {
+			return nil, fmt.Errorf("rule %d has neither a cause nor a suggestion", i+1)
+		}
+	}
+	return parsed, nil
+}
+
+// matches reports whether the rule applies to an error of the named check;
+// lowerErrMsg is the error message in lower case
+func (rule *Rule) matches(testName, lowerErrMsg string) bool {
+	if rule.Check != "" && rule.Check != testName {
+		return false
+	}
+	for _, phrase := range rule.All {
+		if !strings.Contains(lowerErrMsg, strings.ToLower(phrase)) {
+			return false
+		}
+	}
+	if len(rule.Match) == 0 {
+		return true
+	}
+	for _, phrase := range rule.Match {
+		if strings.Contains(lowerErrMsg, strings.ToLower(phrase)) {
+			return true
+		}
+	}
+	return false
+}
+
+// findRule returns the first rule of rules that matches the error, or nil
+func findRule(rules []Rule, testName, lowerErrMsg string) *Rule {
+	for i := range rules {
+		if rules[i].matches(testName, lowerErrMsg) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// apply returns the remediation the rule gives for errMsg on the provider
+func (rule *Rule) apply(errMsg, provider string) *Remediation {
+	commands, ok := rule.ProviderCommands[provider]
+	if !ok {
+		commands = rule.Commands
+	}
+	return &Remediation{
+		Error:      errMsg,
+		Cause:      rule.Cause,
+		Suggestion: rule.Suggestion,
+		Commands:   commands,
+	}
+}
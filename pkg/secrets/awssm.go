@@ -0,0 +1,183 @@
+package secrets
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/s3-bucket-tester/s3tester/pkg/credentials"
+)
+
+// SecretsManagerBackend reads secrets from AWS Secrets Manager:
+// awssm://<name or ARN>#<key>. A secret string holding a JSON object has
+// its keys as fields; any other secret string is a single plain value.
+type SecretsManagerBackend struct {
+	credentials credentials.Provider
+	region      string
+	proxy       func(*http.Request) (*url.URL, error)
+	timeout     time.Duration
+}
+
+// NewSecretsManagerBackend creates a Secrets Manager backend that signs with
+// the credentials of provider. The region of an ARN reference takes
+// precedence over AWS_REGION, AWS_DEFAULT_REGION and then region.
+func NewSecretsManagerBackend(provider credentials.Provider, region string, proxy func(*http.Request) (*url.URL, error), timeout time.Duration) *SecretsManagerBackend {
+	return &SecretsManagerBackend{
+		credentials: provider,
+		region:      region,
+		proxy:       proxy,
+		timeout:     timeout,
+	}
+}
+
+// Scheme returns the URL scheme of Secrets Manager references
+func (b *SecretsManagerBackend) Scheme() string {
+	return "awssm"
+}
+
+// secretsManagerError is the JSON error of the Secrets Manager API
+type secretsManagerError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// Fetch calls GetSecretValue for the secret's current version
+func (b *SecretsManagerBackend) Fetch(path string) (map[string]string, error) {
+	creds, err := b.credentials.Retrieve()
+	if err != nil {
+		return nil, fmt.Errorf("no AWS credentials for Secrets Manager: %w", err)
+	}
+
+	region := b.secretRegion(path)
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_SECRETS_MANAGER")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+	}
+
+	payload, _ := json.Marshal(map[string]string{"SecretId": path})
+	req, err := http.NewRequest("POST", strings.TrimSuffix(endpoint, "/")+"/", strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Secrets Manager endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	req.Header.Set("User-Agent", "s3-bucket-tester/1.0")
+	signSecretsManager(req, string(payload), creds, region)
+
+	client := &http.Client{Timeout: b.timeout, Transport: &http.Transport{Proxy: b.proxy}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Secrets Manager unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr secretsManagerError
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Type != "" {
+			code := apiErr.Type[strings.LastIndex(apiErr.Type, "#")+1:]
+			return nil, fmt.Errorf("%s: %s", code, apiErr.Message)
+		}
+		return nil, fmt.Errorf("Secrets Manager returned HTTP %d", resp.StatusCode)
+	}
+
+	var secret struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("invalid GetSecretValue response: %w", err)
+	}
+	if secret.SecretString == nil {
+		return nil, fmt.Errorf("the secret is binary; only secret strings are supported")
+	}
+
+	var object map[string]interface{}
+	if json.Unmarshal([]byte(*secret.SecretString), &object) == nil {
+		return stringFields(object), nil
+	}
+	return map[string]string{"": *secret.SecretString}, nil
+}
+
+// secretRegion returns the region of an ARN reference, or else the
+// configured one
+func (b *SecretsManagerBackend) secretRegion(path string) string {
+	if parts := strings.SplitN(path, ":", 5); len(parts) == 5 && parts[0] == "arn" && parts[3] != "" {
+		return parts[3]
+	}
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := os.Getenv(name); region != "" {
+			return region
+		}
+	}
+	return b.region
+}
+
+// signSecretsManager adds AWS Signature Version 4 authentication for the
+// Secrets Manager service
+func signSecretsManager(req *http.Request, payload string, creds *credentials.Value, region string) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-date:%s\n",
+		req.Header.Get("Content-Type"), req.URL.Host, amzDate)
+	signedHeaders := "content-type;host;x-amz-date"
+	if creds.SessionToken != "" {
+		canonicalHeaders += fmt.Sprintf("x-amz-security-token:%s\n", creds.SessionToken)
+		signedHeaders += ";x-amz-security-token"
+	}
+	canonicalHeaders += fmt.Sprintf("x-amz-target:%s\n", req.Header.Get("X-Amz-Target"))
+	signedHeaders += ";x-amz-target"
+
+	payloadHash := sha256.Sum256([]byte(payload))
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	credentialScope := fmt.Sprintf("%s/%s/secretsmanager/aws4_request", dateStamp, region)
+	stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s\n%s",
+		amzDate,
+		credentialScope,
+		hex.EncodeToString(requestHash[:]))
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.SecretKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "secretsmanager")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKey,
+		credentialScope,
+		signedHeaders,
+		signature))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of the input with the key
+func hmacSHA256(key []byte, input string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,136 @@
+package secrets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Backend fetches secrets from a secret store. A secret is a set of named
+// fields, such as the keys of a Vault KV secret or of a JSON secret string;
+// a secret that is a single plain value has one field named "".
+type Backend interface {
+	// Scheme returns the URL scheme of the backend's references, such as
+	// "vault"
+	Scheme() string
+
+	// Fetch returns the fields of the secret at path
+	Fetch(path string) (map[string]string, error)
+}
+
+// Reference names a field of a secret: <scheme>://<path>#<field>. The field
+// may be left out when the secret has a single one.
+type Reference struct {
+	Scheme string
+	Path   string
+	Field  string
+}
+
+// String returns the reference in its <scheme>://<path>#<field> form
+func (r Reference) String() string {
+	s := r.Scheme + "://" + r.Path
+	if r.Field != "" {
+		s += "#" + r.Field
+	}
+	return s
+}
+
+// ParseReference splits a secret reference. The path is taken as is, so it
+// may hold colons and slashes, such as those of an ARN.
+func ParseReference(value string) (Reference, error) {
+	scheme, rest, ok := strings.Cut(value, "://")
+	if !ok || scheme == "" {
+		return Reference{}, fmt.Errorf("invalid secret reference %q: expected <scheme>://<path>#<field>", value)
+	}
+
+	ref := Reference{Scheme: scheme, Path: rest}
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		ref.Path, ref.Field = rest[:i], rest[i+1:]
+	}
+	if ref.Path == "" {
+		return Reference{}, fmt.Errorf("invalid secret reference %q: the path is empty", value)
+	}
+	return ref, nil
+}
+
+// Resolver replaces secret references with the values they name. Each secret
+// is fetched once, so the access key and secret key can come from the same
+// secret with a single request.
+type Resolver struct {
+	backends map[string]Backend
+	cache    map[string]map[string]string
+}
+
+// NewResolver creates a resolver for the references of the given backends
+func NewResolver(backends ...Backend) *Resolver {
+	r := &Resolver{
+		backends: make(map[string]Backend),
+		cache:    make(map[string]map[string]string),
+	}
+	for _, b := range backends {
+		r.backends[b.Scheme()] = b
+	}
+	return r
+}
+
+// IsReference reports whether value refers to a secret of one of the
+// resolver's backends rather than being a literal value
+func (r *Resolver) IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, "://")
+	return ok && r.backends[scheme] != nil
+}
+
+// Resolve returns the value a reference names
+func (r *Resolver) Resolve(value string) (string, error) {
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+	backend := r.backends[ref.Scheme]
+	if backend == nil {
+		return "", fmt.Errorf("unknown secrets backend %q", ref.Scheme)
+	}
+
+	key := ref.Scheme + "://" + ref.Path
+	fields, ok := r.cache[key]
+	if !ok {
+		fields, err = backend.Fetch(ref.Path)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", key, err)
+		}
+		r.cache[key] = fields
+	}
+
+	return field(key, fields, ref.Field)
+}
+
+// field picks a field of a secret. Without a field name, a secret with a
+// single field gives that one.
+func field(key string, fields map[string]string, name string) (string, error) {
+	if len(fields) == 0 {
+		return "", fmt.Errorf("%s is empty", key)
+	}
+	if name == "" && len(fields) == 1 {
+		for _, value := range fields {
+			return value, nil
+		}
+	}
+	if value, ok := fields[name]; ok && name != "" {
+		return value, nil
+	}
+
+	names := make([]string, 0, len(fields))
+	for n := range fields {
+		if n != "" {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return "", fmt.Errorf("%s is a plain value; remove #%s", key, name)
+	}
+	if name == "" {
+		return "", fmt.Errorf("%s has several fields, name one with #<field> (fields: %s)", key, strings.Join(names, ", "))
+	}
+	return "", fmt.Errorf("%s has no field %q (fields: %s)", key, name, strings.Join(names, ", "))
+}
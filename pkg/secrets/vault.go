@@ -0,0 +1,213 @@
+package secrets
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultVaultAddr is the address the Vault CLI uses when VAULT_ADDR is unset
+const defaultVaultAddr = "https://127.0.0.1:8200"
+
+// VaultBackend reads secrets from a HashiCorp Vault KV secrets engine,
+// version 1 or 2: vault://<mount>/<path>#<key>. It is configured like the
+// Vault CLI, through VAULT_ADDR, VAULT_TOKEN (or ~/.vault-token, as written by
+// vault login and Vault Agent), VAULT_NAMESPACE, VAULT_CACERT and
+// VAULT_SKIP_VERIFY.
+type VaultBackend struct {
+	proxy   func(*http.Request) (*url.URL, error)
+	timeout time.Duration
+}
+
+// NewVaultBackend creates a Vault backend connecting through proxy
+func NewVaultBackend(proxy func(*http.Request) (*url.URL, error), timeout time.Duration) *VaultBackend {
+	return &VaultBackend{proxy: proxy, timeout: timeout}
+}
+
+// Scheme returns the URL scheme of Vault references
+func (b *VaultBackend) Scheme() string {
+	return "vault"
+}
+
+// vaultResponse is the envelope of Vault API responses
+type vaultResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []string        `json:"errors"`
+}
+
+// vaultMount is the data of a sys/internal/ui/mounts lookup
+type vaultMount struct {
+	Path    string `json:"path"`
+	Options struct {
+		Version string `json:"version"`
+	} `json:"options"`
+}
+
+// Fetch reads the secret at path. The mount and KV version are looked up the
+// way the Vault CLI does; when the token may not do that, the first path
+// element is taken as the mount, trying version 2 before version 1.
+func (b *VaultBackend) Fetch(path string) (map[string]string, error) {
+	client, err := b.client()
+	if err != nil {
+		return nil, err
+	}
+	path = strings.Trim(path, "/")
+
+	var mount vaultMount
+	if b.get(client, "sys/internal/ui/mounts/"+path, &mount) == nil && mount.Path != "" {
+		rest := strings.TrimPrefix(path, strings.TrimSuffix(mount.Path, "/"))
+		if mount.Options.Version == "2" {
+			return b.readKV2(client, mount.Path+"data"+rest)
+		}
+		return b.readKV1(client, path)
+	}
+
+	mountPath, rest, ok := strings.Cut(path, "/")
+	if !ok {
+		return nil, fmt.Errorf("expected vault://<mount>/<path>")
+	}
+	fields, err := b.readKV2(client, mountPath+"/data/"+rest)
+	if errors.Is(err, errVaultNotFound) {
+		return b.readKV1(client, path)
+	}
+	return fields, err
+}
+
+// readKV2 reads a secret of a KV version 2 engine, whose data is nested
+func (b *VaultBackend) readKV2(client *http.Client, apiPath string) (map[string]string, error) {
+	var data struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := b.get(client, apiPath, &data); err != nil {
+		return nil, err
+	}
+	if data.Data == nil {
+		return nil, fmt.Errorf("the secret was deleted")
+	}
+	return stringFields(data.Data), nil
+}
+
+// readKV1 reads a secret of a KV version 1 engine
+func (b *VaultBackend) readKV1(client *http.Client, apiPath string) (map[string]string, error) {
+	var data map[string]interface{}
+	if err := b.get(client, apiPath, &data); err != nil {
+		return nil, err
+	}
+	return stringFields(data), nil
+}
+
+// errVaultNotFound is returned for a path Vault has nothing at
+var errVaultNotFound = errors.New("secret not found")
+
+// get reads a Vault API path and decodes the data of the response into v
+func (b *VaultBackend) get(client *http.Client, apiPath string, v interface{}) error {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		addr = defaultVaultAddr
+	}
+	token, err := vaultToken()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimSuffix(addr, "/")+"/v1/"+apiPath, nil)
+	if err != nil {
+		return fmt.Errorf("invalid VAULT_ADDR: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	req.Header.Set("X-Vault-Request", "true")
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var envelope vaultResponse
+	if err := json.Unmarshal(body, &envelope); err != nil && resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("invalid vault response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound && len(envelope.Errors) == 0:
+		return errVaultNotFound
+	case resp.StatusCode != http.StatusOK && len(envelope.Errors) > 0:
+		return fmt.Errorf("vault returned HTTP %d: %s", resp.StatusCode, strings.Join(envelope.Errors, "; "))
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("vault returned HTTP %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		return fmt.Errorf("invalid vault response: %w", err)
+	}
+	return nil
+}
+
+// client creates the HTTP client for Vault, trusting VAULT_CACERT if set
+func (b *VaultBackend) client() (*http.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: os.Getenv("VAULT_SKIP_VERIFY") == "true" || os.Getenv("VAULT_SKIP_VERIFY") == "1"}
+	if caCert := os.Getenv("VAULT_CACERT"); caCert != "" {
+		data, err := os.ReadFile(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read VAULT_CACERT: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no PEM certificates found in VAULT_CACERT %s", caCert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &http.Client{
+		Timeout: b.timeout,
+		Transport: &http.Transport{
+			Proxy:           b.proxy,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+// vaultToken returns VAULT_TOKEN, or the token in ~/.vault-token
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	home, err := os.UserHomeDir()
+	if err == nil {
+		if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil && len(strings.TrimSpace(string(data))) > 0 {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+	return "", fmt.Errorf("no vault token: set VAULT_TOKEN or run vault login")
+}
+
+// stringFields converts the values of a secret to strings. Values that are
+// not strings, such as numbers, are given in their JSON form.
+func stringFields(data map[string]interface{}) map[string]string {
+	fields := make(map[string]string, len(data))
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			fields[key] = s
+			continue
+		}
+		encoded, _ := json.Marshal(value)
+		fields[key] = string(encoded)
+	}
+	return fields
+}
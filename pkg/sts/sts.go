@@ -0,0 +1,327 @@
+package sts
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// apiVersion is the STS API version used for all requests
+	apiVersion = "2011-06-15"
+
+	// defaultDuration is the lifetime requested for temporary credentials;
+	// 15 minutes is the minimum STS accepts and is plenty for a test run
+	defaultDuration = 900
+)
+
+// Credentials holds temporary security credentials returned by STS
+type Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Expiration   time.Time
+}
+
+// AssumedRole describes the identity obtained by assuming a role
+type AssumedRole struct {
+	Arn           string
+	AssumedRoleID string
+	SessionName   string
+	Credentials   Credentials
+}
+
+// Client sends requests to an STS endpoint
+type Client struct {
+	Endpoint     string
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	httpClient   *http.Client
+}
+
+// NewClient creates a new STS client signing with the given credentials
+func NewClient(endpoint, region, accessKey, secretKey, sessionToken string, insecure bool, timeout time.Duration) *Client {
+	return &Client{
+		Endpoint:     endpoint,
+		Region:       region,
+		AccessKey:    accessKey,
+		SecretKey:    secretKey,
+		SessionToken: sessionToken,
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
+			},
+		},
+	}
+}
+
+// SetProxy overrides the proxy taken from HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+func (c *Client) SetProxy(proxy func(*http.Request) (*url.URL, error)) {
+	if transport, ok := c.httpClient.Transport.(*http.Transport); ok {
+		transport.Proxy = proxy
+	}
+}
+
+// SetDialNetwork restricts connections to a network such as "tcp4" or
+// "tcp6", to force an address family
+func (c *Client) SetDialNetwork(network string) {
+	if transport, ok := c.httpClient.Transport.(*http.Transport); ok {
+		dialer := &net.Dialer{Timeout: c.httpClient.Timeout}
+		transport.DialContext = func(ctx context.Context, _, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		}
+	}
+}
+
+// DefaultEndpoint returns the regional AWS STS endpoint
+func DefaultEndpoint(region string) string {
+	return fmt.Sprintf("https://sts.%s.amazonaws.com", region)
+}
+
+// assumeRoleResult is the result element shared by the AssumeRole* actions
+type assumeRoleResult struct {
+	Credentials struct {
+		AccessKeyID     string    `xml:"AccessKeyId"`
+		SecretAccessKey string    `xml:"SecretAccessKey"`
+		SessionToken    string    `xml:"SessionToken"`
+		Expiration      time.Time `xml:"Expiration"`
+	} `xml:"Credentials"`
+	AssumedRoleUser struct {
+		Arn           string `xml:"Arn"`
+		AssumedRoleID string `xml:"AssumedRoleId"`
+	} `xml:"AssumedRoleUser"`
+}
+
+// assumedRole converts the result into an AssumedRole
+func (r assumeRoleResult) assumedRole(sessionName string) (*AssumedRole, error) {
+	creds := r.Credentials
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("response contains no credentials")
+	}
+
+	return &AssumedRole{
+		Arn:           r.AssumedRoleUser.Arn,
+		AssumedRoleID: r.AssumedRoleUser.AssumedRoleID,
+		SessionName:   sessionName,
+		Credentials: Credentials{
+			AccessKey:    creds.AccessKeyID,
+			SecretKey:    creds.SecretAccessKey,
+			SessionToken: creds.SessionToken,
+			Expiration:   creds.Expiration,
+		},
+	}, nil
+}
+
+// errorResponse is the XML error response of STS
+type errorResponse struct {
+	Error struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+}
+
+// AssumeRole calls sts:AssumeRole and returns the temporary credentials
+func (c *Client) AssumeRole(roleArn, externalID, sessionName string) (*AssumedRole, error) {
+	form := url.Values{}
+	form.Set("Action", "AssumeRole")
+	form.Set("Version", apiVersion)
+	form.Set("RoleArn", roleArn)
+	form.Set("RoleSessionName", sessionName)
+	form.Set("DurationSeconds", fmt.Sprintf("%d", defaultDuration))
+	if externalID != "" {
+		form.Set("ExternalId", externalID)
+	}
+
+	body, err := c.post(form, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result assumeRoleResult `xml:"AssumeRoleResult"`
+	}
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid AssumeRole response: %w", err)
+	}
+
+	return resp.Result.assumedRole(sessionName)
+}
+
+// CallerIdentity describes the identity the credentials belong to
+type CallerIdentity struct {
+	Account string
+	Arn     string
+	UserID  string
+}
+
+// GetCallerIdentity calls sts:GetCallerIdentity, which needs no permissions
+// and cannot be denied by a policy
+func (c *Client) GetCallerIdentity() (*CallerIdentity, error) {
+	form := url.Values{}
+	form.Set("Action", "GetCallerIdentity")
+	form.Set("Version", apiVersion)
+
+	body, err := c.post(form, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result struct {
+			Account string `xml:"Account"`
+			Arn     string `xml:"Arn"`
+			UserID  string `xml:"UserId"`
+		} `xml:"GetCallerIdentityResult"`
+	}
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid GetCallerIdentity response: %w", err)
+	}
+	if resp.Result.Account == "" {
+		return nil, fmt.Errorf("response contains no account")
+	}
+
+	return &CallerIdentity{
+		Account: resp.Result.Account,
+		Arn:     resp.Result.Arn,
+		UserID:  resp.Result.UserID,
+	}, nil
+}
+
+// AssumeRoleWithWebIdentity exchanges an OIDC token for temporary credentials.
+// The call is not signed; the token itself authenticates the caller.
+func (c *Client) AssumeRoleWithWebIdentity(roleArn, sessionName, token string) (*AssumedRole, error) {
+	form := url.Values{}
+	form.Set("Action", "AssumeRoleWithWebIdentity")
+	form.Set("Version", apiVersion)
+	form.Set("RoleArn", roleArn)
+	form.Set("RoleSessionName", sessionName)
+	form.Set("WebIdentityToken", token)
+
+	body, err := c.post(form, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result assumeRoleResult `xml:"AssumeRoleWithWebIdentityResult"`
+	}
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid AssumeRoleWithWebIdentity response: %w", err)
+	}
+
+	return resp.Result.assumedRole(sessionName)
+}
+
+// post sends a form POST, signed unless anonymous, and returns the response
+// body of a successful call
+func (c *Client) post(form url.Values, signed bool) ([]byte, error) {
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid STS endpoint: %w", err)
+	}
+	if endpoint.Path == "" {
+		endpoint.Path = "/"
+	}
+
+	payload := form.Encode()
+	req, err := http.NewRequest("POST", endpoint.String(), strings.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	req.Header.Set("User-Agent", "s3-bucket-tester/1.0")
+	if signed {
+		c.sign(req, payload)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if xml.Unmarshal(body, &errResp) == nil && errResp.Error.Code != "" {
+			return nil, fmt.Errorf("%s: %s", errResp.Error.Code, errResp.Error.Message)
+		}
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+// sign adds AWS Signature Version 4 authentication for the STS service
+func (c *Client) sign(req *http.Request, payload string) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-date:%s\n",
+		req.Header.Get("Content-Type"), req.URL.Host, amzDate)
+	signedHeaders := "content-type;host;x-amz-date"
+	if c.SessionToken != "" {
+		canonicalHeaders += fmt.Sprintf("x-amz-security-token:%s\n", c.SessionToken)
+		signedHeaders += ";x-amz-security-token"
+	}
+
+	payloadHash := sha256.Sum256([]byte(payload))
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	credentialScope := fmt.Sprintf("%s/%s/sts/aws4_request", dateStamp, c.Region)
+	stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s\n%s",
+		amzDate,
+		credentialScope,
+		hex.EncodeToString(requestHash[:]))
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.SecretKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, c.Region)
+	signingKey = hmacSHA256(signingKey, "sts")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.AccessKey,
+		credentialScope,
+		signedHeaders,
+		signature))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of the input with the key
+func hmacSHA256(key []byte, input string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}